@@ -83,6 +83,9 @@ type ClusterNamespace string
 //     import the Service; and
 //   - the EndpointSliceExport controller to find out from annotations on a ServiceImport which member clusters
 //     have requested to import the Service.
+//
+// Deprecated: the member clusters which have imported a Service are now kept in the ImportedBy field of the
+// ServiceImport status; this object is kept only for migrating the existing annotations.
 type ServiceInUseBy struct {
 	MemberClusters map[ClusterNamespace]ClusterID
 }
@@ -60,6 +60,45 @@ type EndpointSliceExportSpec struct {
 	OwnerServiceReference OwnerServiceReference `json:"ownerServiceReference"`
 }
 
+// EndpointSliceExportConditionType identifies a specific condition on an EndpointSliceExport.
+type EndpointSliceExportConditionType string
+
+const (
+	// EndpointSliceExportDistributed means that the exported EndpointSlice has been distributed, as
+	// EndpointSliceImports, to all the member clusters which import its owner Service.
+	EndpointSliceExportDistributed EndpointSliceExportConditionType = "Distributed"
+)
+
+// EndpointSliceDistribution describes the distribution of an exported EndpointSlice to a specific member cluster.
+type EndpointSliceDistribution struct {
+	// clusterNamespace is the namespace reserved for the importing member cluster in the hub cluster.
+	ClusterNamespace ClusterNamespace `json:"clusterNamespace"`
+	// cluster is the ID of the importing member cluster.
+	Cluster ClusterID `json:"cluster"`
+	// distributed is whether the EndpointSliceImport has been created or updated in the cluster namespace.
+	Distributed bool `json:"distributed"`
+	// message is a human-readable message indicating details about the distribution.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// EndpointSliceExportStatus describes the observed state of an exported EndpointSlice.
+type EndpointSliceExportStatus struct {
+	// conditions describe the current state of the exported EndpointSlice.
+	// +optional
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+
+	// distributions is the per-cluster result of the latest distribution of the exported EndpointSlice.
+	// +optional
+	// +listType=map
+	// +listMapKey=clusterNamespace
+	Distributions []EndpointSliceDistribution `json:"distributions,omitempty"`
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:scope=Namespaced,categories={fleet-networking}
 // +kubebuilder:subresource:status
@@ -72,6 +111,8 @@ type EndpointSliceExport struct {
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 	// +kubebuilder:validation:Required
 	Spec EndpointSliceExportSpec `json:"spec"`
+	// +optional
+	Status EndpointSliceExportStatus `json:"status,omitempty"`
 }
 
 // +kubebuilder:object:root=true
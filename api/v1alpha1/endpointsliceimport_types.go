@@ -9,8 +9,47 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// EndpointSliceImportConditionType identifies a specific condition on an EndpointSliceImport.
+type EndpointSliceImportConditionType string
+
+const (
+	// EndpointSliceImportImported means that the distributed EndpointSlice has been imported into the member
+	// cluster.
+	EndpointSliceImportImported EndpointSliceImportConditionType = "Imported"
+	// EndpointSliceImportPending means that the member cluster cannot import the distributed EndpointSlice yet,
+	// e.g. the derived Service of the imported Service is not ready.
+	EndpointSliceImportPending EndpointSliceImportConditionType = "ImportPending"
+)
+
+// ImportedEndpointSliceReference points to an EndpointSlice in the member cluster which carries the endpoints
+// of an EndpointSliceImport.
+type ImportedEndpointSliceReference struct {
+	// The namespace of the imported EndpointSlice.
+	Namespace string `json:"namespace"`
+	// The name of the imported EndpointSlice.
+	Name string `json:"name"`
+}
+
+// EndpointSliceImportStatus describes the observed state of an EndpointSlice imported into a member cluster.
+type EndpointSliceImportStatus struct {
+	// conditions describe the current state of the imported EndpointSlice.
+	// +optional
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+
+	// importedEndpointSlices are the EndpointSlices in the member cluster which carry the endpoints of the
+	// EndpointSliceImport; there can be more than one when the member cluster aggregates imported endpoints.
+	// +optional
+	// +listType=atomic
+	ImportedEndpointSlices []ImportedEndpointSliceReference `json:"importedEndpointSlices,omitempty"`
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:scope=Namespaced,categories={fleet-networking}
+// +kubebuilder:subresource:status
 
 // EndpointSliceImport is a data transport type that hub cluster uses to distribute exported EndpointSlices
 // to member clusters.
@@ -20,6 +59,8 @@ type EndpointSliceImport struct {
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 	// +kubebuilder:validation:Required
 	Spec EndpointSliceExportSpec `json:"spec"`
+	// +optional
+	Status EndpointSliceImportStatus `json:"status,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -10,8 +10,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-// InternalServiceExportSpec specifies the spec of an exported Service; at this stage only the ports of an
-// exported Service are sync'd.
+// InternalServiceExportSpec specifies the spec of an exported Service; at this stage only the ports, the session
+// affinity settings and the allow-listed labels and annotations of an exported Service are sync'd.
 type InternalServiceExportSpec struct {
 	// A list of ports exposed by the exported Service.
 	// +listType=atomic
@@ -32,6 +32,20 @@ type InternalServiceExportSpec struct {
 	IsInternalLoadBalancer bool `json:"isInternalLoadBalancer,omitempty"`
 	// PublicIPResourceID is the Azure Resource URI of public IP. This is only applicable for Load Balancer type Services.
 	PublicIPResourceID *string `json:"externalIPResourceID,omitempty"`
+	// SessionAffinity is the session affinity setting of the exported Service.
+	// +optional
+	SessionAffinity corev1.ServiceAffinity `json:"sessionAffinity,omitempty"`
+	// SessionAffinityConfig is the session affinity configuration of the exported Service.
+	// +optional
+	SessionAffinityConfig *corev1.SessionAffinityConfig `json:"sessionAffinityConfig,omitempty"`
+	// ExportedLabels are the labels of the exported Service whose keys are allowed to be exported by the
+	// member agent.
+	// +optional
+	ExportedLabels map[string]string `json:"exportedLabels,omitempty"`
+	// ExportedAnnotations are the annotations of the exported Service whose keys are allowed to be exported
+	// by the member agent.
+	// +optional
+	ExportedAnnotations map[string]string `json:"exportedAnnotations,omitempty"`
 }
 
 // InternalServiceExportStatus contains the current status of an InternalServiceExport.
@@ -35,6 +35,11 @@ type InternalServiceImportSpec struct {
 	// The reference to the source ServiceImport.
 	// +kubebuilder:validation:Required
 	ServiceImportReference ExportedObjectReference `json:"serviceImportReference"`
+
+	// ClusterSelector selects the exporting member clusters whose endpoints are imported; it is copied from the
+	// MultiClusterService which imports the Service in the member cluster.
+	// +optional
+	ClusterSelector *ClusterSelector `json:"clusterSelector,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -14,6 +14,123 @@ import (
 type MultiClusterServiceSpec struct {
 	// ServiceImport is the reference to the Service with the same name exported in the member clusters.
 	ServiceImport ServiceImportRef `json:"serviceImport,omitempty"`
+
+	// ClusterSelector selects the exporting member clusters whose endpoints are imported; the endpoints from all
+	// the exporting member clusters are imported if it is not specified.
+	// +optional
+	ClusterSelector *ClusterSelector `json:"clusterSelector,omitempty"`
+
+	// TrafficDistribution configures how the traffic is distributed across the endpoints imported from the
+	// exporting member clusters; the traffic is distributed evenly across all the imported endpoints if it is not
+	// specified.
+	// +optional
+	TrafficDistribution *TrafficDistribution `json:"trafficDistribution,omitempty"`
+
+	// DerivedService configures the Service derived from the multi-cluster service in the fleet system namespace,
+	// which exposes the imported endpoints; a LoadBalancer Service is derived if it is not specified.
+	// +optional
+	DerivedService *DerivedServiceSpec `json:"derivedService,omitempty"`
+}
+
+// DerivedServiceSpec configures the Service derived from a multi-cluster service.
+type DerivedServiceSpec struct {
+	// Type is the type of the derived Service; it is one of ClusterIP, NodePort and LoadBalancer.
+	// +kubebuilder:validation:Enum=ClusterIP;NodePort;LoadBalancer
+	// +kubebuilder:default=LoadBalancer
+	// +optional
+	Type corev1.ServiceType `json:"type,omitempty"`
+
+	// Annotations are added to the derived Service, e.g. the annotations configuring the load balancer of the cloud
+	// provider, such as its resource group, public IP name, DNS label or health probe paths.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// LoadBalancerClass is the class of the load balancer implementation of the derived Service; it only applies to
+	// the LoadBalancer type.
+	// +optional
+	LoadBalancerClass *string `json:"loadBalancerClass,omitempty"`
+
+	// ExternalTrafficPolicy describes how nodes distribute the external traffic they receive on the externally-facing
+	// addresses of the derived Service; it is one of Cluster and Local.
+	// +kubebuilder:validation:Enum=Cluster;Local
+	// +optional
+	ExternalTrafficPolicy corev1.ServiceExternalTrafficPolicy `json:"externalTrafficPolicy,omitempty"`
+
+	// LoadBalancerSourceRanges restricts the client IPs allowed to access the load balancer of the derived Service;
+	// it only applies to the LoadBalancer type.
+	// +optional
+	// +listType=atomic
+	LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges,omitempty"`
+
+	// AllocateLoadBalancerNodePorts defines if node ports are allocated for the derived Service; it only applies to
+	// the LoadBalancer type, and node ports are allocated if it is not specified.
+	// +optional
+	AllocateLoadBalancerNodePorts *bool `json:"allocateLoadBalancerNodePorts,omitempty"`
+}
+
+// ClusterSelector selects member clusters either by their names or by the labels on their MemberCluster objects
+// in the hub cluster. A member cluster is selected if it is listed in ClusterNames or its labels match
+// LabelSelector; no member cluster is selected if both are empty.
+type ClusterSelector struct {
+	// LabelSelector selects member clusters by the labels on their MemberCluster objects.
+	// +optional
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+
+	// ClusterNames are the names of the selected member clusters.
+	// +optional
+	// +listType=set
+	ClusterNames []string `json:"clusterNames,omitempty"`
+}
+
+// TrafficDistributionMode identifies how the traffic is distributed across the exporting member clusters.
+// +enum
+type TrafficDistributionMode string
+
+const (
+	// TrafficDistributionModeEven distributes the traffic evenly across all the imported endpoints, so that each
+	// exporting member cluster receives a share proportional to the number of its endpoints.
+	TrafficDistributionModeEven TrafficDistributionMode = "Even"
+	// TrafficDistributionModeWeighted distributes the traffic across the exporting member clusters by their weights.
+	TrafficDistributionModeWeighted TrafficDistributionMode = "Weighted"
+	// TrafficDistributionModePreferLocal sends the traffic to the endpoints exported by the importing member cluster
+	// itself, and fails over to the endpoints exported by other member clusters only when the importing member cluster
+	// has no ready endpoints.
+	TrafficDistributionModePreferLocal TrafficDistributionMode = "PreferLocal"
+)
+
+// TrafficDistribution configures how the traffic is distributed across the exporting member clusters.
+//
+// Kubernetes load-balances evenly across the endpoints of a Service; the weights are therefore enforced by importing
+// only a subset of the endpoints from each exporting member cluster: weight*k endpoints are imported from each member
+// cluster, with the largest scale k for which every member cluster has enough endpoints. At least weight endpoints
+// are imported from each member cluster (k >= 1), or all of its endpoints if it has fewer, in which case the traffic
+// is split only approximately by the weights.
+type TrafficDistribution struct {
+	// Mode is the traffic distribution mode; it is one of Even, Weighted and PreferLocal.
+	// +kubebuilder:validation:Enum=Even;Weighted;PreferLocal
+	// +kubebuilder:default=Even
+	// +optional
+	Mode TrafficDistributionMode `json:"mode,omitempty"`
+
+	// ClusterWeights are the weights of the exporting member clusters in the Weighted mode; a member cluster that is
+	// not listed has the weight of 1, and no traffic is sent to a member cluster with the weight of 0.
+	// +optional
+	// +listType=map
+	// +listMapKey=cluster
+	ClusterWeights []ClusterWeight `json:"clusterWeights,omitempty"`
+}
+
+// ClusterWeight is the weight of an exporting member cluster.
+type ClusterWeight struct {
+	// Cluster is the name of the exporting member cluster.
+	// +required
+	Cluster string `json:"cluster"`
+
+	// Weight is the relative weight of the member cluster.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=1000
+	// +required
+	Weight int32 `json:"weight"`
 }
 
 // ServiceImportRef is the reference to the ServiceImport. To consume multi-cluster service, users are expected to use
@@ -42,6 +159,14 @@ type MultiClusterServiceStatus struct {
 	// +listType=map
 	// +listMapKey=type
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+
+	// Clusters are the exporting member clusters whose endpoints are included in the multi-cluster service.
+	// +optional
+	// +patchStrategy=merge
+	// +patchMergeKey=cluster
+	// +listType=map
+	// +listMapKey=cluster
+	Clusters []ClusterStatus `json:"clusters,omitempty" patchStrategy:"merge" patchMergeKey:"cluster"`
 }
 
 // MultiClusterServiceConditionType identifies a specific condition.
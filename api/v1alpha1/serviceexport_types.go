@@ -21,6 +21,13 @@ const (
 	// field(s) under contention, which cluster won, and why.
 	// Users should not expect detailed per-cluster information in the conflict message.
 	ServiceExportConflict ServiceExportConditionType = "Conflict"
+	// ServiceExportAuthorized means that the member cluster is allowed to export the Service by the
+	// ServiceExportImportPolicies in the hub cluster.
+	// When "False", the exported Service is not accepted as a backend of the imported service.
+	ServiceExportAuthorized ServiceExportConditionType = "Authorized"
+	// ServiceExportStale means that the member cluster has stopped sending heartbeats to the hub cluster.
+	// When "True", the exported Service is withdrawn from the imported service until the heartbeats resume.
+	ServiceExportStale ServiceExportConditionType = "Stale"
 )
 
 // ServiceExportStatus contains the current status of an export.
@@ -0,0 +1,68 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ServiceExportImportPolicySpec specifies which member clusters may export or import the Services in a namespace.
+type ServiceExportImportPolicySpec struct {
+	// ServiceNames are the names of the Services, in the namespace of the policy, which the policy applies to;
+	// the policy applies to all the Services in the namespace if it is empty.
+	// +optional
+	// +listType=set
+	ServiceNames []string `json:"serviceNames,omitempty"`
+	// Export restricts which member clusters may export the Services; any member cluster may export the Services
+	// if it is not specified.
+	// +optional
+	Export *ClusterAccessRule `json:"export,omitempty"`
+	// Import restricts which member clusters may import the Services; any member cluster may import the Services
+	// if it is not specified.
+	// +optional
+	Import *ClusterAccessRule `json:"import,omitempty"`
+}
+
+// ClusterAccessRule lists the member clusters which are allowed to perform an operation.
+type ClusterAccessRule struct {
+	// AllowedClusters are the names of the member clusters which are allowed to perform the operation; no member
+	// cluster is allowed if it is empty.
+	// +optional
+	// +listType=set
+	AllowedClusters []string `json:"allowedClusters,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,categories={fleet-networking},shortName=svcpolicy
+// +kubebuilder:printcolumn:JSONPath=`.metadata.creationTimestamp`,name="Age",type=date
+
+// ServiceExportImportPolicy is a hub cluster resource which authorizes member clusters to export Services to, or
+// import Services from, the fleet. A member cluster is allowed to export (import) a Service only if every policy
+// which applies to the Service allows it; the Services to which no policy applies can be exported and imported by
+// any member cluster.
+type ServiceExportImportPolicy struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +required
+	Spec ServiceExportImportPolicySpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// ServiceExportImportPolicyList contains a list of ServiceExportImportPolicy.
+type ServiceExportImportPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	// +listType=set
+	Items []ServiceExportImportPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ServiceExportImportPolicy{}, &ServiceExportImportPolicyList{})
+}
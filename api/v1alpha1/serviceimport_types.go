@@ -37,6 +37,34 @@ const (
 	Headless ServiceImportType = "Headless"
 )
 
+// ServiceImportConditionType identifies a specific condition on a ServiceImport.
+type ServiceImportConditionType string
+
+const (
+	// ServiceImportReady means that the service spec has been resolved from at least one exporting cluster and
+	// the service can be imported.
+	ServiceImportReady ServiceImportConditionType = "Ready"
+)
+
+// ClusterExportState is the state of a service exported from a cluster.
+type ClusterExportState string
+
+const (
+	// ClusterExportStateAccepted means that the exported service has been accepted as a backend of the
+	// imported service.
+	ClusterExportStateAccepted ClusterExportState = "Accepted"
+	// ClusterExportStateConflicted means that the exported service is in conflict with the resolved service spec.
+	ClusterExportStateConflicted ClusterExportState = "Conflicted"
+	// ClusterExportStatePending means that the exported service has not been processed yet.
+	ClusterExportStatePending ClusterExportState = "Pending"
+	// ClusterExportStateRejected means that the cluster is not allowed to export the service by the
+	// ServiceExportImportPolicies.
+	ClusterExportStateRejected ClusterExportState = "Rejected"
+	// ClusterExportStateStale means that the exported service is withdrawn as the cluster has stopped sending
+	// heartbeats to the hub cluster.
+	ClusterExportStateStale ClusterExportState = "Stale"
+)
+
 // ServicePort represents the port on which the service is exposed.
 type ServicePort struct {
 	// The name of this port within the service. This must be a DNS_LABEL.
@@ -110,6 +138,30 @@ type ServiceImportStatus struct {
 	// +optional
 	Ports []ServicePort `json:"ports,omitempty"`
 
+	// exportedLabels are the labels exported with the service; all the exporting clusters must agree on
+	// them.
+	// +optional
+	ExportedLabels map[string]string `json:"exportedLabels,omitempty"`
+	// exportedAnnotations are the annotations exported with the service; all the exporting clusters must
+	// agree on them.
+	// +optional
+	ExportedAnnotations map[string]string `json:"exportedAnnotations,omitempty"`
+
+	// conditions describe the current state of the imported service.
+	// +optional
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+
+	// clusterExports is the status of the service exported from every cluster with the same namespaced name,
+	// including the ones which are not accepted.
+	// +optional
+	// +listType=map
+	// +listMapKey=cluster
+	ClusterExports []ClusterExportStatus `json:"clusterExports,omitempty"`
+
 	// clusters is the list of exporting clusters from which this service was derived.
 	// +optional
 	// +patchStrategy=merge
@@ -117,6 +169,13 @@ type ServiceImportStatus struct {
 	// +listType=map
 	// +listMapKey=cluster
 	Clusters []ClusterStatus `json:"clusters,omitempty"`
+
+	// importedBy is the list of member clusters which have imported the service. At this moment, a service can
+	// only be imported by one member cluster across the fleet.
+	// +optional
+	// +listType=map
+	// +listMapKey=clusterNamespace
+	ImportedBy []ClusterImportStatus `json:"importedBy,omitempty"`
 }
 
 // ClusterStatus contains service configuration mapped to a specific source cluster.
@@ -125,6 +184,31 @@ type ClusterStatus struct {
 	Cluster string `json:"cluster"`
 }
 
+// ClusterExportStatus describes the state of a service exported from a specific cluster.
+type ClusterExportStatus struct {
+	// cluster is the name of the exporting cluster.
+	Cluster string `json:"cluster"`
+	// state is the state of the exported service; it is one of Accepted, Conflicted, Pending, Rejected and Stale.
+	// +kubebuilder:validation:Enum=Accepted;Conflicted;Pending;Rejected;Stale
+	State ClusterExportState `json:"state"`
+	// reason is a brief CamelCase reason for the state.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// message is a human-readable message indicating details about the state.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ClusterImportStatus describes a member cluster which has imported a service.
+type ClusterImportStatus struct {
+	// clusterNamespace is the namespace reserved for the importing member cluster in the hub cluster.
+	ClusterNamespace ClusterNamespace `json:"clusterNamespace"`
+	// cluster is the ID of the importing member cluster.
+	Cluster ClusterID `json:"cluster"`
+	// importedSince is the time when the member cluster imported the service.
+	ImportedSince metav1.Time `json:"importedSince"`
+}
+
 // +kubebuilder:object:root=true
 
 // ServiceImportList contains a list of ServiceImport.
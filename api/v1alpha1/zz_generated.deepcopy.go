@@ -16,6 +16,82 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterAccessRule) DeepCopyInto(out *ClusterAccessRule) {
+	*out = *in
+	if in.AllowedClusters != nil {
+		in, out := &in.AllowedClusters, &out.AllowedClusters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterAccessRule.
+func (in *ClusterAccessRule) DeepCopy() *ClusterAccessRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterAccessRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterExportStatus) DeepCopyInto(out *ClusterExportStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterExportStatus.
+func (in *ClusterExportStatus) DeepCopy() *ClusterExportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterExportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterImportStatus) DeepCopyInto(out *ClusterImportStatus) {
+	*out = *in
+	in.ImportedSince.DeepCopyInto(&out.ImportedSince)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterImportStatus.
+func (in *ClusterImportStatus) DeepCopy() *ClusterImportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterImportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterSelector) DeepCopyInto(out *ClusterSelector) {
+	*out = *in
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClusterNames != nil {
+		in, out := &in.ClusterNames, &out.ClusterNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterSelector.
+func (in *ClusterSelector) DeepCopy() *ClusterSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterStatus) DeepCopyInto(out *ClusterStatus) {
 	*out = *in
@@ -31,6 +107,58 @@ func (in *ClusterStatus) DeepCopy() *ClusterStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterWeight) DeepCopyInto(out *ClusterWeight) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterWeight.
+func (in *ClusterWeight) DeepCopy() *ClusterWeight {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterWeight)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DerivedServiceSpec) DeepCopyInto(out *DerivedServiceSpec) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LoadBalancerClass != nil {
+		in, out := &in.LoadBalancerClass, &out.LoadBalancerClass
+		*out = new(string)
+		**out = **in
+	}
+	if in.LoadBalancerSourceRanges != nil {
+		in, out := &in.LoadBalancerSourceRanges, &out.LoadBalancerSourceRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllocateLoadBalancerNodePorts != nil {
+		in, out := &in.AllocateLoadBalancerNodePorts, &out.AllocateLoadBalancerNodePorts
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DerivedServiceSpec.
+func (in *DerivedServiceSpec) DeepCopy() *DerivedServiceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DerivedServiceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Endpoint) DeepCopyInto(out *Endpoint) {
 	*out = *in
@@ -51,12 +179,28 @@ func (in *Endpoint) DeepCopy() *Endpoint {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EndpointSliceDistribution) DeepCopyInto(out *EndpointSliceDistribution) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointSliceDistribution.
+func (in *EndpointSliceDistribution) DeepCopy() *EndpointSliceDistribution {
+	if in == nil {
+		return nil
+	}
+	out := new(EndpointSliceDistribution)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EndpointSliceExport) DeepCopyInto(out *EndpointSliceExport) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointSliceExport.
@@ -140,12 +284,40 @@ func (in *EndpointSliceExportSpec) DeepCopy() *EndpointSliceExportSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EndpointSliceExportStatus) DeepCopyInto(out *EndpointSliceExportStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Distributions != nil {
+		in, out := &in.Distributions, &out.Distributions
+		*out = make([]EndpointSliceDistribution, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointSliceExportStatus.
+func (in *EndpointSliceExportStatus) DeepCopy() *EndpointSliceExportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EndpointSliceExportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EndpointSliceImport) DeepCopyInto(out *EndpointSliceImport) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointSliceImport.
@@ -198,6 +370,33 @@ func (in *EndpointSliceImportList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EndpointSliceImportStatus) DeepCopyInto(out *EndpointSliceImportStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ImportedEndpointSlices != nil {
+		in, out := &in.ImportedEndpointSlices, &out.ImportedEndpointSlices
+		*out = make([]ImportedEndpointSliceReference, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointSliceImportStatus.
+func (in *EndpointSliceImportStatus) DeepCopy() *EndpointSliceImportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EndpointSliceImportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExportedObjectReference) DeepCopyInto(out *ExportedObjectReference) {
 	*out = *in
@@ -214,6 +413,21 @@ func (in *ExportedObjectReference) DeepCopy() *ExportedObjectReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImportedEndpointSliceReference) DeepCopyInto(out *ImportedEndpointSliceReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImportedEndpointSliceReference.
+func (in *ImportedEndpointSliceReference) DeepCopy() *ImportedEndpointSliceReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ImportedEndpointSliceReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InternalServiceExport) DeepCopyInto(out *InternalServiceExport) {
 	*out = *in
@@ -289,6 +503,25 @@ func (in *InternalServiceExportSpec) DeepCopyInto(out *InternalServiceExportSpec
 		*out = new(string)
 		**out = **in
 	}
+	if in.SessionAffinityConfig != nil {
+		in, out := &in.SessionAffinityConfig, &out.SessionAffinityConfig
+		*out = new(corev1.SessionAffinityConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExportedLabels != nil {
+		in, out := &in.ExportedLabels, &out.ExportedLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExportedAnnotations != nil {
+		in, out := &in.ExportedAnnotations, &out.ExportedAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternalServiceExportSpec.
@@ -386,6 +619,11 @@ func (in *InternalServiceImportList) DeepCopyObject() runtime.Object {
 func (in *InternalServiceImportSpec) DeepCopyInto(out *InternalServiceImportSpec) {
 	*out = *in
 	in.ServiceImportReference.DeepCopyInto(&out.ServiceImportReference)
+	if in.ClusterSelector != nil {
+		in, out := &in.ClusterSelector, &out.ClusterSelector
+		*out = new(ClusterSelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternalServiceImportSpec.
@@ -448,7 +686,7 @@ func (in *MultiClusterService) DeepCopyInto(out *MultiClusterService) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -506,6 +744,21 @@ func (in *MultiClusterServiceList) DeepCopyObject() runtime.Object {
 func (in *MultiClusterServiceSpec) DeepCopyInto(out *MultiClusterServiceSpec) {
 	*out = *in
 	out.ServiceImport = in.ServiceImport
+	if in.ClusterSelector != nil {
+		in, out := &in.ClusterSelector, &out.ClusterSelector
+		*out = new(ClusterSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TrafficDistribution != nil {
+		in, out := &in.TrafficDistribution, &out.TrafficDistribution
+		*out = new(TrafficDistribution)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DerivedService != nil {
+		in, out := &in.DerivedService, &out.DerivedService
+		*out = new(DerivedServiceSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiClusterServiceSpec.
@@ -529,6 +782,11 @@ func (in *MultiClusterServiceStatus) DeepCopyInto(out *MultiClusterServiceStatus
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]ClusterStatus, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiClusterServiceStatus.
@@ -582,6 +840,94 @@ func (in *ServiceExport) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceExportImportPolicy) DeepCopyInto(out *ServiceExportImportPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceExportImportPolicy.
+func (in *ServiceExportImportPolicy) DeepCopy() *ServiceExportImportPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceExportImportPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ServiceExportImportPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceExportImportPolicyList) DeepCopyInto(out *ServiceExportImportPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ServiceExportImportPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceExportImportPolicyList.
+func (in *ServiceExportImportPolicyList) DeepCopy() *ServiceExportImportPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceExportImportPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ServiceExportImportPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceExportImportPolicySpec) DeepCopyInto(out *ServiceExportImportPolicySpec) {
+	*out = *in
+	if in.ServiceNames != nil {
+		in, out := &in.ServiceNames, &out.ServiceNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Export != nil {
+		in, out := &in.Export, &out.Export
+		*out = new(ClusterAccessRule)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Import != nil {
+		in, out := &in.Import, &out.Import
+		*out = new(ClusterAccessRule)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceExportImportPolicySpec.
+func (in *ServiceExportImportPolicySpec) DeepCopy() *ServiceExportImportPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceExportImportPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceExportList) DeepCopyInto(out *ServiceExportList) {
 	*out = *in
@@ -729,11 +1075,44 @@ func (in *ServiceImportStatus) DeepCopyInto(out *ServiceImportStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ExportedLabels != nil {
+		in, out := &in.ExportedLabels, &out.ExportedLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExportedAnnotations != nil {
+		in, out := &in.ExportedAnnotations, &out.ExportedAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ClusterExports != nil {
+		in, out := &in.ClusterExports, &out.ClusterExports
+		*out = make([]ClusterExportStatus, len(*in))
+		copy(*out, *in)
+	}
 	if in.Clusters != nil {
 		in, out := &in.Clusters, &out.Clusters
 		*out = make([]ClusterStatus, len(*in))
 		copy(*out, *in)
 	}
+	if in.ImportedBy != nil {
+		in, out := &in.ImportedBy, &out.ImportedBy
+		*out = make([]ClusterImportStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceImportStatus.
@@ -789,6 +1168,26 @@ func (in *ServicePort) DeepCopy() *ServicePort {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrafficDistribution) DeepCopyInto(out *TrafficDistribution) {
+	*out = *in
+	if in.ClusterWeights != nil {
+		in, out := &in.ClusterWeights, &out.ClusterWeights
+		*out = make([]ClusterWeight, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrafficDistribution.
+func (in *TrafficDistribution) DeepCopy() *TrafficDistribution {
+	if in == nil {
+		return nil
+	}
+	out := new(TrafficDistribution)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TrafficManagerBackend) DeepCopyInto(out *TrafficManagerBackend) {
 	*out = *in
@@ -0,0 +1,157 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Binary fleetnetctl inspects the multi-cluster services and the Traffic Manager resources of a fleet from the hub
+// cluster, to triage them without reading the controller logs.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
+	// to ensure that the CLI can authenticate with the hub cluster the same way kubectl does.
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/fleetnetctl"
+)
+
+const usage = `fleetnetctl inspects the multi-cluster services of a fleet from the hub cluster.
+
+Usage:
+  fleetnetctl [flags] status <namespace>/<service>            Show the exports, conflicts, imports and endpoints of a service
+  fleetnetctl [flags] explain-conflict <namespace>/<service>  Show how the exports of a service differ from the resolved spec
+  fleetnetctl [flags] endpoints <namespace>/<service>         List the endpoints of a service by exporting cluster
+  fleetnetctl [flags] tm status <namespace>/<profile>         Show a TrafficManagerProfile, its backends and endpoints
+
+Flags:
+`
+
+var (
+	scheme = runtime.NewScheme()
+
+	timeout = flag.Duration("timeout", 30*time.Second, "How long to wait for the objects to be read from the hub cluster.")
+)
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(fleetnetv1alpha1.AddToScheme(scheme))
+	klog.InitFlags(nil)
+}
+
+// command is a fleetnetctl command run against a service or a TrafficManagerProfile.
+type command struct {
+	run func(ctx context.Context, inspector *fleetnetctl.Inspector, name types.NamespacedName) error
+}
+
+var commands = map[string]command{
+	"status": {
+		run: func(ctx context.Context, inspector *fleetnetctl.Inspector, name types.NamespacedName) error {
+			return inspector.Status(ctx, name)
+		},
+	},
+	"explain-conflict": {
+		run: func(ctx context.Context, inspector *fleetnetctl.Inspector, name types.NamespacedName) error {
+			return inspector.ExplainConflict(ctx, name)
+		},
+	},
+	"endpoints": {
+		run: func(ctx context.Context, inspector *fleetnetctl.Inspector, name types.NamespacedName) error {
+			return inspector.Endpoints(ctx, name)
+		},
+	},
+	"tm status": {
+		run: func(ctx context.Context, inspector *fleetnetctl.Inspector, name types.NamespacedName) error {
+			return inspector.TrafficManagerStatus(ctx, name)
+		},
+	},
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	// The logs of the hub client are only of interest when debugging the CLI itself, with -v.
+	ctrl.SetLogger(klog.NewKlogr())
+
+	if err := run(flag.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	cmd, name, err := parseArgs(args)
+	if err != nil {
+		flag.Usage()
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	hubClient, err := newHubClient()
+	if err != nil {
+		return err
+	}
+	inspector := &fleetnetctl.Inspector{HubClient: hubClient, Out: os.Stdout}
+	if err := cmd.run(ctx, inspector, name); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("%w; the hub cluster may be unreachable, or the objects take longer than -timeout to read", err)
+		}
+		return err
+	}
+	return nil
+}
+
+// parseArgs returns the command and the namespaced name it is run against.
+func parseArgs(args []string) (command, types.NamespacedName, error) {
+	if len(args) == 0 {
+		return command{}, types.NamespacedName{}, errors.New("no command is specified")
+	}
+	cmdName, args := args[0], args[1:]
+	if cmdName == "tm" && len(args) > 0 {
+		cmdName, args = cmdName+" "+args[0], args[1:]
+	}
+	cmd, ok := commands[cmdName]
+	if !ok {
+		return command{}, types.NamespacedName{}, fmt.Errorf("unknown command %q", cmdName)
+	}
+	if len(args) != 1 {
+		return command{}, types.NamespacedName{}, fmt.Errorf("command %q takes exactly one <namespace>/<name> argument", cmdName)
+	}
+	name, err := fleetnetctl.ParseNamespacedName(args[0])
+	if err != nil {
+		return command{}, types.NamespacedName{}, err
+	}
+	return cmd, name, nil
+}
+
+// newHubClient returns a client which reads the objects from the hub cluster directly; a command reads a handful
+// of objects once, which does not pay off the cost of caching every object of the types it reads.
+func newHubClient() (client.Reader, error) {
+	hubConfig, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the hub cluster config: %w", err)
+	}
+	hubClient, err := client.New(hubConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the hub cluster client: %w", err)
+	}
+	return hubClient, nil
+}
@@ -8,6 +8,7 @@ Licensed under the MIT license.
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
 	"time"
@@ -24,6 +25,7 @@ import (
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
@@ -32,10 +34,18 @@ import (
 	"go.goms.io/fleet/pkg/utils"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/clustersetip"
+	"go.goms.io/fleet-networking/pkg/common/events"
+	"go.goms.io/fleet-networking/pkg/common/gc"
+	"go.goms.io/fleet-networking/pkg/common/health"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+	"go.goms.io/fleet-networking/pkg/common/tracing"
 	"go.goms.io/fleet-networking/pkg/controllers/hub/endpointsliceexport"
+	hubgc "go.goms.io/fleet-networking/pkg/controllers/hub/gc"
 	"go.goms.io/fleet-networking/pkg/controllers/hub/internalserviceexport"
 	"go.goms.io/fleet-networking/pkg/controllers/hub/internalserviceimport"
 	"go.goms.io/fleet-networking/pkg/controllers/hub/membercluster"
+	"go.goms.io/fleet-networking/pkg/controllers/hub/memberhealth"
 	"go.goms.io/fleet-networking/pkg/controllers/hub/serviceimport"
 )
 
@@ -59,6 +69,19 @@ var (
 
 	enableTrafficManagerFeature = flag.Bool("enable-traffic-manager-feature", false, "If set, the traffic manager feature will be enabled.")
 
+	memberHeartbeatGracePeriod = flag.Duration("member-heartbeat-grace-period", 5*time.Minute, "The duration the hub agent waits after the last heartbeat of the networking agents of a member cluster before it withdraws the services exported from the member cluster. If set to 0, the member health check is disabled.")
+
+	gcInterval = flag.Duration("gc-interval", 10*time.Minute, "The interval between two garbage collection sweeps for orphaned fleet networking objects. If set to 0, garbage collection is disabled.")
+	gcDryRun   = flag.Bool("gc-dry-run", false, "If set, the garbage collection sweeper only reports the orphaned fleet networking objects, without deleting them.")
+
+	reconcileStallTimeout = flag.Duration("reconcile-stall-timeout", 15*time.Minute, "How long a single reconcile may run before the controller manager is reported as unhealthy. If set to 0, the check is disabled.")
+
+	tracingOTLPEndpoint  = flag.String("tracing-otlp-endpoint", "", "The host:port of the OTLP/HTTP endpoint to which the traces of the propagation of exported services are sent. If empty, tracing is disabled.")
+	tracingOTLPInsecure  = flag.Bool("tracing-otlp-insecure", false, "If set, the traces are sent to the OTLP endpoint without TLS.")
+	tracingSamplingRatio = flag.Float64("tracing-sampling-ratio", 1, "The ratio of the exports traced, between 0 and 1; the traces continued from another cluster follow the sampling decision of the cluster which started them.")
+
+	clusterSetIPCIDR = flag.String("clusterset-ip-cidr", "", "The IPv4 CIDR from which ClusterSetIPs are allocated to ServiceImports; it must be within the service CIDR of every member cluster and must not be used by other Services. If empty, ClusterSetIPs will not be allocated.")
+
 	// cloudConfigFile = flag.String("cloud-config", "/etc/kubernetes/provider/azure.json", "The path to the cloud config file which will be used to access the Azure resource.")
 )
 
@@ -117,20 +140,59 @@ func main() {
 
 	//+kubebuilder:scaffold:builder
 
-	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
+	if err := mgr.AddHealthzCheck("healthz", health.Instrument(health.CheckTypeHealthz, "healthz", healthz.Ping)); err != nil {
 		klog.ErrorS(err, "Unable to set up health check")
 		exitWithErrorFunc()
 	}
-	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
-		klog.ErrorS(err, "Unable to set up ready check")
+	if *reconcileStallTimeout > 0 {
+		if err := mgr.AddHealthzCheck("reconcile-stall", health.Instrument(health.CheckTypeHealthz, "reconcile-stall", health.ReconcileStallCheck(*reconcileStallTimeout))); err != nil {
+			klog.ErrorS(err, "Unable to set up reconcile stall check")
+			exitWithErrorFunc()
+		}
+	}
+	if err := mgr.AddReadyzCheck("cache-sync", health.Instrument(health.CheckTypeReadyz, "cache-sync", health.CacheSyncCheck(mgr.GetCache()))); err != nil {
+		klog.ErrorS(err, "Unable to set up cache sync check")
 		exitWithErrorFunc()
 	}
 
 	ctx := ctrl.SetupSignalHandler()
 
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Options{
+		ServiceName:   "hub-net-controller-manager",
+		OTLPEndpoint:  *tracingOTLPEndpoint,
+		OTLPInsecure:  *tracingOTLPInsecure,
+		SamplingRatio: *tracingSamplingRatio,
+	})
+	if err != nil {
+		klog.ErrorS(err, "Unable to set up tracing")
+		exitWithErrorFunc()
+	}
+	defer func() {
+		// Flush the recorded spans; the signal handler context is done at this point.
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			klog.ErrorS(err, "Failed to shut down tracing")
+		}
+	}()
+
+	// The deprecated ServiceInUseBy annotations are migrated by the leader; the InternalServiceImport controller
+	// waits for the migration to complete.
+	migrator := internalserviceimport.NewMigrator(mgr.GetClient())
+	if err := mgr.Add(migrator); err != nil {
+		klog.ErrorS(err, "Unable to set up the ServiceInUseBy annotation migration")
+		exitWithErrorFunc()
+	}
+
+	discoverClient := discovery.NewDiscoveryClientForConfigOrDie(hubConfig)
+	memberClusterGVK := clusterv1beta1.GroupVersion.WithKind(clusterv1beta1.MemberClusterKind)
+	isMemberClusterInstalled := *enableV1Beta1APIs && utils.CheckCRDInstalled(discoverClient, memberClusterGVK) == nil
+
 	klog.V(1).InfoS("Start to setup EndpointsliceExport controller")
 	if err := (&endpointsliceexport.Reconciler{
-		HubClient: mgr.GetClient(),
+		HubClient:           mgr.GetClient(),
+		WatchMemberClusters: isMemberClusterInstalled,
+		Recorder:            events.NewRecorder(mgr.GetEventRecorderFor(endpointsliceexport.ControllerName)),
 	}).SetupWithManager(ctx, mgr); err != nil {
 		klog.ErrorS(err, "Unable to create EndpointsliceExport controller")
 		exitWithErrorFunc()
@@ -140,43 +202,76 @@ func main() {
 	if err := (&internalserviceexport.Reconciler{
 		Client:        mgr.GetClient(),
 		RetryInternal: *internalServiceExportRetryInterval,
-	}).SetupWithManager(mgr); err != nil {
+	}).SetupWithManager(ctx, mgr); err != nil {
 		klog.ErrorS(err, "Unable to create InternalServiceExport controller")
 		exitWithErrorFunc()
 	}
 
 	klog.V(1).InfoS("Start to setup InternalServiceImport controller")
 	if err := (&internalserviceimport.Reconciler{
-		HubClient: mgr.GetClient(),
+		HubClient:           mgr.GetClient(),
+		WatchMemberClusters: isMemberClusterInstalled,
+		Recorder:            events.NewRecorder(mgr.GetEventRecorderFor(internalserviceimport.ControllerName)),
+		MigrationDone:       migrator.Done(),
 	}).SetupWithManager(ctx, mgr); err != nil {
 		klog.ErrorS(err, "Unable to create InternalServiceImport controller")
 		exitWithErrorFunc()
 	}
 
+	var clusterSetIPAllocator *clustersetip.Allocator
+	if *clusterSetIPCIDR != "" {
+		klog.V(1).InfoS("ClusterSetIP allocation is enabled", "cidr", *clusterSetIPCIDR)
+		if clusterSetIPAllocator, err = clustersetip.NewAllocator(*clusterSetIPCIDR); err != nil {
+			klog.ErrorS(err, "Invalid ClusterSetIP CIDR", "cidr", *clusterSetIPCIDR)
+			exitWithErrorFunc()
+		}
+	}
+
 	klog.V(1).InfoS("Start to setup ServiceImport controller")
 	if err := (&serviceimport.Reconciler{
-		Client:   mgr.GetClient(),
-		Recorder: mgr.GetEventRecorderFor(serviceimport.ControllerName),
+		Client:                mgr.GetClient(),
+		Recorder:              events.NewRecorder(mgr.GetEventRecorderFor(serviceimport.ControllerName)),
+		ClusterSetIPAllocator: clusterSetIPAllocator,
 	}).SetupWithManager(ctx, mgr); err != nil {
 		klog.ErrorS(err, "Unable to create ServiceImport controller")
 		exitWithErrorFunc()
 	}
 
-	discoverClient := discovery.NewDiscoveryClientForConfigOrDie(hubConfig)
-	if *enableV1Beta1APIs {
-		gvk := clusterv1beta1.GroupVersion.WithKind(clusterv1beta1.MemberClusterKind)
-		if utils.CheckCRDInstalled(discoverClient, gvk) == nil {
-			klog.V(1).InfoS("Start to setup MemberCluster controller")
-			if err := (&membercluster.Reconciler{
-				Client:              mgr.GetClient(),
-				Recorder:            mgr.GetEventRecorderFor(membercluster.ControllerName),
-				ForceDeleteWaitTime: *forceDeleteWaitTime,
+	if isMemberClusterInstalled {
+		klog.V(1).InfoS("Start to setup MemberCluster controller")
+		if err := (&membercluster.Reconciler{
+			Client:              mgr.GetClient(),
+			Recorder:            events.NewRecorder(mgr.GetEventRecorderFor(membercluster.ControllerName)),
+			ForceDeleteWaitTime: *forceDeleteWaitTime,
+		}).SetupWithManager(mgr); err != nil {
+			klog.ErrorS(err, "Unable to create MemberCluster controller")
+			exitWithErrorFunc()
+		}
+
+		if *memberHeartbeatGracePeriod > 0 {
+			klog.V(1).InfoS("Start to setup MemberHealth controller")
+			if err := (&memberhealth.Reconciler{
+				Client:               mgr.GetClient(),
+				HeartbeatGracePeriod: *memberHeartbeatGracePeriod,
 			}).SetupWithManager(mgr); err != nil {
-				klog.ErrorS(err, "Unable to create MemberCluster controller")
+				klog.ErrorS(err, "Unable to create MemberHealth controller")
 				exitWithErrorFunc()
 			}
 		}
 	}
+	if *gcInterval > 0 {
+		klog.V(1).InfoS("Start to setup garbage collection sweeper")
+		if err := mgr.Add(&gc.Sweeper{
+			Client:   mgr.GetClient(),
+			Finder:   &hubgc.OrphanFinder{HubClient: mgr.GetClient()},
+			Interval: *gcInterval,
+			DryRun:   *gcDryRun,
+		}); err != nil {
+			klog.ErrorS(err, "Unable to create garbage collection sweeper")
+			exitWithErrorFunc()
+		}
+	}
+
 	if *enableTrafficManagerFeature {
 		klog.V(1).InfoS("Traffic manager feature is enabled, checking the required CRDs")
 		for _, gvk := range trafficManagerFeatureRequiredGVKs {
@@ -197,6 +292,12 @@ func main() {
 		// klog.V(1).InfoS("Cloud config loaded", "config", cloudConfig)
 	}
 
+	// The state metrics are computed from the cache of the manager when they are scraped.
+	if err := ctrlmetrics.Registry.Register(metrics.NewHubStateCollector(mgr.GetClient(), *enableTrafficManagerFeature)); err != nil {
+		klog.ErrorS(err, "Unable to register the state metrics")
+		exitWithErrorFunc()
+	}
+
 	klog.V(1).InfoS("Starting ServiceExportImport controller manager")
 	if err := mgr.Start(ctx); err != nil {
 		klog.ErrorS(err, "Problem running manager")
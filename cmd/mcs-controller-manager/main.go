@@ -10,36 +10,34 @@ import (
 	"context"
 	"flag"
 	"os"
-	"os/signal"
-	"sync"
-	"syscall"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/rand"
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
-	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
-	"sigs.k8s.io/controller-runtime/pkg/manager"
-	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
-	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/healthz"
 
 	//+kubebuilder:scaffold:imports
 	clusterv1beta1 "go.goms.io/fleet/apis/cluster/v1beta1"
 	fleetv1alpha1 "go.goms.io/fleet/apis/v1alpha1"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
-	"go.goms.io/fleet-networking/pkg/common/hubconfig"
-	imcv1alpha1 "go.goms.io/fleet-networking/pkg/controllers/member/internalmembercluster/v1alpha1"
-	imcv1beta1 "go.goms.io/fleet-networking/pkg/controllers/member/internalmembercluster/v1beta1"
+	"go.goms.io/fleet-networking/pkg/agent"
+	"go.goms.io/fleet-networking/pkg/common/events"
+	"go.goms.io/fleet-networking/pkg/common/membership"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+	"go.goms.io/fleet-networking/pkg/common/tracing"
+	"go.goms.io/fleet-networking/pkg/controllers/member/clustersetdns"
 	"go.goms.io/fleet-networking/pkg/controllers/multiclusterservice"
 )
 
@@ -51,6 +49,14 @@ var (
 	metricsAddr    = flag.String("metrics-bind-address", ":8090", "The address the metric endpoint binds to.")
 	probeAddr      = flag.String("health-probe-bind-address", ":8091", "The address the probe endpoint binds to.")
 
+	combinedProbeAddr = flag.String("combined-health-probe-bind-address", "0", "The address the combined probe endpoint of both controller managers binds to. If set to 0, the combined probe endpoint is disabled.")
+
+	reconcileStallTimeout = flag.Duration("reconcile-stall-timeout", 15*time.Minute, "How long a single reconcile may run before the controller manager is reported as unhealthy. If set to 0, the check is disabled.")
+
+	tracingOTLPEndpoint  = flag.String("tracing-otlp-endpoint", "", "The host:port of the OTLP/HTTP endpoint to which the traces of the propagation of exported services are sent. If empty, tracing is disabled.")
+	tracingOTLPInsecure  = flag.Bool("tracing-otlp-insecure", false, "If set, the traces are sent to the OTLP endpoint without TLS.")
+	tracingSamplingRatio = flag.Float64("tracing-sampling-ratio", 1, "The ratio of the exports traced, between 0 and 1; the traces continued from another cluster follow the sampling decision of the cluster which started them.")
+
 	enableLeaderElection = flag.Bool("leader-elect", true,
 		"Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
 	leaderElectionNamespace = flag.String("leader-election-namespace", "fleet-system", "The namespace in which the leader election resource will be created.")
@@ -60,6 +66,10 @@ var (
 
 	isV1Alpha1APIEnabled = flag.Bool("enable-v1alpha1-apis", true, "If set, the agents will watch for the v1alpha1 APIs.")
 	isV1Beta1APIEnabled  = flag.Bool("enable-v1beta1-apis", false, "If set, the agents will watch for the v1beta1 APIs.")
+
+	enableClusterSetDNS        = flag.Bool("enable-clusterset-dns", false, "If set, the agent will serve the clusterset.local DNS records of the imported services by writing them into a CoreDNS ConfigMap.")
+	clusterSetDNSConfigMapNS   = flag.String("clusterset-dns-configmap-namespace", "kube-system", "The namespace of the ConfigMap the clusterset.local DNS records are written into.")
+	clusterSetDNSConfigMapName = flag.String("clusterset-dns-configmap-name", "coredns-custom", "The name of the ConfigMap the clusterset.local DNS records are written into.")
 )
 
 func init() {
@@ -92,189 +102,114 @@ func main() {
 		klog.InfoS("flag:", "name", f.Name, "value", f.Value)
 	})
 
-	memberConfig, memberOptions := prepareMemberParameters()
-
-	hubConfig, hubOptions, err := prepareHubParameters(memberConfig)
-	if err != nil {
-		exitWithErrorFunc()
+	opts := agent.Options{
+		Name:                    "MultiClusterService",
+		Scheme:                  scheme,
+		HubMetricsAddr:          *hubMetricsAddr,
+		HubProbeAddr:            *hubProbeAddr,
+		MemberMetricsAddr:       *metricsAddr,
+		MemberProbeAddr:         *probeAddr,
+		HealthProbeAddr:         *combinedProbeAddr,
+		ReconcileStallTimeout:   *reconcileStallTimeout,
+		LeaderElection:          *enableLeaderElection,
+		LeaderElectionNamespace: *leaderElectionNamespace,
+		HubLeaderElectionID:     "2bf2b407.mcs.hub.networking.fleet.azure.com",
+		MemberLeaderElectionID:  "2bf2b407.mcs.member.networking.fleet.azure.com",
+		TLSClientInsecure:       *tlsClientInsecure,
+	}
+	if *enableClusterSetDNS {
+		// Only the ConfigMap holding the clusterset.local DNS records is of interest.
+		opts.MemberCache = cache.Options{
+			ByObject: map[client.Object]cache.ByObject{
+				&corev1.ConfigMap{}: {
+					Namespaces: map[string]cache.Config{
+						*clusterSetDNSConfigMapNS: {},
+					},
+				},
+			},
+		}
 	}
-
-	// Setup hub controller manager.
-	hubMgr, err := ctrl.NewManager(hubConfig, *hubOptions)
+	a, err := agent.New(opts)
 	if err != nil {
-		klog.ErrorS(err, "Unable to start hub manager")
-		exitWithErrorFunc()
-	}
-	if err := hubMgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
-		klog.ErrorS(err, "Unable to set up health check for hub manager")
-		exitWithErrorFunc()
-	}
-	if err := hubMgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
-		klog.ErrorS(err, "Unable to set up ready check for hub manager")
+		klog.ErrorS(err, "Unable to set up MultiClusterService agent")
 		exitWithErrorFunc()
 	}
 
-	// Setup member controller manager.
-	memberMgr, err := ctrl.NewManager(memberConfig, *memberOptions)
+	// All managers stop if either of them is dead or Linux SIGTERM or SIGINT signal is received.
+	ctx := ctrl.SetupSignalHandler()
+
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Options{
+		ServiceName:   "mcs-controller-manager",
+		OTLPEndpoint:  *tracingOTLPEndpoint,
+		OTLPInsecure:  *tracingOTLPInsecure,
+		SamplingRatio: *tracingSamplingRatio,
+	})
 	if err != nil {
-		klog.ErrorS(err, "Unable to start member manager")
-		exitWithErrorFunc()
-	}
-	if err := memberMgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
-		klog.ErrorS(err, "Unable to set up health check for member manager")
-		exitWithErrorFunc()
-	}
-	if err := memberMgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
-		klog.ErrorS(err, "Unable to set up ready check for member manager")
+		klog.ErrorS(err, "Unable to set up tracing")
 		exitWithErrorFunc()
 	}
+	defer func() {
+		// Flush the recorded spans; the signal handler context is done at this point.
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			klog.ErrorS(err, "Failed to shut down tracing")
+		}
+	}()
 
-	ctx, cancel := context.WithCancel(context.Background())
-
-	klog.V(1).InfoS("Setup controllers with controller manager")
-	if err := setupControllersWithManager(ctx, hubMgr, memberMgr); err != nil {
+	if err := a.Register(ctx, agentControllers()...); err != nil {
 		klog.ErrorS(err, "Unable to setup controllers with manager")
 		exitWithErrorFunc()
 	}
 
-	// All managers should stop if either of them is dead or Linux SIGTERM or SIGINT signal is received
-	ch := make(chan os.Signal, 1)
-	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-ch
-		klog.Info("Received termination, signaling shutdown MultiClusterService agent")
-		cancel()
-	}()
-
-	var startErrors []error
-	wg := &sync.WaitGroup{}
-	wg.Add(1)
-	go func() {
-		klog.V(1).InfoS("Starting hub manager for MultiClusterService agent")
-		defer func() {
-			wg.Done()
-			klog.V(1).InfoS("Shutting down hub manager")
-			cancel()
-		}()
-		if err := hubMgr.Start(ctx); err != nil {
-			klog.ErrorS(err, "Failed to start hub manager")
-			startErrors = append(startErrors, err)
-		}
-	}()
-	wg.Add(1)
-	go func() {
-		klog.V(1).InfoS("Starting member manager for MultiClusterService agent")
-		defer func() {
-			klog.V(1).InfoS("Shutting down member manager")
-			wg.Done()
-			cancel()
-		}()
-		if err = memberMgr.Start(ctx); err != nil {
-			klog.ErrorS(err, "Failed to start member manager")
-			startErrors = append(startErrors, err)
-		}
-	}()
-
-	wg.Wait()
-
-	if len(startErrors) > 0 {
+	klog.V(1).InfoS("Starting MultiClusterService agent")
+	if err := a.Start(ctx); err != nil {
+		klog.ErrorS(err, "MultiClusterService agent stopped with error")
 		exitWithErrorFunc()
 	}
 }
 
-func prepareHubParameters(memberConfig *rest.Config) (*rest.Config, *ctrl.Options, error) {
-	hubConfig, err := hubconfig.PrepareHubConfig(*tlsClientInsecure)
-	if err != nil {
-		klog.ErrorS(err, "Failed to get hub config")
-		return nil, nil, err
-	}
-
-	mcHubNamespace, err := hubconfig.FetchMemberClusterNamespace()
-	if err != nil {
-		klog.ErrorS(err, "Failed to get member cluster hub namespace")
-		return nil, nil, err
-	}
-
-	hubOptions := &ctrl.Options{
-		Scheme: scheme,
-		Metrics: metricsserver.Options{
-			BindAddress: *hubMetricsAddr,
-		},
-		WebhookServer: webhook.NewServer(webhook.Options{
-			Port: 9443,
-		}),
-		HealthProbeBindAddress:  *hubProbeAddr,
-		LeaderElection:          *enableLeaderElection,
-		LeaderElectionID:        "2bf2b407.mcs.hub.networking.fleet.azure.com",
-		LeaderElectionNamespace: *leaderElectionNamespace, // This requires we have access to resource "leases" in API group "coordination.k8s.io" under leaderElectionNamespace.
-		LeaderElectionConfig:    memberConfig,
-		// Restricts the manager's cache to watch objects in the member hub namespace.
-		Cache: cache.Options{
-			DefaultNamespaces: map[string]cache.Config{
-				mcHubNamespace: {},
+// agentControllers returns the controllers of the MultiClusterService agent.
+func agentControllers() []agent.Controller {
+	// The membership gate stops the controllers from exporting or importing new resources while the member
+	// cluster is leaving the fleet.
+	membershipGate := membership.NewGate()
+
+	controllers := []agent.Controller{
+		{
+			Name: "multiclusterservice reconciler",
+			Setup: func(_ context.Context, a *agent.Agent) error {
+				return (&multiclusterservice.Reconciler{
+					Client:               a.MemberManager.GetClient(),
+					Scheme:               a.MemberManager.GetScheme(),
+					FleetSystemNamespace: *fleetSystemNamespace,
+					Recorder:             events.NewRecorder(a.MemberManager.GetEventRecorderFor(multiclusterservice.ControllerName)),
+					MembershipGate:       membershipGate,
+				}).SetupWithManager(a.MemberManager)
 			},
 		},
-	}
-	return hubConfig, hubOptions, nil
-}
-
-func prepareMemberParameters() (*rest.Config, *ctrl.Options) {
-	memberOpts := &ctrl.Options{
-		Scheme: scheme,
-		Metrics: metricsserver.Options{
-			BindAddress: *metricsAddr,
+		{
+			Name: "multiclusterservice metrics",
+			Setup: func(_ context.Context, a *agent.Agent) error {
+				return ctrlmetrics.Registry.Register(metrics.NewMultiClusterServiceCollector(a.MemberManager.GetClient()))
+			},
 		},
-		WebhookServer: webhook.NewServer(webhook.Options{
-			Port: 8443,
-		}),
-		HealthProbeBindAddress:  *probeAddr,
-		LeaderElection:          *enableLeaderElection,
-		LeaderElectionNamespace: *leaderElectionNamespace,
-		LeaderElectionID:        "2bf2b407.mcs.member.networking.fleet.azure.com",
-	}
-	return ctrl.GetConfigOrDie(), memberOpts
-}
-
-func setupControllersWithManager(_ context.Context, hubMgr, memberMgr manager.Manager) error {
-	klog.V(1).InfoS("Begin to setup controllers with controller manager")
-	memberClient := memberMgr.GetClient()
-	hubClient := hubMgr.GetClient()
-
-	klog.V(1).InfoS("Create multiclusterservice reconciler")
-	if err := (&multiclusterservice.Reconciler{
-		Client:               memberClient,
-		Scheme:               memberMgr.GetScheme(),
-		FleetSystemNamespace: *fleetSystemNamespace,
-		Recorder:             memberMgr.GetEventRecorderFor(multiclusterservice.ControllerName),
-	}).SetupWithManager(memberMgr); err != nil {
-		klog.ErrorS(err, "Unable to create multiclusterservice reconciler")
-		return err
 	}
 
-	if *isV1Alpha1APIEnabled {
-		klog.V(1).InfoS("Create internalmembercluster (v1alpha1 API) reconciler")
-		if err := (&imcv1alpha1.Reconciler{
-			MemberClient: memberClient,
-			HubClient:    hubClient,
-			AgentType:    fleetv1alpha1.MultiClusterServiceAgent,
-		}).SetupWithManager(hubMgr); err != nil {
-			klog.ErrorS(err, "Unable to create internalmembercluster (v1alpha1 API) reconciler")
-			return err
-		}
-	}
-
-	if *isV1Beta1APIEnabled {
-		klog.V(1).InfoS("Create internalmembercluster (v1beta1 API) reconciler")
-		if err := (&imcv1beta1.Reconciler{
-			MemberClient: memberClient,
-			HubClient:    hubClient,
-			AgentType:    clusterv1beta1.MultiClusterServiceAgent,
-		}).SetupWithManager(hubMgr); err != nil {
-			klog.ErrorS(err, "Unable to create internalmembercluster (v1beta1 API) reconciler")
-			return err
-		}
+	if *enableClusterSetDNS {
+		controllers = append(controllers, agent.Controller{
+			Name: "clustersetdns reconciler",
+			Setup: func(_ context.Context, a *agent.Agent) error {
+				return (&clustersetdns.Reconciler{
+					Client:               a.MemberManager.GetClient(),
+					ConfigMapNamespace:   *clusterSetDNSConfigMapNS,
+					ConfigMapName:        *clusterSetDNSConfigMapName,
+					FleetSystemNamespace: *fleetSystemNamespace,
+				}).SetupWithManager(a.MemberManager)
+			},
+		})
 	}
 
-	klog.V(1).InfoS("Succeeded to setup controllers with controller manager")
-	return nil
+	return append(controllers, agent.InternalMemberClusterControllers(clusterv1beta1.MultiClusterServiceAgent,
+		*isV1Alpha1APIEnabled, *isV1Beta1APIEnabled, membershipGate)...)
 }
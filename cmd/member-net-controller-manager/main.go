@@ -11,9 +11,7 @@ import (
 	"context"
 	"flag"
 	"os"
-	"os/signal"
-	"sync"
-	"syscall"
+	"strings"
 	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
@@ -23,27 +21,26 @@ import (
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
-	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/cache"
-	"sigs.k8s.io/controller-runtime/pkg/healthz"
-	"sigs.k8s.io/controller-runtime/pkg/manager"
-	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
-	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	//+kubebuilder:scaffold:imports
 	clusterv1beta1 "go.goms.io/fleet/apis/cluster/v1beta1"
 	fleetv1alpha1 "go.goms.io/fleet/apis/v1alpha1"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/agent"
 	"go.goms.io/fleet-networking/pkg/common/env"
-	"go.goms.io/fleet-networking/pkg/common/hubconfig"
+	"go.goms.io/fleet-networking/pkg/common/events"
+	"go.goms.io/fleet-networking/pkg/common/gc"
+	"go.goms.io/fleet-networking/pkg/common/membership"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+	"go.goms.io/fleet-networking/pkg/common/tracing"
 	"go.goms.io/fleet-networking/pkg/controllers/member/endpointslice"
 	"go.goms.io/fleet-networking/pkg/controllers/member/endpointsliceexport"
 	"go.goms.io/fleet-networking/pkg/controllers/member/endpointsliceimport"
-	imcv1alpha1 "go.goms.io/fleet-networking/pkg/controllers/member/internalmembercluster/v1alpha1"
-	imcv1beta1 "go.goms.io/fleet-networking/pkg/controllers/member/internalmembercluster/v1beta1"
+	membergc "go.goms.io/fleet-networking/pkg/controllers/member/gc"
 	"go.goms.io/fleet-networking/pkg/controllers/member/internalserviceexport"
 	"go.goms.io/fleet-networking/pkg/controllers/member/internalserviceimport"
 	"go.goms.io/fleet-networking/pkg/controllers/member/serviceexport"
@@ -58,6 +55,14 @@ var (
 	metricsAddr    = flag.String("member-metrics-bind-address", ":8090", "The address of member controller manager the metric endpoint binds to.")
 	probeAddr      = flag.String("member-health-probe-bind-address", ":8091", "The address of member controller manager the probe endpoint binds to.")
 
+	combinedProbeAddr = flag.String("combined-health-probe-bind-address", "0", "The address the combined probe endpoint of both controller managers binds to. If set to 0, the combined probe endpoint is disabled.")
+
+	reconcileStallTimeout = flag.Duration("reconcile-stall-timeout", 15*time.Minute, "How long a single reconcile may run before the controller manager is reported as unhealthy. If set to 0, the check is disabled.")
+
+	tracingOTLPEndpoint  = flag.String("tracing-otlp-endpoint", "", "The host:port of the OTLP/HTTP endpoint to which the traces of the propagation of exported services are sent. If empty, tracing is disabled.")
+	tracingOTLPInsecure  = flag.Bool("tracing-otlp-insecure", false, "If set, the traces are sent to the OTLP endpoint without TLS.")
+	tracingSamplingRatio = flag.Float64("tracing-sampling-ratio", 1, "The ratio of the exports traced, between 0 and 1; the traces continued from another cluster follow the sampling decision of the cluster which started them.")
+
 	enableLeaderElection    = flag.Bool("leader-elect", true, "Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
 	leaderElectionNamespace = flag.String("leader-election-namespace", "fleet-system", "The namespace in which the leader election resource will be created.")
 
@@ -67,6 +72,14 @@ var (
 	isV1Alpha1APIEnabled = flag.Bool("enable-v1alpha1-apis", true, "If set, the agents will watch for the v1alpha1 APIs.")
 	isV1Beta1APIEnabled  = flag.Bool("enable-v1beta1-apis", false, "If set, the agents will watch for the v1beta1 APIs.")
 
+	exportedServiceLabelKeys      = flag.String("exported-service-label-keys", "", "A comma-separated list of the label keys of a Service which are exported with the Service; an entry ending with '*' matches all the keys with the same prefix. The keys under networking.fleet.azure.com/ are never exported.")
+	exportedServiceAnnotationKeys = flag.String("exported-service-annotation-keys", "", "A comma-separated list of the annotation keys of a Service which are exported with the Service; an entry ending with '*' matches all the keys with the same prefix. The keys under networking.fleet.azure.com/ are never exported.")
+
+	enableEndpointSliceAggregation = flag.Bool("enable-endpointslice-aggregation", false, "If set, the endpoints imported for a Service are packed into EndpointSlices of up to 100 endpoints each, instead of one EndpointSlice per exported EndpointSlice.")
+
+	gcInterval = flag.Duration("gc-interval", 10*time.Minute, "The interval between two garbage collection sweeps for orphaned fleet networking objects. If set to 0, garbage collection is disabled.")
+	gcDryRun   = flag.Bool("gc-dry-run", false, "If set, the garbage collection sweeper only reports the orphaned fleet networking objects, without deleting them.")
+
 	enableTrafficManagerFeature = flag.Bool("enable-traffic-manager-feature", false, "If set, the traffic manager feature will be enabled.")
 
 	cloudConfigFile = flag.String("cloud-config", "/etc/kubernetes/provider/azure.json", "The path to the cloud config file which will be used to access the Azure resource.")
@@ -102,216 +115,155 @@ func main() {
 		klog.InfoS("flag:", "name", f.Name, "value", f.Value)
 	})
 
-	memberConfig, memberOptions := prepareMemberParameters()
-
-	hubConfig, hubOptions, err := prepareHubParameters(memberConfig)
+	a, err := agent.New(agent.Options{
+		Name:                    "ServiceExportImport",
+		Scheme:                  scheme,
+		HubMetricsAddr:          *hubMetricsAddr,
+		HubProbeAddr:            *hubProbeAddr,
+		MemberMetricsAddr:       *metricsAddr,
+		MemberProbeAddr:         *probeAddr,
+		HealthProbeAddr:         *combinedProbeAddr,
+		ReconcileStallTimeout:   *reconcileStallTimeout,
+		LeaderElection:          *enableLeaderElection,
+		LeaderElectionNamespace: *leaderElectionNamespace,
+		HubLeaderElectionID:     "2bf2b407.hub.networking.fleet.azure.com",
+		MemberLeaderElectionID:  "2bf2b407.member.networking.fleet.azure.com",
+		TLSClientInsecure:       *tlsClientInsecure,
+	})
 	if err != nil {
+		klog.ErrorS(err, "Unable to set up ServiceExportImport agent")
 		exitWithErrorFunc()
 	}
 
-	// Setup hub controller manager.
-	hubMgr, err := ctrl.NewManager(hubConfig, *hubOptions)
+	// All managers stop if either of them is dead or Linux SIGTERM or SIGINT signal is received.
+	ctx := ctrl.SetupSignalHandler()
+
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Options{
+		ServiceName:   "member-net-controller-manager",
+		OTLPEndpoint:  *tracingOTLPEndpoint,
+		OTLPInsecure:  *tracingOTLPInsecure,
+		SamplingRatio: *tracingSamplingRatio,
+	})
 	if err != nil {
-		klog.ErrorS(err, "Unable to start hub manager")
-		exitWithErrorFunc()
-	}
-	if err := hubMgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
-		klog.ErrorS(err, "Unable to set up health check for hub manager")
-		exitWithErrorFunc()
-	}
-	if err := hubMgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
-		klog.ErrorS(err, "Unable to set up ready check for hub manager")
+		klog.ErrorS(err, "Unable to set up tracing")
 		exitWithErrorFunc()
 	}
+	defer func() {
+		// Flush the recorded spans; the signal handler context is done at this point.
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			klog.ErrorS(err, "Failed to shut down tracing")
+		}
+	}()
 
-	// Setup member controller manager.
-	memberMgr, err := ctrl.NewManager(memberConfig, *memberOptions)
+	controllers, err := agentControllers()
 	if err != nil {
-		klog.ErrorS(err, "Unable to start member manager")
 		exitWithErrorFunc()
 	}
-	if err := memberMgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
-		klog.ErrorS(err, "Unable to set up health check for member manager")
-		exitWithErrorFunc()
-	}
-	if err := memberMgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
-		klog.ErrorS(err, "Unable to set up ready check for member manager")
-		exitWithErrorFunc()
-	}
-
-	ctx, cancel := context.WithCancel(context.Background())
-
-	klog.V(1).InfoS("Setup controllers with controller manager")
-	if err := setupControllersWithManager(ctx, hubMgr, memberMgr); err != nil {
+	if err := a.Register(ctx, controllers...); err != nil {
 		klog.ErrorS(err, "Unable to setup controllers with manager")
 		exitWithErrorFunc()
 	}
 
-	// All managers should stop if either of them is dead or Linux SIGTERM or SIGINT signal is received
-	ch := make(chan os.Signal, 1)
-	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-ch
-		klog.Info("Received termination, signaling shutdown ServiceExportImport agent")
-		cancel()
-	}()
-
-	var startErrors []error
-	wg := &sync.WaitGroup{}
-	wg.Add(1)
-	go func() {
-		klog.V(1).InfoS("Starting hub manager for ServiceExportImport agent")
-		defer func() {
-			wg.Done()
-			klog.V(1).InfoS("Shutting down hub manager")
-			cancel()
-		}()
-		if err := hubMgr.Start(ctx); err != nil {
-			klog.ErrorS(err, "Failed to start hub manager")
-			startErrors = append(startErrors, err)
-		}
-	}()
-	wg.Add(1)
-	go func() {
-		klog.V(1).InfoS("Starting member manager for ServiceExportImport agent")
-		defer func() {
-			klog.V(1).InfoS("Shutting down member manager")
-			wg.Done()
-			cancel()
-		}()
-		if err = memberMgr.Start(ctx); err != nil {
-			klog.ErrorS(err, "Failed to start member manager")
-			startErrors = append(startErrors, err)
-		}
-	}()
-
-	wg.Wait()
-
-	if len(startErrors) > 0 {
+	klog.V(1).InfoS("Starting ServiceExportImport agent")
+	if err := a.Start(ctx); err != nil {
+		klog.ErrorS(err, "ServiceExportImport agent stopped with error")
 		exitWithErrorFunc()
 	}
 }
 
-func prepareHubParameters(memberConfig *rest.Config) (*rest.Config, *ctrl.Options, error) {
-	hubConfig, err := hubconfig.PrepareHubConfig(*tlsClientInsecure)
-	if err != nil {
-		klog.ErrorS(err, "Failed to get hub config")
-		return nil, nil, err
-	}
-
-	mcHubNamespace, err := hubconfig.FetchMemberClusterNamespace()
+// agentControllers returns the controllers of the ServiceExportImport agent.
+func agentControllers() ([]agent.Controller, error) {
+	mcName, err := env.LookupMemberClusterName()
 	if err != nil {
-		klog.ErrorS(err, "Failed to get member cluster hub namespace")
-		return nil, nil, err
+		klog.ErrorS(err, "Member cluster name cannot be empty")
+		return nil, err
 	}
 
-	hubOptions := &ctrl.Options{
-		Scheme: scheme,
-		Metrics: metricsserver.Options{
-			BindAddress: *hubMetricsAddr,
+	// The membership gate stops the controllers from exporting or importing new resources while the member
+	// cluster is leaving the fleet.
+	membershipGate := membership.NewGate()
+
+	controllers := []agent.Controller{
+		{
+			Name: "endpointslice controller",
+			Setup: func(ctx context.Context, a *agent.Agent) error {
+				return (&endpointslice.Reconciler{
+					MemberClusterID: mcName,
+					MemberClient:    a.MemberManager.GetClient(),
+					HubClient:       a.HubManager.GetClient(),
+					HubNamespace:    a.HubNamespace,
+				}).SetupWithManager(ctx, a.MemberManager)
+			},
 		},
-		WebhookServer: webhook.NewServer(webhook.Options{
-			Port: 9443,
-		}),
-		HealthProbeBindAddress:  *hubProbeAddr,
-		LeaderElection:          *enableLeaderElection,
-		LeaderElectionID:        "2bf2b407.hub.networking.fleet.azure.com",
-		LeaderElectionNamespace: *leaderElectionNamespace, // This requires we have access to resource "leases" in API group "coordination.k8s.io" under leaderElectionNamespace.
-		LeaderElectionConfig:    memberConfig,
-		// Restricts the manager's cache to watch objects in the member hub namespace.
-		Cache: cache.Options{
-			DefaultNamespaces: map[string]cache.Config{
-				mcHubNamespace: {},
+		{
+			Name: "endpointsliceexport controller",
+			Setup: func(_ context.Context, a *agent.Agent) error {
+				return (&endpointsliceexport.Reconciler{
+					MemberClient: a.MemberManager.GetClient(),
+					HubClient:    a.HubManager.GetClient(),
+				}).SetupWithManager(a.HubManager)
 			},
 		},
-	}
-	return hubConfig, hubOptions, nil
-}
-
-func prepareMemberParameters() (*rest.Config, *ctrl.Options) {
-	memberOpts := &ctrl.Options{
-		Scheme: scheme,
-		Metrics: metricsserver.Options{
-			BindAddress: *metricsAddr,
+		{
+			Name: "endpointsliceimport controller",
+			Setup: func(ctx context.Context, a *agent.Agent) error {
+				return (&endpointsliceimport.Reconciler{
+					MemberClusterID:         mcName,
+					MemberClient:            a.MemberManager.GetClient(),
+					HubClient:               a.HubManager.GetClient(),
+					FleetSystemNamespace:    *fleetSystemNamespace,
+					AggregateEndpointSlices: *enableEndpointSliceAggregation,
+				}).SetupWithManager(ctx, a.MemberManager, a.HubManager)
+			},
+		},
+		{
+			Name: "imported endpoints metrics",
+			Setup: func(_ context.Context, a *agent.Agent) error {
+				return ctrlmetrics.Registry.Register(metrics.NewImportedEndpointsCollector(a.MemberManager.GetClient(),
+					*fleetSystemNamespace, endpointsliceimport.ControllerID))
+			},
+		},
+		{
+			Name: "internalserviceexport controller",
+			Setup: func(_ context.Context, a *agent.Agent) error {
+				return (&internalserviceexport.Reconciler{
+					MemberClusterID: mcName,
+					MemberClient:    a.MemberManager.GetClient(),
+					HubClient:       a.HubManager.GetClient(),
+					Recorder:        events.NewRecorder(a.MemberManager.GetEventRecorderFor(internalserviceexport.ControllerName)),
+				}).SetupWithManager(a.HubManager)
+			},
+		},
+		{
+			Name: "internalserviceimport controller",
+			Setup: func(_ context.Context, a *agent.Agent) error {
+				return (&internalserviceimport.Reconciler{
+					MemberClient: a.MemberManager.GetClient(),
+					HubClient:    a.HubManager.GetClient(),
+				}).SetupWithManager(a.HubManager)
+			},
 		},
-		WebhookServer: webhook.NewServer(webhook.Options{
-			Port: 8443,
-		}),
-		HealthProbeBindAddress:  *probeAddr,
-		LeaderElection:          *enableLeaderElection,
-		LeaderElectionNamespace: *leaderElectionNamespace,
-		LeaderElectionID:        "2bf2b407.member.networking.fleet.azure.com",
-	}
-	return ctrl.GetConfigOrDie(), memberOpts
-}
-
-func setupControllersWithManager(ctx context.Context, hubMgr, memberMgr manager.Manager) error {
-	klog.V(1).InfoS("Begin to setup controllers with controller manager")
-
-	mcName, err := env.LookupMemberClusterName()
-	if err != nil {
-		klog.ErrorS(err, "Member cluster name cannot be empty")
-		return err
-	}
-
-	mcHubNamespace, err := hubconfig.FetchMemberClusterNamespace()
-	if err != nil {
-		klog.ErrorS(err, "Failed to get member cluster hub namespace")
-		return err
-	}
-
-	memberClient := memberMgr.GetClient()
-	hubClient := hubMgr.GetClient()
-
-	klog.V(1).InfoS("Create endpointslice controller")
-	if err := (&endpointslice.Reconciler{
-		MemberClusterID: mcName,
-		MemberClient:    memberClient,
-		HubClient:       hubClient,
-		HubNamespace:    mcHubNamespace,
-	}).SetupWithManager(ctx, memberMgr); err != nil {
-		klog.ErrorS(err, "Unable to create endpointslice controller")
-		return err
-	}
-
-	klog.V(1).InfoS("Create endpointsliceexport controller")
-	if err := (&endpointsliceexport.Reconciler{
-		MemberClient: memberClient,
-		HubClient:    hubClient,
-	}).SetupWithManager(hubMgr); err != nil {
-		klog.ErrorS(err, "Unable to create endpointsliceexport controller")
-		return err
-	}
-
-	klog.V(1).InfoS("Create endpointsliceimport controller")
-	if err := (&endpointsliceimport.Reconciler{
-		MemberClusterID:      mcName,
-		MemberClient:         memberClient,
-		HubClient:            hubClient,
-		FleetSystemNamespace: *fleetSystemNamespace,
-	}).SetupWithManager(ctx, memberMgr, hubMgr); err != nil {
-		klog.ErrorS(err, "Unable to create endpointsliceimport controller")
-		return err
-	}
-
-	klog.V(1).InfoS("Create internalserviceexport controller")
-	if err := (&internalserviceexport.Reconciler{
-		MemberClusterID: mcName,
-		MemberClient:    memberClient,
-		HubClient:       hubClient,
-		Recorder:        memberMgr.GetEventRecorderFor(internalserviceexport.ControllerName),
-	}).SetupWithManager(hubMgr); err != nil {
-		klog.ErrorS(err, "Unable to create internalserviceexport controller")
-		return err
 	}
 
-	klog.V(1).InfoS("Create internalserviceimport controller")
-	if err := (&internalserviceimport.Reconciler{
-		MemberClient: memberClient,
-		HubClient:    hubClient,
-	}).SetupWithManager(hubMgr); err != nil {
-		klog.ErrorS(err, "Unable to create internalserviceimport controller")
-		return err
+	if *gcInterval > 0 {
+		controllers = append(controllers, agent.Controller{
+			Name: "garbage collection sweeper",
+			Setup: func(_ context.Context, a *agent.Agent) error {
+				return a.MemberManager.Add(&gc.Sweeper{
+					Client: a.MemberManager.GetClient(),
+					Finder: &membergc.OrphanFinder{
+						MemberClient:         a.MemberManager.GetClient(),
+						HubClient:            a.HubManager.GetClient(),
+						HubNamespace:         a.HubNamespace,
+						FleetSystemNamespace: *fleetSystemNamespace,
+					},
+					Interval: *gcInterval,
+					DryRun:   *gcDryRun,
+				})
+			},
+		})
 	}
 
 	if *enableTrafficManagerFeature {
@@ -325,54 +277,48 @@ func setupControllersWithManager(ctx context.Context, hubMgr, memberMgr manager.
 		// cloudConfig.SetUserAgent("fleet-member-net-controller-manager")
 	}
 
-	klog.V(1).InfoS("Create serviceexport reconciler", "enableTrafficManagerFeature", *enableTrafficManagerFeature)
-	if err := (&serviceexport.Reconciler{
-		MemberClient:                memberClient,
-		HubClient:                   hubClient,
-		MemberClusterID:             mcName,
-		HubNamespace:                mcHubNamespace,
-		Recorder:                    memberMgr.GetEventRecorderFor(serviceexport.ControllerName),
-		EnableTrafficManagerFeature: *enableTrafficManagerFeature,
-	}).SetupWithManager(memberMgr); err != nil {
-		klog.ErrorS(err, "Unable to create serviceexport reconciler")
-		return err
-	}
-
-	klog.V(1).InfoS("Create serviceimport reconciler")
-	if err := (&serviceimport.Reconciler{
-		MemberClient:    memberClient,
-		HubClient:       hubClient,
-		MemberClusterID: mcName,
-		HubNamespace:    mcHubNamespace,
-	}).SetupWithManager(memberMgr); err != nil {
-		klog.ErrorS(err, "Unable to create serviceimport reconciler")
-		return err
-	}
+	controllers = append(controllers,
+		agent.Controller{
+			Name: "serviceexport reconciler",
+			Setup: func(_ context.Context, a *agent.Agent) error {
+				return (&serviceexport.Reconciler{
+					MemberClient:                a.MemberManager.GetClient(),
+					HubClient:                   a.HubManager.GetClient(),
+					MemberClusterID:             mcName,
+					HubNamespace:                a.HubNamespace,
+					Recorder:                    events.NewRecorder(a.MemberManager.GetEventRecorderFor(serviceexport.ControllerName)),
+					EnableTrafficManagerFeature: *enableTrafficManagerFeature,
+					ExportedLabelKeys:           splitKeys(*exportedServiceLabelKeys),
+					ExportedAnnotationKeys:      splitKeys(*exportedServiceAnnotationKeys),
+					MembershipGate:              membershipGate,
+				}).SetupWithManager(a.MemberManager)
+			},
+		},
+		agent.Controller{
+			Name: "serviceimport reconciler",
+			Setup: func(_ context.Context, a *agent.Agent) error {
+				return (&serviceimport.Reconciler{
+					MemberClient:    a.MemberManager.GetClient(),
+					HubClient:       a.HubManager.GetClient(),
+					MemberClusterID: mcName,
+					HubNamespace:    a.HubNamespace,
+				}).SetupWithManager(a.MemberManager)
+			},
+		},
+	)
 
-	if *isV1Alpha1APIEnabled {
-		klog.V(1).InfoS("Create internalmembercluster (v1alpha1 API) reconciler")
-		if err := (&imcv1alpha1.Reconciler{
-			MemberClient: memberClient,
-			HubClient:    hubClient,
-			AgentType:    fleetv1alpha1.ServiceExportImportAgent,
-		}).SetupWithManager(hubMgr); err != nil {
-			klog.ErrorS(err, "Unable to create internalmembercluster (v1alpha1 API) reconciler")
-			return err
-		}
-	}
+	controllers = append(controllers, agent.InternalMemberClusterControllers(clusterv1beta1.ServiceExportImportAgent,
+		*isV1Alpha1APIEnabled, *isV1Beta1APIEnabled, membershipGate)...)
+	return controllers, nil
+}
 
-	if *isV1Beta1APIEnabled {
-		klog.V(1).InfoS("Create internalmembercluster (v1beta1 API) reconciler")
-		if err := (&imcv1beta1.Reconciler{
-			MemberClient: memberClient,
-			HubClient:    hubClient,
-			AgentType:    clusterv1beta1.ServiceExportImportAgent,
-		}).SetupWithManager(hubMgr); err != nil {
-			klog.ErrorS(err, "Unable to create internalmembercluster (v1beta1 API) reconciler")
-			return err
+// splitKeys splits a comma-separated list of keys, dropping the empty entries.
+func splitKeys(keys string) []string {
+	var res []string
+	for _, key := range strings.Split(keys, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			res = append(res, key)
 		}
 	}
-
-	klog.V(1).InfoS("Succeeded to setup controllers with controller manager")
-	return nil
+	return res
 }
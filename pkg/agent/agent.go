@@ -0,0 +1,361 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package agent features the runtime shared by the fleet networking agents running in the member clusters
+// (the ServiceExportImport agent and the MultiClusterService agent), each of which watches resources in both the
+// hub cluster and the member cluster with a pair of controller managers.
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	"go.goms.io/fleet-networking/pkg/common/health"
+	"go.goms.io/fleet-networking/pkg/common/hubconfig"
+)
+
+const (
+	hubWebhookPort    = 9443
+	memberWebhookPort = 8443
+
+	// healthProbeShutdownTimeout is how long the combined health endpoint waits for in-flight probes on shutdown.
+	healthProbeShutdownTimeout = 5 * time.Second
+)
+
+var (
+	// errManagerStopped is returned when a manager stops on its own while the agent is still running.
+	errManagerStopped = errors.New("manager stopped unexpectedly")
+)
+
+// Options is the configuration of an agent.
+type Options struct {
+	// Name of the agent, e.g. ServiceExportImport; it is used in logs only.
+	Name string
+	// Scheme is shared by the hub and member managers.
+	Scheme *runtime.Scheme
+
+	// HubMetricsAddr and HubProbeAddr are the addresses the metrics and health probe endpoints of the hub manager
+	// bind to.
+	HubMetricsAddr string
+	HubProbeAddr   string
+	// MemberMetricsAddr and MemberProbeAddr are the addresses the metrics and health probe endpoints of the member
+	// manager bind to.
+	MemberMetricsAddr string
+	MemberProbeAddr   string
+	// HealthProbeAddr is the address the combined health probe endpoint binds to, which reports the health checks
+	// (/healthz) and readiness checks (/readyz) of both managers at once; it is disabled if empty or "0".
+	HealthProbeAddr string
+
+	// LeaderElection enables the leader election of both managers; the leases of both managers are created in the
+	// member cluster.
+	LeaderElection          bool
+	LeaderElectionNamespace string
+	HubLeaderElectionID     string
+	MemberLeaderElectionID  string
+
+	// ReconcileStallTimeout is how long a single reconcile may run before the agent is reported as unhealthy, so
+	// that it gets restarted; the check is disabled if it is zero.
+	ReconcileStallTimeout time.Duration
+
+	// TLSClientInsecure skips the verification of the hub API server certificate (for testing purpose only).
+	TLSClientInsecure bool
+	// MemberCache configures the cache of the member manager, e.g. to restrict the objects it watches.
+	MemberCache cache.Options
+}
+
+// Agent holds the hub and member managers of a fleet networking agent.
+type Agent struct {
+	name string
+	// HubManager watches the resources in the hub namespace reserved for the member cluster.
+	HubManager manager.Manager
+	// MemberManager watches the resources in the member cluster.
+	MemberManager manager.Manager
+	// HubNamespace is the namespace reserved for the member cluster in the hub cluster.
+	HubNamespace string
+
+	healthProbeAddr string
+	healthChecks    map[string]healthz.Checker
+	readyChecks     map[string]healthz.Checker
+}
+
+// Controller is a controller (or any other runnable) of an agent, which is registered declaratively with
+// Agent.Register.
+type Controller struct {
+	// Name of the controller; it is used in logs and errors only.
+	Name string
+	// Setup sets up the controller with the hub and/or member managers of the agent.
+	Setup func(ctx context.Context, a *Agent) error
+}
+
+// New builds the hub and member managers of an agent.
+func New(opts Options) (*Agent, error) {
+	memberConfig, err := ctrl.GetConfig()
+	if err != nil {
+		klog.ErrorS(err, "Failed to get member config")
+		return nil, err
+	}
+	hubConfig, err := hubconfig.PrepareHubConfig(opts.TLSClientInsecure)
+	if err != nil {
+		klog.ErrorS(err, "Failed to get hub config")
+		return nil, err
+	}
+	mcHubNamespace, err := hubconfig.FetchMemberClusterNamespace()
+	if err != nil {
+		klog.ErrorS(err, "Failed to get member cluster hub namespace")
+		return nil, err
+	}
+
+	hubMgr, err := ctrl.NewManager(hubConfig, hubManagerOptions(opts, memberConfig, mcHubNamespace))
+	if err != nil {
+		klog.ErrorS(err, "Unable to start hub manager")
+		return nil, err
+	}
+	memberMgr, err := ctrl.NewManager(memberConfig, memberManagerOptions(opts))
+	if err != nil {
+		klog.ErrorS(err, "Unable to start member manager")
+		return nil, err
+	}
+
+	a := &Agent{
+		name:            opts.Name,
+		HubManager:      hubMgr,
+		MemberManager:   memberMgr,
+		HubNamespace:    mcHubNamespace,
+		healthProbeAddr: opts.HealthProbeAddr,
+		healthChecks:    map[string]healthz.Checker{},
+		readyChecks:     map[string]healthz.Checker{},
+	}
+	if err := a.addDefaultChecks(hubConfig, opts.ReconcileStallTimeout); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// addDefaultChecks sets up the checks of the agent:
+//   - the agent is healthy unless the reconcile loop of any of its controllers is stalled; and
+//   - the agent is ready once the caches of both managers have synced, as long as the hub API server is reachable
+//     with the current credentials.
+func (a *Agent) addDefaultChecks(hubConfig *rest.Config, reconcileStallTimeout time.Duration) error {
+	if err := a.AddHealthzCheck(nil, "ping", healthz.Ping); err != nil {
+		klog.ErrorS(err, "Unable to set up health check", "agent", a.name)
+		return err
+	}
+	if reconcileStallTimeout > 0 {
+		if err := a.AddHealthzCheck(nil, "reconcile-stall", health.ReconcileStallCheck(reconcileStallTimeout)); err != nil {
+			klog.ErrorS(err, "Unable to set up reconcile stall check", "agent", a.name)
+			return err
+		}
+	}
+	for _, mgr := range []manager.Manager{a.HubManager, a.MemberManager} {
+		if err := a.AddReadyzCheck(mgr, "cache-sync", health.CacheSyncCheck(mgr.GetCache())); err != nil {
+			klog.ErrorS(err, "Unable to set up cache sync check", "agent", a.name, "manager", a.managerName(mgr))
+			return err
+		}
+	}
+	hubAPIServerCheck, err := health.APIServerCheck(hubConfig)
+	if err != nil {
+		klog.ErrorS(err, "Unable to create hub API server check", "agent", a.name)
+		return err
+	}
+	if err := a.AddReadyzCheck(a.HubManager, "api-server", hubAPIServerCheck); err != nil {
+		klog.ErrorS(err, "Unable to set up hub API server check", "agent", a.name)
+		return err
+	}
+	return nil
+}
+
+func hubManagerOptions(opts Options, memberConfig *rest.Config, mcHubNamespace string) ctrl.Options {
+	return ctrl.Options{
+		Scheme: opts.Scheme,
+		Metrics: metricsserver.Options{
+			BindAddress: opts.HubMetricsAddr,
+		},
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Port: hubWebhookPort,
+		}),
+		HealthProbeBindAddress:  opts.HubProbeAddr,
+		LeaderElection:          opts.LeaderElection,
+		LeaderElectionID:        opts.HubLeaderElectionID,
+		LeaderElectionNamespace: opts.LeaderElectionNamespace, // This requires we have access to resource "leases" in API group "coordination.k8s.io" under leaderElectionNamespace.
+		LeaderElectionConfig:    memberConfig,
+		// Restricts the manager's cache to watch objects in the member hub namespace.
+		Cache: cache.Options{
+			DefaultNamespaces: map[string]cache.Config{
+				mcHubNamespace: {},
+			},
+		},
+	}
+}
+
+func memberManagerOptions(opts Options) ctrl.Options {
+	return ctrl.Options{
+		Scheme: opts.Scheme,
+		Metrics: metricsserver.Options{
+			BindAddress: opts.MemberMetricsAddr,
+		},
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Port: memberWebhookPort,
+		}),
+		HealthProbeBindAddress:  opts.MemberProbeAddr,
+		LeaderElection:          opts.LeaderElection,
+		LeaderElectionNamespace: opts.LeaderElectionNamespace,
+		LeaderElectionID:        opts.MemberLeaderElectionID,
+		Cache:                   opts.MemberCache,
+	}
+}
+
+// Register sets up the controllers of the agent in order, stopping at the first failure.
+func (a *Agent) Register(ctx context.Context, controllers ...Controller) error {
+	klog.V(1).InfoS("Begin to setup controllers with controller manager", "agent", a.name)
+	for _, c := range controllers {
+		klog.V(1).InfoS("Create controller", "agent", a.name, "controller", c.Name)
+		if err := c.Setup(ctx, a); err != nil {
+			klog.ErrorS(err, "Unable to create controller", "agent", a.name, "controller", c.Name)
+			return fmt.Errorf("failed to set up %s: %w", c.Name, err)
+		}
+	}
+	klog.V(1).InfoS("Succeeded to setup controllers with controller manager", "agent", a.name)
+	return nil
+}
+
+// AddHealthzCheck adds a health check to the given manager of the agent, which is also reported by the combined
+// health probe endpoint as "<hub|member>-<name>"; a check of the whole agent (nil manager) is added to both
+// managers, and reported by the combined health probe endpoint as "<name>".
+func (a *Agent) AddHealthzCheck(mgr manager.Manager, name string, check healthz.Checker) error {
+	key := a.checkKey(mgr, name)
+	check = health.Instrument(health.CheckTypeHealthz, key, check)
+	for _, m := range a.managersOf(mgr) {
+		if err := m.AddHealthzCheck(name, check); err != nil {
+			return err
+		}
+	}
+	a.healthChecks[key] = check
+	return nil
+}
+
+// AddReadyzCheck adds a readiness check to the given manager of the agent, which is also reported by the combined
+// health probe endpoint as "<hub|member>-<name>"; a check of the whole agent (nil manager) is added to both
+// managers, and reported by the combined health probe endpoint as "<name>".
+func (a *Agent) AddReadyzCheck(mgr manager.Manager, name string, check healthz.Checker) error {
+	key := a.checkKey(mgr, name)
+	check = health.Instrument(health.CheckTypeReadyz, key, check)
+	for _, m := range a.managersOf(mgr) {
+		if err := m.AddReadyzCheck(name, check); err != nil {
+			return err
+		}
+	}
+	a.readyChecks[key] = check
+	return nil
+}
+
+func (a *Agent) checkKey(mgr manager.Manager, name string) string {
+	if mgr == nil {
+		return name
+	}
+	return a.managerName(mgr) + "-" + name
+}
+
+func (a *Agent) managersOf(mgr manager.Manager) []manager.Manager {
+	if mgr == nil {
+		return []manager.Manager{a.HubManager, a.MemberManager}
+	}
+	return []manager.Manager{mgr}
+}
+
+func (a *Agent) managerName(mgr manager.Manager) string {
+	if mgr == a.HubManager {
+		return "hub"
+	}
+	return "member"
+}
+
+// Start runs the hub and member managers (and the combined health probe endpoint, if enabled) until the context
+// is cancelled or any of them fails; either way, all of them are stopped before Start returns.
+func (a *Agent) Start(ctx context.Context) error {
+	errs, ctx := errgroup.WithContext(ctx)
+	errs.Go(func() error {
+		return a.runManager(ctx, a.HubManager)
+	})
+	errs.Go(func() error {
+		return a.runManager(ctx, a.MemberManager)
+	})
+	if a.healthProbeAddr != "" && a.healthProbeAddr != "0" {
+		errs.Go(func() error {
+			return a.serveHealthProbes(ctx)
+		})
+	}
+	return errs.Wait()
+}
+
+// runManager runs a manager until the context is cancelled; a manager which stops on its own is reported as an
+// error, so that the other managers of the agent are stopped as well.
+func (a *Agent) runManager(ctx context.Context, mgr manager.Manager) error {
+	mgrName := a.managerName(mgr)
+	klog.V(1).InfoS("Starting manager", "agent", a.name, "manager", mgrName)
+	defer klog.V(1).InfoS("Shutting down manager", "agent", a.name, "manager", mgrName)
+
+	if err := mgr.Start(ctx); err != nil {
+		klog.ErrorS(err, "Failed to start manager", "agent", a.name, "manager", mgrName)
+		return fmt.Errorf("%s manager: %w", mgrName, err)
+	}
+	if ctx.Err() == nil {
+		klog.ErrorS(errManagerStopped, "Manager stopped while the agent is running", "agent", a.name, "manager", mgrName)
+		return fmt.Errorf("%s manager: %w", mgrName, errManagerStopped)
+	}
+	return nil
+}
+
+// serveHealthProbes serves the combined health probe endpoint until the context is cancelled.
+func (a *Agent) serveHealthProbes(ctx context.Context) error {
+	listener, err := net.Listen("tcp", a.healthProbeAddr)
+	if err != nil {
+		klog.ErrorS(err, "Failed to listen on the health probe address", "agent", a.name, "address", a.healthProbeAddr)
+		return err
+	}
+	server := &http.Server{
+		Handler:           a.healthProbeHandler(),
+		ReadHeaderTimeout: healthProbeShutdownTimeout,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), healthProbeShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			klog.ErrorS(err, "Failed to shut down the health probe endpoint", "agent", a.name)
+		}
+	}()
+	klog.V(1).InfoS("Serving the combined health probe endpoint", "agent", a.name, "address", listener.Addr().String())
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		klog.ErrorS(err, "Failed to serve the health probe endpoint", "agent", a.name)
+		return err
+	}
+	return nil
+}
+
+func (a *Agent) healthProbeHandler() http.Handler {
+	mux := http.NewServeMux()
+	healthHandler := http.StripPrefix("/healthz", &healthz.Handler{Checks: a.healthChecks})
+	readyHandler := http.StripPrefix("/readyz", &healthz.Handler{Checks: a.readyChecks})
+	mux.Handle("/healthz", healthHandler)
+	mux.Handle("/healthz/", healthHandler)
+	mux.Handle("/readyz", readyHandler)
+	mux.Handle("/readyz/", readyHandler)
+	return mux
+}
@@ -0,0 +1,215 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+var errFake = errors.New("fake error")
+
+// fakeManager is a manager which runs the given start function.
+type fakeManager struct {
+	manager.Manager
+	start func(ctx context.Context) error
+}
+
+func (m *fakeManager) Start(ctx context.Context) error {
+	return m.start(ctx)
+}
+
+func (m *fakeManager) AddHealthzCheck(_ string, _ healthz.Checker) error {
+	return nil
+}
+
+func (m *fakeManager) AddReadyzCheck(_ string, _ healthz.Checker) error {
+	return nil
+}
+
+// runUntilCancelled is the start function of a manager which runs until the context is cancelled.
+func runUntilCancelled(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+func newTestAgent(hubStart, memberStart func(ctx context.Context) error) *Agent {
+	return &Agent{
+		name:          "test",
+		HubManager:    &fakeManager{start: hubStart},
+		MemberManager: &fakeManager{start: memberStart},
+		healthChecks:  map[string]healthz.Checker{},
+		readyChecks:   map[string]healthz.Checker{},
+	}
+}
+
+func TestRegister(t *testing.T) {
+	testCases := []struct {
+		name       string
+		setupErrs  []error
+		wantSetups []string
+		wantErr    error
+	}{
+		{
+			name:       "all controllers are set up in order",
+			setupErrs:  []error{nil, nil, nil},
+			wantSetups: []string{"c0", "c1", "c2"},
+		},
+		{
+			name:       "stop at the first failure",
+			setupErrs:  []error{nil, errFake, nil},
+			wantSetups: []string{"c0", "c1"},
+			wantErr:    errFake,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := newTestAgent(runUntilCancelled, runUntilCancelled)
+			var gotSetups []string
+			controllers := make([]Controller, len(tc.setupErrs))
+			for i := range tc.setupErrs {
+				name := fmt.Sprintf("c%d", i)
+				controllers[i] = Controller{
+					Name: name,
+					Setup: func(_ context.Context, got *Agent) error {
+						if got != a {
+							t.Errorf("Setup() agent = %p, want %p", got, a)
+						}
+						gotSetups = append(gotSetups, name)
+						return tc.setupErrs[i]
+					},
+				}
+			}
+			if err := a.Register(context.Background(), controllers...); !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Register() = %v, want %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.wantSetups, gotSetups); diff != "" {
+				t.Errorf("Register() setups mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStart(t *testing.T) {
+	testCases := []struct {
+		name        string
+		hubStart    func(ctx context.Context) error
+		memberStart func(ctx context.Context) error
+		cancel      bool
+		wantErr     error
+	}{
+		{
+			name:        "stop both managers when the context is cancelled",
+			hubStart:    runUntilCancelled,
+			memberStart: runUntilCancelled,
+			cancel:      true,
+		},
+		{
+			name: "stop the member manager when the hub manager fails",
+			hubStart: func(_ context.Context) error {
+				return errFake
+			},
+			memberStart: runUntilCancelled,
+			wantErr:     errFake,
+		},
+		{
+			name:     "stop the hub manager when the member manager stops on its own",
+			hubStart: runUntilCancelled,
+			memberStart: func(_ context.Context) error {
+				return nil
+			},
+			wantErr: errManagerStopped,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := newTestAgent(tc.hubStart, tc.memberStart)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tc.cancel {
+				cancel()
+			}
+
+			done := make(chan error)
+			go func() {
+				done <- a.Start(ctx)
+			}()
+			select {
+			case err := <-done:
+				if !errors.Is(err, tc.wantErr) {
+					t.Errorf("Start() = %v, want %v", err, tc.wantErr)
+				}
+			case <-time.After(10 * time.Second):
+				t.Fatal("Start() does not return, want all managers stopped")
+			}
+		})
+	}
+}
+
+func TestHealthProbeHandler(t *testing.T) {
+	a := newTestAgent(runUntilCancelled, runUntilCancelled)
+	if err := a.AddHealthzCheck(a.HubManager, "ping", healthz.Ping); err != nil {
+		t.Fatalf("AddHealthzCheck() = %v, want no error", err)
+	}
+	if err := a.AddHealthzCheck(a.MemberManager, "ping", healthz.Ping); err != nil {
+		t.Fatalf("AddHealthzCheck() = %v, want no error", err)
+	}
+	if err := a.AddReadyzCheck(a.HubManager, "ping", healthz.Ping); err != nil {
+		t.Fatalf("AddReadyzCheck() = %v, want no error", err)
+	}
+	if err := a.AddReadyzCheck(a.MemberManager, "synced", func(_ *http.Request) error { return errFake }); err != nil {
+		t.Fatalf("AddReadyzCheck() = %v, want no error", err)
+	}
+
+	testCases := []struct {
+		path           string
+		wantStatusCode int
+	}{
+		{
+			path:           "/healthz",
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			path:           "/healthz/hub-ping",
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			path:           "/readyz",
+			wantStatusCode: http.StatusInternalServerError,
+		},
+		{
+			path:           "/readyz/hub-ping",
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			path:           "/readyz/member-synced",
+			wantStatusCode: http.StatusInternalServerError,
+		},
+		{
+			path:           "/readyz/unknown",
+			wantStatusCode: http.StatusNotFound,
+		},
+	}
+	handler := a.healthProbeHandler()
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			if rec.Code != tc.wantStatusCode {
+				t.Errorf("GET %s status code = %d, want %d", tc.path, rec.Code, tc.wantStatusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package agent
+
+import (
+	"context"
+
+	clusterv1beta1 "go.goms.io/fleet/apis/cluster/v1beta1"
+	fleetv1alpha1 "go.goms.io/fleet/apis/v1alpha1"
+
+	"go.goms.io/fleet-networking/pkg/common/membership"
+	imcv1alpha1 "go.goms.io/fleet-networking/pkg/controllers/member/internalmembercluster/v1alpha1"
+	imcv1beta1 "go.goms.io/fleet-networking/pkg/controllers/member/internalmembercluster/v1beta1"
+)
+
+// InternalMemberClusterControllers returns the InternalMemberCluster controllers of an agent for the enabled fleet
+// API versions, which report the agent status to the hub cluster and drive the membership gate.
+//
+// The membership gate is opened right away if no fleet API is enabled, as no controller will observe the membership.
+func InternalMemberClusterControllers(agentType clusterv1beta1.AgentType, enableV1Alpha1APIs, enableV1Beta1APIs bool, membershipGate *membership.Gate) []Controller {
+	if !enableV1Alpha1APIs && !enableV1Beta1APIs {
+		membershipGate.Open()
+		return nil
+	}
+	var controllers []Controller
+	if enableV1Alpha1APIs {
+		controllers = append(controllers, Controller{
+			Name: "internalmembercluster (v1alpha1 API) reconciler",
+			Setup: func(_ context.Context, a *Agent) error {
+				return (&imcv1alpha1.Reconciler{
+					MemberClient:   a.MemberManager.GetClient(),
+					HubClient:      a.HubManager.GetClient(),
+					AgentType:      fleetv1alpha1.AgentType(agentType),
+					MembershipGate: membershipGate,
+				}).SetupWithManager(a.HubManager)
+			},
+		})
+	}
+	if enableV1Beta1APIs {
+		controllers = append(controllers, Controller{
+			Name: "internalmembercluster (v1beta1 API) reconciler",
+			Setup: func(_ context.Context, a *Agent) error {
+				return (&imcv1beta1.Reconciler{
+					MemberClient:   a.MemberManager.GetClient(),
+					HubClient:      a.HubManager.GetClient(),
+					AgentType:      agentType,
+					MembershipGate: membershipGate,
+				}).SetupWithManager(a.HubManager)
+			},
+		})
+	}
+	return controllers
+}
@@ -8,6 +8,7 @@ package azureerrors
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
@@ -37,3 +38,13 @@ func IsThrottled(err error) bool {
 	var responseError *azcore.ResponseError
 	return errors.As(err, &responseError) && responseError.StatusCode == http.StatusTooManyRequests
 }
+
+// Summary returns a short description of the error which does not vary between the requests (unlike the error
+// message of the azure server, which includes the request ID), e.g. to report it in a Kubernetes Event.
+func Summary(err error) string {
+	var responseError *azcore.ResponseError
+	if errors.As(err, &responseError) {
+		return fmt.Sprintf("%s (status code %d)", responseError.ErrorCode, responseError.StatusCode)
+	}
+	return err.Error()
+}
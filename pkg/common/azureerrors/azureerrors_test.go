@@ -7,6 +7,7 @@ package azureerrors
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
@@ -159,3 +160,30 @@ func TestIsThrottled(t *testing.T) {
 		})
 	}
 }
+
+func TestSummary(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "not azure error",
+			err:  errors.New("not azure error"),
+			want: "not azure error",
+		},
+		{
+			name: "wrapped conflict error",
+			err:  fmt.Errorf("failed to create profile: %w", &azcore.ResponseError{StatusCode: 409, ErrorCode: "Conflict"}),
+			want: "Conflict (status code 409)",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Summary(tc.err)
+			if got != tc.want {
+				t.Errorf("Summary() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package clusterselector features utils to evaluate the cluster selectors of MultiClusterServices, which select
+// the exporting member clusters whose endpoints are imported.
+package clusterselector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1beta1 "go.goms.io/fleet/apis/cluster/v1beta1"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+// Selects returns if a member cluster, with the given labels on its MemberCluster object, is selected by the
+// selector; all the member clusters are selected by a nil selector.
+func Selects(selector *fleetnetv1alpha1.ClusterSelector, clusterID string, clusterLabels map[string]string) (bool, error) {
+	if selector == nil || slices.Contains(selector.ClusterNames, clusterID) {
+		return true, nil
+	}
+	if selector.LabelSelector == nil {
+		return false, nil
+	}
+	labelSelector, err := metav1.LabelSelectorAsSelector(selector.LabelSelector)
+	if err != nil {
+		return false, fmt.Errorf("invalid label selector: %w", err)
+	}
+	return labelSelector.Matches(labels.Set(clusterLabels)), nil
+}
+
+// IsClusterSelected returns if a member cluster is selected by the selector; the labels of the member cluster are
+// read from its MemberCluster object in the hub cluster when the selector has a label selector.
+//
+// A member cluster whose MemberCluster object cannot be found, or whose MemberCluster API is not installed, is
+// considered to have no labels.
+func IsClusterSelected(ctx context.Context, c client.Reader, selector *fleetnetv1alpha1.ClusterSelector, clusterID string) (bool, error) {
+	if selector == nil || selector.LabelSelector == nil || slices.Contains(selector.ClusterNames, clusterID) {
+		return Selects(selector, clusterID, nil)
+	}
+	memberCluster := &clusterv1beta1.MemberCluster{}
+	err := c.Get(ctx, types.NamespacedName{Name: clusterID}, memberCluster)
+	switch {
+	case errors.IsNotFound(err) || meta.IsNoMatchError(err):
+		return Selects(selector, clusterID, nil)
+	case err != nil:
+		return false, fmt.Errorf("failed to get memberCluster %s: %w", clusterID, err)
+	}
+	return Selects(selector, clusterID, memberCluster.Labels)
+}
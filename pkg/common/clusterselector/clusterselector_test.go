@@ -0,0 +1,107 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package clusterselector
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1beta1 "go.goms.io/fleet/apis/cluster/v1beta1"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+func TestIsClusterSelected(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	euSelector := &metav1.LabelSelector{MatchLabels: map[string]string{"region": "eu"}}
+
+	testCases := []struct {
+		name      string
+		selector  *fleetnetv1alpha1.ClusterSelector
+		clusterID string
+		want      bool
+		wantErr   bool
+	}{
+		{
+			name:      "nil selector selects all the clusters",
+			clusterID: "member-1",
+			want:      true,
+		},
+		{
+			name:      "empty selector selects no cluster",
+			selector:  &fleetnetv1alpha1.ClusterSelector{},
+			clusterID: "member-1",
+		},
+		{
+			name:      "cluster is listed by name",
+			selector:  &fleetnetv1alpha1.ClusterSelector{ClusterNames: []string{"member-2", "member-1"}},
+			clusterID: "member-1",
+			want:      true,
+		},
+		{
+			name:      "cluster labels match",
+			selector:  &fleetnetv1alpha1.ClusterSelector{LabelSelector: euSelector},
+			clusterID: "member-1",
+			want:      true,
+		},
+		{
+			name:      "cluster labels do not match",
+			selector:  &fleetnetv1alpha1.ClusterSelector{LabelSelector: euSelector},
+			clusterID: "member-2",
+		},
+		{
+			name: "cluster under maintenance is excluded",
+			selector: &fleetnetv1alpha1.ClusterSelector{
+				LabelSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "maintenance", Operator: metav1.LabelSelectorOpDoesNotExist},
+					},
+				},
+			},
+			clusterID: "member-2",
+		},
+		{
+			name:      "memberCluster does not exist",
+			selector:  &fleetnetv1alpha1.ClusterSelector{LabelSelector: euSelector},
+			clusterID: "member-3",
+		},
+		{
+			name: "invalid label selector",
+			selector: &fleetnetv1alpha1.ClusterSelector{
+				LabelSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "region", Operator: "Unknown"},
+					},
+				},
+			},
+			clusterID: "member-1",
+			wantErr:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+				&clusterv1beta1.MemberCluster{ObjectMeta: metav1.ObjectMeta{Name: "member-1", Labels: map[string]string{"region": "eu"}}},
+				&clusterv1beta1.MemberCluster{ObjectMeta: metav1.ObjectMeta{Name: "member-2", Labels: map[string]string{"region": "us", "maintenance": "true"}}},
+			).Build()
+			got, err := IsClusterSelected(context.Background(), fakeClient, tc.selector, tc.clusterID)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("IsClusterSelected() got error %v, want error %t", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("IsClusterSelected() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package clustersetip features an allocator which assigns ClusterSetIPs (virtual IPs shared by all clusters in
+// the fleet) to ServiceImports from a configured CIDR.
+package clustersetip
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"sync"
+)
+
+var (
+	// ErrCIDRExhausted is returned when no more IP addresses can be allocated from the CIDR.
+	ErrCIDRExhausted = errors.New("no available ClusterSetIP in the CIDR")
+)
+
+// Allocator assigns IPv4 addresses from a CIDR to ServiceImports.
+//
+// The allocator itself is not the source of truth: the allocated IPs are persisted in the ServiceImport status,
+// and callers are expected to tell whether an IP is already in use when asking for a new allocation.
+// The allocator only keeps track of its own recent allocations, so that two ServiceImports processed in quick
+// succession will not be assigned the same IP even if the informer cache has not caught up yet.
+type Allocator struct {
+	cidr *net.IPNet
+	// base is the first address of the CIDR (the network address) in its integer form.
+	base uint32
+	// size is the number of addresses in the CIDR.
+	size uint32
+
+	mu sync.Mutex
+	// reserved tracks the allocations made by this allocator, keyed by IP; the value is the key of the
+	// ServiceImport (usually its namespaced name) which owns the IP.
+	reserved map[string]string
+}
+
+// NewAllocator returns an Allocator which assigns IPs from the given IPv4 CIDR.
+func NewAllocator(cidr string) (*Allocator, error) {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ClusterSetIP CIDR %q: %w", cidr, err)
+	}
+	if ipNet.IP.To4() == nil {
+		return nil, fmt.Errorf("ClusterSetIP CIDR %q is not an IPv4 CIDR", cidr)
+	}
+	ones, bits := ipNet.Mask.Size()
+	// The network address and the broadcast address are never allocated; a CIDR must have at least one
+	// usable address left.
+	if bits-ones < 2 {
+		return nil, fmt.Errorf("ClusterSetIP CIDR %q is too small", cidr)
+	}
+	return &Allocator{
+		cidr:     ipNet,
+		base:     binary.BigEndian.Uint32(ipNet.IP.To4()),
+		size:     uint32(1) << uint(bits-ones),
+		reserved: map[string]string{},
+	}, nil
+}
+
+// CIDR returns the CIDR the allocator assigns IPs from.
+func (a *Allocator) CIDR() string {
+	return a.cidr.String()
+}
+
+// Contains returns if an IP is a valid, allocatable address in the CIDR.
+func (a *Allocator) Contains(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil || parsed.To4() == nil || !a.cidr.Contains(parsed) {
+		return false
+	}
+	offset := binary.BigEndian.Uint32(parsed.To4()) - a.base
+	return offset != 0 && offset != a.size-1
+}
+
+// Allocate assigns an IP to the ServiceImport identified by key.
+//
+// inUse reports whether an IP is currently assigned to another ServiceImport; a nil inUse means no IP is in use.
+// The search starts at an offset derived from the key, so that the same ServiceImport is likely to receive the same
+// IP again should its status be lost.
+func (a *Allocator) Allocate(key string, inUse func(ip string) (bool, error)) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// Usable addresses are [base+1, base+size-2].
+	usable := a.size - 2
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	start := h.Sum32() % usable
+	for i := uint32(0); i < usable; i++ {
+		offset := (start+i)%usable + 1
+		ip := make(net.IP, net.IPv4len)
+		binary.BigEndian.PutUint32(ip, a.base+offset)
+		candidate := ip.String()
+		if owner, ok := a.reserved[candidate]; ok && owner != key {
+			continue
+		}
+		if inUse != nil {
+			used, err := inUse(candidate)
+			if err != nil {
+				return "", err
+			}
+			if used {
+				continue
+			}
+		}
+		a.releaseLocked(key)
+		a.reserved[candidate] = key
+		return candidate, nil
+	}
+	return "", ErrCIDRExhausted
+}
+
+// Release drops the allocation kept in memory for the ServiceImport identified by key.
+func (a *Allocator) Release(key string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.releaseLocked(key)
+}
+
+func (a *Allocator) releaseLocked(key string) {
+	for ip, owner := range a.reserved {
+		if owner == key {
+			delete(a.reserved, ip)
+		}
+	}
+}
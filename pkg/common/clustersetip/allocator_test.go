@@ -0,0 +1,155 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package clustersetip
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+// TestNewAllocator tests the NewAllocator function.
+func TestNewAllocator(t *testing.T) {
+	testCases := []struct {
+		name    string
+		cidr    string
+		wantErr bool
+	}{
+		{
+			name: "should accept an IPv4 CIDR",
+			cidr: "10.100.0.0/16",
+		},
+		{
+			name:    "should reject an invalid CIDR",
+			cidr:    "10.100.0.0",
+			wantErr: true,
+		},
+		{
+			name:    "should reject an IPv6 CIDR",
+			cidr:    "fd00::/64",
+			wantErr: true,
+		},
+		{
+			name:    "should reject a CIDR without usable addresses",
+			cidr:    "10.100.0.0/31",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewAllocator(tc.cidr)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("NewAllocator(%q) = %v, want error %t", tc.cidr, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+// TestContains tests the Allocator.Contains method.
+func TestContains(t *testing.T) {
+	a, err := NewAllocator("10.100.0.0/24")
+	if err != nil {
+		t.Fatalf("NewAllocator() = %v", err)
+	}
+
+	testCases := []struct {
+		name string
+		ip   string
+		want bool
+	}{
+		{
+			name: "usable address",
+			ip:   "10.100.0.10",
+			want: true,
+		},
+		{
+			name: "network address",
+			ip:   "10.100.0.0",
+		},
+		{
+			name: "broadcast address",
+			ip:   "10.100.0.255",
+		},
+		{
+			name: "address outside of the CIDR",
+			ip:   "10.101.0.10",
+		},
+		{
+			name: "malformed address",
+			ip:   "not-an-ip",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := a.Contains(tc.ip); got != tc.want {
+				t.Fatalf("Contains(%q) = %t, want %t", tc.ip, got, tc.want)
+			}
+		})
+	}
+}
+
+// TestAllocate tests the Allocator.Allocate and Allocator.Release methods.
+func TestAllocate(t *testing.T) {
+	// A /29 CIDR has 6 usable addresses.
+	a, err := NewAllocator("10.100.0.0/29")
+	if err != nil {
+		t.Fatalf("NewAllocator() = %v", err)
+	}
+
+	first, err := a.Allocate("work/app", nil)
+	if err != nil {
+		t.Fatalf("Allocate() = %v", err)
+	}
+	if !a.Contains(first) {
+		t.Fatalf("Allocate() = %s, want an address in %s", first, a.CIDR())
+	}
+
+	// Allocating for the same key again should yield the same IP.
+	again, err := a.Allocate("work/app", nil)
+	if err != nil || again != first {
+		t.Fatalf("Allocate() = %s, %v, want %s", again, err, first)
+	}
+
+	// IPs reserved in memory or in use should not be handed out again.
+	inUseIPs := map[string]bool{}
+	inUse := func(ip string) (bool, error) { return inUseIPs[ip], nil }
+	allocated := map[string]bool{first: true}
+	for _, key := range []string{"work/a", "work/b", "work/c", "work/d"} {
+		ip, err := a.Allocate(key, inUse)
+		if err != nil {
+			t.Fatalf("Allocate(%s) = %v", key, err)
+		}
+		if allocated[ip] {
+			t.Fatalf("Allocate(%s) = %s, which has been allocated", key, ip)
+		}
+		allocated[ip] = true
+	}
+	// The last free IP is assigned to a ServiceImport the allocator does not know about, e.g. by the previous leader.
+	for _, offset := range []byte{1, 2, 3, 4, 5, 6} {
+		if ip := net.IPv4(10, 100, 0, offset).String(); !allocated[ip] {
+			inUseIPs[ip] = true
+		}
+	}
+
+	if _, err := a.Allocate("work/f", inUse); !errors.Is(err, ErrCIDRExhausted) {
+		t.Fatalf("Allocate() = %v, want %v", err, ErrCIDRExhausted)
+	}
+
+	// Releasing a key should make its IP available again.
+	a.Release("work/app")
+	ip, err := a.Allocate("work/f", inUse)
+	if err != nil || ip != first {
+		t.Fatalf("Allocate() after Release() = %s, %v, want %s", ip, err, first)
+	}
+
+	// An error looking up the IPs in use fails the allocation.
+	lookupErr := errors.New("lookup failed")
+	if _, err := a.Allocate("work/g", func(string) (bool, error) { return false, lookupErr }); !errors.Is(err, lookupErr) {
+		t.Fatalf("Allocate() = %v, want %v", err, lookupErr)
+	}
+}
@@ -18,6 +18,10 @@ import (
 const (
 	conditionReasonNoConflictFound = "NoConflictFound"
 	conditionReasonConflictFound   = "ConflictFound"
+	conditionReasonExportAllowed   = "ExportAllowed"
+	conditionReasonExportDenied    = "ExportNotAllowed"
+	conditionReasonHeartbeatLost   = "HeartbeatLost"
+	conditionReasonHeartbeatFound  = "HeartbeatReceived"
 )
 
 // EqualCondition compares one condition with another; it ignores the LastTransitionTime and Message fields,
@@ -79,3 +83,51 @@ func ConflictedServiceExportConflictCondition(internalServiceExport fleetnetv1al
 		Message:            fmt.Sprintf("service %s is in conflict with other exported services", svcName),
 	}
 }
+
+// AuthorizedServiceExportCondition returns the desired condition when the export is allowed by the policies.
+func AuthorizedServiceExportCondition(internalServiceExport fleetnetv1alpha1.InternalServiceExport) metav1.Condition {
+	svcName := types.NamespacedName{
+		Namespace: internalServiceExport.Spec.ServiceReference.Namespace,
+		Name:      internalServiceExport.Spec.ServiceReference.Name,
+	}
+	return metav1.Condition{
+		Type:               string(fleetnetv1alpha1.ServiceExportAuthorized),
+		Status:             metav1.ConditionTrue,
+		Reason:             conditionReasonExportAllowed,
+		ObservedGeneration: internalServiceExport.Spec.ServiceReference.Generation, // use the generation of the original object
+		Message:            fmt.Sprintf("service %s is allowed to be exported", svcName),
+	}
+}
+
+// UnauthorizedServiceExportCondition returns the desired condition when the export is denied by the policies.
+func UnauthorizedServiceExportCondition(internalServiceExport fleetnetv1alpha1.InternalServiceExport, message string) metav1.Condition {
+	return metav1.Condition{
+		Type:               string(fleetnetv1alpha1.ServiceExportAuthorized),
+		Status:             metav1.ConditionFalse,
+		Reason:             conditionReasonExportDenied,
+		ObservedGeneration: internalServiceExport.Spec.ServiceReference.Generation, // use the generation of the original object
+		Message:            message,
+	}
+}
+
+// StaleServiceExportCondition returns the desired condition when the member cluster has stopped sending heartbeats.
+func StaleServiceExportCondition(internalServiceExport fleetnetv1alpha1.InternalServiceExport, message string) metav1.Condition {
+	return metav1.Condition{
+		Type:               string(fleetnetv1alpha1.ServiceExportStale),
+		Status:             metav1.ConditionTrue,
+		Reason:             conditionReasonHeartbeatLost,
+		ObservedGeneration: internalServiceExport.Spec.ServiceReference.Generation, // use the generation of the original object
+		Message:            message,
+	}
+}
+
+// FreshServiceExportCondition returns the desired condition when the member cluster sends heartbeats again.
+func FreshServiceExportCondition(internalServiceExport fleetnetv1alpha1.InternalServiceExport) metav1.Condition {
+	return metav1.Condition{
+		Type:               string(fleetnetv1alpha1.ServiceExportStale),
+		Status:             metav1.ConditionFalse,
+		Reason:             conditionReasonHeartbeatFound,
+		ObservedGeneration: internalServiceExport.Spec.ServiceReference.Generation, // use the generation of the original object
+		Message:            fmt.Sprintf("member cluster %s is sending heartbeats", internalServiceExport.Spec.ServiceReference.ClusterID),
+	}
+}
@@ -0,0 +1,172 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package events features the reasons of the Kubernetes Events which the fleet networking controllers emit on the
+// objects they manage, and a recorder which de-duplicates them.
+//
+// The controllers emit an Event only when an object transitions from one state to another (e.g. a Service is
+// claimed by a member cluster, or an exported Service falls into conflict), rather than on every reconcile, so that
+// `kubectl describe` tells the story of an object; the failures which are retried are reported only once in a row.
+// The reasons are part of the fleet networking API, which users may alert on, and must not change.
+package events
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+)
+
+// The reasons of the Events emitted when an object, or a resource the controller manages on behalf of an object
+// (e.g. an Azure Traffic Manager profile), is created, updated or deleted.
+const (
+	ReasonCreated      = "Created"
+	ReasonUpdated      = "Updated"
+	ReasonDeleted      = "Deleted"
+	ReasonCreateFailed = "CreateFailed"
+	ReasonUpdateFailed = "UpdateFailed"
+	ReasonDeleteFailed = "DeleteFailed"
+)
+
+// The reasons of the Events emitted when an exported Service falls into or recovers from a conflict with the exports
+// from other member clusters.
+const (
+	ReasonConflict         = "Conflict"
+	ReasonConflictResolved = "ConflictResolved"
+)
+
+// The reasons of the Events emitted when a member cluster claims the import of a Service, gives it up, or is not
+// allowed to claim it.
+const (
+	ReasonClaimed       = "Claimed"
+	ReasonReleased      = "Released"
+	ReasonClaimRejected = "ClaimRejected"
+)
+
+// The reasons of the Events emitted when an exported object is distributed to, or withdrawn from, the member
+// clusters.
+const (
+	ReasonDistributed        = "Distributed"
+	ReasonDistributionFailed = "DistributionFailed"
+	ReasonWithdrawn          = "Withdrawn"
+	ReasonWithdrawalFailed   = "WithdrawalFailed"
+)
+
+// The reasons of the Events emitted when an object (e.g. a TrafficManagerBackend) is accepted by the controller, is
+// found invalid, or is pending on a transient error.
+const (
+	ReasonAccepted = "Accepted"
+	ReasonInvalid  = "Invalid"
+	ReasonPending  = "Pending"
+)
+
+// The reasons of the Events emitted when the networking resources of a force-deleted member cluster are cleaned up.
+const (
+	ReasonForceDeleteCleanupSucceeded = "ForceDeleteCleanupSucceeded"
+	ReasonForceDeleteCleanupFailed    = "ForceDeleteCleanupFailed"
+)
+
+const (
+	// deduplicationWindow is how long an Event is suppressed for after the same Event has been emitted on the same
+	// object.
+	deduplicationWindow = 10 * time.Minute
+	// pruneThreshold is the number of objects tracked by the recorder above which the expired entries are pruned.
+	pruneThreshold = 1024
+)
+
+// IsTransition returns true if a condition transitions from the current one to the desired one, i.e. the condition
+// is new, or its status or reason changes; a new generation observed alone is not a transition.
+func IsTransition(current *metav1.Condition, desired metav1.Condition) bool {
+	return current == nil || current.Status != desired.Status || current.Reason != desired.Reason
+}
+
+// lastEvent is the last Event emitted on an object.
+type lastEvent struct {
+	eventType string
+	reason    string
+	message   string
+	timestamp time.Time
+}
+
+// deduplicatingRecorder is an EventRecorder which drops an Event if it is the same as the last Event emitted on the
+// same object within the deduplication window, e.g. when a failure is retried. Unlike the aggregation of the
+// client-go event broadcaster, which merges similar Events into one with a count, the Event is not written at all,
+// and a different Event emitted in between (e.g. ConflictResolved between two Conflict Events) is never dropped.
+type deduplicatingRecorder struct {
+	recorder record.EventRecorder
+	now      func() time.Time
+
+	mu         sync.Mutex
+	lastEvents map[types.UID]lastEvent
+}
+
+var _ record.EventRecorder = &deduplicatingRecorder{}
+
+// NewRecorder returns an EventRecorder which de-duplicates the Events emitted with the given recorder.
+func NewRecorder(recorder record.EventRecorder) record.EventRecorder {
+	return &deduplicatingRecorder{
+		recorder:   recorder,
+		now:        time.Now,
+		lastEvents: map[types.UID]lastEvent{},
+	}
+}
+
+// Event implements record.EventRecorder.
+func (r *deduplicatingRecorder) Event(object runtime.Object, eventType, reason, message string) {
+	if r.isDuplicate(object, eventType, reason, message) {
+		return
+	}
+	r.recorder.Event(object, eventType, reason, message)
+}
+
+// Eventf implements record.EventRecorder.
+func (r *deduplicatingRecorder) Eventf(object runtime.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	r.Event(object, eventType, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+// AnnotatedEventf implements record.EventRecorder.
+func (r *deduplicatingRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventType, reason, messageFmt string, args ...interface{}) {
+	message := fmt.Sprintf(messageFmt, args...)
+	if r.isDuplicate(object, eventType, reason, message) {
+		return
+	}
+	r.recorder.AnnotatedEventf(object, annotations, eventType, reason, "%s", message)
+}
+
+// isDuplicate returns true if the Event is the same as the last Event emitted on the object within the deduplication
+// window; otherwise, the Event is remembered as the last one.
+func (r *deduplicatingRecorder) isDuplicate(object runtime.Object, eventType, reason, message string) bool {
+	accessor, err := meta.Accessor(object)
+	if err != nil || accessor.GetUID() == "" {
+		// The Event cannot be attributed to a persisted object; leave it to the underlying recorder.
+		klog.V(4).InfoS("Skipping the deduplication of an event on an object without UID", "reason", reason)
+		return false
+	}
+	uid := accessor.GetUID()
+	now := r.now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if last, ok := r.lastEvents[uid]; ok &&
+		last.eventType == eventType && last.reason == reason && last.message == message &&
+		now.Sub(last.timestamp) < deduplicationWindow {
+		return true
+	}
+	if len(r.lastEvents) >= pruneThreshold {
+		for k, last := range r.lastEvents {
+			if now.Sub(last.timestamp) >= deduplicationWindow {
+				delete(r.lastEvents, k)
+			}
+		}
+	}
+	r.lastEvents[uid] = lastEvent{eventType: eventType, reason: reason, message: message, timestamp: now}
+	return false
+}
@@ -0,0 +1,100 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestIsTransition(t *testing.T) {
+	desired := metav1.Condition{Type: "Conflict", Status: metav1.ConditionTrue, Reason: "ConflictFound", ObservedGeneration: 2}
+	testCases := []struct {
+		name    string
+		current *metav1.Condition
+		want    bool
+	}{
+		{
+			name: "new condition",
+			want: true,
+		},
+		{
+			name:    "status changes",
+			current: &metav1.Condition{Type: "Conflict", Status: metav1.ConditionFalse, Reason: "ConflictFound"},
+			want:    true,
+		},
+		{
+			name:    "reason changes",
+			current: &metav1.Condition{Type: "Conflict", Status: metav1.ConditionTrue, Reason: "Pending"},
+			want:    true,
+		},
+		{
+			name:    "new generation observed",
+			current: &metav1.Condition{Type: "Conflict", Status: metav1.ConditionTrue, Reason: "ConflictFound", ObservedGeneration: 1},
+			want:    false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := IsTransition(tc.current, desired); got != tc.want {
+				t.Errorf("IsTransition() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDeduplicatingRecorder(t *testing.T) {
+	now := time.Now()
+	fakeRecorder := record.NewFakeRecorder(10)
+	recorder := NewRecorder(fakeRecorder).(*deduplicatingRecorder)
+	recorder.now = func() time.Time { return now }
+
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "work", Name: "app", UID: types.UID("1")}}
+	otherSvc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "work", Name: "other", UID: types.UID("2")}}
+	newSvc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "work", Name: "new"}}
+
+	recorder.Eventf(svc, corev1.EventTypeWarning, ReasonConflict, "conflict with %d clusters", 2)
+	// Dropped as the same Event has just been emitted on the same object.
+	recorder.Eventf(svc, corev1.EventTypeWarning, ReasonConflict, "conflict with %d clusters", 2)
+	// Emitted on another object.
+	recorder.Eventf(otherSvc, corev1.EventTypeWarning, ReasonConflict, "conflict with %d clusters", 2)
+	recorder.Event(svc, corev1.EventTypeNormal, ReasonConflictResolved, "resolved")
+	// Emitted as a different Event has been emitted in between.
+	recorder.Eventf(svc, corev1.EventTypeWarning, ReasonConflict, "conflict with %d clusters", 2)
+	// Emitted as the message changes.
+	recorder.Eventf(svc, corev1.EventTypeWarning, ReasonConflict, "conflict with %d clusters", 3)
+	// Emitted as the object has no UID.
+	recorder.Event(newSvc, corev1.EventTypeNormal, ReasonCreated, "created")
+	recorder.Event(newSvc, corev1.EventTypeNormal, ReasonCreated, "created")
+	// Emitted after the deduplication window.
+	now = now.Add(deduplicationWindow)
+	recorder.Eventf(svc, corev1.EventTypeWarning, ReasonConflict, "conflict with %d clusters", 3)
+
+	want := []string{
+		"Warning Conflict conflict with 2 clusters",
+		"Warning Conflict conflict with 2 clusters",
+		"Normal ConflictResolved resolved",
+		"Warning Conflict conflict with 2 clusters",
+		"Warning Conflict conflict with 3 clusters",
+		"Normal Created created",
+		"Normal Created created",
+		"Warning Conflict conflict with 3 clusters",
+	}
+	close(fakeRecorder.Events)
+	var got []string
+	for event := range fakeRecorder.Events {
+		got = append(got, event)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("recorded events mismatch (-want, +got):\n%s", diff)
+	}
+}
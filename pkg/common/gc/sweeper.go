@@ -0,0 +1,156 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package gc features the garbage collection sweeper shared by the hub and member controllers; the sweeper
+// periodically removes the orphaned fleet networking objects found by a per-cluster OrphanFinder.
+package gc
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+)
+
+// MinOrphanAge is the minimum age of an object before the sweeper considers it an orphan; this helps guard against
+// objects whose owners are being created at the same time, or have not been synced to the caches yet.
+const MinOrphanAge = time.Minute
+
+var (
+	// orphanedObjects is a Prometheus gauge metric which tracks the number of orphaned objects found in the last
+	// sweep, by kind.
+	orphanedObjects = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metrics.MetricsNamespace,
+			Subsystem: metrics.MetricsSubsystem,
+			Name:      "gc_orphaned_objects",
+			Help:      "The number of orphaned objects found in the last garbage collection sweep",
+		},
+		[]string{"kind"},
+	)
+	// deletedObjectsTotal is a Prometheus counter metric which tracks the number of orphaned objects deleted,
+	// by kind.
+	deletedObjectsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metrics.MetricsNamespace,
+			Subsystem: metrics.MetricsSubsystem,
+			Name:      "gc_deleted_objects_total",
+			Help:      "The number of orphaned objects deleted by garbage collection",
+		},
+		[]string{"kind"},
+	)
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(orphanedObjects, deletedObjectsTotal)
+}
+
+// Orphans are the orphaned objects of a kind found in a sweep.
+type Orphans struct {
+	// Kind is the kind reported in the metrics and logs, e.g. EndpointSliceExport.
+	Kind    string
+	Objects []client.Object
+}
+
+// OrphanFinder finds the orphaned objects in a cluster.
+type OrphanFinder interface {
+	// FindOrphans scans the cluster once and returns the orphaned objects by kind; the objects are deleted in the
+	// order returned.
+	FindOrphans(ctx context.Context, now time.Time) ([]Orphans, error)
+}
+
+// Sweeper periodically deletes the orphaned objects found by its OrphanFinder.
+type Sweeper struct {
+	// Client deletes the orphaned objects.
+	Client client.Client
+	Finder OrphanFinder
+	// Interval is the interval between two sweeps.
+	Interval time.Duration
+	// DryRun makes the sweeper only report the orphaned objects, without deleting them.
+	DryRun bool
+}
+
+// Start runs the sweeper until the context is cancelled.
+func (s *Sweeper) Start(ctx context.Context) error {
+	klog.V(2).InfoS("Starting the garbage collection sweeper", "interval", s.Interval, "dryRun", s.DryRun)
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := s.Sweep(ctx); err != nil {
+			klog.ErrorS(err, "Failed to sweep orphaned objects")
+		}
+	}, s.Interval)
+	return nil
+}
+
+// NeedLeaderElection implements the LeaderElectionRunnable interface, so that only the leader sweeps.
+func (s *Sweeper) NeedLeaderElection() bool {
+	return true
+}
+
+// Sweep finds the orphaned objects once and deletes them.
+func (s *Sweeper) Sweep(ctx context.Context) error {
+	startTime := time.Now()
+	klog.V(2).InfoS("Sweep starts")
+	defer func() {
+		klog.V(2).InfoS("Sweep ends", "latency", time.Since(startTime).Milliseconds())
+	}()
+
+	orphans, err := s.Finder.FindOrphans(ctx, startTime)
+	if err != nil {
+		return err
+	}
+	for _, o := range orphans {
+		orphanedObjects.WithLabelValues(o.Kind).Set(float64(len(o.Objects)))
+	}
+	for _, o := range orphans {
+		for _, obj := range o.Objects {
+			if err := s.deleteOrphan(ctx, o.Kind, obj); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// deleteOrphan deletes an orphaned object, unless the sweeper runs in the dry-run mode.
+func (s *Sweeper) deleteOrphan(ctx context.Context, kind string, obj client.Object) error {
+	if s.DryRun {
+		klog.V(2).InfoS("Found orphaned object; skip deletion in the dry-run mode", "kind", kind, "object", klog.KObj(obj))
+		return nil
+	}
+	klog.V(2).InfoS("Delete orphaned object", "kind", kind, "object", klog.KObj(obj))
+	if err := s.Client.Delete(ctx, obj, client.Preconditions{UID: ptr.To(obj.GetUID())}); err != nil {
+		if errors.IsNotFound(err) || errors.IsConflict(err) {
+			// The object has been deleted or re-created since the scan.
+			return nil
+		}
+		klog.ErrorS(err, "Failed to delete orphaned object", "kind", kind, "object", klog.KObj(obj))
+		return err
+	}
+	deletedObjectsTotal.WithLabelValues(kind).Inc()
+	return nil
+}
+
+// IsOrphanCandidate returns if an object should be checked for orphans; objects which have been marked for
+// deletion or are too young are skipped.
+func IsOrphanCandidate(obj client.Object, now time.Time) bool {
+	return obj.GetDeletionTimestamp() == nil && now.Sub(obj.GetCreationTimestamp().Time) >= MinOrphanAge
+}
+
+// ToObjects converts typed objects to client objects, e.g. the orphans found by an OrphanFinder.
+func ToObjects[T client.Object](objs []T) []client.Object {
+	res := make([]client.Object, 0, len(objs))
+	for _, obj := range objs {
+		res = append(res, obj)
+	}
+	return res
+}
@@ -0,0 +1,159 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package gc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	testNamespace = "work"
+	testKind      = "ConfigMap"
+)
+
+var (
+	now       = time.Date(2024, 1, 1, 0, 10, 0, 0, time.UTC)
+	oldTime   = metav1.NewTime(now.Add(-time.Hour))
+	youngTime = metav1.NewTime(now.Add(-time.Second))
+)
+
+// orphanFinderFunc finds the orphans with a function.
+type orphanFinderFunc func(ctx context.Context, now time.Time) ([]Orphans, error)
+
+func (f orphanFinderFunc) FindOrphans(ctx context.Context, now time.Time) ([]Orphans, error) {
+	return f(ctx, now)
+}
+
+func configMap(name string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      name,
+		},
+	}
+}
+
+// TestSweep tests the Sweep method.
+func TestSweep(t *testing.T) {
+	testCases := []struct {
+		name        string
+		dryRun      bool
+		wantDeleted bool
+	}{
+		{
+			name:        "should delete orphaned objects",
+			wantDeleted: true,
+		},
+		{
+			name:   "should keep orphaned objects in the dry-run mode",
+			dryRun: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(configMap("kept"), configMap("orphaned")).
+				Build()
+			sweeper := &Sweeper{
+				Client: fakeClient,
+				Finder: orphanFinderFunc(func(ctx context.Context, _ time.Time) ([]Orphans, error) {
+					orphan := &corev1.ConfigMap{}
+					if err := fakeClient.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: "orphaned"}, orphan); err != nil {
+						return nil, err
+					}
+					return []Orphans{{Kind: testKind, Objects: []client.Object{orphan}}}, nil
+				}),
+				DryRun: tc.dryRun,
+			}
+			deletedBefore := testutil.ToFloat64(deletedObjectsTotal.WithLabelValues(testKind))
+
+			ctx := context.Background()
+			if err := sweeper.Sweep(ctx); err != nil {
+				t.Fatalf("Sweep() = %v, want no error", err)
+			}
+
+			if err := fakeClient.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: "kept"}, &corev1.ConfigMap{}); err != nil {
+				t.Errorf("Get(kept) = %v, want no error", err)
+			}
+			err := fakeClient.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: "orphaned"}, &corev1.ConfigMap{})
+			if tc.wantDeleted != errors.IsNotFound(err) {
+				t.Errorf("Get(orphaned) = %v, want deleted %t", err, tc.wantDeleted)
+			}
+
+			if got := testutil.ToFloat64(orphanedObjects.WithLabelValues(testKind)); got != 1 {
+				t.Errorf("orphaned objects = %v, want 1", got)
+			}
+			wantDeletedCount := 0.0
+			if tc.wantDeleted {
+				wantDeletedCount = 1
+			}
+			if got := testutil.ToFloat64(deletedObjectsTotal.WithLabelValues(testKind)) - deletedBefore; got != wantDeletedCount {
+				t.Errorf("deleted objects = %v, want %v", got, wantDeletedCount)
+			}
+		})
+	}
+}
+
+// TestIsOrphanCandidate tests the IsOrphanCandidate function.
+func TestIsOrphanCandidate(t *testing.T) {
+	deletionTimestamp := metav1.NewTime(now)
+	testCases := []struct {
+		name string
+		obj  *corev1.ConfigMap
+		want bool
+	}{
+		{
+			name: "old object",
+			obj:  &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: oldTime}},
+			want: true,
+		},
+		{
+			name: "young object",
+			obj:  &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: youngTime}},
+		},
+		{
+			name: "deleted object",
+			obj:  &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: oldTime, DeletionTimestamp: &deletionTimestamp}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := IsOrphanCandidate(tc.obj, now); got != tc.want {
+				t.Errorf("IsOrphanCandidate() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestToObjects tests the ToObjects function.
+func TestToObjects(t *testing.T) {
+	if got := ToObjects([]*corev1.ConfigMap{}); len(got) != 0 {
+		t.Errorf("ToObjects(empty) = %v, want no objects", got)
+	}
+	configMaps := []*corev1.ConfigMap{configMap("app"), configMap("db")}
+	got := ToObjects(configMaps)
+	if len(got) != len(configMaps) {
+		t.Fatalf("ToObjects() returns %d objects, want %d", len(got), len(configMaps))
+	}
+	for i := range configMaps {
+		if got[i] != client.Object(configMaps[i]) {
+			t.Errorf("ToObjects()[%d] = %v, want %v", i, got[i], configMaps[i])
+		}
+	}
+}
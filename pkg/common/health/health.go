@@ -0,0 +1,168 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package health features the health (liveness) and readiness checks of the fleet networking controller managers.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+)
+
+const (
+	// CheckTypeHealthz and CheckTypeReadyz are the types of the checks, as reported in the metrics.
+	CheckTypeHealthz = "healthz"
+	CheckTypeReadyz  = "readyz"
+
+	// checkTimeout is the longest time a single check may take.
+	checkTimeout = 5 * time.Second
+
+	// azureManagementScope is the scope of the tokens requested to check the Azure credentials.
+	azureManagementScope = "https://management.azure.com/.default"
+
+	// longestRunningProcessorMetricName is the name of the workqueue metric which tracks how long the longest running
+	// reconcile of each controller has been running.
+	longestRunningProcessorMetricName = "workqueue_longest_running_processor_seconds"
+)
+
+var (
+	// checkStatus is a Prometheus gauge metric which tracks the result of the last run of each check, 1 for passing
+	// and 0 for failing.
+	checkStatus = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metrics.MetricsNamespace,
+			Subsystem: metrics.MetricsSubsystem,
+			Name:      "health_check_status",
+			Help:      "The result of the last run of the health or readiness check, 1 for passing and 0 for failing",
+		},
+		[]string{"type", "check"},
+	)
+	// checkFailuresTotal is a Prometheus counter metric which tracks the number of failed runs of each check.
+	checkFailuresTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metrics.MetricsNamespace,
+			Subsystem: metrics.MetricsSubsystem,
+			Name:      "health_check_failures_total",
+			Help:      "The number of failed runs of the health or readiness check",
+		},
+		[]string{"type", "check"},
+	)
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(checkStatus, checkFailuresTotal)
+}
+
+// Instrument wraps a check so that the result of each run is exported as metrics.
+func Instrument(checkType, name string, check healthz.Checker) healthz.Checker {
+	return func(req *http.Request) error {
+		err := check(req)
+		if err != nil {
+			checkStatus.WithLabelValues(checkType, name).Set(0)
+			checkFailuresTotal.WithLabelValues(checkType, name).Inc()
+			return err
+		}
+		checkStatus.WithLabelValues(checkType, name).Set(1)
+		return nil
+	}
+}
+
+// CacheSyncCheck returns a readiness check which passes once the informer caches of a manager have synced.
+func CacheSyncCheck(c cache.Cache) healthz.Checker {
+	return func(req *http.Request) error {
+		ctx, cancel := context.WithTimeout(req.Context(), checkTimeout)
+		defer cancel()
+		if !c.WaitForCacheSync(ctx) {
+			return errors.New("informer caches have not synced")
+		}
+		return nil
+	}
+}
+
+// APIServerCheck returns a readiness check which passes if the API server is reachable and accepts the current
+// credentials of the given config; as the token file of the config is re-read when it changes, a rotated token
+// is picked up without restarts.
+func APIServerCheck(cfg *rest.Config) (healthz.Checker, error) {
+	cfg = rest.CopyConfig(cfg)
+	cfg.Timeout = checkTimeout
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the discovery client: %w", err)
+	}
+	return func(req *http.Request) error {
+		if err := discoveryClient.RESTClient().Get().AbsPath("/version").Do(req.Context()).Error(); err != nil {
+			return fmt.Errorf("API server %s is not reachable: %w", cfg.Host, err)
+		}
+		return nil
+	}, nil
+}
+
+// AzureCredentialCheck returns a readiness check which passes if a token for the Azure Resource Manager can be
+// acquired with the given credential; the credential caches the token, so most runs do not reach Azure.
+//
+// It is not registered by the controller managers yet, as the Traffic Manager controllers, which would build the
+// Azure credential from the cloud config, are not started.
+func AzureCredentialCheck(cred azcore.TokenCredential) healthz.Checker {
+	return func(req *http.Request) error {
+		ctx, cancel := context.WithTimeout(req.Context(), checkTimeout)
+		defer cancel()
+		if _, err := cred.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{azureManagementScope}}); err != nil {
+			return fmt.Errorf("failed to acquire the Azure token: %w", err)
+		}
+		return nil
+	}
+}
+
+// ReconcileStallCheck returns a health check which fails when any controller of the process has been running a
+// single reconcile for longer than the given timeout, i.e. the reconcile loop of the controller is stalled.
+//
+// The check reads the workqueue metrics maintained by controller-runtime, so it covers all the controllers
+// without any change to them.
+func ReconcileStallCheck(timeout time.Duration) healthz.Checker {
+	return reconcileStallCheck(ctrlmetrics.Registry, timeout)
+}
+
+func reconcileStallCheck(gatherer prometheus.Gatherer, timeout time.Duration) healthz.Checker {
+	return func(_ *http.Request) error {
+		families, err := gatherer.Gather()
+		if err != nil {
+			// Do not restart the process only because some metrics cannot be collected.
+			return nil
+		}
+		for _, family := range families {
+			if family.GetName() != longestRunningProcessorMetricName {
+				continue
+			}
+			for _, m := range family.GetMetric() {
+				running := time.Duration(m.GetGauge().GetValue() * float64(time.Second))
+				if running <= timeout {
+					continue
+				}
+				controller := ""
+				for _, label := range m.GetLabel() {
+					if label.GetName() == "name" {
+						controller = label.GetValue()
+					}
+				}
+				return fmt.Errorf("the reconcile loop of controller %q has been stalled for %s", controller, running.Round(time.Second))
+			}
+		}
+		return nil
+	}
+}
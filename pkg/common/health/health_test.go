@@ -0,0 +1,167 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+var errFake = errors.New("fake error")
+
+type fakeCache struct {
+	cache.Cache
+	synced bool
+}
+
+func (c *fakeCache) WaitForCacheSync(_ context.Context) bool {
+	return c.synced
+}
+
+type fakeCredential struct {
+	err error
+}
+
+func (c *fakeCredential) GetToken(_ context.Context, _ policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "token", ExpiresOn: time.Now().Add(time.Hour)}, c.err
+}
+
+func newRequest() *http.Request {
+	return httptest.NewRequest(http.MethodGet, "/readyz", nil)
+}
+
+func TestInstrument(t *testing.T) {
+	var checkErr error
+	check := Instrument(CheckTypeReadyz, "test-instrument", func(_ *http.Request) error {
+		return checkErr
+	})
+
+	if err := check(newRequest()); err != nil {
+		t.Fatalf("check() = %v, want no error", err)
+	}
+	if got := testutil.ToFloat64(checkStatus.WithLabelValues(CheckTypeReadyz, "test-instrument")); got != 1 {
+		t.Errorf("health_check_status = %v, want 1", got)
+	}
+
+	checkErr = errFake
+	if err := check(newRequest()); !errors.Is(err, errFake) {
+		t.Fatalf("check() = %v, want %v", err, errFake)
+	}
+	if got := testutil.ToFloat64(checkStatus.WithLabelValues(CheckTypeReadyz, "test-instrument")); got != 0 {
+		t.Errorf("health_check_status = %v, want 0", got)
+	}
+	if got := testutil.ToFloat64(checkFailuresTotal.WithLabelValues(CheckTypeReadyz, "test-instrument")); got != 1 {
+		t.Errorf("health_check_failures_total = %v, want 1", got)
+	}
+}
+
+func TestCacheSyncCheck(t *testing.T) {
+	if err := CacheSyncCheck(&fakeCache{synced: true})(newRequest()); err != nil {
+		t.Errorf("CacheSyncCheck() = %v, want no error", err)
+	}
+	if err := CacheSyncCheck(&fakeCache{synced: false})(newRequest()); err == nil {
+		t.Error("CacheSyncCheck() = nil, want error")
+	}
+}
+
+func TestAPIServerCheck(t *testing.T) {
+	testCases := []struct {
+		name       string
+		statusCode int
+		wantErr    bool
+	}{
+		{
+			name:       "API server is reachable",
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "token is rejected",
+			statusCode: http.StatusUnauthorized,
+			wantErr:    true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/version" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tc.statusCode)
+				_, _ = w.Write([]byte(`{}`))
+			}))
+			defer server.Close()
+
+			check, err := APIServerCheck(&rest.Config{Host: server.URL})
+			if err != nil {
+				t.Fatalf("APIServerCheck() = %v, want no error", err)
+			}
+			if err := check(newRequest()); (err != nil) != tc.wantErr {
+				t.Errorf("check() = %v, want error %t", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestAzureCredentialCheck(t *testing.T) {
+	if err := AzureCredentialCheck(&fakeCredential{})(newRequest()); err != nil {
+		t.Errorf("AzureCredentialCheck() = %v, want no error", err)
+	}
+	if err := AzureCredentialCheck(&fakeCredential{err: errFake})(newRequest()); !errors.Is(err, errFake) {
+		t.Errorf("AzureCredentialCheck() = %v, want %v", err, errFake)
+	}
+}
+
+func TestReconcileStallCheck(t *testing.T) {
+	testCases := []struct {
+		name           string
+		longestRunning map[string]float64
+		wantErr        bool
+	}{
+		{
+			name: "no controller",
+		},
+		{
+			name:           "all reconcile loops are making progress",
+			longestRunning: map[string]float64{"serviceexport": 0, "serviceimport": 30},
+		},
+		{
+			name:           "stalled reconcile loop",
+			longestRunning: map[string]float64{"serviceexport": 0, "serviceimport": 3600},
+			wantErr:        true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			registry := prometheus.NewRegistry()
+			longestRunningProcessor := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+				Subsystem: "workqueue",
+				Name:      "longest_running_processor_seconds",
+			}, []string{"name", "controller"})
+			registry.MustRegister(longestRunningProcessor)
+			for controller, seconds := range tc.longestRunning {
+				longestRunningProcessor.WithLabelValues(controller, controller).Set(seconds)
+			}
+
+			err := reconcileStallCheck(registry, 15*time.Minute)(newRequest())
+			if (err != nil) != tc.wantErr {
+				t.Errorf("reconcileStallCheck() = %v, want error %t", err, tc.wantErr)
+			}
+		})
+	}
+}
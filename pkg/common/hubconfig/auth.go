@@ -0,0 +1,232 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package hubconfig
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"golang.org/x/oauth2"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/transport"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+
+	"go.goms.io/fleet-networking/pkg/common/env"
+)
+
+const (
+	// Environment variable keys for the authentication to the hub cluster.
+	hubAuthTypeEnvKey       = "HUB_AUTH_TYPE"
+	hubClientCertPathEnvKey = "HUB_CLIENT_CERTIFICATE_PATH"
+	hubClientKeyPathEnvKey  = "HUB_CLIENT_KEY_PATH"
+	hubExecCommandEnvKey    = "HUB_EXEC_COMMAND"
+	hubExecArgsEnvKey       = "HUB_EXEC_ARGS"
+	hubExecAPIVersionEnvKey = "HUB_EXEC_API_VERSION"
+	hubAADServerAppIDEnvKey = "HUB_AAD_SERVER_APP_ID"
+
+	// Environment variable keys injected by the Azure workload identity webhook.
+	azureClientIDEnvKey           = "AZURE_CLIENT_ID"
+	azureTenantIDEnvKey           = "AZURE_TENANT_ID"
+	azureFederatedTokenFileEnvKey = "AZURE_FEDERATED_TOKEN_FILE"
+
+	// The supported authentication types.
+	authTypeToken                 = "token"
+	authTypeCertificate           = "certificate"
+	authTypeExec                  = "exec"
+	authTypeAzureWorkloadIdentity = "azure-workload-identity"
+
+	defaultExecAPIVersion = "client.authentication.k8s.io/v1"
+	// defaultAADServerAppID is the application ID of the Microsoft Entra server application used by AKS clusters
+	// with managed Microsoft Entra integration.
+	defaultAADServerAppID = "6dae42f8-4368-4678-94ff-3960e28e3630"
+
+	// azureTokenEarlyExpiry is how long before its expiry an Azure access token is refreshed.
+	azureTokenEarlyExpiry = 5 * time.Minute
+	// azureTokenRequestTimeout is how long to wait for an Azure access token.
+	azureTokenRequestTimeout = 30 * time.Second
+)
+
+var (
+	// tokenFileBackoff is how long to wait for the token file, which is created asynchronously by the token refresh
+	// container, before the agent starts without it.
+	tokenFileBackoff = wait.Backoff{
+		Steps:    6,
+		Duration: time.Second,
+		Factor:   2.0,
+	}
+)
+
+// setAuth sets the authentication to the hub cluster, which is one of
+//   - "token" (default): a bearer token read from the file specified by the "CONFIG_PATH" environment variable;
+//   - "certificate": a client certificate and key read from the files specified by the
+//     "HUB_CLIENT_CERTIFICATE_PATH" and "HUB_CLIENT_KEY_PATH" environment variables;
+//   - "exec": a client-go credential plugin run with the command specified by the "HUB_EXEC_COMMAND" environment
+//     variable and the comma-separated arguments specified by the "HUB_EXEC_ARGS" environment variable; or
+//   - "azure-workload-identity": a Microsoft Entra token acquired with the Azure workload identity of the pod, for
+//     the application specified by the "HUB_AAD_SERVER_APP_ID" environment variable.
+//
+// The token and certificate files are reloaded whenever they change, so that the credentials can be rotated
+// without restarts; the credentials of the other methods are refreshed before they expire.
+func setAuth(hubConfig *rest.Config) (string, error) {
+	authType := authTypeToken
+	if t, err := env.Lookup(hubAuthTypeEnvKey); err == nil {
+		authType = t
+	}
+	var err error
+	switch authType {
+	case authTypeToken:
+		err = setTokenAuth(hubConfig)
+	case authTypeCertificate:
+		err = setCertificateAuth(hubConfig)
+	case authTypeExec:
+		err = setExecAuth(hubConfig)
+	case authTypeAzureWorkloadIdentity:
+		err = setAzureWorkloadIdentityAuth(hubConfig)
+	default:
+		err = fmt.Errorf("unsupported hub authentication type %q", authType)
+		klog.ErrorS(err, "Invalid hub authentication type", "authType", authType)
+	}
+	return authType, err
+}
+
+func setTokenAuth(hubConfig *rest.Config) error {
+	tokenFilePath, err := env.Lookup(tokenConfigPathEnvKey)
+	if err != nil {
+		klog.ErrorS(err, "Hub token file path cannot be empty")
+		return err
+	}
+
+	// Retry on obtaining token file as it is created asynchronously by token-refesh container
+	if err := retry.OnError(tokenFileBackoff, func(e error) bool {
+		return true
+	}, func() error {
+		// Stat returns file info. It will return an error if there is no file.
+		_, err := os.Stat(tokenFilePath)
+		return err
+	}); err != nil {
+		// Instead of crashing, start without the token file: the requests to the hub cluster fail with a clear
+		// error, and the agent is reported as not ready, until the token file is created.
+		klog.ErrorS(err, "The hub token file is still missing; the agent keeps waiting for it and stays not ready until it is created", "path", tokenFilePath)
+		hubConfig.Wrap(transport.ResettableTokenSourceWrapTransport(transport.NewCachedFileTokenSource(tokenFilePath)))
+		return nil
+	}
+	hubConfig.BearerTokenFile = tokenFilePath
+	return nil
+}
+
+func setCertificateAuth(hubConfig *rest.Config) error {
+	certPath, err := env.Lookup(hubClientCertPathEnvKey)
+	if err != nil {
+		klog.ErrorS(err, "Hub client certificate path cannot be empty")
+		return err
+	}
+	keyPath, err := env.Lookup(hubClientKeyPathEnvKey)
+	if err != nil {
+		klog.ErrorS(err, "Hub client key path cannot be empty")
+		return err
+	}
+	// client-go reloads the client certificate whenever the files change.
+	hubConfig.CertFile = certPath
+	hubConfig.KeyFile = keyPath
+	return nil
+}
+
+func setExecAuth(hubConfig *rest.Config) error {
+	command, err := env.Lookup(hubExecCommandEnvKey)
+	if err != nil {
+		klog.ErrorS(err, "Hub credential plugin command cannot be empty")
+		return err
+	}
+	apiVersion := defaultExecAPIVersion
+	if v, err := env.Lookup(hubExecAPIVersionEnvKey); err == nil {
+		apiVersion = v
+	}
+	var args []string
+	if a, err := env.Lookup(hubExecArgsEnvKey); err == nil {
+		for _, arg := range strings.Split(a, ",") {
+			if arg = strings.TrimSpace(arg); arg != "" {
+				args = append(args, arg)
+			}
+		}
+	}
+	hubConfig.ExecProvider = &clientcmdapi.ExecConfig{
+		Command:         command,
+		Args:            args,
+		APIVersion:      apiVersion,
+		InteractiveMode: clientcmdapi.NeverExecInteractiveMode,
+	}
+	return nil
+}
+
+func setAzureWorkloadIdentityAuth(hubConfig *rest.Config) error {
+	clientID, err := env.Lookup(azureClientIDEnvKey)
+	if err != nil {
+		klog.ErrorS(err, "Azure client ID cannot be empty; is the Azure workload identity enabled for the pod?")
+		return err
+	}
+	tenantID, err := env.Lookup(azureTenantIDEnvKey)
+	if err != nil {
+		klog.ErrorS(err, "Azure tenant ID cannot be empty; is the Azure workload identity enabled for the pod?")
+		return err
+	}
+	federatedTokenFile, err := env.Lookup(azureFederatedTokenFileEnvKey)
+	if err != nil {
+		klog.ErrorS(err, "Azure federated token file cannot be empty; is the Azure workload identity enabled for the pod?")
+		return err
+	}
+	serverAppID := defaultAADServerAppID
+	if id, err := env.Lookup(hubAADServerAppIDEnvKey); err == nil {
+		serverAppID = id
+	}
+
+	// The credential honors the "AZURE_AUTHORITY_HOST" environment variable injected by the webhook as well, and
+	// reads the latest federated token, which is rotated by the kubelet, whenever it requests a new access token.
+	cred, err := azidentity.NewWorkloadIdentityCredential(&azidentity.WorkloadIdentityCredentialOptions{
+		ClientID:      clientID,
+		TenantID:      tenantID,
+		TokenFilePath: federatedTokenFile,
+	})
+	if err != nil {
+		klog.ErrorS(err, "Failed to create the Azure workload identity credential")
+		return err
+	}
+	src := &azureTokenSource{
+		cred:  cred,
+		scope: serverAppID + "/.default",
+	}
+	hubConfig.Wrap(transport.TokenSourceWrapTransport(oauth2.ReuseTokenSourceWithExpiry(nil, src, azureTokenEarlyExpiry)))
+	return nil
+}
+
+// azureTokenSource acquires Microsoft Entra access tokens for a scope with an Azure credential.
+type azureTokenSource struct {
+	cred  azcore.TokenCredential
+	scope string
+}
+
+// Token implements oauth2.TokenSource.
+func (s *azureTokenSource) Token() (*oauth2.Token, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), azureTokenRequestTimeout)
+	defer cancel()
+	token, err := s.cred.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{s.scope}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to request the Azure access token: %w", err)
+	}
+	return &oauth2.Token{
+		AccessToken: token.Token,
+		TokenType:   "Bearer",
+		Expiry:      token.ExpiresOn,
+	}, nil
+}
@@ -0,0 +1,70 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package hubconfig
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/google/go-cmp/cmp"
+)
+
+type fakeTokenCredential struct {
+	token     azcore.AccessToken
+	err       error
+	gotScopes []string
+}
+
+func (c *fakeTokenCredential) GetToken(_ context.Context, opts policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	c.gotScopes = opts.Scopes
+	return c.token, c.err
+}
+
+func TestAzureTokenSource(t *testing.T) {
+	expiresOn := time.Now().Add(time.Hour)
+	testCases := []struct {
+		name      string
+		cred      *fakeTokenCredential
+		wantToken string
+		wantErr   bool
+	}{
+		{
+			name:      "token is acquired",
+			cred:      &fakeTokenCredential{token: azcore.AccessToken{Token: "access-token", ExpiresOn: expiresOn}},
+			wantToken: "access-token",
+		},
+		{
+			name:    "token request is rejected",
+			cred:    &fakeTokenCredential{err: errors.New("unauthorized client")},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			src := &azureTokenSource{
+				cred:  tc.cred,
+				scope: "server-app-id/.default",
+			}
+			token, err := src.Token()
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Token() = %v, want error %t", err, tc.wantErr)
+			}
+			if diff := cmp.Diff([]string{"server-app-id/.default"}, tc.cred.gotScopes); diff != "" {
+				t.Errorf("Token() requests scopes mismatch (-want, +got):\n%s", diff)
+			}
+			if tc.wantErr {
+				return
+			}
+			if token.AccessToken != tc.wantToken || token.TokenType != "Bearer" || !token.Expiry.Equal(expiresOn) {
+				t.Errorf("Token() = %+v, want bearer token %q expiring at %v", token, tc.wantToken, expiresOn)
+			}
+		})
+	}
+}
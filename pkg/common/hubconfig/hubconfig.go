@@ -14,11 +14,11 @@ import (
 	"io"
 	"net/http"
 	"net/textproto"
+	"net/url"
 	"os"
 	"strings"
 
 	"k8s.io/client-go/rest"
-	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 
 	"go.goms.io/fleet-networking/pkg/common/env"
@@ -30,6 +30,7 @@ const (
 	hubServerURLEnvKey    = "HUB_SERVER_URL"
 	tokenConfigPathEnvKey = "CONFIG_PATH" //nolint:gosec
 	hubCAEnvKey           = "HUB_CERTIFICATE_AUTHORITY"
+	hubCAPathEnvKey       = "HUB_CERTIFICATE_AUTHORITY_PATH"
 	hubKubeHeaderEnvKey   = "HUB_KUBE_HEADER"
 
 	// Naming pattern of member cluster namespace in hub cluster, should be the same as envValue as defined in
@@ -39,6 +40,11 @@ const (
 
 // PrepareHubConfig return the config holding attributes for a Kubernetes client to request hub cluster.
 // Called must make sure all required environment variables are well set.
+//
+// The member agents authenticate to the hub cluster with one of the methods selected by the "HUB_AUTH_TYPE"
+// environment variable (see setAuth); the certificate authority of the hub cluster is either set inline (base64
+// encoded) with the "HUB_CERTIFICATE_AUTHORITY" environment variable, or read from the file specified by the
+// "HUB_CERTIFICATE_AUTHORITY_PATH" environment variable, which is reloaded whenever it changes.
 func PrepareHubConfig(tlsClientInsecure bool) (*rest.Config, error) {
 	hubURL, err := env.Lookup(hubServerURLEnvKey)
 	if err != nil {
@@ -46,49 +52,19 @@ func PrepareHubConfig(tlsClientInsecure bool) (*rest.Config, error) {
 		return nil, err
 	}
 
-	tokenFilePath, err := env.Lookup(tokenConfigPathEnvKey)
-	if err != nil {
-		klog.ErrorS(err, "Hub token file path cannot be empty")
-		return nil, err
+	hubConfig := &rest.Config{
+		Host: hubURL,
+		TLSClientConfig: rest.TLSClientConfig{
+			Insecure: tlsClientInsecure,
+		},
 	}
-
-	// Retry on obtaining token file as it is created asynchronously by token-refesh container
-	if err := retry.OnError(retry.DefaultRetry, func(e error) bool {
-		return true
-	}, func() error {
-		// Stat returns file info. It will return an error if there is no file.
-		_, err := os.Stat(tokenFilePath)
-		return err
-	}); err != nil {
-		klog.ErrorS(err, "Cannot retrieve token file from the path %s", tokenFilePath)
+	authType, err := setAuth(hubConfig)
+	if err != nil {
 		return nil, err
 	}
-	var hubConfig *rest.Config
-	if tlsClientInsecure {
-		hubConfig = &rest.Config{
-			BearerTokenFile: tokenFilePath,
-			Host:            hubURL,
-			TLSClientConfig: rest.TLSClientConfig{
-				Insecure: tlsClientInsecure,
-			},
-		}
-	} else {
-		var caData []byte
-		hubCA, err := env.Lookup(hubCAEnvKey)
-		if err == nil {
-			caData, err = base64.StdEncoding.DecodeString(hubCA)
-			if err != nil {
-				klog.ErrorS(err, "Cannot decode hub cluster certificate authority data")
-				return nil, err
-			}
-		}
-		hubConfig = &rest.Config{
-			BearerTokenFile: tokenFilePath,
-			Host:            hubURL,
-			TLSClientConfig: rest.TLSClientConfig{
-				Insecure: tlsClientInsecure,
-				CAData:   caData,
-			},
+	if !tlsClientInsecure {
+		if err := setCA(hubConfig, authType); err != nil {
+			return nil, err
 		}
 	}
 
@@ -101,13 +77,72 @@ func PrepareHubConfig(tlsClientInsecure bool) (*rest.Config, error) {
 			klog.ErrorS(err, "failed to parse HUB_KUBE_HEADER %q", header)
 			return nil, err
 		}
-		hubConfig.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
+		hubConfig.Wrap(func(rt http.RoundTripper) http.RoundTripper {
 			return httpclient.NewCustomHeadersRoundTripper(http.Header(h), rt)
-		}
+		})
 	}
 	return hubConfig, nil
 }
 
+// setCA sets the certificate authority of the hub cluster; if none is set, the OS's CA bundle is used.
+func setCA(hubConfig *rest.Config, authType string) error {
+	if hubCA, err := env.Lookup(hubCAEnvKey); err == nil {
+		caData, err := base64.StdEncoding.DecodeString(hubCA)
+		if err != nil {
+			klog.ErrorS(err, "Cannot decode hub cluster certificate authority data")
+			return err
+		}
+		hubConfig.CAData = caData
+		return nil
+	}
+
+	caPath, err := env.Lookup(hubCAPathEnvKey)
+	if err != nil {
+		return nil
+	}
+	if authType == authTypeExec {
+		// The credential plugins may provide client certificates through a TLS callback, which client-go does not
+		// allow to be combined with a custom transport; the certificate authority is loaded once instead.
+		klog.InfoS("The hub cluster certificate authority is not reloaded with the exec authentication", "path", caPath)
+		hubConfig.CAFile = caPath
+		return nil
+	}
+	// Verify the certificate authority file once, so that a misconfiguration is reported at startup.
+	if _, err := os.Stat(caPath); err != nil {
+		klog.ErrorS(err, "Cannot read hub cluster certificate authority file", "path", caPath)
+		return err
+	}
+	serverName, err := hubServerName(hubConfig)
+	if err != nil {
+		klog.ErrorS(err, "Cannot determine the hub API server name to verify its certificate", "host", hubConfig.Host)
+		return err
+	}
+	hubConfig.Transport = newReloadingTransport(serverName, caPath, hubConfig.CertFile, hubConfig.KeyFile)
+	// The client certificate (if any) is served by the transport as well, which reloads it as client-go does.
+	hubConfig.CertFile, hubConfig.KeyFile = "", ""
+	return nil
+}
+
+// hubServerName returns the name which the certificate of the hub API server is verified against, i.e. the
+// explicitly configured server name, or the hostname of the hub server URL.
+func hubServerName(hubConfig *rest.Config) (string, error) {
+	if hubConfig.ServerName != "" {
+		return hubConfig.ServerName, nil
+	}
+	host := hubConfig.Host
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+	u, err := url.Parse(host)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the hub server URL %q: %w", hubConfig.Host, err)
+	}
+	if u.Hostname() == "" {
+		return "", fmt.Errorf("hub server URL %q has no hostname", hubConfig.Host)
+	}
+	return u.Hostname(), nil
+}
+
 // FetchMemberClusterNamespace gets the assigned namespace for the member cluster in the hub.
 func FetchMemberClusterNamespace() (string, error) {
 	mcName, err := env.LookupMemberClusterName()
@@ -10,9 +10,12 @@ import (
 	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 )
 
 func TestPrepareHubConfig(t *testing.T) {
@@ -110,6 +113,159 @@ func TestPrepareHubConfig(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:                 "token file is missing - start without it",
+			environmentVariables: map[string]string{hubServerURLEnvKey: fakeHubhubServerURLEnvVal, tokenConfigPathEnvKey: "testdata/missing-config-path"},
+			tlsClientInsecure:    true,
+			validate: func(t *testing.T, config *rest.Config, err error) {
+				if err != nil {
+					t.Errorf("not expect error but actually get error %s", err)
+				}
+				if config.BearerTokenFile != "" || config.WrapTransport == nil {
+					t.Errorf("got hub config %+v, want the token to be read by WrapTransport", config)
+				}
+			},
+		},
+		{
+			name: "certificate authentication",
+			environmentVariables: map[string]string{
+				hubServerURLEnvKey:      fakeHubhubServerURLEnvVal,
+				hubAuthTypeEnvKey:       authTypeCertificate,
+				hubClientCertPathEnvKey: "testdata/tls.crt",
+				hubClientKeyPathEnvKey:  "testdata/tls.key",
+				hubCAEnvKey:             fakeCerhubCAEnvVal,
+			},
+			tlsClientInsecure: false,
+			validate: func(t *testing.T, config *rest.Config, err error) {
+				if err != nil {
+					t.Errorf("not expect error but actually get error %s", err)
+				}
+				wantConfig := &rest.Config{
+					Host: fakeHubhubServerURLEnvVal,
+					TLSClientConfig: rest.TLSClientConfig{
+						CertFile: "testdata/tls.crt",
+						KeyFile:  "testdata/tls.key",
+						CAData:   fakeCerhubCA,
+					},
+				}
+				if !cmp.Equal(config, wantConfig) {
+					t.Errorf("got hub config %+v, want %+v", config, wantConfig)
+				}
+			},
+		},
+		{
+			name:                 "certificate authentication without the key - error",
+			environmentVariables: map[string]string{hubServerURLEnvKey: fakeHubhubServerURLEnvVal, hubAuthTypeEnvKey: authTypeCertificate, hubClientCertPathEnvKey: "testdata/tls.crt"},
+			tlsClientInsecure:    true,
+			validate: func(t *testing.T, config *rest.Config, err error) {
+				if err == nil {
+					t.Errorf("expect return error if HUB_CLIENT_KEY_PATH not present")
+				}
+			},
+		},
+		{
+			name: "exec authentication",
+			environmentVariables: map[string]string{
+				hubServerURLEnvKey:   fakeHubhubServerURLEnvVal,
+				hubAuthTypeEnvKey:    authTypeExec,
+				hubExecCommandEnvKey: "kubelogin",
+				hubExecArgsEnvKey:    "get-token, --login,msi",
+				hubCAPathEnvKey:      "testdata/ca.crt",
+			},
+			tlsClientInsecure: false,
+			validate: func(t *testing.T, config *rest.Config, err error) {
+				if err != nil {
+					t.Errorf("not expect error but actually get error %s", err)
+				}
+				wantConfig := &rest.Config{
+					Host: fakeHubhubServerURLEnvVal,
+					ExecProvider: &clientcmdapi.ExecConfig{
+						Command:         "kubelogin",
+						Args:            []string{"get-token", "--login", "msi"},
+						APIVersion:      defaultExecAPIVersion,
+						InteractiveMode: clientcmdapi.NeverExecInteractiveMode,
+					},
+					TLSClientConfig: rest.TLSClientConfig{
+						CAFile: "testdata/ca.crt",
+					},
+				}
+				if !cmp.Equal(config, wantConfig) {
+					t.Errorf("got hub config %+v, want %+v", config, wantConfig)
+				}
+			},
+		},
+		{
+			name: "azure workload identity authentication",
+			environmentVariables: map[string]string{
+				hubServerURLEnvKey:            fakeHubhubServerURLEnvVal,
+				hubAuthTypeEnvKey:             authTypeAzureWorkloadIdentity,
+				azureClientIDEnvKey:           "client-id",
+				azureTenantIDEnvKey:           "tenant-id",
+				azureFederatedTokenFileEnvKey: fakeConfigtokenConfigPathEnvVal,
+			},
+			tlsClientInsecure: true,
+			validate: func(t *testing.T, config *rest.Config, err error) {
+				if err != nil {
+					t.Errorf("not expect error but actually get error %s", err)
+				}
+				if config.WrapTransport == nil {
+					t.Error("config.WrapTransport should not be nil with the azure workload identity authentication")
+				}
+			},
+		},
+		{
+			name:                 "azure workload identity authentication without the workload identity - error",
+			environmentVariables: map[string]string{hubServerURLEnvKey: fakeHubhubServerURLEnvVal, hubAuthTypeEnvKey: authTypeAzureWorkloadIdentity},
+			tlsClientInsecure:    true,
+			validate: func(t *testing.T, config *rest.Config, err error) {
+				if err == nil {
+					t.Errorf("expect return error if AZURE_CLIENT_ID not present")
+				}
+			},
+		},
+		{
+			name:                 "unsupported authentication type - error",
+			environmentVariables: map[string]string{hubServerURLEnvKey: fakeHubhubServerURLEnvVal, hubAuthTypeEnvKey: "password"},
+			tlsClientInsecure:    true,
+			validate: func(t *testing.T, config *rest.Config, err error) {
+				if err == nil {
+					t.Errorf("expect return error if HUB_AUTH_TYPE is not supported")
+				}
+			},
+		},
+		{
+			name:                 "environment variable `HUB_CERTIFICATE_AUTHORITY_PATH` is present - use the reloading transport",
+			environmentVariables: map[string]string{hubServerURLEnvKey: fakeHubhubServerURLEnvVal, tokenConfigPathEnvKey: fakeConfigtokenConfigPathEnvVal, hubCAPathEnvKey: "testdata/ca.crt"},
+			tlsClientInsecure:    false,
+			validate: func(t *testing.T, config *rest.Config, err error) {
+				if err != nil {
+					t.Errorf("not expect error but actually get error %s", err)
+				}
+				if config.Transport == nil || config.CAFile != "" {
+					t.Errorf("got hub config %+v, want the certificate authority to be loaded by Transport", config)
+				}
+			},
+		},
+		{
+			name:                 "hub server URL has no hostname with `HUB_CERTIFICATE_AUTHORITY_PATH` - error",
+			environmentVariables: map[string]string{hubServerURLEnvKey: "https://:6443", tokenConfigPathEnvKey: fakeConfigtokenConfigPathEnvVal, hubCAPathEnvKey: "testdata/ca.crt"},
+			tlsClientInsecure:    false,
+			validate: func(t *testing.T, config *rest.Config, err error) {
+				if err == nil {
+					t.Errorf("expect return error if the hostname of the hub server URL cannot be verified")
+				}
+			},
+		},
+		{
+			name:                 "file `HUB_CERTIFICATE_AUTHORITY_PATH` does not exist - error",
+			environmentVariables: map[string]string{hubServerURLEnvKey: fakeHubhubServerURLEnvVal, tokenConfigPathEnvKey: fakeConfigtokenConfigPathEnvVal, hubCAPathEnvKey: "testdata/missing-ca.crt"},
+			tlsClientInsecure:    false,
+			validate: func(t *testing.T, config *rest.Config, err error) {
+				if err == nil {
+					t.Errorf("expect return error if HUB_CERTIFICATE_AUTHORITY_PATH does not exist")
+				}
+			},
+		},
 		{
 			name:                 "environment variable `HUB_KUBE_HEADER` exists - config have WrapTransport",
 			environmentVariables: map[string]string{hubServerURLEnvKey: fakeHubhubServerURLEnvVal, hubKubeHeaderEnvKey: "custom-header: value", tokenConfigPathEnvKey: fakeConfigtokenConfigPathEnvVal},
@@ -136,6 +292,7 @@ func TestPrepareHubConfig(t *testing.T) {
 		},
 	}
 
+	tokenFileBackoff = wait.Backoff{Steps: 1, Duration: time.Millisecond}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			for envKey, envVal := range tc.environmentVariables {
@@ -193,3 +350,44 @@ func TestFetchMemberClusterNamespace(t *testing.T) {
 		})
 	}
 }
+
+func TestHubServerName(t *testing.T) {
+	testCases := []struct {
+		name      string
+		hubConfig *rest.Config
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:      "hostname of the hub server URL",
+			hubConfig: &rest.Config{Host: "https://hub.example.com:443"},
+			want:      "hub.example.com",
+		},
+		{
+			name:      "hub server URL without a scheme",
+			hubConfig: &rest.Config{Host: "hub.example.com"},
+			want:      "hub.example.com",
+		},
+		{
+			name:      "explicitly configured server name",
+			hubConfig: &rest.Config{Host: "https://10.0.0.1", TLSClientConfig: rest.TLSClientConfig{ServerName: "hub.example.com"}},
+			want:      "hub.example.com",
+		},
+		{
+			name:      "hub server URL without a hostname",
+			hubConfig: &rest.Config{Host: "https://:6443"},
+			wantErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := hubServerName(tc.hubConfig)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("hubServerName() = %v, want error %t", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("hubServerName() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package hubconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/klog/v2"
+)
+
+// reloadingFiles caches a value parsed from a set of files, and parses the files again whenever any of them
+// changes; if the changed files cannot be parsed (e.g. they are being rotated), the last good value is kept.
+type reloadingFiles[T any] struct {
+	paths []string
+	parse func(contents [][]byte) (T, error)
+
+	mu       sync.Mutex
+	modTimes []time.Time
+	value    T
+	loaded   bool
+}
+
+func newReloadingFiles[T any](parse func(contents [][]byte) (T, error), paths ...string) *reloadingFiles[T] {
+	return &reloadingFiles[T]{
+		paths:    paths,
+		parse:    parse,
+		modTimes: make([]time.Time, len(paths)),
+	}
+}
+
+// get returns the value parsed from the latest files.
+func (f *reloadingFiles[T]) get() (T, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	modTimes := make([]time.Time, len(f.paths))
+	changed := !f.loaded
+	for i, path := range f.paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return f.fallback(fmt.Errorf("failed to stat %s: %w", path, err))
+		}
+		modTimes[i] = info.ModTime()
+		changed = changed || !modTimes[i].Equal(f.modTimes[i])
+	}
+	if !changed {
+		return f.value, nil
+	}
+
+	contents := make([][]byte, len(f.paths))
+	for i, path := range f.paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return f.fallback(fmt.Errorf("failed to read %s: %w", path, err))
+		}
+		contents[i] = data
+	}
+	value, err := f.parse(contents)
+	if err != nil {
+		return f.fallback(fmt.Errorf("failed to parse %v: %w", f.paths, err))
+	}
+	if f.loaded {
+		klog.V(2).InfoS("Reloaded hub credential files", "paths", f.paths)
+	}
+	f.value, f.modTimes, f.loaded = value, modTimes, true
+	return value, nil
+}
+
+func (f *reloadingFiles[T]) fallback(err error) (T, error) {
+	if !f.loaded {
+		return f.value, err
+	}
+	klog.ErrorS(err, "Failed to reload hub credential files, using the last loaded ones", "paths", f.paths)
+	return f.value, nil
+}
+
+func parseCertPool(contents [][]byte) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(contents[0]) {
+		return nil, errors.New("no valid certificate found")
+	}
+	return pool, nil
+}
+
+func parseKeyPair(contents [][]byte) (*tls.Certificate, error) {
+	cert, err := tls.X509KeyPair(contents[0], contents[1])
+	if err != nil {
+		return nil, err
+	}
+	return &cert, nil
+}
+
+// newReloadingTransport returns a transport which verifies the hub API server, by the serverName, against the
+// certificate authority read from caPath, and presents the client certificate (if any) read from certPath and
+// keyPath; the files are reloaded whenever they change, and the new ones apply to the new connections.
+func newReloadingTransport(serverName, caPath, certPath, keyPath string) http.RoundTripper {
+	caBundle := newReloadingFiles(parseCertPool, caPath)
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// The server certificate is verified in VerifyConnection instead, against the latest certificate authority.
+		InsecureSkipVerify: true, //nolint:gosec
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			return verifyServerCertificate(cs, serverName, caBundle)
+		},
+	}
+	if certPath != "" && keyPath != "" {
+		keyPair := newReloadingFiles(parseKeyPair, certPath, keyPath)
+		tlsConfig.GetClientCertificate = func(_ *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return keyPair.get()
+		}
+	}
+	return utilnet.SetTransportDefaults(&http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		TLSHandshakeTimeout: 10 * time.Second,
+		TLSClientConfig:     tlsConfig,
+		MaxIdleConnsPerHost: 25,
+	})
+}
+
+// verifyServerCertificate verifies the certificate chain presented by the hub API server, including that it is
+// issued for the serverName; an empty serverName is rejected, as the hostname would not be verified otherwise.
+func verifyServerCertificate(cs tls.ConnectionState, serverName string, caBundle *reloadingFiles[*x509.CertPool]) error {
+	if serverName == "" {
+		return errors.New("hub API server name is not set")
+	}
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("hub API server presents no certificate")
+	}
+	roots, err := caBundle.get()
+	if err != nil {
+		return fmt.Errorf("failed to load the hub cluster certificate authority: %w", err)
+	}
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		DNSName:       serverName,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, err = cs.PeerCertificates[0].Verify(opts)
+	return err
+}
@@ -0,0 +1,182 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package hubconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// newTestCert creates a certificate signed by the parent, or a self-signed certificate authority if the parent is nil.
+func newTestCert(t *testing.T, name string, parent *testCert) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate the key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	signerCert, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		signerCert, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signerCert, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("failed to create the certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse the certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal the key: %v", err)
+	}
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+// writeFile writes the file and moves its modification time forward, so that the change is detected even if the
+// file is written more than once within the timestamp granularity of the file system.
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	var modTime time.Time
+	if info, err := os.Stat(path); err == nil {
+		modTime = info.ModTime().Add(time.Second)
+	} else {
+		modTime = time.Now()
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("failed to change the modification time of %s: %v", path, err)
+	}
+}
+
+func TestReloadingTransport(t *testing.T) {
+	serverCA := newTestCert(t, "server-ca", nil)
+	otherCA := newTestCert(t, "other-ca", nil)
+	clientCA := newTestCert(t, "client-ca", nil)
+	serverCert := newTestCert(t, "server", serverCA)
+	oldClientCert := newTestCert(t, "old-client", clientCA)
+	newClientCert := newTestCert(t, "new-client", clientCA)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCA.cert)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	server.TLS = &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		Certificates: []tls.Certificate{{
+			Certificate: [][]byte{serverCert.cert.Raw},
+			PrivateKey:  serverCert.key,
+		}},
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	dir := t.TempDir()
+	caPath := filepath.Join(dir, "ca.crt")
+	certPath := filepath.Join(dir, "tls.crt")
+	keyPath := filepath.Join(dir, "tls.key")
+	writeFile(t, caPath, otherCA.certPEM)
+	writeFile(t, certPath, oldClientCert.certPEM)
+	writeFile(t, keyPath, oldClientCert.keyPEM)
+
+	// The test server certificate is issued for 127.0.0.1.
+	transport := newReloadingTransport("127.0.0.1", caPath, certPath, keyPath).(*http.Transport)
+	client := &http.Client{Transport: transport}
+	get := func() (string, error) {
+		// Start a new connection every time, so that the new credentials apply.
+		transport.CloseIdleConnections()
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		buf := make([]byte, 64)
+		n, _ := resp.Body.Read(buf)
+		return string(buf[:n]), nil
+	}
+
+	if _, err := get(); err == nil {
+		t.Fatal("Get() with an untrusted server certificate = nil, want error")
+	}
+
+	writeFile(t, caPath, serverCA.certPEM)
+	got, err := get()
+	if err != nil {
+		t.Fatalf("Get() after the certificate authority is rotated = %v, want no error", err)
+	}
+	if got != "old-client" {
+		t.Errorf("Get() presents client certificate %q, want %q", got, "old-client")
+	}
+
+	writeFile(t, certPath, newClientCert.certPEM)
+	writeFile(t, keyPath, newClientCert.keyPEM)
+	if got, err = get(); err != nil {
+		t.Fatalf("Get() after the client certificate is rotated = %v, want no error", err)
+	}
+	if got != "new-client" {
+		t.Errorf("Get() presents client certificate %q, want %q", got, "new-client")
+	}
+
+	// A certificate authority file which is being rotated (e.g. empty) does not break the connections.
+	writeFile(t, caPath, nil)
+	if _, err := get(); err != nil {
+		t.Errorf("Get() with an invalid certificate authority file = %v, want the last loaded one to be used", err)
+	}
+
+	// A trusted certificate which is issued for another server is rejected.
+	writeFile(t, caPath, serverCA.certPEM)
+	for _, serverName := range []string{"hub.example.com", ""} {
+		mismatchedTransport := newReloadingTransport(serverName, caPath, certPath, keyPath)
+		resp, err := (&http.Client{Transport: mismatchedTransport}).Get(server.URL)
+		if err == nil {
+			resp.Body.Close()
+			t.Errorf("Get() with server name %q and a certificate issued for 127.0.0.1 = nil, want error", serverName)
+		}
+	}
+}
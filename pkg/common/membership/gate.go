@@ -0,0 +1,81 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package membership features the membership gate, which stops the fleet networking controllers in a member
+// cluster from exporting or importing new resources while the member cluster is leaving (or has left) the fleet.
+package membership
+
+import (
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// Gate tracks whether the member cluster has an active membership in the fleet.
+//
+// The gate starts closed, as the membership is unknown until the InternalMemberCluster controller observes that
+// the member cluster has joined the fleet and opens it; the controller closes it again when it observes that the
+// member cluster is leaving the fleet. The export and import controllers consult the gate before exporting or
+// importing a resource, and watch the gate (see Source) so that the resources skipped while the gate was closed are
+// processed once it opens.
+type Gate struct {
+	mu          sync.RWMutex
+	closed      bool
+	subscribers []chan event.GenericEvent
+}
+
+// NewGate returns a closed Gate.
+func NewGate() *Gate {
+	return &Gate{closed: true}
+}
+
+// IsOpen returns if the member cluster has an active membership in the fleet.
+func (g *Gate) IsOpen() bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return !g.closed
+}
+
+// Close closes the gate; it returns true if the gate was open.
+func (g *Gate) Close() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	wasOpen := !g.closed
+	g.closed = true
+	return wasOpen
+}
+
+// Open opens the gate; it returns true if the gate was closed, in which case all the subscribers are notified.
+func (g *Gate) Open() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.closed {
+		return false
+	}
+	g.closed = false
+	for _, ch := range g.subscribers {
+		select {
+		case ch <- event.GenericEvent{Object: &metav1.PartialObjectMetadata{}}:
+		default:
+			// A notification is already pending; the subscriber will process all the resources anyway.
+		}
+	}
+	return true
+}
+
+// Source returns a controller source which triggers the given handler each time the gate is re-opened; the handler
+// is expected to enqueue all the resources the controller manages, as the object carried by the event is a
+// placeholder.
+func (g *Gate) Source(h handler.EventHandler) source.Source {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	ch := make(chan event.GenericEvent, 1)
+	g.subscribers = append(g.subscribers, ch)
+	return source.Channel[client.Object](ch, h)
+}
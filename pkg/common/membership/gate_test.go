@@ -0,0 +1,81 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package membership
+
+import (
+	"testing"
+
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+// TestGate tests the open/close transitions of a Gate.
+func TestGate(t *testing.T) {
+	g := NewGate()
+	if g.IsOpen() {
+		t.Fatalf("IsOpen() = true, want false for a new gate")
+	}
+	if !g.Open() {
+		t.Fatalf("Open() = false, want true for a new gate")
+	}
+	if g.Open() {
+		t.Fatalf("Open() = true, want false for an open gate")
+	}
+
+	if !g.Close() {
+		t.Fatalf("Close() = false, want true for an open gate")
+	}
+	if g.IsOpen() {
+		t.Fatalf("IsOpen() = true, want false for a closed gate")
+	}
+	if g.Close() {
+		t.Fatalf("Close() = true, want false for a closed gate")
+	}
+
+	if !g.Open() {
+		t.Fatalf("Open() = false, want true for a closed gate")
+	}
+	if !g.IsOpen() {
+		t.Fatalf("IsOpen() = false, want true for a re-opened gate")
+	}
+}
+
+// TestGate_Notifications tests that the subscribers are notified (once) when a Gate is re-opened.
+func TestGate_Notifications(t *testing.T) {
+	g := NewGate()
+	if src := g.Source(&handler.EnqueueRequestForObject{}); src == nil {
+		t.Fatalf("Source() = nil, want a source")
+	}
+	if len(g.subscribers) != 1 {
+		t.Fatalf("subscribers = %d, want 1", len(g.subscribers))
+	}
+	ch := g.subscribers[0]
+
+	// Opening a new gate should notify the subscribers, so that the resources skipped before are processed.
+	g.Open()
+	if len(ch) != 1 {
+		t.Fatalf("pending notifications = %d, want 1", len(ch))
+	}
+	<-ch
+
+	// Opening an open gate should not notify the subscribers.
+	g.Open()
+	if len(ch) != 0 {
+		t.Fatalf("pending notifications = %d, want 0", len(ch))
+	}
+
+	// Re-opening a closed gate twice should leave exactly one pending notification.
+	for i := 0; i < 2; i++ {
+		g.Close()
+		g.Open()
+	}
+	if len(ch) != 1 {
+		t.Fatalf("pending notifications = %d, want 1", len(ch))
+	}
+	e := <-ch
+	if e.Object == nil {
+		t.Fatalf("notification object = nil, want a placeholder object")
+	}
+}
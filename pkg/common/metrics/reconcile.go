@@ -0,0 +1,75 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/prometheus/client_golang/prometheus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// The reasons of the reconcile errors which are not returned by the API servers.
+const (
+	ReconcileErrorReasonTimeout  = "Timeout"
+	ReconcileErrorReasonCanceled = "Canceled"
+	ReconcileErrorReasonUnknown  = "Unknown"
+)
+
+var (
+	// reconcileErrorsTotal is a Prometheus counter metric which tracks the number of reconciles which have failed,
+	// by controller and reason.
+	reconcileErrorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "reconcile_errors_total",
+			Help:      "The number of failed reconciles, by controller and reason",
+		},
+		[]string{"controller", "reason"},
+	)
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(reconcileErrorsTotal)
+}
+
+// InstrumentReconciler wraps a reconciler so that the errors it returns are counted by reason; the controller
+// name should be the one controller-runtime names the controller with (the lowercase kind of the reconciled
+// objects, unless named otherwise), so that the metric can be joined with the controller-runtime ones.
+func InstrumentReconciler(controller string, r reconcile.Reconciler) reconcile.Reconciler {
+	return reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		res, err := r.Reconcile(ctx, req)
+		if err != nil {
+			reconcileErrorsTotal.WithLabelValues(controller, ReconcileErrorReason(err)).Inc()
+		}
+		return res, err
+	})
+}
+
+// ReconcileErrorReason returns a short, low cardinality reason of a reconcile error: the reason of the status
+// returned by the API server (e.g. Conflict), "Azure" followed by the status code of an error returned by Azure
+// (e.g. Azure429), Timeout or Canceled for context errors, and Unknown otherwise.
+func ReconcileErrorReason(err error) string {
+	if reason := apierrors.ReasonForError(err); reason != "" {
+		return string(reason)
+	}
+	var responseErr *azcore.ResponseError
+	switch {
+	case errors.As(err, &responseErr):
+		return fmt.Sprintf("Azure%d", responseErr.StatusCode)
+	case errors.Is(err, context.DeadlineExceeded):
+		return ReconcileErrorReasonTimeout
+	case errors.Is(err, context.Canceled):
+		return ReconcileErrorReasonCanceled
+	}
+	return ReconcileErrorReasonUnknown
+}
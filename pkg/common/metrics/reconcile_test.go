@@ -0,0 +1,87 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReconcileErrorReason(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "API server conflict",
+			err:  apierrors.NewConflict(schema.GroupResource{Resource: "serviceimports"}, "app", errors.New("fake")),
+			want: "Conflict",
+		},
+		{
+			name: "wrapped API server not found",
+			err:  fmt.Errorf("failed to get: %w", apierrors.NewNotFound(schema.GroupResource{Resource: "serviceimports"}, "app")),
+			want: "NotFound",
+		},
+		{
+			name: "Azure throttling",
+			err:  fmt.Errorf("failed to create profile: %w", &azcore.ResponseError{StatusCode: http.StatusTooManyRequests}),
+			want: "Azure429",
+		},
+		{
+			name: "timeout",
+			err:  fmt.Errorf("failed to list: %w", context.DeadlineExceeded),
+			want: ReconcileErrorReasonTimeout,
+		},
+		{
+			name: "canceled",
+			err:  context.Canceled,
+			want: ReconcileErrorReasonCanceled,
+		},
+		{
+			name: "unknown",
+			err:  errors.New("fake"),
+			want: ReconcileErrorReasonUnknown,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ReconcileErrorReason(tc.err); got != tc.want {
+				t.Errorf("ReconcileErrorReason() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestInstrumentReconciler(t *testing.T) {
+	var err error
+	r := InstrumentReconciler("test-instrument", reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+		return reconcile.Result{}, err
+	}))
+
+	if _, gotErr := r.Reconcile(context.Background(), reconcile.Request{}); gotErr != nil {
+		t.Fatalf("Reconcile() = %v, want no error", gotErr)
+	}
+	err = context.DeadlineExceeded
+	if _, gotErr := r.Reconcile(context.Background(), reconcile.Request{}); !errors.Is(gotErr, err) {
+		t.Fatalf("Reconcile() = %v, want %v", gotErr, err)
+	}
+	if got := testutil.ToFloat64(reconcileErrorsTotal.WithLabelValues("test-instrument", ReconcileErrorReasonTimeout)); got != 1 {
+		t.Errorf("reconcile errors = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(reconcileErrorsTotal.WithLabelValues("test-instrument", ReconcileErrorReasonUnknown)); got != 0 {
+		t.Errorf("unknown reconcile errors = %v, want 0", got)
+	}
+}
@@ -0,0 +1,370 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+const (
+	// stateCollectTimeout is how long the objects may be listed for when the state metrics are collected.
+	stateCollectTimeout = 10 * time.Second
+)
+
+var (
+	// conditionStatuses are the statuses for which the condition gauges are always reported, so that a status
+	// which no object has is reported as 0 rather than missing.
+	conditionStatuses = []metav1.ConditionStatus{metav1.ConditionTrue, metav1.ConditionFalse, metav1.ConditionUnknown}
+)
+
+// State metrics, which are computed from the objects in the cache of the controller manager when the metrics are
+// scraped.
+var (
+	// exportedServicesDesc describes a Prometheus gauge metric which tracks the number of Services exported by
+	// each member cluster (i.e. InternalServiceExports).
+	exportedServicesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, MetricsSubsystem, "exported_services"),
+		"The number of Services exported by a member cluster",
+		[]string{"cluster"}, nil,
+	)
+	// conflictedExportsDesc describes a Prometheus gauge metric which tracks the number of Services exported by
+	// each member cluster which are in conflict with the exports from other member clusters.
+	conflictedExportsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, MetricsSubsystem, "conflicted_exports"),
+		"The number of Services exported by a member cluster which are in conflict with other exports",
+		[]string{"cluster"}, nil,
+	)
+	// importsDesc describes a Prometheus gauge metric which tracks the number of Services imported by each member
+	// cluster (i.e. InternalServiceImports).
+	importsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, MetricsSubsystem, "imports"),
+		"The number of Services imported by a member cluster",
+		[]string{"cluster"}, nil,
+	)
+	// endpointSliceExportsDesc describes a Prometheus gauge metric which tracks the number of EndpointSlices
+	// exported by each member cluster.
+	endpointSliceExportsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, MetricsSubsystem, "endpointslice_exports"),
+		"The number of EndpointSlices exported by a member cluster",
+		[]string{"cluster"}, nil,
+	)
+	// endpointSliceImportsDesc describes a Prometheus gauge metric which tracks the number of EndpointSliceImports
+	// distributed to each member cluster, by the hub namespace reserved for the member cluster.
+	endpointSliceImportsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, MetricsSubsystem, "endpointslice_imports"),
+		"The number of EndpointSliceImports distributed to the namespace reserved for a member cluster",
+		[]string{"namespace"}, nil,
+	)
+	// trafficManagerProfilesDesc describes a Prometheus gauge metric which tracks the number of
+	// TrafficManagerProfiles by the status of their Programmed condition.
+	trafficManagerProfilesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, MetricsSubsystem, "traffic_manager_profiles"),
+		"The number of TrafficManagerProfiles by condition status",
+		[]string{"condition", "status"}, nil,
+	)
+	// trafficManagerBackendsDesc describes a Prometheus gauge metric which tracks the number of
+	// TrafficManagerBackends by the status of their Accepted condition.
+	trafficManagerBackendsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, MetricsSubsystem, "traffic_manager_backends"),
+		"The number of TrafficManagerBackends by condition status",
+		[]string{"condition", "status"}, nil,
+	)
+	// importedEndpointsDesc describes a Prometheus gauge metric which tracks the number of endpoints imported into
+	// a member cluster for each derived Service.
+	importedEndpointsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, MetricsSubsystem, "imported_endpoints"),
+		"The number of endpoints imported for a derived Service",
+		[]string{"service"}, nil,
+	)
+	// multiClusterServicesDesc describes a Prometheus gauge metric which tracks the number of MultiClusterServices
+	// by the status of their Valid condition.
+	multiClusterServicesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, MetricsSubsystem, "multicluster_services"),
+		"The number of MultiClusterServices by condition status",
+		[]string{"condition", "status"}, nil,
+	)
+)
+
+// stateMetric is a set of state metrics computed from the same list of objects.
+type stateMetric struct {
+	// name of the objects listed; it is used in logs only.
+	name    string
+	descs   []*prometheus.Desc
+	collect func(ctx context.Context, reader client.Reader, ch chan<- prometheus.Metric) error
+}
+
+// StateCollector is a Prometheus collector which reports the state of the fleet networking objects.
+//
+// The metrics are computed from the cache of the controller manager when they are scraped, rather than maintained
+// by the controllers as the objects change, so that the controllers do not have to report anything and the metrics
+// never drift from the objects, e.g. when an object is deleted while the controller is down. Listing the objects
+// from the cache is cheap and scrapes are infrequent; the objects listed are watched by the controllers anyway.
+type StateCollector struct {
+	reader  client.Reader
+	metrics []stateMetric
+}
+
+var _ prometheus.Collector = &StateCollector{}
+
+// NewHubStateCollector returns a collector which reports the exports and imports of the member clusters, and,
+// if the traffic manager feature is enabled, the TrafficManagerProfiles and TrafficManagerBackends, in the hub
+// cluster.
+func NewHubStateCollector(reader client.Reader, trafficManagerEnabled bool) *StateCollector {
+	c := &StateCollector{
+		reader: reader,
+		metrics: []stateMetric{
+			{
+				name:    "InternalServiceExports",
+				descs:   []*prometheus.Desc{exportedServicesDesc, conflictedExportsDesc},
+				collect: collectInternalServiceExports,
+			},
+			{
+				name:    "InternalServiceImports",
+				descs:   []*prometheus.Desc{importsDesc},
+				collect: collectInternalServiceImports,
+			},
+			{
+				name:    "EndpointSliceExports",
+				descs:   []*prometheus.Desc{endpointSliceExportsDesc},
+				collect: collectEndpointSliceExports,
+			},
+			{
+				name:    "EndpointSliceImports",
+				descs:   []*prometheus.Desc{endpointSliceImportsDesc},
+				collect: collectEndpointSliceImports,
+			},
+		},
+	}
+	if trafficManagerEnabled {
+		c.metrics = append(c.metrics,
+			stateMetric{
+				name:    "TrafficManagerProfiles",
+				descs:   []*prometheus.Desc{trafficManagerProfilesDesc},
+				collect: collectTrafficManagerProfiles,
+			},
+			stateMetric{
+				name:    "TrafficManagerBackends",
+				descs:   []*prometheus.Desc{trafficManagerBackendsDesc},
+				collect: collectTrafficManagerBackends,
+			})
+	}
+	return c
+}
+
+// NewImportedEndpointsCollector returns a collector which reports the endpoints imported into a member cluster,
+// i.e. the endpoints of the EndpointSlices in the given namespace which are managed by the given controller.
+func NewImportedEndpointsCollector(reader client.Reader, namespace, managedBy string) *StateCollector {
+	return &StateCollector{
+		reader: reader,
+		metrics: []stateMetric{
+			{
+				name:  "EndpointSlices",
+				descs: []*prometheus.Desc{importedEndpointsDesc},
+				collect: func(ctx context.Context, reader client.Reader, ch chan<- prometheus.Metric) error {
+					return collectImportedEndpoints(ctx, reader, namespace, managedBy, ch)
+				},
+			},
+		},
+	}
+}
+
+// NewMultiClusterServiceCollector returns a collector which reports the validity of the MultiClusterServices in
+// a member cluster.
+func NewMultiClusterServiceCollector(reader client.Reader) *StateCollector {
+	return &StateCollector{
+		reader: reader,
+		metrics: []stateMetric{
+			{
+				name:    "MultiClusterServices",
+				descs:   []*prometheus.Desc{multiClusterServicesDesc},
+				collect: collectMultiClusterServices,
+			},
+		},
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *StateCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c.metrics {
+		for _, desc := range m.descs {
+			ch <- desc
+		}
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (c *StateCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), stateCollectTimeout)
+	defer cancel()
+	for _, m := range c.metrics {
+		// A failure to list some objects (e.g. the cache is not synced yet) must not fail the whole scrape; the
+		// metrics of these objects are missing from the scrape instead.
+		if err := m.collect(ctx, c.reader, ch); err != nil {
+			klog.ErrorS(err, "Failed to collect the state metrics", "objects", m.name)
+		}
+	}
+}
+
+func collectInternalServiceExports(ctx context.Context, reader client.Reader, ch chan<- prometheus.Metric) error {
+	internalSvcExportList := &fleetnetv1alpha1.InternalServiceExportList{}
+	if err := reader.List(ctx, internalSvcExportList); err != nil {
+		return err
+	}
+	exported := map[string]int{}
+	conflicted := map[string]int{}
+	for i := range internalSvcExportList.Items {
+		internalSvcExport := &internalSvcExportList.Items[i]
+		cluster := internalSvcExport.Spec.ServiceReference.ClusterID
+		exported[cluster]++
+		if meta.IsStatusConditionTrue(internalSvcExport.Status.Conditions, string(fleetnetv1alpha1.ServiceExportConflict)) {
+			conflicted[cluster]++
+		}
+	}
+	sendCounts(ch, exportedServicesDesc, exported)
+	// Report 0 for the clusters without any conflict, so that the ratio of conflicted exports can be computed.
+	for cluster := range exported {
+		ch <- prometheus.MustNewConstMetric(conflictedExportsDesc, prometheus.GaugeValue, float64(conflicted[cluster]), cluster)
+	}
+	return nil
+}
+
+func collectInternalServiceImports(ctx context.Context, reader client.Reader, ch chan<- prometheus.Metric) error {
+	internalSvcImportList := &fleetnetv1alpha1.InternalServiceImportList{}
+	if err := reader.List(ctx, internalSvcImportList); err != nil {
+		return err
+	}
+	imports := map[string]int{}
+	for i := range internalSvcImportList.Items {
+		imports[internalSvcImportList.Items[i].Spec.ServiceImportReference.ClusterID]++
+	}
+	sendCounts(ch, importsDesc, imports)
+	return nil
+}
+
+func collectEndpointSliceExports(ctx context.Context, reader client.Reader, ch chan<- prometheus.Metric) error {
+	endpointSliceExportList := &fleetnetv1alpha1.EndpointSliceExportList{}
+	if err := reader.List(ctx, endpointSliceExportList); err != nil {
+		return err
+	}
+	exports := map[string]int{}
+	for i := range endpointSliceExportList.Items {
+		exports[endpointSliceExportList.Items[i].Spec.EndpointSliceReference.ClusterID]++
+	}
+	sendCounts(ch, endpointSliceExportsDesc, exports)
+	return nil
+}
+
+func collectEndpointSliceImports(ctx context.Context, reader client.Reader, ch chan<- prometheus.Metric) error {
+	endpointSliceImportList := &fleetnetv1alpha1.EndpointSliceImportList{}
+	if err := reader.List(ctx, endpointSliceImportList); err != nil {
+		return err
+	}
+	imports := map[string]int{}
+	for i := range endpointSliceImportList.Items {
+		imports[endpointSliceImportList.Items[i].Namespace]++
+	}
+	sendCounts(ch, endpointSliceImportsDesc, imports)
+	return nil
+}
+
+func collectTrafficManagerProfiles(ctx context.Context, reader client.Reader, ch chan<- prometheus.Metric) error {
+	profileList := &fleetnetv1alpha1.TrafficManagerProfileList{}
+	if err := reader.List(ctx, profileList); err != nil {
+		return err
+	}
+	conditionType := string(fleetnetv1alpha1.TrafficManagerProfileConditionProgrammed)
+	statuses := newConditionStatusCounts()
+	for i := range profileList.Items {
+		statuses[conditionStatus(profileList.Items[i].Status.Conditions, conditionType)]++
+	}
+	sendConditionStatusCounts(ch, trafficManagerProfilesDesc, conditionType, statuses)
+	return nil
+}
+
+func collectTrafficManagerBackends(ctx context.Context, reader client.Reader, ch chan<- prometheus.Metric) error {
+	backendList := &fleetnetv1alpha1.TrafficManagerBackendList{}
+	if err := reader.List(ctx, backendList); err != nil {
+		return err
+	}
+	conditionType := string(fleetnetv1alpha1.TrafficManagerBackendConditionAccepted)
+	statuses := newConditionStatusCounts()
+	for i := range backendList.Items {
+		statuses[conditionStatus(backendList.Items[i].Status.Conditions, conditionType)]++
+	}
+	sendConditionStatusCounts(ch, trafficManagerBackendsDesc, conditionType, statuses)
+	return nil
+}
+
+func collectImportedEndpoints(ctx context.Context, reader client.Reader, namespace, managedBy string, ch chan<- prometheus.Metric) error {
+	endpointSliceList := &discoveryv1.EndpointSliceList{}
+	if err := reader.List(ctx, endpointSliceList, client.InNamespace(namespace), client.MatchingLabels{discoveryv1.LabelManagedBy: managedBy}); err != nil {
+		return err
+	}
+	endpoints := map[string]int{}
+	for i := range endpointSliceList.Items {
+		endpointSlice := &endpointSliceList.Items[i]
+		svcName, ok := endpointSlice.Labels[discoveryv1.LabelServiceName]
+		if !ok {
+			continue
+		}
+		endpoints[svcName] += len(endpointSlice.Endpoints)
+	}
+	sendCounts(ch, importedEndpointsDesc, endpoints)
+	return nil
+}
+
+func collectMultiClusterServices(ctx context.Context, reader client.Reader, ch chan<- prometheus.Metric) error {
+	mcsList := &fleetnetv1alpha1.MultiClusterServiceList{}
+	if err := reader.List(ctx, mcsList); err != nil {
+		return err
+	}
+	conditionType := string(fleetnetv1alpha1.MultiClusterServiceValid)
+	statuses := newConditionStatusCounts()
+	for i := range mcsList.Items {
+		statuses[conditionStatus(mcsList.Items[i].Status.Conditions, conditionType)]++
+	}
+	sendConditionStatusCounts(ch, multiClusterServicesDesc, conditionType, statuses)
+	return nil
+}
+
+// conditionStatus returns the status of the given condition; a missing condition is reported as Unknown.
+func conditionStatus(conditions []metav1.Condition, conditionType string) metav1.ConditionStatus {
+	cond := meta.FindStatusCondition(conditions, conditionType)
+	if cond == nil {
+		return metav1.ConditionUnknown
+	}
+	return cond.Status
+}
+
+func newConditionStatusCounts() map[metav1.ConditionStatus]int {
+	counts := make(map[metav1.ConditionStatus]int, len(conditionStatuses))
+	for _, status := range conditionStatuses {
+		counts[status] = 0
+	}
+	return counts
+}
+
+func sendConditionStatusCounts(ch chan<- prometheus.Metric, desc *prometheus.Desc, conditionType string, counts map[metav1.ConditionStatus]int) {
+	for status, count := range counts {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(count), conditionType, string(status))
+	}
+}
+
+func sendCounts(ch chan<- prometheus.Metric, desc *prometheus.Desc, counts map[string]int) {
+	for label, count := range counts {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(count), label)
+	}
+}
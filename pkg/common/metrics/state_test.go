@@ -0,0 +1,228 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package metrics
+
+import (
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+const (
+	memberClusterID1     = "member-1"
+	memberClusterID2     = "member-2"
+	hubNSForMember1      = "fleet-member-member-1"
+	hubNSForMember2      = "fleet-member-member-2"
+	fleetSystemNS        = "fleet-system"
+	endpointSliceManager = "endpointsliceimport-controller.networking.fleet.azure.com"
+)
+
+func TestMain(m *testing.M) {
+	// Add custom APIs to the runtime scheme.
+	if err := fleetnetv1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		log.Fatalf("failed to add custom APIs to the runtime scheme: %v", err)
+	}
+
+	os.Exit(m.Run())
+}
+
+func condition(conditionType string, status metav1.ConditionStatus) []metav1.Condition {
+	return []metav1.Condition{{Type: conditionType, Status: status, Reason: "Test"}}
+}
+
+func internalServiceExport(namespace, name, clusterID string, conflicted bool) *fleetnetv1alpha1.InternalServiceExport {
+	internalSvcExport := &fleetnetv1alpha1.InternalServiceExport{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+			ServiceReference: fleetnetv1alpha1.ExportedObjectReference{ClusterID: clusterID},
+		},
+	}
+	if conflicted {
+		internalSvcExport.Status.Conditions = condition(string(fleetnetv1alpha1.ServiceExportConflict), metav1.ConditionTrue)
+	}
+	return internalSvcExport
+}
+
+func endpointSlice(name, svcName, managedBy string, endpoints int) *discoveryv1.EndpointSlice {
+	endpointSlice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: fleetSystemNS,
+			Name:      name,
+			Labels: map[string]string{
+				discoveryv1.LabelServiceName: svcName,
+				discoveryv1.LabelManagedBy:   managedBy,
+			},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+	}
+	for i := 0; i < endpoints; i++ {
+		endpointSlice.Endpoints = append(endpointSlice.Endpoints, discoveryv1.Endpoint{Addresses: []string{"1.2.3.4"}})
+	}
+	return endpointSlice
+}
+
+func TestHubStateCollector(t *testing.T) {
+	objs := []client.Object{
+		internalServiceExport(hubNSForMember1, "work-app", memberClusterID1, false),
+		internalServiceExport(hubNSForMember1, "work-app2", memberClusterID1, true),
+		internalServiceExport(hubNSForMember2, "work-app", memberClusterID2, false),
+		&fleetnetv1alpha1.InternalServiceImport{
+			ObjectMeta: metav1.ObjectMeta{Namespace: hubNSForMember2, Name: "work-app"},
+			Spec: fleetnetv1alpha1.InternalServiceImportSpec{
+				ServiceImportReference: fleetnetv1alpha1.ExportedObjectReference{ClusterID: memberClusterID2},
+			},
+		},
+		&fleetnetv1alpha1.EndpointSliceExport{
+			ObjectMeta: metav1.ObjectMeta{Namespace: hubNSForMember1, Name: "work-app-slice"},
+			Spec: fleetnetv1alpha1.EndpointSliceExportSpec{
+				EndpointSliceReference: fleetnetv1alpha1.ExportedObjectReference{ClusterID: memberClusterID1},
+			},
+		},
+		&fleetnetv1alpha1.EndpointSliceImport{
+			ObjectMeta: metav1.ObjectMeta{Namespace: hubNSForMember2, Name: "work-app-slice"},
+		},
+		&fleetnetv1alpha1.TrafficManagerProfile{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "work", Name: "profile"},
+			Status: fleetnetv1alpha1.TrafficManagerProfileStatus{
+				Conditions: condition(string(fleetnetv1alpha1.TrafficManagerProfileConditionProgrammed), metav1.ConditionTrue),
+			},
+		},
+		&fleetnetv1alpha1.TrafficManagerBackend{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "work", Name: "backend"},
+		},
+	}
+	fakeHubClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objs...).Build()
+
+	testCases := []struct {
+		name                  string
+		trafficManagerEnabled bool
+		want                  string
+		metricNames           []string
+	}{
+		{
+			name: "exports and imports",
+			want: `
+# HELP fleet_networking_conflicted_exports The number of Services exported by a member cluster which are in conflict with other exports
+# TYPE fleet_networking_conflicted_exports gauge
+fleet_networking_conflicted_exports{cluster="member-1"} 1
+fleet_networking_conflicted_exports{cluster="member-2"} 0
+# HELP fleet_networking_endpointslice_exports The number of EndpointSlices exported by a member cluster
+# TYPE fleet_networking_endpointslice_exports gauge
+fleet_networking_endpointslice_exports{cluster="member-1"} 1
+# HELP fleet_networking_endpointslice_imports The number of EndpointSliceImports distributed to the namespace reserved for a member cluster
+# TYPE fleet_networking_endpointslice_imports gauge
+fleet_networking_endpointslice_imports{namespace="fleet-member-member-2"} 1
+# HELP fleet_networking_exported_services The number of Services exported by a member cluster
+# TYPE fleet_networking_exported_services gauge
+fleet_networking_exported_services{cluster="member-1"} 2
+fleet_networking_exported_services{cluster="member-2"} 1
+# HELP fleet_networking_imports The number of Services imported by a member cluster
+# TYPE fleet_networking_imports gauge
+fleet_networking_imports{cluster="member-2"} 1
+`,
+		},
+		{
+			name:                  "traffic manager is enabled",
+			trafficManagerEnabled: true,
+			want: `
+# HELP fleet_networking_traffic_manager_backends The number of TrafficManagerBackends by condition status
+# TYPE fleet_networking_traffic_manager_backends gauge
+fleet_networking_traffic_manager_backends{condition="Accepted",status="False"} 0
+fleet_networking_traffic_manager_backends{condition="Accepted",status="True"} 0
+fleet_networking_traffic_manager_backends{condition="Accepted",status="Unknown"} 1
+# HELP fleet_networking_traffic_manager_profiles The number of TrafficManagerProfiles by condition status
+# TYPE fleet_networking_traffic_manager_profiles gauge
+fleet_networking_traffic_manager_profiles{condition="Programmed",status="False"} 0
+fleet_networking_traffic_manager_profiles{condition="Programmed",status="True"} 1
+fleet_networking_traffic_manager_profiles{condition="Programmed",status="Unknown"} 0
+`,
+			metricNames: []string{"fleet_networking_traffic_manager_backends", "fleet_networking_traffic_manager_profiles"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			collector := NewHubStateCollector(fakeHubClient, tc.trafficManagerEnabled)
+			if err := testutil.CollectAndCompare(collector, strings.NewReader(tc.want), tc.metricNames...); err != nil {
+				t.Errorf("CollectAndCompare() = %v", err)
+			}
+		})
+	}
+}
+
+func TestHubStateCollectorTrafficManagerDisabled(t *testing.T) {
+	fakeHubClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	collector := NewHubStateCollector(fakeHubClient, false)
+	for _, name := range []string{"fleet_networking_traffic_manager_backends", "fleet_networking_traffic_manager_profiles"} {
+		if got := testutil.CollectAndCount(collector, name); got != 0 {
+			t.Errorf("CollectAndCount(%s) = %d, want 0", name, got)
+		}
+	}
+}
+
+func TestImportedEndpointsCollector(t *testing.T) {
+	objs := []client.Object{
+		endpointSlice("derived-1-a", "derived-1", endpointSliceManager, 2),
+		endpointSlice("derived-1-b", "derived-1", endpointSliceManager, 3),
+		endpointSlice("derived-2-a", "derived-2", endpointSliceManager, 0),
+		// EndpointSlices which are not imported are not counted.
+		endpointSlice("local", "local", "endpointslice-controller.k8s.io", 5),
+	}
+	fakeMemberClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objs...).Build()
+
+	want := `
+# HELP fleet_networking_imported_endpoints The number of endpoints imported for a derived Service
+# TYPE fleet_networking_imported_endpoints gauge
+fleet_networking_imported_endpoints{service="derived-1"} 5
+fleet_networking_imported_endpoints{service="derived-2"} 0
+`
+	collector := NewImportedEndpointsCollector(fakeMemberClient, fleetSystemNS, endpointSliceManager)
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(want)); err != nil {
+		t.Errorf("CollectAndCompare() = %v", err)
+	}
+}
+
+func TestMultiClusterServiceCollector(t *testing.T) {
+	objs := []client.Object{
+		&fleetnetv1alpha1.MultiClusterService{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "work", Name: "valid"},
+			Status: fleetnetv1alpha1.MultiClusterServiceStatus{
+				Conditions: condition(string(fleetnetv1alpha1.MultiClusterServiceValid), metav1.ConditionTrue),
+			},
+		},
+		&fleetnetv1alpha1.MultiClusterService{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "work", Name: "invalid"},
+			Status: fleetnetv1alpha1.MultiClusterServiceStatus{
+				Conditions: condition(string(fleetnetv1alpha1.MultiClusterServiceValid), metav1.ConditionFalse),
+			},
+		},
+		&fleetnetv1alpha1.MultiClusterService{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "work", Name: "new"},
+		},
+	}
+	fakeMemberClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objs...).Build()
+
+	want := `
+# HELP fleet_networking_multicluster_services The number of MultiClusterServices by condition status
+# TYPE fleet_networking_multicluster_services gauge
+fleet_networking_multicluster_services{condition="Valid",status="False"} 1
+fleet_networking_multicluster_services{condition="Valid",status="True"} 1
+fleet_networking_multicluster_services{condition="Valid",status="Unknown"} 1
+`
+	collector := NewMultiClusterServiceCollector(fakeMemberClient)
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(want)); err != nil {
+		t.Errorf("CollectAndCompare() = %v", err)
+	}
+}
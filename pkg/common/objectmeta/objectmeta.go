@@ -6,10 +6,24 @@ Licensed under the MIT license.
 // Package objectmeta defines shared meta const used by the networking objects.
 package objectmeta
 
+import "strings"
+
 const (
-	fleetNetworkingPrefix = "networking.fleet.azure.com/"
+	fleetNetworkingDomain = "networking.fleet.azure.com"
+	fleetNetworkingPrefix = fleetNetworkingDomain + "/"
 )
 
+// IsReservedKey returns true if a label or annotation key is under the prefix reserved for the fleet networking
+// controllers, i.e. networking.fleet.azure.com/ or one of its subdomains; such keys are never exported from the
+// member clusters, as the controllers interpret them.
+func IsReservedKey(key string) bool {
+	prefix, _, ok := strings.Cut(key, "/")
+	if !ok {
+		return false
+	}
+	return prefix == fleetNetworkingDomain || strings.HasSuffix(prefix, "."+fleetNetworkingDomain)
+}
+
 // Finalizers
 const (
 	// InternalServiceExportFinalizer is the finalizer InternalServiceExport controllers adds to mark that a
@@ -17,6 +31,10 @@ const (
 	// result have been updated.
 	InternalServiceExportFinalizer = fleetNetworkingPrefix + "internal-svc-export-cleanup"
 
+	// ServiceImportCleanupFinalizer is the finalizer the InternalServiceImport controller adds to a ServiceImport
+	// while any member cluster imports it.
+	ServiceImportCleanupFinalizer = fleetNetworkingPrefix + "serviceimport-cleanup"
+
 	// TrafficManagerProfileFinalizer a finalizer added by the TrafficManagerProfile controller to all trafficManagerProfiles,
 	// to make sure that the controller can react to profile deletions if necessary.
 	TrafficManagerProfileFinalizer = fleetNetworkingPrefix + "traffic-manager-profile-cleanup"
@@ -31,18 +49,39 @@ const (
 	// MultiClusterServiceLabelDerivedService is the label added by the MCS controller, which marks the
 	// derived Service behind a MCS.
 	MultiClusterServiceLabelDerivedService = fleetNetworkingPrefix + "derived-service"
+
+	// ServiceLabelMultiClusterServiceName and ServiceLabelMultiClusterServiceNamespace are the labels added by the
+	// MCS controller, which mark the MCS behind a derived Service.
+	ServiceLabelMultiClusterServiceName      = fleetNetworkingPrefix + "multi-cluster-service-name"
+	ServiceLabelMultiClusterServiceNamespace = fleetNetworkingPrefix + "multi-cluster-service-namespace"
+
+	// EndpointSliceLabelAggregatedServiceName and EndpointSliceLabelAggregatedServiceNamespace are the labels added
+	// by the EndpointSliceImport controller, which mark the Service whose endpoints an aggregated EndpointSlice carries.
+	EndpointSliceLabelAggregatedServiceName      = fleetNetworkingPrefix + "aggregated-service-name"
+	EndpointSliceLabelAggregatedServiceNamespace = fleetNetworkingPrefix + "aggregated-service-namespace"
 )
 
 // Annotations
 const (
 	// ServiceImportAnnotationServiceInUseBy is the key of the ServiceInUseBy annotation, which marks the list
 	// of member clusters importing an exported Service.
+	//
+	// Deprecated: the importing member clusters are kept in the ServiceImport status; the annotation is only read
+	// when migrating existing ServiceImports.
 	ServiceImportAnnotationServiceInUseBy = fleetNetworkingPrefix + "service-in-use-by"
 
 	// ExportedObjectAnnotationUniqueName is an annotation that marks the fleet-scoped unique name assigned to
 	// an exported object.
 	ExportedObjectAnnotationUniqueName = fleetNetworkingPrefix + "fleet-unique-name"
 
+	// ExportedObjectAnnotationTraceContext is an annotation that carries the W3C trace context (traceparent) of
+	// the last change made to an exported object, so that the controllers processing the object continue the trace.
+	ExportedObjectAnnotationTraceContext = fleetNetworkingPrefix + "trace-context"
+
+	// EndpointSliceAnnotationAggregatedSources is an annotation that lists the names of the EndpointSliceImports
+	// whose endpoints an aggregated EndpointSlice carries, separated by commas.
+	EndpointSliceAnnotationAggregatedSources = fleetNetworkingPrefix + "aggregated-endpointslice-sources"
+
 	// ServiceAnnotationAzureLoadBalancerInternal is an annotation that marks the Service as an internal load balancer by cloud-provider-azure.
 	ServiceAnnotationAzureLoadBalancerInternal = "service.beta.kubernetes.io/azure-load-balancer-internal"
 
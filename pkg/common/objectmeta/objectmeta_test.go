@@ -0,0 +1,31 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package objectmeta
+
+import "testing"
+
+// TestIsReservedKey tests the IsReservedKey function.
+func TestIsReservedKey(t *testing.T) {
+	testCases := []struct {
+		key  string
+		want bool
+	}{
+		{key: "app", want: false},
+		{key: "service.beta.kubernetes.io/azure-load-balancer-internal", want: false},
+		{key: "networking.fleet.azure.com", want: false},
+		{key: "example.com/networking.fleet.azure.com", want: false},
+		{key: "fakenetworking.fleet.azure.com/x", want: false},
+		{key: "networking.fleet.azure.com/derived-service", want: true},
+		{key: "lb.networking.fleet.azure.com/x", want: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.key, func(t *testing.T) {
+			if got := IsReservedKey(tc.key); got != tc.want {
+				t.Errorf("IsReservedKey(%q) = %t, want %t", tc.key, got, tc.want)
+			}
+		})
+	}
+}
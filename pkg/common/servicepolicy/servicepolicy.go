@@ -0,0 +1,60 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package servicepolicy features utils to evaluate the ServiceExportImportPolicies which authorize member clusters
+// to export Services to, or import Services from, the fleet.
+package servicepolicy
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+// IsExportAllowed returns if a member cluster is allowed to export the Service with the given namespace and name;
+// if not, a message explaining the decision is returned as well.
+func IsExportAllowed(ctx context.Context, c client.Reader, namespace, name, clusterID string) (bool, string, error) {
+	return isAllowed(ctx, c, namespace, name, clusterID, "export", func(spec *fleetnetv1alpha1.ServiceExportImportPolicySpec) *fleetnetv1alpha1.ClusterAccessRule {
+		return spec.Export
+	})
+}
+
+// IsImportAllowed returns if a member cluster is allowed to import the Service with the given namespace and name;
+// if not, a message explaining the decision is returned as well.
+func IsImportAllowed(ctx context.Context, c client.Reader, namespace, name, clusterID string) (bool, string, error) {
+	return isAllowed(ctx, c, namespace, name, clusterID, "import", func(spec *fleetnetv1alpha1.ServiceExportImportPolicySpec) *fleetnetv1alpha1.ClusterAccessRule {
+		return spec.Import
+	})
+}
+
+func isAllowed(ctx context.Context, c client.Reader, namespace, name, clusterID, operation string,
+	ruleFunc func(spec *fleetnetv1alpha1.ServiceExportImportPolicySpec) *fleetnetv1alpha1.ClusterAccessRule) (bool, string, error) {
+	policyList := &fleetnetv1alpha1.ServiceExportImportPolicyList{}
+	if err := c.List(ctx, policyList, client.InNamespace(namespace)); err != nil {
+		return false, "", fmt.Errorf("failed to list serviceExportImportPolicies in namespace %s: %w", namespace, err)
+	}
+	for i := range policyList.Items {
+		policy := &policyList.Items[i]
+		if !AppliesTo(policy, name) {
+			continue
+		}
+		rule := ruleFunc(&policy.Spec)
+		if rule == nil || slices.Contains(rule.AllowedClusters, clusterID) {
+			continue
+		}
+		return false, fmt.Sprintf("member cluster %s is not allowed to %s service %s/%s by serviceExportImportPolicy %s",
+			clusterID, operation, namespace, name, policy.Name), nil
+	}
+	return true, "", nil
+}
+
+// AppliesTo returns if a policy applies to the Service with the given name in the namespace of the policy.
+func AppliesTo(policy *fleetnetv1alpha1.ServiceExportImportPolicy, name string) bool {
+	return len(policy.Spec.ServiceNames) == 0 || slices.Contains(policy.Spec.ServiceNames, name)
+}
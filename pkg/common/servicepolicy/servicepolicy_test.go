@@ -0,0 +1,118 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package servicepolicy
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+const (
+	testNamespace = "work"
+	testService   = "app"
+)
+
+func policyForTest(name string, serviceNames []string, export, imports *fleetnetv1alpha1.ClusterAccessRule) *fleetnetv1alpha1.ServiceExportImportPolicy {
+	return &fleetnetv1alpha1.ServiceExportImportPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      name,
+		},
+		Spec: fleetnetv1alpha1.ServiceExportImportPolicySpec{
+			ServiceNames: serviceNames,
+			Export:       export,
+			Import:       imports,
+		},
+	}
+}
+
+func TestIsExportAndImportAllowed(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+
+	testCases := []struct {
+		name       string
+		policies   []client.Object
+		clusterID  string
+		wantExport bool
+		wantImport bool
+	}{
+		{
+			name:       "no policies",
+			clusterID:  "member-1",
+			wantExport: true,
+			wantImport: true,
+		},
+		{
+			name: "policy for other services",
+			policies: []client.Object{
+				policyForTest("other", []string{"db"}, &fleetnetv1alpha1.ClusterAccessRule{}, &fleetnetv1alpha1.ClusterAccessRule{}),
+			},
+			clusterID:  "member-1",
+			wantExport: true,
+			wantImport: true,
+		},
+		{
+			name: "export is restricted to other clusters",
+			policies: []client.Object{
+				policyForTest("exporters", nil, &fleetnetv1alpha1.ClusterAccessRule{AllowedClusters: []string{"member-2"}}, nil),
+			},
+			clusterID:  "member-1",
+			wantImport: true,
+		},
+		{
+			name: "cluster is allowed by all the policies",
+			policies: []client.Object{
+				policyForTest("exporters", nil, &fleetnetv1alpha1.ClusterAccessRule{AllowedClusters: []string{"member-1"}}, nil),
+				policyForTest("importers", []string{testService}, nil, &fleetnetv1alpha1.ClusterAccessRule{AllowedClusters: []string{"member-1"}}),
+			},
+			clusterID:  "member-1",
+			wantExport: true,
+			wantImport: true,
+		},
+		{
+			name: "cluster is denied by one of the policies",
+			policies: []client.Object{
+				policyForTest("importers", nil, nil, &fleetnetv1alpha1.ClusterAccessRule{AllowedClusters: []string{"member-1"}}),
+				policyForTest("no-importers", []string{testService}, nil, &fleetnetv1alpha1.ClusterAccessRule{}),
+			},
+			clusterID:  "member-1",
+			wantExport: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.policies...).Build()
+
+			gotExport, msg, err := IsExportAllowed(ctx, fakeClient, testNamespace, testService, tc.clusterID)
+			if err != nil {
+				t.Fatalf("IsExportAllowed() got error %v, want no error", err)
+			}
+			if gotExport != tc.wantExport || (gotExport != (msg == "")) {
+				t.Errorf("IsExportAllowed() = %t, %q, want %t", gotExport, msg, tc.wantExport)
+			}
+
+			gotImport, msg, err := IsImportAllowed(ctx, fakeClient, testNamespace, testService, tc.clusterID)
+			if err != nil {
+				t.Fatalf("IsImportAllowed() got error %v, want no error", err)
+			}
+			if gotImport != tc.wantImport || (gotImport != (msg == "")) {
+				t.Errorf("IsImportAllowed() = %t, %q, want %t", gotImport, msg, tc.wantImport)
+			}
+		})
+	}
+}
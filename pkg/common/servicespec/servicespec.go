@@ -0,0 +1,20 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package servicespec features common utilities for the exported service specs.
+package servicespec
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// NormalizeSessionAffinity returns the session affinity with the Kubernetes default applied, as member agents of
+// older versions do not export the session affinity settings at all; an empty session affinity is resolved as None.
+func NormalizeSessionAffinity(affinity corev1.ServiceAffinity) corev1.ServiceAffinity {
+	if affinity == "" {
+		return corev1.ServiceAffinityNone
+	}
+	return affinity
+}
@@ -0,0 +1,42 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package servicespec
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestNormalizeSessionAffinity(t *testing.T) {
+	tests := []struct {
+		name     string
+		affinity corev1.ServiceAffinity
+		want     corev1.ServiceAffinity
+	}{
+		{
+			name: "empty session affinity",
+			want: corev1.ServiceAffinityNone,
+		},
+		{
+			name:     "none",
+			affinity: corev1.ServiceAffinityNone,
+			want:     corev1.ServiceAffinityNone,
+		},
+		{
+			name:     "client ip",
+			affinity: corev1.ServiceAffinityClientIP,
+			want:     corev1.ServiceAffinityClientIP,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := NormalizeSessionAffinity(tc.affinity); got != tc.want {
+				t.Errorf("NormalizeSessionAffinity(%q) = %q, want %q", tc.affinity, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package tracing features the OpenTelemetry tracing of the propagation of exported Services and EndpointSlices
+// across the fleet.
+//
+// The member cluster which exports a Service or an EndpointSlice starts a trace, and stamps its context on the
+// exported object (an InternalServiceExport or an EndpointSliceExport) as an annotation; the controllers which
+// process the exported object, in the hub cluster and then in the importing member clusters, continue the trace
+// from the annotation, so that a single trace covers the whole propagation of a change. Unlike the export duration
+// metrics, which compare timestamps taken in different clusters, the spans of a trace show where the time goes
+// without depending on the clocks of the clusters being in sync.
+//
+// Tracing is disabled by default, in which case no span is recorded and no annotation is stamped.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+const (
+	// tracerName is the name of the tracer with which all the spans of fleet networking are recorded.
+	tracerName = "go.goms.io/fleet-networking"
+
+	// traceParentKey is the key of the W3C trace context which is stamped on the exported objects.
+	traceParentKey = "traceparent"
+)
+
+// Attribute keys of the spans.
+const (
+	AttributeKeyNamespace = attribute.Key("k8s.namespace.name")
+	AttributeKeyName      = attribute.Key("k8s.object.name")
+	AttributeKeyClusterID = attribute.Key("fleet.cluster.id")
+)
+
+// propagator carries the trace context across the clusters; only the trace context itself is propagated, as
+// baggage is of no use to the controllers.
+var propagator = propagation.TraceContext{}
+
+// Options are the options of the tracing.
+type Options struct {
+	// ServiceName is the name with which the process is reported in the traces.
+	ServiceName string
+	// OTLPEndpoint is the host:port of the OTLP/HTTP endpoint to which the spans are exported; if empty, tracing
+	// is disabled.
+	OTLPEndpoint string
+	// OTLPInsecure disables TLS when exporting the spans.
+	OTLPInsecure bool
+	// SamplingRatio is the ratio of the traces started in the process which are sampled; the traces continued from
+	// an exported object follow the sampling decision of the process which started them.
+	SamplingRatio float64
+}
+
+// Setup sets up the global tracer provider with the given options, and returns a function which flushes the
+// recorded spans and shuts the tracer provider down; if no OTLP endpoint is set, the default no-op tracer provider
+// is kept.
+func Setup(ctx context.Context, opts Options) (func(context.Context) error, error) {
+	if opts.OTLPEndpoint == "" {
+		klog.V(2).InfoS("Tracing is disabled")
+		return func(context.Context) error { return nil }, nil
+	}
+	if opts.SamplingRatio < 0 || opts.SamplingRatio > 1 {
+		return nil, fmt.Errorf("sampling ratio %v is not in [0, 1]", opts.SamplingRatio)
+	}
+
+	exporterOpts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(opts.OTLPEndpoint)}
+	if opts.OTLPInsecure {
+		exporterOpts = append(exporterOpts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(ctx, exporterOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the OTLP trace exporter: %w", err)
+	}
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(semconv.ServiceName(opts.ServiceName)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the tracing resource: %w", err)
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SamplingRatio))),
+	)
+	otel.SetTracerProvider(tp)
+	klog.V(1).InfoS("Tracing is enabled", "endpoint", opts.OTLPEndpoint, "samplingRatio", opts.SamplingRatio)
+	return tp.Shutdown, nil
+}
+
+// StartSpan starts a span as a child of the span in the context, if any.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartSpanFromObject starts a span which continues the trace stamped on the given object, if any; otherwise it
+// starts a new trace.
+func StartSpanFromObject(ctx context.Context, obj metav1.Object, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	ctx = propagator.Extract(ctx, annotationCarrier{obj: obj})
+	attrs = append(attrs, AttributeKeyNamespace.String(obj.GetNamespace()), AttributeKeyName.String(obj.GetName()))
+	return StartSpan(ctx, name, attrs...)
+}
+
+// Inject stamps the trace context of the span in the context on the given object, so that the controllers which
+// process the object continue the trace; if the span is not being recorded (e.g. tracing is disabled), the trace
+// context stamped earlier, if any, is removed instead.
+//
+// Inject should only be called when the object actually changes; otherwise every reconcile would update the
+// object.
+func Inject(ctx context.Context, obj metav1.Object) {
+	if annotations := obj.GetAnnotations(); annotations != nil {
+		delete(annotations, objectmeta.ExportedObjectAnnotationTraceContext)
+	}
+	propagator.Inject(ctx, annotationCarrier{obj: obj})
+}
+
+// EndSpan ends the span, and marks it as failed if the given error is not nil.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// annotationCarrier stores the W3C trace context in the annotations of an object.
+type annotationCarrier struct {
+	obj metav1.Object
+}
+
+var _ propagation.TextMapCarrier = annotationCarrier{}
+
+// Get implements propagation.TextMapCarrier.
+func (c annotationCarrier) Get(key string) string {
+	if key != traceParentKey {
+		return ""
+	}
+	return c.obj.GetAnnotations()[objectmeta.ExportedObjectAnnotationTraceContext]
+}
+
+// Set implements propagation.TextMapCarrier.
+func (c annotationCarrier) Set(key, value string) {
+	// The trace state is vendor specific and is not propagated.
+	if key != traceParentKey {
+		return
+	}
+	annotations := c.obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[objectmeta.ExportedObjectAnnotationTraceContext] = value
+	c.obj.SetAnnotations(annotations)
+}
+
+// Keys implements propagation.TextMapCarrier.
+func (c annotationCarrier) Keys() []string {
+	return []string{traceParentKey}
+}
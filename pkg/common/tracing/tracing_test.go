@@ -0,0 +1,137 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+const (
+	testNamespace = "fleet-member-member-1"
+	testName      = "work-app"
+)
+
+// setUpRecorder installs a tracer provider which records all the spans in memory, for the duration of the test.
+func setUpRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		_ = tp.Shutdown(context.Background())
+	})
+	return recorder
+}
+
+func TestSetup(t *testing.T) {
+	testCases := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{
+			name: "tracing is disabled",
+			opts: Options{ServiceName: "hub-net-controller-manager"},
+		},
+		{
+			name:    "invalid sampling ratio",
+			opts:    Options{ServiceName: "hub-net-controller-manager", OTLPEndpoint: "localhost:4318", SamplingRatio: 2},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			shutdown, err := Setup(context.Background(), tc.opts)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Setup() = %v, want error %t", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if err := shutdown(context.Background()); err != nil {
+				t.Errorf("shutdown() = %v, want no error", err)
+			}
+		})
+	}
+}
+
+func TestPropagation(t *testing.T) {
+	recorder := setUpRecorder(t)
+
+	// The exporting member cluster stamps the trace context on the export.
+	ctx, exportSpan := StartSpan(context.Background(), "ExportService")
+	internalSvcExport := &fleetnetv1alpha1.InternalServiceExport{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+	}
+	Inject(ctx, internalSvcExport)
+	exportSpan.End()
+	if internalSvcExport.Annotations[objectmeta.ExportedObjectAnnotationTraceContext] == "" {
+		t.Fatalf("Inject() sets annotations %v, want the trace context", internalSvcExport.Annotations)
+	}
+
+	// The hub cluster continues the trace.
+	_, reconcileSpan := StartSpanFromObject(context.Background(), internalSvcExport, "ReconcileInternalServiceExport")
+	EndSpan(reconcileSpan, errors.New("fake error"))
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	exported, reconciled := spans[0], spans[1]
+	if reconciled.SpanContext().TraceID() != exported.SpanContext().TraceID() {
+		t.Errorf("continued span has trace ID %s, want %s", reconciled.SpanContext().TraceID(), exported.SpanContext().TraceID())
+	}
+	if reconciled.Parent().SpanID() != exported.SpanContext().SpanID() {
+		t.Errorf("continued span has parent %s, want %s", reconciled.Parent().SpanID(), exported.SpanContext().SpanID())
+	}
+	if !reconciled.Parent().IsRemote() {
+		t.Error("continued span has a local parent, want a remote one")
+	}
+	if reconciled.Status().Code != codes.Error {
+		t.Errorf("failed span has status %v, want %v", reconciled.Status().Code, codes.Error)
+	}
+	gotAttrs := map[string]string{}
+	for _, attr := range reconciled.Attributes() {
+		gotAttrs[string(attr.Key)] = attr.Value.Emit()
+	}
+	if gotAttrs[string(AttributeKeyNamespace)] != testNamespace || gotAttrs[string(AttributeKeyName)] != testName {
+		t.Errorf("continued span has attributes %v, want the namespace and name of the object", gotAttrs)
+	}
+}
+
+func TestInjectWithoutRecordingSpan(t *testing.T) {
+	endpointSliceExport := &fleetnetv1alpha1.EndpointSliceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testName,
+			Annotations: map[string]string{
+				objectmeta.ExportedObjectAnnotationTraceContext: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+				objectmeta.ExportedObjectAnnotationUniqueName:   testName,
+			},
+		},
+	}
+	// No span is in the context, e.g. tracing is disabled; the stale trace context is removed.
+	Inject(context.Background(), endpointSliceExport)
+	if _, ok := endpointSliceExport.Annotations[objectmeta.ExportedObjectAnnotationTraceContext]; ok {
+		t.Errorf("Inject() keeps the stale trace context in annotations %v", endpointSliceExport.Annotations)
+	}
+	if endpointSliceExport.Annotations[objectmeta.ExportedObjectAnnotationUniqueName] != testName {
+		t.Errorf("Inject() changes the other annotations %v", endpointSliceExport.Annotations)
+	}
+}
@@ -8,6 +8,8 @@ Licensed under the MIT license.
 package uniquename
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"strings"
 	"unicode"
@@ -57,7 +59,23 @@ func removeDots(s string) string {
 //   - the input object name follows one of the three formats used in Kubernetes (RFC 1123 DNS subdomain,
 //     RFC 1123 DNS label, RFC 1035 DNS label).
 func ClusterScopedUniqueName(format Format, namespace, name string) (string, error) {
-	reservedSlots := 2 + uuidLength // 2 dashes + 5 character UUID string
+	return clusterScopedName(format, namespace, name, string(uuid.NewUUID()[:uuidLength]))
+}
+
+// ClusterScopedHashedName returns a name that is unique within a cluster and stays the same for the same object.
+// The name is formatted the same way as ClusterScopedUniqueName, except that the 5 character long suffix is a hash
+// of the object's namespace and name instead of a UUID, e.g. an object `app` from the namespace `work` will always
+// be assigned the name `work-app-` followed by the same suffix. The hash suffix keeps the name unique when name
+// components are truncated, or when different namespaces and names are joined into the same string (e.g. `a-b`/`c`
+// and `a`/`b-c`). The same assumptions as ClusterScopedUniqueName apply.
+func ClusterScopedHashedName(format Format, namespace, name string) (string, error) {
+	hash := sha256.Sum256([]byte(namespace + "/" + name))
+	return clusterScopedName(format, namespace, name, hex.EncodeToString(hash[:])[:uuidLength])
+}
+
+// clusterScopedName formats a name in the format of [NAMESPACE]-[NAME]-[SUFFIX].
+func clusterScopedName(format Format, namespace, name, suffix string) (string, error) {
+	reservedSlots := 2 + uuidLength // 2 dashes + 5 character suffix
 
 	switch format {
 	case DNS1123Subdomain:
@@ -66,7 +84,7 @@ func ClusterScopedUniqueName(format Format, namespace, name string) (string, err
 		uniqueName := fmt.Sprintf("%s-%s-%s",
 			namespace[:minInt(slotsPerSeg, len(namespace))],
 			name[:minInt(slotsPerSeg, len(name))],
-			uuid.NewUUID()[:uuidLength],
+			suffix,
 		)
 
 		if errs := validation.IsDNS1123Subdomain(uniqueName); len(errs) != 0 {
@@ -84,7 +102,7 @@ func ClusterScopedUniqueName(format Format, namespace, name string) (string, err
 		uniqueName := fmt.Sprintf("%s-%s-%s",
 			namespace[:minInt(slotsPerSeg, len(namespace))],
 			name[:minInt(slotsPerSeg, len(name))],
-			uuid.NewUUID()[:uuidLength],
+			suffix,
 		)
 
 		if errs := validation.IsDNS1123Label(uniqueName); len(errs) != 0 {
@@ -107,7 +125,7 @@ func ClusterScopedUniqueName(format Format, namespace, name string) (string, err
 		uniqueName := fmt.Sprintf("%s-%s-%s",
 			namespace[:minInt(slotsPerSeg, len(namespace))],
 			name[:minInt(slotsPerSeg, len(name))],
-			uuid.NewUUID()[:uuidLength],
+			suffix,
 		)
 
 		if errs := validation.IsDNS1035Label(uniqueName); len(errs) != 0 {
@@ -133,6 +133,65 @@ func TestClusterScopedUniqueName(t *testing.T) {
 	}
 }
 
+// TestClusterScopedHashedName tests the ClusterScopedHashedName function.
+func TestClusterScopedHashedName(t *testing.T) {
+	testCases := []struct {
+		name       string
+		format     Format
+		objectNS   string
+		objectName string
+		want       string
+	}{
+		{
+			name:       "should format RFC 1035 DNS label name",
+			format:     DNS1035Label,
+			objectNS:   objectNS,
+			objectName: objectName,
+			want:       "work-app-bf2fb",
+		},
+		{
+			name:       "should format different names for objects with the same joined namespace and name",
+			format:     DNS1035Label,
+			objectNS:   "a-b",
+			objectName: "c",
+			want:       "a-b-c-4e847",
+		},
+		{
+			name:       "should format different names for objects with the same joined namespace and name (the other one)",
+			format:     DNS1035Label,
+			objectNS:   "a",
+			objectName: "b-c",
+			want:       "a-b-c-b88f8",
+		},
+		{
+			name:       "should format RFC 1035 DNS label name (no numeric starts allowed)",
+			format:     DNS1035Label,
+			objectNS:   "0" + objectNS,
+			objectName: objectName,
+			want:       "ns0work-app-96947",
+		},
+		{
+			name:       "should format RFC 1035 DNS label name (truncated)",
+			format:     DNS1035Label,
+			objectNS:   longObjectNS,
+			objectName: longObjectName,
+			want:       "mdlmpqe2ev31zgxar1gswscd3hsv-c7t2c6oppjnryqcihwweexeobs7t-6cd45",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ClusterScopedHashedName(tc.format, tc.objectNS, tc.objectName)
+			if err != nil {
+				t.Fatalf("ClusterScopedHashedName(%d, %s, %s), got %v, want no error", tc.format, tc.objectNS, tc.objectName, err)
+			}
+			if got != tc.want {
+				t.Errorf("ClusterScopedHashedName(%d, %s, %s)=%s, want %s", tc.format, tc.objectNS, tc.objectName, got, tc.want)
+			}
+		})
+	}
+}
+
 // TestFleetScopedUniqueName tests the FleetScopedUniqueName function.
 func TestFleetScopedUniqueName(t *testing.T) {
 	testCases := []struct {
@@ -9,32 +9,65 @@ package endpointsliceexport
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 	"time"
 
+	"golang.org/x/sync/errgroup"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	clusterv1beta1 "go.goms.io/fleet/apis/cluster/v1beta1"
+
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 	"go.goms.io/fleet-networking/pkg/common/apiretry"
-	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/clusterselector"
+	"go.goms.io/fleet-networking/pkg/common/condition"
+	"go.goms.io/fleet-networking/pkg/common/events"
+	"go.goms.io/fleet-networking/pkg/common/hubconfig"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+	"go.goms.io/fleet-networking/pkg/common/tracing"
 )
 
 const (
+	// ControllerName is the name of the Reconciler.
+	ControllerName = "endpointsliceexport-controller"
+
 	endpointSliceExportCleanupFinalizer = "networking.fleet.azure.com/endpointsliceexport-cleanup"
 
 	endpointSliceImportNameFieldKey                   = ".metadata.name"
 	endpointSliceExportOwnerSvcNamespacedNameFieldKey = ".spec.ownerServiceReference.namespacedName"
 
 	endpointSliceExportRetryInterval = time.Second * 5
+
+	// fieldManager is the name of the field manager with which the controller applies EndpointSliceImports.
+	fieldManager = "endpointsliceexport-controller.networking.fleet.azure.com"
+	// maxConcurrentEndpointSliceImportWrites is the maximum number of EndpointSliceImports the controller writes
+	// to at the same time when distributing an EndpointSlice.
+	maxConcurrentEndpointSliceImportWrites = 8
+
+	// The reasons of the Distributed condition of an EndpointSliceExport.
+	distributedReasonServiceImportNotFound = "ServiceImportNotFound"
+	distributedReasonServiceImportPending  = "ServiceImportPending"
+	distributedReasonNoImportingClusters   = "NoImportingClusters"
+	distributedReasonDistributed           = "Distributed"
+	distributedReasonDistributionFailed    = "DistributionFailed"
+	distributedReasonExportStale           = "ExportStale"
 )
 
 var (
@@ -53,16 +86,32 @@ var (
 		}
 		return []string{endpointSliceExport.Spec.OwnerServiceReference.NamespacedName}
 	}
+
+	// memberClusterLabelChangedPredicate filters out the MemberCluster events which do not change its labels, e.g.
+	// the frequent status updates; creations and deletions are always handled.
+	memberClusterLabelChangedPredicate = predicate.LabelChangedPredicate{}
 )
 
 // Reconciler reconciles the distribution of EndpointSlices across the fleet.
 type Reconciler struct {
 	HubClient client.Client
+	// WatchMemberClusters enables re-distributing the EndpointSlices when the labels of a MemberCluster change,
+	// which may change the clusters selected by the cluster selectors of the imports; it requires the MemberCluster
+	// API to be installed in the hub cluster.
+	WatchMemberClusters bool
+	// Recorder records the distribution of the EndpointSlices to, and their withdrawal from, the member clusters
+	// on the EndpointSliceExports.
+	Recorder record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=endpointsliceexports,verbs=get;list;watch;create;update;patch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=endpointsliceexports/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=endpointsliceimports,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalserviceimports,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalserviceexports,verbs=get;list;watch
+//+kubebuilder:rbac:groups=cluster.kubernetes-fleet.io,resources=memberclusters,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 //+kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;create;update;patch;delete;list;watch
 
 // Reconcile distributes an exported EndpointSlice (in the form of EndpointSliceExports) to whichever member
@@ -91,6 +140,12 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return ctrl.Result{}, err
 	}
 
+	// Continue the trace of the export started in the member cluster; the trace is passed on to the importing
+	// member clusters with the distributed EndpointSliceImports.
+	ctx, span := tracing.StartSpanFromObject(ctx, endpointSliceExport, "DistributeEndpointSlice",
+		tracing.AttributeKeyClusterID.String(endpointSliceExport.Spec.EndpointSliceReference.ClusterID))
+	defer span.End()
+
 	// Check if the EndpointSliceExport has been marked for deletion; withdraw EndpointSliceImports across
 	// the fleet if the EndpointSlice has been distributed.
 	if endpointSliceExport.DeletionTimestamp != nil {
@@ -106,6 +161,27 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return ctrl.Result{}, nil
 	}
 
+	// Withdraw the distributed EndpointSlices if the member cluster where the EndpointSlice is exported from has
+	// stopped sending heartbeats, as the endpoints are likely unreachable; the EndpointSlice will be distributed
+	// again once the heartbeats resume.
+	stale, err := r.isExportStale(ctx, endpointSliceExport)
+	if err != nil {
+		klog.ErrorS(err, "Failed to check if the export is stale", "endpointSliceExport", endpointSliceExportRef)
+		return ctrl.Result{}, err
+	}
+	if stale {
+		klog.V(2).InfoS("Exporting cluster has stopped sending heartbeats; withdraw distributed EndpointSlices", "endpointSliceExport", endpointSliceExportRef)
+		if err := r.withdrawAllEndpointSliceImports(ctx, endpointSliceExport); err != nil {
+			return ctrl.Result{}, err
+		}
+		cond := undistributedCondition(endpointSliceExport, distributedReasonExportStale,
+			fmt.Sprintf("member cluster %s has stopped sending heartbeats", endpointSliceExport.Spec.EndpointSliceReference.ClusterID))
+		if err := r.updateEndpointSliceExportStatus(ctx, endpointSliceExport, cond, nil); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
 	// Inquire the corresponding ServiceImport to find out which member clusters the EndpointSlice should be
 	// distributed to.
 	ownerSvcNS := endpointSliceExport.Spec.OwnerServiceReference.Namespace
@@ -116,7 +192,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	klog.V(2).InfoS("Inquire ServceImport to find out which member clusters have requested the EndpointSlice",
 		"serviceImport", svcImportRef,
 		"endpointSliceExport", endpointSliceExportRef)
-	err := r.HubClient.Get(ctx, svcImportKey, svcImport)
+	err = r.HubClient.Get(ctx, svcImportKey, svcImport)
 	switch {
 	case err != nil && errors.IsNotFound(err):
 		// The corresponding ServiceImport does not exist; normally this will never happen as an EndpointSlice can
@@ -125,6 +201,11 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		// and the system does not get to withdraw exported EndpointSlices from the Service yet. The controller
 		// will requeue the EndpointSliceExport and wait until the state stablizes.
 		klog.V(2).InfoS("ServiceImport does not exist", "serviceImport", svcImportRef, "endpointSliceExport", endpointSliceExportRef)
+		cond := undistributedCondition(endpointSliceExport, distributedReasonServiceImportNotFound,
+			fmt.Sprintf("serviceImport %s does not exist", svcImportKey))
+		if err := r.updateEndpointSliceExportStatus(ctx, endpointSliceExport, cond, endpointSliceExport.Status.Distributions); err != nil {
+			return ctrl.Result{}, err
+		}
 		return ctrl.Result{RequeueAfter: endpointSliceExportRetryInterval}, nil
 	case err != nil:
 		// An unexpected error occurs.
@@ -137,11 +218,15 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		klog.V(2).InfoS("ServiceImport is being processed (no accepted exports yet)",
 			"serviceImport", svcImportRef,
 			"endpointSliceExport", endpointSliceExportRef)
+		cond := undistributedCondition(endpointSliceExport, distributedReasonServiceImportPending,
+			fmt.Sprintf("serviceImport %s has no accepted exports yet", svcImportKey))
+		if err := r.updateEndpointSliceExportStatus(ctx, endpointSliceExport, cond, endpointSliceExport.Status.Distributions); err != nil {
+			return ctrl.Result{}, err
+		}
 		return ctrl.Result{RequeueAfter: endpointSliceExportRetryInterval}, nil
 	}
 
-	data, ok := svcImport.ObjectMeta.Annotations[objectmeta.ServiceImportAnnotationServiceInUseBy]
-	if !ok {
+	if len(svcImport.Status.ImportedBy) == 0 {
 		// No cluster has requested to import the EndpointSlice's owner service.
 		// If the exported EndpointSlice has been distributed across the fleet before; withdraw the
 		// EndpointSliceImports.
@@ -153,18 +238,26 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		}
 		// There is no need to remove the local EndpointSlice copy in this situation (the copy might be in
 		// use by load balancing solutions on the hub cluster).
+		cond := undistributedCondition(endpointSliceExport, distributedReasonNoImportingClusters,
+			fmt.Sprintf("no member cluster has imported service %s", svcImportKey))
+		if err := r.updateEndpointSliceExportStatus(ctx, endpointSliceExport, cond, nil); err != nil {
+			return ctrl.Result{}, err
+		}
 		return ctrl.Result{}, nil
 	}
 
-	svcInUseBy := &fleetnetv1alpha1.ServiceInUseBy{}
-	if err := json.Unmarshal([]byte(data), svcInUseBy); err != nil {
-		klog.ErrorS(err, "Failed to unmarshal data for in-use Services from ServiceImport annotations",
+	importingClusters := make(map[fleetnetv1alpha1.ClusterNamespace]fleetnetv1alpha1.ClusterID, len(svcImport.Status.ImportedBy))
+	for _, clusterImport := range svcImport.Status.ImportedBy {
+		importingClusters[clusterImport.ClusterNamespace] = clusterImport.Cluster
+	}
+
+	// Skip the member clusters which import the Service, yet do not select the member cluster where the EndpointSlice
+	// is exported from; the EndpointSlice will be withdrawn from these member clusters, if it has been distributed.
+	if err := r.excludeUnselectingClusters(ctx, endpointSliceExport, importingClusters); err != nil {
+		klog.ErrorS(err, "Failed to evaluate the cluster selectors of the imports",
 			"serviceImport", svcImportRef,
-			"endpointSliceExport", endpointSliceExportRef,
-			"data", data)
-		// This error cannot be recovered by retrying; a reconciliation will be triggered when the ServiceInUseBy
-		// data is overwritten.
-		return ctrl.Result{}, nil
+			"endpointSliceExport", endpointSliceExportRef)
+		return ctrl.Result{}, err
 	}
 
 	// Distribute the EndpointSlices.
@@ -179,69 +272,86 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 
 	// Scan for EndpointSlices to withdraw and EndpointSlices to create or update.
 	klog.V(2).InfoS("Scan for EndpointSliceImports to withdraw and to create/update",
-		"serviceInUseBy", svcInUseBy,
+		"importingClusters", importingClusters,
 		"endpointSliceExport", endpointSliceExport)
-	endpointSliceImportsToWithdraw, endpointSlicesImportsToCreateOrUpdate, err := r.scanForEndpointSliceImports(ctx, endpointSliceExport, svcInUseBy)
+	// Keep a copy of the importing clusters, as scanning for EndpointSliceImports consumes the map.
+	importingClusterIDs := maps.Clone(importingClusters)
+	endpointSliceImportsToWithdraw, endpointSlicesImportsToCreateOrUpdate, err := r.scanForEndpointSliceImports(ctx, endpointSliceExport, importingClusters)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 	klog.V(4).InfoS("EndpointSliceImports to withdraw", "count", len(endpointSliceImportsToWithdraw))
 	klog.V(4).InfoS("EndpointSliceImports to create or update", "count", len(endpointSlicesImportsToCreateOrUpdate))
 
-	// Delete distributed EndpointSlices that are no longer needed.
+	// Withdraw distributed EndpointSlices that are no longer needed, and create or update the ones requested by
+	// member clusters, in parallel.
 	//
 	// Note: At this moment, it is guaranteed that any Service can only be imported once across the fleet, consequently
-	// len(endpointSliceImportsToDelete) is at most 1. However, this behavior is subject to change as fleet
-	// networking evolves, and for future compatibility reasons, the function assumes that a Service might have been
-	// imported to multiple clusters.
+	// there is at most one EndpointSliceImport to withdraw or to create or update. However, this behavior is subject
+	// to change as fleet networking evolves, and for future compatibility reasons, the controller assumes that a
+	// Service might have been imported to multiple clusters.
+	//
+	// A failed write does not cancel the others, so that the distribution to each member cluster is reported on its
+	// own merits.
+	errs := errgroup.Group{}
+	errs.SetLimit(maxConcurrentEndpointSliceImportWrites)
 	for idx := range endpointSliceImportsToWithdraw {
 		endpointSliceImport := endpointSliceImportsToWithdraw[idx]
 		// Skip if the EndpointSliceImport has been marked for deletion.
 		if endpointSliceImport.DeletionTimestamp != nil {
 			continue
 		}
-		klog.V(4).InfoS("Withdraw endpointSlice",
-			"endpointSliceImport", klog.KObj(endpointSliceImport),
-			"endpointSliceExport", endpointSliceExportRef)
-		if err := apiretry.Do(func() error {
-			return r.HubClient.Delete(ctx, endpointSliceImport)
-		}); err != nil && !errors.IsNotFound(err) {
-			klog.ErrorS(err, "Failed to withdraw EndpointSlice",
-				"endpointSliceImport", klog.KObj(endpointSliceImport),
-				"endpointSliceExport", endpointSliceExportRef)
-			return ctrl.Result{}, err
-		}
+		errs.Go(func() error {
+			return r.withdrawEndpointSliceImport(ctx, endpointSliceImport, endpointSliceExport)
+		})
 	}
-
-	// Create or update distributed EndpointSlices.
-	//
-	// Note: At this moment, it is guaranteed that any Service can only be imported once across the fleet, consequently
-	// len(endpointSliceImportsToCreateOrUpdate) is at most 1. However, this behavior is subject to change as fleet
-	// networking evolves, and for future compatibility reasons, the function assumes that a Service might have been
-	// imported to multiple clusters.
+	// Each EndpointSliceImport to create or update records its distribution result at its own index, so that
+	// the results can be collected without locking.
+	distributions := make([]fleetnetv1alpha1.EndpointSliceDistribution, len(endpointSlicesImportsToCreateOrUpdate))
 	for idx := range endpointSlicesImportsToCreateOrUpdate {
 		endpointSliceImport := endpointSlicesImportsToCreateOrUpdate[idx]
-		klog.V(4).InfoS("Create/update endpointSliceImport",
-			"endpointSliceImport", klog.KObj(endpointSliceImport),
-			"endpointSliceExport", endpointSliceExportRef)
-
-		var op controllerutil.OperationResult
-		if err := apiretry.Do(func() error {
-			var createOrUpdateErr error
-			op, createOrUpdateErr = controllerutil.CreateOrUpdate(ctx, r.HubClient, endpointSliceImport, func() error {
-				endpointSliceImport.Spec = *endpointSliceExport.Spec.DeepCopy()
-				return nil
-			})
-			return createOrUpdateErr
-		}); err != nil {
-			klog.ErrorS(err, "Failed to create or update EndpointSliceImport",
+		clusterNS := fleetnetv1alpha1.ClusterNamespace(endpointSliceImport.Namespace)
+		distributions[idx] = fleetnetv1alpha1.EndpointSliceDistribution{
+			ClusterNamespace: clusterNS,
+			Cluster:          importingClusterIDs[clusterNS],
+			Distributed:      true,
+		}
+		// Skip if the EndpointSliceImport has been distributed and is up to date.
+		if endpointSliceImport.ResourceVersion != "" && equality.Semantic.DeepEqual(endpointSliceImport.Spec, endpointSliceExport.Spec) {
+			klog.V(4).InfoS("EndpointSliceImport is up to date",
 				"endpointSliceImport", klog.KObj(endpointSliceImport),
-				"endpointSliceExport", endpointSliceExportRef,
-				"op", op)
-			return ctrl.Result{}, err
+				"endpointSliceExport", endpointSliceExportRef)
+			continue
 		}
+		errs.Go(func() error {
+			if err := r.applyEndpointSliceImport(ctx, endpointSliceImport, endpointSliceExport); err != nil {
+				distributions[idx].Distributed = false
+				distributions[idx].Message = err.Error()
+				r.Recorder.Eventf(endpointSliceExport, corev1.EventTypeWarning, events.ReasonDistributionFailed,
+					"Failed to distribute the EndpointSlice to namespace %s: %v", endpointSliceImport.Namespace, err)
+				return err
+			}
+			// Only the first distribution to a member cluster is a transition; the updates follow the changes
+			// of the exported EndpointSlice.
+			if endpointSliceImport.ResourceVersion == "" {
+				r.Recorder.Eventf(endpointSliceExport, corev1.EventTypeNormal, events.ReasonDistributed,
+					"Distributed the EndpointSlice to namespace %s", endpointSliceImport.Namespace)
+			}
+			return nil
+		})
 	}
+	distributionErr := errs.Wait()
 
+	slices.SortFunc(distributions, func(a, b fleetnetv1alpha1.EndpointSliceDistribution) int {
+		return strings.Compare(string(a.ClusterNamespace), string(b.ClusterNamespace))
+	})
+	cond := distributedCondition(endpointSliceExport, distributions)
+	if err := r.updateEndpointSliceExportStatus(ctx, endpointSliceExport, cond, distributions); err != nil {
+		return ctrl.Result{}, err
+	}
+	if distributionErr != nil {
+		return ctrl.Result{}, distributionErr
+	}
 	return ctrl.Result{}, nil
 }
 
@@ -284,23 +394,153 @@ func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) err
 				"serviceImport", klog.KObj(svcImport))
 			return []reconcile.Request{}
 		}
+		return endpointSliceExportRequests(endpointSliceExportList.Items)
+	})
+
+	// Enqueue EndpointSliceExports for processing when an InternalServiceImport changes, as its cluster selector
+	// might have changed.
+	internalSvcImportEventHandlers := handler.EnqueueRequestsFromMapFunc(func(_ context.Context, o client.Object) []reconcile.Request {
+		internalSvcImport, ok := o.(*fleetnetv1alpha1.InternalServiceImport)
+		if !ok {
+			return []reconcile.Request{}
+		}
 
-		reqs := make([]reconcile.Request, 0, len(endpointSliceExportList.Items))
-		for _, endpointSliceExport := range endpointSliceExportList.Items {
-			reqs = append(reqs, reconcile.Request{
-				NamespacedName: types.NamespacedName{
-					Namespace: endpointSliceExport.Namespace,
-					Name:      endpointSliceExport.Name,
-				},
-			})
+		endpointSliceExportList := &fleetnetv1alpha1.EndpointSliceExportList{}
+		fieldMatcher := client.MatchingFields{
+			endpointSliceExportOwnerSvcNamespacedNameFieldKey: internalSvcImport.Spec.ServiceImportReference.NamespacedName,
+		}
+		if err := r.HubClient.List(ctx, endpointSliceExportList, fieldMatcher); err != nil {
+			klog.ErrorS(err,
+				"Failed to list EndpointSliceExports for an imported Service",
+				"internalServiceImport", klog.KObj(internalSvcImport))
+			return []reconcile.Request{}
+		}
+		return endpointSliceExportRequests(endpointSliceExportList.Items)
+	})
+
+	// Enqueue the EndpointSliceExports of an exported Service for processing when its InternalServiceExport changes,
+	// as the exporting cluster might have become stale or fresh.
+	internalSvcExportEventHandlers := handler.EnqueueRequestsFromMapFunc(func(_ context.Context, o client.Object) []reconcile.Request {
+		internalSvcExport, ok := o.(*fleetnetv1alpha1.InternalServiceExport)
+		if !ok {
+			return []reconcile.Request{}
+		}
+
+		endpointSliceExportList := &fleetnetv1alpha1.EndpointSliceExportList{}
+		fieldMatcher := client.MatchingFields{
+			endpointSliceExportOwnerSvcNamespacedNameFieldKey: internalSvcExport.Spec.ServiceReference.NamespacedName,
+		}
+		if err := r.HubClient.List(ctx, endpointSliceExportList, client.InNamespace(internalSvcExport.Namespace), fieldMatcher); err != nil {
+			klog.ErrorS(err,
+				"Failed to list EndpointSliceExports for an exported Service",
+				"internalServiceExport", klog.KObj(internalSvcExport))
+			return []reconcile.Request{}
 		}
-		return reqs
+		return endpointSliceExportRequests(endpointSliceExportList.Items)
 	})
 
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&fleetnetv1alpha1.EndpointSliceExport{}).
 		Watches(&fleetnetv1alpha1.ServiceImport{}, eventHandlers).
-		Complete(r)
+		Watches(&fleetnetv1alpha1.InternalServiceImport{}, internalSvcImportEventHandlers).
+		Watches(&fleetnetv1alpha1.InternalServiceExport{}, internalSvcExportEventHandlers)
+	if r.WatchMemberClusters {
+		// Enqueue the EndpointSliceExports exported from a member cluster for processing when its MemberCluster
+		// changes, as its labels might have changed.
+		memberClusterEventHandlers := handler.EnqueueRequestsFromMapFunc(r.endpointSliceExportsForMemberCluster)
+		b = b.Watches(&clusterv1beta1.MemberCluster{}, memberClusterEventHandlers, builder.WithPredicates(memberClusterLabelChangedPredicate))
+	}
+	return b.Complete(metrics.InstrumentReconciler("endpointsliceexport", r))
+}
+
+// endpointSliceExportsForMemberCluster returns the requests of the EndpointSliceExports exported from a member
+// cluster.
+func (r *Reconciler) endpointSliceExportsForMemberCluster(ctx context.Context, o client.Object) []reconcile.Request {
+	endpointSliceExportList := &fleetnetv1alpha1.EndpointSliceExportList{}
+	// The EndpointSliceExports exported from a member cluster are kept in its reserved namespace.
+	mcNamespace := fmt.Sprintf(hubconfig.HubNamespaceNameFormat, o.GetName())
+	if err := r.HubClient.List(ctx, endpointSliceExportList, client.InNamespace(mcNamespace)); err != nil {
+		klog.ErrorS(err, "Failed to list EndpointSliceExports for a member cluster", "memberCluster", klog.KObj(o))
+		return []reconcile.Request{}
+	}
+	endpointSliceExports := make([]fleetnetv1alpha1.EndpointSliceExport, 0, len(endpointSliceExportList.Items))
+	for _, endpointSliceExport := range endpointSliceExportList.Items {
+		if endpointSliceExport.Spec.EndpointSliceReference.ClusterID == o.GetName() {
+			endpointSliceExports = append(endpointSliceExports, endpointSliceExport)
+		}
+	}
+	return endpointSliceExportRequests(endpointSliceExports)
+}
+
+// endpointSliceExportRequests returns the reconcile requests for a list of EndpointSliceExports.
+func endpointSliceExportRequests(endpointSliceExports []fleetnetv1alpha1.EndpointSliceExport) []reconcile.Request {
+	reqs := make([]reconcile.Request, 0, len(endpointSliceExports))
+	for _, endpointSliceExport := range endpointSliceExports {
+		reqs = append(reqs, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: endpointSliceExport.Namespace,
+				Name:      endpointSliceExport.Name,
+			},
+		})
+	}
+	return reqs
+}
+
+// isExportStale returns if the member cluster where an EndpointSlice is exported from has stopped sending heartbeats,
+// as reported on the InternalServiceExport of the EndpointSlice's owner Service.
+//
+// The InternalServiceExport of a Service is named as [NAMESPACE]-[NAME] in the namespace of the member cluster; as
+// the name may be shared by another Service, the Service reference of the InternalServiceExport is verified as well.
+func (r *Reconciler) isExportStale(ctx context.Context, endpointSliceExport *fleetnetv1alpha1.EndpointSliceExport) (bool, error) {
+	ownerSvcRef := endpointSliceExport.Spec.OwnerServiceReference
+	internalSvcExportKey := types.NamespacedName{
+		Namespace: endpointSliceExport.Namespace,
+		Name:      fmt.Sprintf("%s-%s", ownerSvcRef.Namespace, ownerSvcRef.Name),
+	}
+	internalSvcExport := &fleetnetv1alpha1.InternalServiceExport{}
+	if err := r.HubClient.Get(ctx, internalSvcExportKey, internalSvcExport); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get InternalServiceExport %s: %w", internalSvcExportKey, err)
+	}
+	if internalSvcExport.Spec.ServiceReference.NamespacedName != ownerSvcRef.NamespacedName {
+		return false, nil
+	}
+	return meta.IsStatusConditionTrue(internalSvcExport.Status.Conditions, string(fleetnetv1alpha1.ServiceExportStale)), nil
+}
+
+// excludeUnselectingClusters removes the member clusters whose imports do not select the member cluster where an
+// EndpointSlice is exported from.
+func (r *Reconciler) excludeUnselectingClusters(ctx context.Context,
+	endpointSliceExport *fleetnetv1alpha1.EndpointSliceExport,
+	importingClusters map[fleetnetv1alpha1.ClusterNamespace]fleetnetv1alpha1.ClusterID) error {
+	exportingClusterID := endpointSliceExport.Spec.EndpointSliceReference.ClusterID
+	ownerSvcRef := endpointSliceExport.Spec.OwnerServiceReference
+	for clusterNS := range importingClusters {
+		internalSvcImportList := &fleetnetv1alpha1.InternalServiceImportList{}
+		if err := r.HubClient.List(ctx, internalSvcImportList, client.InNamespace(string(clusterNS))); err != nil {
+			return fmt.Errorf("failed to list InternalServiceImports in namespace %s: %w", clusterNS, err)
+		}
+		for idx := range internalSvcImportList.Items {
+			internalSvcImport := &internalSvcImportList.Items[idx]
+			svcImportRef := internalSvcImport.Spec.ServiceImportReference
+			if svcImportRef.Namespace != ownerSvcRef.Namespace || svcImportRef.Name != ownerSvcRef.Name {
+				continue
+			}
+			selected, err := clusterselector.IsClusterSelected(ctx, r.HubClient, internalSvcImport.Spec.ClusterSelector, exportingClusterID)
+			if err != nil {
+				return err
+			}
+			if !selected {
+				klog.V(2).InfoS("The importing member cluster does not select the exporting member cluster",
+					"internalServiceImport", klog.KObj(internalSvcImport),
+					"exportingClusterID", exportingClusterID)
+				delete(importingClusters, clusterNS)
+			}
+		}
+	}
+	return nil
 }
 
 // withdrawEndpointSliceImports withdraws EndpointSliceImports distributed across the fleet.
@@ -317,17 +557,17 @@ func (r *Reconciler) withdrawAllEndpointSliceImports(ctx context.Context, endpoi
 		return err
 	}
 
-	// Withdraw EndpointSliceImports from member clusters.
+	// Withdraw EndpointSliceImports from member clusters; a failed withdrawal does not cancel the others.
+	errs := errgroup.Group{}
+	errs.SetLimit(maxConcurrentEndpointSliceImportWrites)
 	for idx := range endpointSliceImportList.Items {
-		endpointSliceImport := endpointSliceImportList.Items[idx]
-		if err := apiretry.Do(func() error {
-			return r.HubClient.Delete(ctx, &endpointSliceImport)
-		}); err != nil && !errors.IsNotFound(err) {
-			klog.ErrorS(err, "Failed to withdraw EndpointSliceImport",
-				"endpointSliceImport", klog.KObj(&endpointSliceImport),
-				"endpointSliceExport", klog.KObj(endpointSliceExport))
-			return err
-		}
+		endpointSliceImport := &endpointSliceImportList.Items[idx]
+		errs.Go(func() error {
+			return r.withdrawEndpointSliceImport(ctx, endpointSliceImport, endpointSliceExport)
+		})
+	}
+	if err := errs.Wait(); err != nil {
+		return err
 	}
 
 	// Remove the EndpointSliceExport cleanup finalizer.
@@ -338,6 +578,137 @@ func (r *Reconciler) withdrawAllEndpointSliceImports(ctx context.Context, endpoi
 	return nil
 }
 
+// withdrawEndpointSliceImport withdraws an EndpointSliceImport distributed to a member cluster.
+func (r *Reconciler) withdrawEndpointSliceImport(ctx context.Context,
+	endpointSliceImport *fleetnetv1alpha1.EndpointSliceImport,
+	endpointSliceExport *fleetnetv1alpha1.EndpointSliceExport) error {
+	klog.V(4).InfoS("Withdraw endpointSliceImport",
+		"endpointSliceImport", klog.KObj(endpointSliceImport),
+		"endpointSliceExport", klog.KObj(endpointSliceExport))
+	ctx, span := tracing.StartSpan(ctx, "WithdrawEndpointSliceImport", tracing.AttributeKeyNamespace.String(endpointSliceImport.Namespace))
+	err := apiretry.Do(func() error {
+		return r.HubClient.Delete(ctx, endpointSliceImport)
+	})
+	if errors.IsNotFound(err) {
+		// The EndpointSliceImport has been withdrawn already.
+		tracing.EndSpan(span, nil)
+		return nil
+	}
+	tracing.EndSpan(span, err)
+	if err != nil {
+		klog.ErrorS(err, "Failed to withdraw EndpointSliceImport",
+			"endpointSliceImport", klog.KObj(endpointSliceImport),
+			"endpointSliceExport", klog.KObj(endpointSliceExport))
+		r.Recorder.Eventf(endpointSliceExport, corev1.EventTypeWarning, events.ReasonWithdrawalFailed,
+			"Failed to withdraw the EndpointSlice from namespace %s: %v", endpointSliceImport.Namespace, err)
+		return err
+	}
+	r.Recorder.Eventf(endpointSliceExport, corev1.EventTypeNormal, events.ReasonWithdrawn,
+		"Withdrew the EndpointSlice from namespace %s", endpointSliceImport.Namespace)
+	return nil
+}
+
+// applyEndpointSliceImport creates or updates an EndpointSliceImport distributed to a member cluster with
+// server-side apply; the controller owns the whole spec of the EndpointSliceImport.
+func (r *Reconciler) applyEndpointSliceImport(ctx context.Context,
+	endpointSliceImport *fleetnetv1alpha1.EndpointSliceImport,
+	endpointSliceExport *fleetnetv1alpha1.EndpointSliceExport) error {
+	klog.V(4).InfoS("Apply endpointSliceImport",
+		"endpointSliceImport", klog.KObj(endpointSliceImport),
+		"endpointSliceExport", klog.KObj(endpointSliceExport))
+	appliedEndpointSliceImport := &fleetnetv1alpha1.EndpointSliceImport{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: fleetnetv1alpha1.GroupVersion.String(),
+			Kind:       "EndpointSliceImport",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: endpointSliceImport.Namespace,
+			Name:      endpointSliceImport.Name,
+		},
+		Spec: *endpointSliceExport.Spec.DeepCopy(),
+	}
+	ctx, span := tracing.StartSpan(ctx, "ApplyEndpointSliceImport", tracing.AttributeKeyNamespace.String(endpointSliceImport.Namespace))
+	// Pass the trace on to the importing member cluster; the EndpointSliceImport is only applied when it changes.
+	tracing.Inject(ctx, appliedEndpointSliceImport)
+	err := apiretry.Do(func() error {
+		return r.HubClient.Patch(ctx, appliedEndpointSliceImport, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership)
+	})
+	tracing.EndSpan(span, err)
+	if err != nil {
+		klog.ErrorS(err, "Failed to apply EndpointSliceImport",
+			"endpointSliceImport", klog.KObj(endpointSliceImport),
+			"endpointSliceExport", klog.KObj(endpointSliceExport))
+		return err
+	}
+	return nil
+}
+
+// updateEndpointSliceExportStatus updates the Distributed condition and the per-cluster distribution results of
+// an EndpointSliceExport, if they have changed.
+func (r *Reconciler) updateEndpointSliceExportStatus(ctx context.Context,
+	endpointSliceExport *fleetnetv1alpha1.EndpointSliceExport,
+	distributedCond metav1.Condition,
+	distributions []fleetnetv1alpha1.EndpointSliceDistribution) error {
+	currentCond := meta.FindStatusCondition(endpointSliceExport.Status.Conditions, string(fleetnetv1alpha1.EndpointSliceExportDistributed))
+	if condition.EqualCondition(currentCond, &distributedCond) &&
+		equality.Semantic.DeepEqual(endpointSliceExport.Status.Distributions, distributions) {
+		return nil
+	}
+
+	meta.SetStatusCondition(&endpointSliceExport.Status.Conditions, distributedCond)
+	endpointSliceExport.Status.Distributions = distributions
+	klog.V(4).InfoS("Update endpointSliceExport status",
+		"endpointSliceExport", klog.KObj(endpointSliceExport),
+		"condition", distributedCond)
+	ctx, span := tracing.StartSpan(ctx, "UpdateEndpointSliceExportStatus")
+	err := r.HubClient.Status().Update(ctx, endpointSliceExport)
+	tracing.EndSpan(span, err)
+	if err != nil {
+		klog.ErrorS(err, "Failed to update endpointSliceExport status", "endpointSliceExport", klog.KObj(endpointSliceExport))
+		return err
+	}
+	return nil
+}
+
+// distributedCondition returns the Distributed condition of an EndpointSliceExport which has been distributed to
+// the importing member clusters, based on the per-cluster distribution results.
+func distributedCondition(endpointSliceExport *fleetnetv1alpha1.EndpointSliceExport,
+	distributions []fleetnetv1alpha1.EndpointSliceDistribution) metav1.Condition {
+	if len(distributions) == 0 {
+		return undistributedCondition(endpointSliceExport, distributedReasonNoImportingClusters,
+			"no importing member cluster selects the exporting member cluster")
+	}
+	var failedClusters []string
+	for _, distribution := range distributions {
+		if !distribution.Distributed {
+			failedClusters = append(failedClusters, string(distribution.Cluster))
+		}
+	}
+	if len(failedClusters) > 0 {
+		return undistributedCondition(endpointSliceExport, distributedReasonDistributionFailed,
+			fmt.Sprintf("failed to distribute the endpointSlice to %d out of %d member clusters: %s",
+				len(failedClusters), len(distributions), strings.Join(failedClusters, ", ")))
+	}
+	return metav1.Condition{
+		Type:               string(fleetnetv1alpha1.EndpointSliceExportDistributed),
+		Status:             metav1.ConditionTrue,
+		Reason:             distributedReasonDistributed,
+		ObservedGeneration: endpointSliceExport.Generation,
+		Message:            fmt.Sprintf("the endpointSlice has been distributed to %d member clusters", len(distributions)),
+	}
+}
+
+// undistributedCondition returns a False Distributed condition of an EndpointSliceExport.
+func undistributedCondition(endpointSliceExport *fleetnetv1alpha1.EndpointSliceExport, reason, message string) metav1.Condition {
+	return metav1.Condition{
+		Type:               string(fleetnetv1alpha1.EndpointSliceExportDistributed),
+		Status:             metav1.ConditionFalse,
+		Reason:             reason,
+		ObservedGeneration: endpointSliceExport.Generation,
+		Message:            message,
+	}
+}
+
 // removeEndpointSliceExportCleanupFinalizer removes the cleanup finalizer from an EndpointSliceExport.
 func (r *Reconciler) removeEndpointSliceExportCleanupFinalizer(ctx context.Context, endpointSliceExport *fleetnetv1alpha1.EndpointSliceExport) error {
 	controllerutil.RemoveFinalizer(endpointSliceExport, endpointSliceExportCleanupFinalizer)
@@ -357,13 +728,13 @@ func (r *Reconciler) addEndpointSliceExportCleanupFinalizer(ctx context.Context,
 // * a list of EndpointSliceImports to create or update (as some member clusters have requested them).
 //
 // Note: At this moment, it is guaranteed that any Service can only be imported once across the fleet, consequently
-// len(svcInUseBy.MemberClusters) should always be 1. However, this behavior is subject to change as fleet
+// len(importingClusters) should always be 1. However, this behavior is subject to change as fleet
 // networking evolves, and for future compatibility reasons, the function assumes that a Service might have been
 // imported to multiple clusters.
 func (r *Reconciler) scanForEndpointSliceImports(
 	ctx context.Context,
 	endpointSliceExport *fleetnetv1alpha1.EndpointSliceExport,
-	svcInUseBy *fleetnetv1alpha1.ServiceInUseBy,
+	importingClusters map[fleetnetv1alpha1.ClusterNamespace]fleetnetv1alpha1.ClusterID,
 ) (endpointSliceImportsToWithdraw, endpointSliceImportsToCreateOrUpdate []*fleetnetv1alpha1.EndpointSliceImport, err error) {
 	// List all EndpointSlices distributed as EndpointSliceImports.
 	endpointSliceImportList := &fleetnetv1alpha1.EndpointSliceImportList{}
@@ -381,11 +752,11 @@ func (r *Reconciler) scanForEndpointSliceImports(
 	for idx := range endpointSliceImportList.Items {
 		endpointSliceImport := endpointSliceImportList.Items[idx]
 		nsKey := fleetnetv1alpha1.ClusterNamespace(endpointSliceImport.Namespace)
-		if _, ok := svcInUseBy.MemberClusters[nsKey]; ok {
+		if _, ok := importingClusters[nsKey]; ok {
 			// A member cluster has requested the EndpointSlice and an EndpointSlice has been distributed to the
 			// cluster; the EndpointSliceImport should be updated.
 			endpointSliceImportsToCreateOrUpdate = append(endpointSliceImportsToCreateOrUpdate, &endpointSliceImport)
-			delete(svcInUseBy.MemberClusters, nsKey)
+			delete(importingClusters, nsKey)
 		} else {
 			// No member cluster has imported the EndpointSlice yet an EndpointSlice has been distributed to the cluster;
 			// the EndpointSliceImport should be withdrawn.
@@ -394,7 +765,7 @@ func (r *Reconciler) scanForEndpointSliceImports(
 	}
 	// A member cluster has requested the EndpointSlice but no EndpointSlice has been distributed to the cluster;
 	// an EndpointSliceImport should be created.
-	for ns := range svcInUseBy.MemberClusters {
+	for ns := range importingClusters {
 		endpointSliceImport := &fleetnetv1alpha1.EndpointSliceImport{
 			ObjectMeta: metav1.ObjectMeta{
 				Namespace: string(ns),
@@ -6,7 +6,6 @@ Licensed under the MIT license.
 package endpointsliceexport
 
 import (
-	"encoding/json"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
@@ -18,7 +17,6 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
-	"go.goms.io/fleet-networking/pkg/common/objectmeta"
 )
 
 const (
@@ -34,34 +32,43 @@ var (
 	endpointSliceImportCKey = types.NamespacedName{Namespace: hubNSForMemberC, Name: endpointSliceExportName}
 )
 
-// fulfilledSvcInUseByAnnotation returns a fulfilled ServiceInUseBy for annotation use.
-func fulfilledSvcInUseByAnnotation() fleetnetv1alpha1.ServiceInUseBy {
-	return fleetnetv1alpha1.ServiceInUseBy{
-		MemberClusters: map[fleetnetv1alpha1.ClusterNamespace]fleetnetv1alpha1.ClusterID{
-			hubNSForMemberB: clusterIDForMemberB,
-			hubNSForMemberC: clusterIDForMemberC,
-		},
+// importedBy returns the importing clusters, in the form of ServiceImport status, of the given member clusters.
+func importedBy(clusters map[fleetnetv1alpha1.ClusterNamespace]fleetnetv1alpha1.ClusterID) []fleetnetv1alpha1.ClusterImportStatus {
+	clusterImports := make([]fleetnetv1alpha1.ClusterImportStatus, 0, len(clusters))
+	for clusterNamespace, clusterID := range clusters {
+		clusterImports = append(clusterImports, fleetnetv1alpha1.ClusterImportStatus{
+			ClusterNamespace: clusterNamespace,
+			Cluster:          clusterID,
+			ImportedSince:    metav1.NewTime(time.Now().Round(time.Second)),
+		})
 	}
+	return clusterImports
 }
 
-// unfulfilledAndRequestedServiceImport returns an empty ServiceImport annotated with ServiceInUseBy data.
-func unfulfilledAndRequestedServiceImport() *fleetnetv1alpha1.ServiceImport {
-	data, err := json.Marshal(fulfilledSvcInUseByAnnotation())
-	if err != nil {
-		panic("failed to marshal service in use annotation")
-	}
+// fulfilledImportedBy returns the importing clusters of a requested ServiceImport.
+func fulfilledImportedBy() []fleetnetv1alpha1.ClusterImportStatus {
+	return importedBy(map[fleetnetv1alpha1.ClusterNamespace]fleetnetv1alpha1.ClusterID{
+		hubNSForMemberB: clusterIDForMemberB,
+		hubNSForMemberC: clusterIDForMemberC,
+	})
+}
 
+// unfulfilledAndRequestedServiceImport returns an empty ServiceImport; the import requests are added to its status
+// by requestSvcImport.
+func unfulfilledAndRequestedServiceImport() *fleetnetv1alpha1.ServiceImport {
 	return &fleetnetv1alpha1.ServiceImport{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: memberUserNS,
 			Name:      svcName,
-			Annotations: map[string]string{
-				objectmeta.ServiceImportAnnotationServiceInUseBy: string(data),
-			},
 		},
 	}
 }
 
+// requestSvcImport marks a ServiceImport as imported by member clusters B and C in its status.
+func requestSvcImport(svcImport *fleetnetv1alpha1.ServiceImport) {
+	svcImport.Status.ImportedBy = fulfilledImportedBy()
+}
+
 // fulfillSvcImport fulfills a ServiceImport by updating its status.
 func fulfillSvcImport(svcImport *fleetnetv1alpha1.ServiceImport) {
 	svcImport.Status = fleetnetv1alpha1.ServiceImportStatus{
@@ -128,6 +135,7 @@ var _ = Describe("endpointsliceexport controller", func() {
 			svcImport = unfulfilledAndRequestedServiceImport()
 			Expect(hubClient.Create(ctx, svcImport)).Should(Succeed())
 			fulfillSvcImport(svcImport)
+			requestSvcImport(svcImport)
 			Expect(hubClient.Status().Update(ctx, svcImport)).Should(Succeed())
 
 			Expect(hubClient.Create(ctx, endpointSliceExport)).Should(Succeed())
@@ -225,7 +233,6 @@ var _ = Describe("endpointsliceexport controller", func() {
 
 		BeforeEach(func() {
 			svcImport = unfulfilledAndRequestedServiceImport()
-			svcImport.Annotations = map[string]string{}
 			Expect(hubClient.Create(ctx, svcImport)).Should(Succeed())
 			fulfillSvcImport(svcImport)
 			Expect(hubClient.Status().Update(ctx, svcImport)).Should(Succeed())
@@ -292,7 +299,6 @@ var _ = Describe("endpointsliceexport controller", func() {
 
 		BeforeEach(func() {
 			svcImport = unfulfilledAndRequestedServiceImport()
-			svcImport.Annotations = map[string]string{}
 			Expect(hubClient.Create(ctx, svcImport)).Should(Succeed())
 			fulfillSvcImport(svcImport)
 			Expect(hubClient.Status().Update(ctx, svcImport)).Should(Succeed())
@@ -353,82 +359,12 @@ var _ = Describe("endpointsliceexport controller", func() {
 		})
 	})
 
-	Context("new endpointsliceexport (bad service in use annotation)", func() {
+	Context("no importing clusters", func() {
 		var endpointSliceExport *fleetnetv1alpha1.EndpointSliceExport
 		var svcImport *fleetnetv1alpha1.ServiceImport
 
 		BeforeEach(func() {
 			svcImport = unfulfilledAndRequestedServiceImport()
-			svcImport.Annotations = map[string]string{
-				objectmeta.ServiceImportAnnotationServiceInUseBy: "xyz",
-			}
-			Expect(hubClient.Create(ctx, svcImport)).Should(Succeed())
-			fulfillSvcImport(svcImport)
-			Expect(hubClient.Status().Update(ctx, svcImport)).Should(Succeed())
-
-			endpointSliceExport = ipv4EndpointSliceExport()
-			endpointSliceExport.Finalizers = []string{}
-			Expect(hubClient.Create(ctx, endpointSliceExport)).Should(Succeed())
-		})
-
-		AfterEach(func() {
-			Expect(hubClient.Delete(ctx, endpointSliceExport)).Should(Succeed())
-			// Wait until all EndpointSliceExport resources are cleaned up; this helps make the test less flaky.
-			Eventually(func() bool {
-				endpointSliceImportList := &fleetnetv1alpha1.EndpointSliceImportList{}
-				if err := hubClient.List(ctx, endpointSliceImportList); err != nil {
-					return false
-				}
-
-				if len(endpointSliceImportList.Items) != 0 {
-					return false
-				}
-
-				if err := hubClient.Get(ctx, endpointSliceExportKey, endpointSliceExport); !errors.IsNotFound(err) {
-					return false
-				}
-				return true
-			}, eventuallyTimeout, eventuallyInterval).Should(BeTrue())
-
-			Expect(hubClient.Delete(ctx, svcImport)).Should(Succeed())
-			// Confirm that ServiceImport is deleted; this helps make the test less flaky.
-			Eventually(func() error {
-				return client.IgnoreNotFound(hubClient.Get(ctx, svcImportKey, svcImport))
-			}, eventuallyTimeout, eventuallyInterval).Should(BeNil())
-		})
-
-		It("should not distribute endpointslice to member clusters", func() {
-			// Check if no EndpointSlice has been distributed.
-			Consistently(func() bool {
-				endpointSliceImportList := &fleetnetv1alpha1.EndpointSliceImportList{}
-				if err := hubClient.List(ctx, endpointSliceImportList); err != nil {
-					return false
-				}
-
-				if len(endpointSliceImportList.Items) != 0 {
-					return false
-				}
-				return true
-			}, consistentlyDuration, consistentlyInterval).Should(BeTrue())
-
-			// Check if the cleanup finalizer has been added.
-			Eventually(func() bool {
-				if err := hubClient.Get(ctx, endpointSliceExportKey, endpointSliceExport); err != nil {
-					return false
-				}
-
-				return cmp.Equal(endpointSliceExport.Finalizers, []string{endpointSliceExportCleanupFinalizer})
-			})
-		})
-	})
-
-	Context("no service in use by annotation", func() {
-		var endpointSliceExport *fleetnetv1alpha1.EndpointSliceExport
-		var svcImport *fleetnetv1alpha1.ServiceImport
-
-		BeforeEach(func() {
-			svcImport = unfulfilledAndRequestedServiceImport()
-			svcImport.Annotations = map[string]string{}
 			Expect(hubClient.Create(ctx, svcImport)).Should(Succeed())
 			fulfillSvcImport(svcImport)
 			Expect(hubClient.Status().Update(ctx, svcImport)).Should(Succeed())
@@ -497,6 +433,7 @@ var _ = Describe("endpointsliceexport controller", func() {
 			svcImport = unfulfilledAndRequestedServiceImport()
 			Expect(hubClient.Create(ctx, svcImport)).Should(Succeed())
 			fulfillSvcImport(svcImport)
+			requestSvcImport(svcImport)
 			Expect(hubClient.Status().Update(ctx, svcImport)).Should(Succeed())
 
 			endpointSliceExport = ipv4EndpointSliceExport()
@@ -591,6 +528,7 @@ var _ = Describe("endpointsliceexport controller", func() {
 			svcImport = unfulfilledAndRequestedServiceImport()
 			Expect(hubClient.Create(ctx, svcImport)).Should(Succeed())
 			fulfillSvcImport(svcImport)
+			requestSvcImport(svcImport)
 			Expect(hubClient.Status().Update(ctx, svcImport)).Should(Succeed())
 
 			endpointSliceExport = ipv4EndpointSliceExport()
@@ -712,7 +650,7 @@ var _ = Describe("endpointsliceexport controller", func() {
 		})
 	})
 
-	Context("service in use by info changed", func() {
+	Context("importing clusters changed", func() {
 		var endpointSliceExport *fleetnetv1alpha1.EndpointSliceExport
 		var svcImport *fleetnetv1alpha1.ServiceImport
 
@@ -720,6 +658,7 @@ var _ = Describe("endpointsliceexport controller", func() {
 			svcImport = unfulfilledAndRequestedServiceImport()
 			Expect(hubClient.Create(ctx, svcImport)).Should(Succeed())
 			fulfillSvcImport(svcImport)
+			requestSvcImport(svcImport)
 			Expect(hubClient.Status().Update(ctx, svcImport)).Should(Succeed())
 
 			endpointSliceExport = ipv4EndpointSliceExport()
@@ -800,20 +739,13 @@ var _ = Describe("endpointsliceexport controller", func() {
 				return true
 			}, eventuallyTimeout, eventuallyInterval).Should(BeTrue())
 
-			// Update ServiceInUseBy data.
-			updatedSvcInUseBy := fleetnetv1alpha1.ServiceInUseBy{
-				MemberClusters: map[fleetnetv1alpha1.ClusterNamespace]fleetnetv1alpha1.ClusterID{
-					hubNSForMemberA: clusterIDForMemberA,
-					hubNSForMemberC: clusterIDForMemberC,
-				},
-			}
-			updatedSvcInUseByData, err := json.Marshal(updatedSvcInUseBy)
-			Expect(updatedSvcInUseByData).ToNot(BeNil())
-			Expect(err).To(BeNil())
-
+			// Update the importing clusters.
 			Expect(hubClient.Get(ctx, svcImportKey, svcImport)).Should(Succeed())
-			svcImport.Annotations[objectmeta.ServiceImportAnnotationServiceInUseBy] = string(updatedSvcInUseByData)
-			Expect(hubClient.Update(ctx, svcImport)).Should(Succeed())
+			svcImport.Status.ImportedBy = importedBy(map[fleetnetv1alpha1.ClusterNamespace]fleetnetv1alpha1.ClusterID{
+				hubNSForMemberA: clusterIDForMemberA,
+				hubNSForMemberC: clusterIDForMemberC,
+			})
+			Expect(hubClient.Status().Update(ctx, svcImport)).Should(Succeed())
 
 			// Check if the EndpointSlice has been re-distributed.
 			Eventually(func() bool {
@@ -896,17 +828,10 @@ var _ = Describe("endpointsliceexport controller", func() {
 				return true
 			}, eventuallyTimeout, eventuallyInterval).Should(BeTrue())
 
-			// Update ServiceInUseBy data.
-			updatedSvcInUseBy := fleetnetv1alpha1.ServiceInUseBy{
-				MemberClusters: map[fleetnetv1alpha1.ClusterNamespace]fleetnetv1alpha1.ClusterID{},
-			}
-			updatedSvcInUseByData, err := json.Marshal(updatedSvcInUseBy)
-			Expect(updatedSvcInUseByData).ToNot(BeNil())
-			Expect(err).To(BeNil())
-
+			// Update the importing clusters.
 			Expect(hubClient.Get(ctx, svcImportKey, svcImport)).Should(Succeed())
-			svcImport.Annotations[objectmeta.ServiceImportAnnotationServiceInUseBy] = string(updatedSvcInUseByData)
-			Expect(hubClient.Update(ctx, svcImport)).Should(Succeed())
+			svcImport.Status.ImportedBy = nil
+			Expect(hubClient.Status().Update(ctx, svcImport)).Should(Succeed())
 
 			// Check if the EndpointSlice has been re-distributed.
 			Eventually(func() bool {
@@ -966,21 +891,14 @@ var _ = Describe("endpointsliceexport controller", func() {
 				return true
 			}, eventuallyTimeout, eventuallyInterval).Should(BeTrue())
 
-			// Update ServiceInUseBy data.
-			updatedSvcInUseBy := fleetnetv1alpha1.ServiceInUseBy{
-				MemberClusters: map[fleetnetv1alpha1.ClusterNamespace]fleetnetv1alpha1.ClusterID{
-					hubNSForMemberA: clusterIDForMemberA,
-					hubNSForMemberB: clusterIDForMemberB,
-					hubNSForMemberC: clusterIDForMemberC,
-				},
-			}
-			updatedSvcInUseByData, err := json.Marshal(updatedSvcInUseBy)
-			Expect(updatedSvcInUseByData).ToNot(BeNil())
-			Expect(err).To(BeNil())
-
+			// Update the importing clusters.
 			Expect(hubClient.Get(ctx, svcImportKey, svcImport)).Should(Succeed())
-			svcImport.Annotations[objectmeta.ServiceImportAnnotationServiceInUseBy] = string(updatedSvcInUseByData)
-			Expect(hubClient.Update(ctx, svcImport)).Should(Succeed())
+			svcImport.Status.ImportedBy = importedBy(map[fleetnetv1alpha1.ClusterNamespace]fleetnetv1alpha1.ClusterID{
+				hubNSForMemberA: clusterIDForMemberA,
+				hubNSForMemberB: clusterIDForMemberB,
+				hubNSForMemberC: clusterIDForMemberC,
+			})
+			Expect(hubClient.Status().Update(ctx, svcImport)).Should(Succeed())
 
 			// Check if the EndpointSlice has been re-distributed.
 			Eventually(func() bool {
@@ -17,10 +17,16 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	corev1 "k8s.io/api/core/v1"
 	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	clusterv1beta1 "go.goms.io/fleet/apis/cluster/v1beta1"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 )
@@ -167,6 +173,7 @@ func TestWithdrawAllEndpointSliceImports(t *testing.T) {
 			fakeHubClient := fakeHubClientBuilder.Build()
 			reconciler := Reconciler{
 				HubClient: fakeHubClient,
+				Recorder:  record.NewFakeRecorder(10),
 			}
 
 			if err := reconciler.withdrawAllEndpointSliceImports(ctx, tc.endpointSliceExport); err != nil {
@@ -216,6 +223,7 @@ func TestRemoveEndpointSliceExportCleanupFinalizer(t *testing.T) {
 				Build()
 			reconciler := Reconciler{
 				HubClient: fakeHubClient,
+				Recorder:  record.NewFakeRecorder(10),
 			}
 
 			if err := reconciler.removeEndpointSliceExportCleanupFinalizer(ctx, tc.endpointSliceExport); err != nil {
@@ -254,6 +262,7 @@ func TestAddEndpointSliceExportCleanupFinalizer(t *testing.T) {
 				Build()
 			reconciler := Reconciler{
 				HubClient: fakeHubClient,
+				Recorder:  record.NewFakeRecorder(10),
 			}
 
 			if err := reconciler.addEndpointSliceExportCleanupFinalizer(ctx, tc.endpointSliceExport); err != nil {
@@ -280,7 +289,7 @@ func TestScanForEndpointSliceImports(t *testing.T) {
 	testCases := []struct {
 		name                 string
 		endpointSliceExport  *fleetnetv1alpha1.EndpointSliceExport
-		svcInUseBy           *fleetnetv1alpha1.ServiceInUseBy
+		importingClusters    map[fleetnetv1alpha1.ClusterNamespace]fleetnetv1alpha1.ClusterID
 		endpointSliceImports []*fleetnetv1alpha1.EndpointSliceImport
 		wantToWithdraw       []*fleetnetv1alpha1.EndpointSliceImport
 		wantToCreateOrUpdate []*fleetnetv1alpha1.EndpointSliceImport
@@ -288,7 +297,7 @@ func TestScanForEndpointSliceImports(t *testing.T) {
 		{
 			name:                "should withdraw endpointsliceimports",
 			endpointSliceExport: endpointSliceExport,
-			svcInUseBy:          &fleetnetv1alpha1.ServiceInUseBy{},
+			importingClusters:   map[fleetnetv1alpha1.ClusterNamespace]fleetnetv1alpha1.ClusterID{},
 			endpointSliceImports: []*fleetnetv1alpha1.EndpointSliceImport{
 				{
 					ObjectMeta: metav1.ObjectMeta{
@@ -311,10 +320,8 @@ func TestScanForEndpointSliceImports(t *testing.T) {
 		{
 			name:                "should update endpointsliceimports",
 			endpointSliceExport: endpointSliceExport,
-			svcInUseBy: &fleetnetv1alpha1.ServiceInUseBy{
-				MemberClusters: map[fleetnetv1alpha1.ClusterNamespace]fleetnetv1alpha1.ClusterID{
-					hubNSForMemberB: clusterIDForMemberB,
-				},
+			importingClusters: map[fleetnetv1alpha1.ClusterNamespace]fleetnetv1alpha1.ClusterID{
+				hubNSForMemberB: clusterIDForMemberB,
 			},
 			endpointSliceImports: []*fleetnetv1alpha1.EndpointSliceImport{
 				{
@@ -338,10 +345,8 @@ func TestScanForEndpointSliceImports(t *testing.T) {
 		{
 			name:                "should create endpointsliceimports",
 			endpointSliceExport: endpointSliceExport,
-			svcInUseBy: &fleetnetv1alpha1.ServiceInUseBy{
-				MemberClusters: map[fleetnetv1alpha1.ClusterNamespace]fleetnetv1alpha1.ClusterID{
-					hubNSForMemberB: clusterIDForMemberB,
-				},
+			importingClusters: map[fleetnetv1alpha1.ClusterNamespace]fleetnetv1alpha1.ClusterID{
+				hubNSForMemberB: clusterIDForMemberB,
 			},
 			endpointSliceImports: []*fleetnetv1alpha1.EndpointSliceImport{},
 			wantToCreateOrUpdate: []*fleetnetv1alpha1.EndpointSliceImport{
@@ -356,11 +361,9 @@ func TestScanForEndpointSliceImports(t *testing.T) {
 		{
 			name:                "should delete, create and update endpointsliceimports",
 			endpointSliceExport: endpointSliceExport,
-			svcInUseBy: &fleetnetv1alpha1.ServiceInUseBy{
-				MemberClusters: map[fleetnetv1alpha1.ClusterNamespace]fleetnetv1alpha1.ClusterID{
-					hubNSForMemberB: clusterIDForMemberB,
-					hubNSForMemberC: clusterIDForMemberC,
-				},
+			importingClusters: map[fleetnetv1alpha1.ClusterNamespace]fleetnetv1alpha1.ClusterID{
+				hubNSForMemberB: clusterIDForMemberB,
+				hubNSForMemberC: clusterIDForMemberC,
 			},
 			endpointSliceImports: []*fleetnetv1alpha1.EndpointSliceImport{
 				{
@@ -420,11 +423,12 @@ func TestScanForEndpointSliceImports(t *testing.T) {
 			fakeHubClient := fakeHubClientBuilder.Build()
 			reconciler := Reconciler{
 				HubClient: fakeHubClient,
+				Recorder:  record.NewFakeRecorder(10),
 			}
 
-			toWithdraw, toCreateOrUpdate, err := reconciler.scanForEndpointSliceImports(ctx, tc.endpointSliceExport, tc.svcInUseBy)
+			toWithdraw, toCreateOrUpdate, err := reconciler.scanForEndpointSliceImports(ctx, tc.endpointSliceExport, tc.importingClusters)
 			if err != nil {
-				t.Fatalf("scanForEndpointSliceImports(%+v, %v), got %v, want no error", tc.endpointSliceExport, tc.svcInUseBy, err)
+				t.Fatalf("scanForEndpointSliceImports(%+v, %v), got %v, want no error", tc.endpointSliceExport, tc.importingClusters, err)
 			}
 
 			if diff := cmp.Diff(toWithdraw, tc.wantToWithdraw, ignoredObjectMetaFields); diff != "" {
@@ -437,3 +441,311 @@ func TestScanForEndpointSliceImports(t *testing.T) {
 		})
 	}
 }
+
+// TestExcludeUnselectingClusters tests the Reconciler.excludeUnselectingClusters method.
+func TestExcludeUnselectingClusters(t *testing.T) {
+	internalSvcImportForTest := func(namespace string, selector *fleetnetv1alpha1.ClusterSelector) *fleetnetv1alpha1.InternalServiceImport {
+		return &fleetnetv1alpha1.InternalServiceImport{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      fmt.Sprintf("%s-%s", memberUserNS, svcName),
+			},
+			Spec: fleetnetv1alpha1.InternalServiceImportSpec{
+				ServiceImportReference: fleetnetv1alpha1.ExportedObjectReference{
+					Namespace: memberUserNS,
+					Name:      svcName,
+				},
+				ClusterSelector: selector,
+			},
+		}
+	}
+	fakeHubClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(
+			// Member cluster B selects the exporting member cluster A by name.
+			internalSvcImportForTest(hubNSForMemberB, &fleetnetv1alpha1.ClusterSelector{ClusterNames: []string{hubNSForMemberA}}),
+			// Member cluster C only selects member cluster B.
+			internalSvcImportForTest(hubNSForMemberC, &fleetnetv1alpha1.ClusterSelector{ClusterNames: []string{hubNSForMemberB}}),
+		).
+		Build()
+	reconciler := Reconciler{
+		HubClient: fakeHubClient,
+		Recorder:  record.NewFakeRecorder(10),
+	}
+
+	importingClusters := map[fleetnetv1alpha1.ClusterNamespace]fleetnetv1alpha1.ClusterID{
+		hubNSForMemberB: clusterIDForMemberB,
+		hubNSForMemberC: clusterIDForMemberC,
+	}
+	if err := reconciler.excludeUnselectingClusters(context.Background(), ipv4EndpointSliceExport(), importingClusters); err != nil {
+		t.Fatalf("excludeUnselectingClusters() = %v, want no error", err)
+	}
+
+	want := map[fleetnetv1alpha1.ClusterNamespace]fleetnetv1alpha1.ClusterID{
+		hubNSForMemberB: clusterIDForMemberB,
+	}
+	if diff := cmp.Diff(want, importingClusters); diff != "" {
+		t.Errorf("importingClusters (-want, +got):\n%s", diff)
+	}
+}
+
+// TestIsExportStale tests the Reconciler.isExportStale method.
+func TestIsExportStale(t *testing.T) {
+	internalSvcExportForTest := func(svcName string, conds ...metav1.Condition) *fleetnetv1alpha1.InternalServiceExport {
+		return &fleetnetv1alpha1.InternalServiceExport{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: hubNSForMemberA,
+				Name:      fmt.Sprintf("%s-%s", memberUserNS, svcName),
+			},
+			Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+				ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+					ClusterID:      clusterIDForMemberA,
+					Namespace:      memberUserNS,
+					Name:           svcName,
+					NamespacedName: fmt.Sprintf("%s/%s", memberUserNS, svcName),
+				},
+			},
+			Status: fleetnetv1alpha1.InternalServiceExportStatus{
+				Conditions: conds,
+			},
+		}
+	}
+	staleCond := metav1.Condition{
+		Type:   string(fleetnetv1alpha1.ServiceExportStale),
+		Status: metav1.ConditionTrue,
+		Reason: "HeartbeatLost",
+	}
+	freshCond := metav1.Condition{
+		Type:   string(fleetnetv1alpha1.ServiceExportStale),
+		Status: metav1.ConditionFalse,
+		Reason: "HeartbeatReceived",
+	}
+
+	testCases := []struct {
+		name               string
+		internalSvcExports []*fleetnetv1alpha1.InternalServiceExport
+		want               bool
+	}{
+		{
+			name: "no internalServiceExport",
+			want: false,
+		},
+		{
+			name:               "internalServiceExport without stale condition",
+			internalSvcExports: []*fleetnetv1alpha1.InternalServiceExport{internalSvcExportForTest(svcName)},
+			want:               false,
+		},
+		{
+			name:               "fresh internalServiceExport",
+			internalSvcExports: []*fleetnetv1alpha1.InternalServiceExport{internalSvcExportForTest(svcName, freshCond)},
+			want:               false,
+		},
+		{
+			name:               "stale internalServiceExport",
+			internalSvcExports: []*fleetnetv1alpha1.InternalServiceExport{internalSvcExportForTest(svcName, staleCond)},
+			want:               true,
+		},
+		{
+			name:               "stale internalServiceExport of another service",
+			internalSvcExports: []*fleetnetv1alpha1.InternalServiceExport{internalSvcExportForTest("other-app", staleCond)},
+			want:               false,
+		},
+		{
+			name: "stale internalServiceExport of another service with the same name",
+			internalSvcExports: []*fleetnetv1alpha1.InternalServiceExport{
+				func() *fleetnetv1alpha1.InternalServiceExport {
+					// The Service app in the namespace work shares the name work-app with the Service work-app.
+					internalSvcExport := internalSvcExportForTest(svcName, staleCond)
+					internalSvcExport.Spec.ServiceReference.Namespace = internalSvcExport.Name
+					internalSvcExport.Spec.ServiceReference.Name = svcName
+					internalSvcExport.Spec.ServiceReference.NamespacedName = fmt.Sprintf("%s/%s", internalSvcExport.Name, svcName)
+					return internalSvcExport
+				}(),
+			},
+			want: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeHubClientBuilder := fake.NewClientBuilder().WithScheme(scheme.Scheme)
+			for _, internalSvcExport := range tc.internalSvcExports {
+				fakeHubClientBuilder = fakeHubClientBuilder.WithObjects(internalSvcExport)
+			}
+			reconciler := Reconciler{
+				HubClient: fakeHubClientBuilder.Build(),
+			}
+
+			got, err := reconciler.isExportStale(context.Background(), ipv4EndpointSliceExport())
+			if err != nil {
+				t.Fatalf("isExportStale() = %v, want no error", err)
+			}
+			if got != tc.want {
+				t.Errorf("isExportStale() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestDistributedCondition tests the distributedCondition function.
+func TestDistributedCondition(t *testing.T) {
+	endpointSliceExport := ipv4EndpointSliceExport()
+	endpointSliceExport.Generation = 2
+
+	testCases := []struct {
+		name          string
+		distributions []fleetnetv1alpha1.EndpointSliceDistribution
+		wantCond      metav1.Condition
+	}{
+		{
+			name: "all distributed",
+			distributions: []fleetnetv1alpha1.EndpointSliceDistribution{
+				{ClusterNamespace: hubNSForMemberB, Cluster: clusterIDForMemberB, Distributed: true},
+				{ClusterNamespace: hubNSForMemberC, Cluster: clusterIDForMemberC, Distributed: true},
+			},
+			wantCond: metav1.Condition{
+				Type:               string(fleetnetv1alpha1.EndpointSliceExportDistributed),
+				Status:             metav1.ConditionTrue,
+				Reason:             distributedReasonDistributed,
+				ObservedGeneration: 2,
+				Message:            "the endpointSlice has been distributed to 2 member clusters",
+			},
+		},
+		{
+			name: "partially distributed",
+			distributions: []fleetnetv1alpha1.EndpointSliceDistribution{
+				{ClusterNamespace: hubNSForMemberB, Cluster: clusterIDForMemberB, Distributed: true},
+				{ClusterNamespace: hubNSForMemberC, Cluster: clusterIDForMemberC, Message: "timeout"},
+			},
+			wantCond: metav1.Condition{
+				Type:               string(fleetnetv1alpha1.EndpointSliceExportDistributed),
+				Status:             metav1.ConditionFalse,
+				Reason:             distributedReasonDistributionFailed,
+				ObservedGeneration: 2,
+				Message:            "failed to distribute the endpointSlice to 1 out of 2 member clusters: 2",
+			},
+		},
+		{
+			name: "no importing clusters",
+			wantCond: metav1.Condition{
+				Type:               string(fleetnetv1alpha1.EndpointSliceExportDistributed),
+				Status:             metav1.ConditionFalse,
+				Reason:             distributedReasonNoImportingClusters,
+				ObservedGeneration: 2,
+				Message:            "no importing member cluster selects the exporting member cluster",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cond := distributedCondition(endpointSliceExport, tc.distributions)
+			if diff := cmp.Diff(cond, tc.wantCond); diff != "" {
+				t.Fatalf("distributedCondition() mismatch (-got, +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestUpdateEndpointSliceExportStatus tests the Reconciler.updateEndpointSliceExportStatus method.
+func TestUpdateEndpointSliceExportStatus(t *testing.T) {
+	distributions := []fleetnetv1alpha1.EndpointSliceDistribution{
+		{ClusterNamespace: hubNSForMemberB, Cluster: clusterIDForMemberB, Distributed: true},
+	}
+	distributedCond := distributedCondition(ipv4EndpointSliceExport(), distributions)
+
+	ctx := context.Background()
+	endpointSliceExport := ipv4EndpointSliceExport()
+	fakeHubClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(endpointSliceExport).
+		WithStatusSubresource(endpointSliceExport).
+		Build()
+	reconciler := Reconciler{
+		HubClient: fakeHubClient,
+		Recorder:  record.NewFakeRecorder(10),
+	}
+
+	if err := reconciler.updateEndpointSliceExportStatus(ctx, endpointSliceExport, distributedCond, distributions); err != nil {
+		t.Fatalf("updateEndpointSliceExportStatus(), got %v, want no error", err)
+	}
+	updatedEndpointSliceExport := &fleetnetv1alpha1.EndpointSliceExport{}
+	if err := fakeHubClient.Get(ctx, endpointSliceExportKey, updatedEndpointSliceExport); err != nil {
+		t.Fatalf("endpointSliceExport Get(%+v), got %v, want no error", endpointSliceExportKey, err)
+	}
+	gotCond := meta.FindStatusCondition(updatedEndpointSliceExport.Status.Conditions, string(fleetnetv1alpha1.EndpointSliceExportDistributed))
+	if diff := cmp.Diff(gotCond, &distributedCond, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime")); diff != "" {
+		t.Fatalf("distributed condition mismatch (-got, +want):\n%s", diff)
+	}
+	if diff := cmp.Diff(updatedEndpointSliceExport.Status.Distributions, distributions); diff != "" {
+		t.Fatalf("distributions mismatch (-got, +want):\n%s", diff)
+	}
+
+	// Updating the status with the same condition and distributions should be a no-op.
+	resourceVersion := updatedEndpointSliceExport.ResourceVersion
+	if err := reconciler.updateEndpointSliceExportStatus(ctx, updatedEndpointSliceExport, distributedCond, distributions); err != nil {
+		t.Fatalf("updateEndpointSliceExportStatus(), got %v, want no error", err)
+	}
+	if updatedEndpointSliceExport.ResourceVersion != resourceVersion {
+		t.Fatalf("endpointSliceExport resourceVersion, got %s, want %s (no update)", updatedEndpointSliceExport.ResourceVersion, resourceVersion)
+	}
+}
+
+// TestMemberClusterLabelChangedPredicate tests that only the MemberCluster events which may change the selected
+// clusters are handled.
+func TestMemberClusterLabelChangedPredicate(t *testing.T) {
+	memberCluster := &clusterv1beta1.MemberCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "member-1",
+			Labels: map[string]string{"region": "eastus"},
+		},
+	}
+	statusUpdated := memberCluster.DeepCopy()
+	statusUpdated.Status.Conditions = []metav1.Condition{
+		{
+			Type:   string(clusterv1beta1.ConditionTypeMemberClusterJoined),
+			Status: metav1.ConditionTrue,
+			Reason: "Joined",
+		},
+	}
+	labelsUpdated := memberCluster.DeepCopy()
+	labelsUpdated.Labels["region"] = "westus"
+
+	if memberClusterLabelChangedPredicate.Update(event.UpdateEvent{ObjectOld: memberCluster, ObjectNew: statusUpdated}) {
+		t.Error("Update() = true for a status-only update, want false")
+	}
+	if !memberClusterLabelChangedPredicate.Update(event.UpdateEvent{ObjectOld: memberCluster, ObjectNew: labelsUpdated}) {
+		t.Error("Update() = false for a label update, want true")
+	}
+	if !memberClusterLabelChangedPredicate.Create(event.CreateEvent{Object: memberCluster}) {
+		t.Error("Create() = false, want true")
+	}
+	if !memberClusterLabelChangedPredicate.Delete(event.DeleteEvent{Object: memberCluster}) {
+		t.Error("Delete() = false, want true")
+	}
+}
+
+// TestEndpointSliceExportsForMemberCluster tests that only the EndpointSliceExports exported from a member cluster
+// are enqueued when its MemberCluster changes.
+func TestEndpointSliceExportsForMemberCluster(t *testing.T) {
+	exportFor := func(namespace, clusterID string) *fleetnetv1alpha1.EndpointSliceExport {
+		return &fleetnetv1alpha1.EndpointSliceExport{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: endpointSliceExportName},
+			Spec: fleetnetv1alpha1.EndpointSliceExportSpec{
+				EndpointSliceReference: fleetnetv1alpha1.ExportedObjectReference{ClusterID: clusterID},
+			},
+		}
+	}
+	fakeHubClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(exportFor("fleet-member-member-1", "member-1"), exportFor("fleet-member-member-2", "member-2")).
+		Build()
+	r := &Reconciler{HubClient: fakeHubClient}
+
+	memberCluster := &clusterv1beta1.MemberCluster{ObjectMeta: metav1.ObjectMeta{Name: "member-1"}}
+	got := r.endpointSliceExportsForMemberCluster(context.Background(), memberCluster)
+	want := []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "fleet-member-member-1", Name: endpointSliceExportName}}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("endpointSliceExportsForMemberCluster() mismatch (-want, +got):\n%s", diff)
+	}
+}
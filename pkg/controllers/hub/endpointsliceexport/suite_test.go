@@ -106,6 +106,7 @@ var _ = BeforeSuite(func() {
 
 	err = (&Reconciler{
 		HubClient: hubClient,
+		Recorder:  hubCtrlMgr.GetEventRecorderFor(ControllerName),
 	}).SetupWithManager(ctx, hubCtrlMgr)
 	Expect(err).NotTo(HaveOccurred())
 
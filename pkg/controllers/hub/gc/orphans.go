@@ -0,0 +1,116 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package gc features the orphan finder of the garbage collection sweeper, which periodically removes orphaned
+// fleet networking objects from the hub cluster.
+package gc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/gc"
+)
+
+const (
+	kindEndpointSliceExport = "EndpointSliceExport"
+	kindEndpointSliceImport = "EndpointSliceImport"
+)
+
+// OrphanFinder finds the orphaned fleet networking objects in the hub cluster:
+//   - EndpointSliceExports whose Service is no longer exported (no matching InternalServiceExport); and
+//   - EndpointSliceImports whose EndpointSliceExport no longer exists.
+//
+// Deleting an orphaned object triggers the usual cleanup, e.g. withdrawing the EndpointSliceImports distributed
+// from an EndpointSliceExport.
+type OrphanFinder struct {
+	HubClient client.Client
+}
+
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=endpointsliceexports,verbs=get;list;watch;delete
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=endpointsliceimports,verbs=get;list;watch;delete
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalserviceexports,verbs=get;list;watch
+
+// FindOrphans implements the gc.OrphanFinder interface.
+func (f *OrphanFinder) FindOrphans(ctx context.Context, now time.Time) ([]gc.Orphans, error) {
+	internalSvcExportList := &fleetnetv1alpha1.InternalServiceExportList{}
+	if err := f.HubClient.List(ctx, internalSvcExportList); err != nil {
+		return nil, fmt.Errorf("failed to list internalServiceExports: %w", err)
+	}
+	endpointSliceExportList := &fleetnetv1alpha1.EndpointSliceExportList{}
+	if err := f.HubClient.List(ctx, endpointSliceExportList); err != nil {
+		return nil, fmt.Errorf("failed to list endpointSliceExports: %w", err)
+	}
+	endpointSliceImportList := &fleetnetv1alpha1.EndpointSliceImportList{}
+	if err := f.HubClient.List(ctx, endpointSliceImportList); err != nil {
+		return nil, fmt.Errorf("failed to list endpointSliceImports: %w", err)
+	}
+
+	return []gc.Orphans{
+		{
+			Kind:    kindEndpointSliceExport,
+			Objects: gc.ToObjects(findOrphanedEndpointSliceExports(internalSvcExportList.Items, endpointSliceExportList.Items, now)),
+		},
+		{
+			Kind:    kindEndpointSliceImport,
+			Objects: gc.ToObjects(findOrphanedEndpointSliceImports(endpointSliceExportList.Items, endpointSliceImportList.Items, now)),
+		},
+	}, nil
+}
+
+// findOrphanedEndpointSliceExports returns the EndpointSliceExports whose owner Service is no longer exported from
+// the member cluster, i.e. there is no InternalServiceExport of the Service in the same namespace.
+func findOrphanedEndpointSliceExports(internalSvcExports []fleetnetv1alpha1.InternalServiceExport,
+	endpointSliceExports []fleetnetv1alpha1.EndpointSliceExport, now time.Time) []*fleetnetv1alpha1.EndpointSliceExport {
+	exportedSvcs := sets.New[types.NamespacedName]()
+	for i := range internalSvcExports {
+		internalSvcExport := &internalSvcExports[i]
+		exportedSvcs.Insert(types.NamespacedName{
+			Namespace: internalSvcExport.Namespace,
+			Name:      internalSvcExport.Spec.ServiceReference.NamespacedName,
+		})
+	}
+
+	var orphans []*fleetnetv1alpha1.EndpointSliceExport
+	for i := range endpointSliceExports {
+		endpointSliceExport := &endpointSliceExports[i]
+		if !gc.IsOrphanCandidate(endpointSliceExport, now) {
+			continue
+		}
+		ownerSvc := types.NamespacedName{
+			Namespace: endpointSliceExport.Namespace,
+			Name:      endpointSliceExport.Spec.OwnerServiceReference.NamespacedName,
+		}
+		if !exportedSvcs.Has(ownerSvc) {
+			orphans = append(orphans, endpointSliceExport)
+		}
+	}
+	return orphans
+}
+
+// findOrphanedEndpointSliceImports returns the EndpointSliceImports whose EndpointSliceExport no longer exists;
+// an EndpointSliceImport has the same fleet-scoped unique name as the EndpointSliceExport it is distributed from.
+func findOrphanedEndpointSliceImports(endpointSliceExports []fleetnetv1alpha1.EndpointSliceExport,
+	endpointSliceImports []fleetnetv1alpha1.EndpointSliceImport, now time.Time) []*fleetnetv1alpha1.EndpointSliceImport {
+	exportedEndpointSlices := sets.New[string]()
+	for i := range endpointSliceExports {
+		exportedEndpointSlices.Insert(endpointSliceExports[i].Name)
+	}
+
+	var orphans []*fleetnetv1alpha1.EndpointSliceImport
+	for i := range endpointSliceImports {
+		endpointSliceImport := &endpointSliceImports[i]
+		if gc.IsOrphanCandidate(endpointSliceImport, now) && !exportedEndpointSlices.Has(endpointSliceImport.Name) {
+			orphans = append(orphans, endpointSliceImport)
+		}
+	}
+	return orphans
+}
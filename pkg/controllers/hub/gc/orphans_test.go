@@ -0,0 +1,170 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package gc
+
+import (
+	"context"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+const (
+	hubNSForMemberA = "bravelion"
+	hubNSForMemberB = "highflyingcat"
+	memberUserNS    = "work"
+	svcName         = "app"
+	altSvcName      = "app2"
+)
+
+var (
+	now       = time.Date(2024, 1, 1, 0, 10, 0, 0, time.UTC)
+	oldTime   = metav1.NewTime(now.Add(-time.Hour))
+	youngTime = metav1.NewTime(now.Add(-time.Second))
+)
+
+func TestMain(m *testing.M) {
+	// Add custom APIs to the runtime scheme.
+	if err := fleetnetv1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		log.Fatalf("failed to add custom APIs to the runtime scheme: %v", err)
+	}
+
+	os.Exit(m.Run())
+}
+
+func internalServiceExport(namespace, svcName string) fleetnetv1alpha1.InternalServiceExport {
+	return fleetnetv1alpha1.InternalServiceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         namespace,
+			Name:              memberUserNS + "-" + svcName,
+			CreationTimestamp: oldTime,
+		},
+		Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+			ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+				NamespacedName: types.NamespacedName{Namespace: memberUserNS, Name: svcName}.String(),
+			},
+		},
+	}
+}
+
+func endpointSliceExport(namespace, name, svcName string, creationTimestamp metav1.Time) fleetnetv1alpha1.EndpointSliceExport {
+	return fleetnetv1alpha1.EndpointSliceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         namespace,
+			Name:              name,
+			CreationTimestamp: creationTimestamp,
+		},
+		Spec: fleetnetv1alpha1.EndpointSliceExportSpec{
+			OwnerServiceReference: fleetnetv1alpha1.OwnerServiceReference{
+				Namespace:      memberUserNS,
+				Name:           svcName,
+				NamespacedName: types.NamespacedName{Namespace: memberUserNS, Name: svcName}.String(),
+			},
+		},
+	}
+}
+
+func endpointSliceImport(namespace, name string, creationTimestamp metav1.Time) fleetnetv1alpha1.EndpointSliceImport {
+	return fleetnetv1alpha1.EndpointSliceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         namespace,
+			Name:              name,
+			CreationTimestamp: creationTimestamp,
+		},
+	}
+}
+
+func objectKeys[T client.Object](objs []T) []string {
+	keys := make([]string, 0, len(objs))
+	for _, obj := range objs {
+		keys = append(keys, types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}.String())
+	}
+	return keys
+}
+
+// TestFindOrphanedEndpointSliceExports tests the findOrphanedEndpointSliceExports function.
+func TestFindOrphanedEndpointSliceExports(t *testing.T) {
+	deletedExport := endpointSliceExport(hubNSForMemberA, "deleted", altSvcName, oldTime)
+	deletedExport.DeletionTimestamp = &oldTime
+
+	internalSvcExports := []fleetnetv1alpha1.InternalServiceExport{
+		internalServiceExport(hubNSForMemberA, svcName),
+		internalServiceExport(hubNSForMemberB, altSvcName),
+	}
+	endpointSliceExports := []fleetnetv1alpha1.EndpointSliceExport{
+		endpointSliceExport(hubNSForMemberA, "exported", svcName, oldTime),
+		endpointSliceExport(hubNSForMemberA, "orphaned", altSvcName, oldTime),
+		endpointSliceExport(hubNSForMemberA, "young", altSvcName, youngTime),
+		endpointSliceExport(hubNSForMemberB, "exported-2", altSvcName, oldTime),
+		endpointSliceExport(hubNSForMemberB, "orphaned-2", svcName, oldTime),
+		deletedExport,
+	}
+
+	got := objectKeys(findOrphanedEndpointSliceExports(internalSvcExports, endpointSliceExports, now))
+	want := []string{hubNSForMemberA + "/orphaned", hubNSForMemberB + "/orphaned-2"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("findOrphanedEndpointSliceExports() mismatch (-got, +want):\n%s", diff)
+	}
+}
+
+// TestFindOrphanedEndpointSliceImports tests the findOrphanedEndpointSliceImports function.
+func TestFindOrphanedEndpointSliceImports(t *testing.T) {
+	endpointSliceExports := []fleetnetv1alpha1.EndpointSliceExport{
+		endpointSliceExport(hubNSForMemberA, "exported", svcName, oldTime),
+	}
+	endpointSliceImports := []fleetnetv1alpha1.EndpointSliceImport{
+		endpointSliceImport(hubNSForMemberB, "exported", oldTime),
+		endpointSliceImport(hubNSForMemberB, "orphaned", oldTime),
+		endpointSliceImport(hubNSForMemberB, "young", youngTime),
+	}
+
+	got := objectKeys(findOrphanedEndpointSliceImports(endpointSliceExports, endpointSliceImports, now))
+	want := []string{hubNSForMemberB + "/orphaned"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("findOrphanedEndpointSliceImports() mismatch (-got, +want):\n%s", diff)
+	}
+}
+
+// TestFindOrphans tests the OrphanFinder.FindOrphans method.
+func TestFindOrphans(t *testing.T) {
+	internalSvcExport := internalServiceExport(hubNSForMemberA, svcName)
+	exported := endpointSliceExport(hubNSForMemberA, "exported", svcName, oldTime)
+	orphanedExport := endpointSliceExport(hubNSForMemberA, "orphaned", altSvcName, oldTime)
+	importedExport := endpointSliceImport(hubNSForMemberB, "exported", oldTime)
+	orphanedImport := endpointSliceImport(hubNSForMemberB, "orphaned-2", oldTime)
+
+	fakeHubClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(&internalSvcExport, &exported, &orphanedExport, &importedExport, &orphanedImport).
+		Build()
+	finder := &OrphanFinder{HubClient: fakeHubClient}
+
+	orphans, err := finder.FindOrphans(context.Background(), now)
+	if err != nil {
+		t.Fatalf("FindOrphans() = %v, want no error", err)
+	}
+	got := map[string][]string{}
+	for _, o := range orphans {
+		got[o.Kind] = objectKeys(o.Objects)
+	}
+	want := map[string][]string{
+		kindEndpointSliceExport: {hubNSForMemberA + "/orphaned"},
+		kindEndpointSliceImport: {hubNSForMemberB + "/orphaned-2"},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("FindOrphans() mismatch (-got, +want):\n%s", diff)
+	}
+}
@@ -20,10 +20,22 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 	"go.goms.io/fleet-networking/pkg/common/condition"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
 	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/servicepolicy"
+	"go.goms.io/fleet-networking/pkg/common/servicespec"
+	"go.goms.io/fleet-networking/pkg/common/tracing"
+	"go.goms.io/fleet-networking/pkg/controllers/hub/serviceimport"
+)
+
+const (
+	// exportedServiceFieldNamespace is the index of the namespace of the exported services.
+	exportedServiceFieldNamespace = ".spec.serviceReference.namespace"
 )
 
 // Reconciler reconciles a InternalServiceExport object.
@@ -39,6 +51,7 @@ type Reconciler struct {
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalserviceexports/finalizers,verbs=update
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports,verbs=get;list;watch;create;update;patch
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceexportimportpolicies,verbs=get;list;watch
 
 // Reconcile creates/updates ServiceImport by watching internalServiceExport objects.
 // To simplify the design and implementation in the first phase, the serviceExport will be marked as conflicted if its
@@ -66,6 +79,11 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return ctrl.Result{}, err
 	}
 
+	// Continue the trace of the export started in the member cluster.
+	ctx, span := tracing.StartSpanFromObject(ctx, &internalServiceExport, "ReconcileInternalServiceExport",
+		tracing.AttributeKeyClusterID.String(internalServiceExport.Spec.ServiceReference.ClusterID))
+	defer span.End()
+
 	if internalServiceExport.ObjectMeta.DeletionTimestamp != nil {
 		return r.handleDelete(ctx, &internalServiceExport)
 	}
@@ -112,27 +130,13 @@ func (r *Reconciler) handleDelete(ctx context.Context, internalServiceExport *fl
 	}
 
 	oldStatus := serviceImport.Status.DeepCopy()
-	removeClusterFromServiceImportStatus(serviceImport, internalServiceExport.Spec.ServiceReference.ClusterID)
+	serviceimport.RemoveExportingCluster(serviceImport, internalServiceExport.Spec.ServiceReference.ClusterID)
 	if err := r.updateServiceImportStatus(ctx, serviceImport, oldStatus); err != nil {
 		return ctrl.Result{}, err
 	}
 	return r.removeFinalizer(ctx, internalServiceExport)
 }
 
-func removeClusterFromServiceImportStatus(serviceImport *fleetnetv1alpha1.ServiceImport, clusterID string) {
-	var updatedClusters []fleetnetv1alpha1.ClusterStatus
-	for _, c := range serviceImport.Status.Clusters {
-		if c.Cluster != clusterID {
-			updatedClusters = append(updatedClusters, c)
-		}
-	}
-	if len(updatedClusters) == 0 {
-		serviceImport.Status = fleetnetv1alpha1.ServiceImportStatus{}
-	} else {
-		serviceImport.Status.Clusters = updatedClusters
-	}
-}
-
 func addClusterToServiceImportStatus(serviceImport *fleetnetv1alpha1.ServiceImport, clusterID string) {
 	for _, c := range serviceImport.Status.Clusters {
 		if c.Cluster == clusterID {
@@ -149,7 +153,10 @@ func (r *Reconciler) updateServiceImportStatus(ctx context.Context, serviceImpor
 	serviceImportKObj := klog.KObj(serviceImport)
 	klog.V(2).InfoS("Updating the serviceImport status", "serviceImport", serviceImportKObj, "oldStatus", oldStatus, "status", serviceImport.Status)
 
-	if err := r.Client.Status().Update(ctx, serviceImport); err != nil {
+	ctx, span := tracing.StartSpan(ctx, "UpdateServiceImportStatus")
+	err := r.Client.Status().Update(ctx, serviceImport)
+	tracing.EndSpan(span, err)
+	if err != nil {
 		klog.ErrorS(err, "Failed to update the serviceImport status", "serviceImport", serviceImportKObj, "oldStatus", oldStatus, "status", serviceImport.Status)
 		return err
 	}
@@ -180,13 +187,65 @@ func (r *Reconciler) updateInternalServiceExportStatus(ctx context.Context, inte
 	meta.SetStatusCondition(&internalServiceExport.Status.Conditions, desiredCond)
 
 	klog.V(2).InfoS("Updating internalServiceExport status", "internalServiceExport", exportKObj, "status", internalServiceExport.Status, "oldStatus", oldStatus)
-	if err := r.Status().Update(ctx, internalServiceExport); err != nil {
+	ctx, span := tracing.StartSpan(ctx, "UpdateInternalServiceExportStatus")
+	err := r.Status().Update(ctx, internalServiceExport)
+	tracing.EndSpan(span, err)
+	if err != nil {
 		klog.ErrorS(err, "Failed to update internalServiceExport status", "internalServiceExport", exportKObj, "status", internalServiceExport.Status, "oldStatus", oldStatus)
 		return err
 	}
 	return nil
 }
 
+// updateInternalServiceExportAuthorizedCondition sets the Authorized condition of the internalServiceExport.
+func (r *Reconciler) updateInternalServiceExportAuthorizedCondition(ctx context.Context, internalServiceExport *fleetnetv1alpha1.InternalServiceExport, desiredCond metav1.Condition) error {
+	currentCond := meta.FindStatusCondition(internalServiceExport.Status.Conditions, string(fleetnetv1alpha1.ServiceExportAuthorized))
+	if condition.EqualCondition(currentCond, &desiredCond) && currentCond.Message == desiredCond.Message {
+		return nil
+	}
+	exportKObj := klog.KObj(internalServiceExport)
+	oldStatus := internalServiceExport.Status.DeepCopy()
+	meta.SetStatusCondition(&internalServiceExport.Status.Conditions, desiredCond)
+
+	klog.V(2).InfoS("Updating internalServiceExport authorized condition", "internalServiceExport", exportKObj, "status", internalServiceExport.Status, "oldStatus", oldStatus)
+	if err := r.Status().Update(ctx, internalServiceExport); err != nil {
+		klog.ErrorS(err, "Failed to update internalServiceExport authorized condition", "internalServiceExport", exportKObj, "status", internalServiceExport.Status, "oldStatus", oldStatus)
+		return err
+	}
+	return nil
+}
+
+// handleUnauthorized withdraws the cluster from the serviceImport when it is not allowed to export the service.
+func (r *Reconciler) handleUnauthorized(ctx context.Context, internalServiceExport *fleetnetv1alpha1.InternalServiceExport, message string) (ctrl.Result, error) {
+	klog.V(2).InfoS("Member cluster is not allowed to export the service", "internalServiceExport", klog.KObj(internalServiceExport), "reason", message)
+	if err := r.withdrawFromServiceImport(ctx, internalServiceExport); err != nil {
+		return ctrl.Result{}, err
+	}
+	desiredCond := condition.UnauthorizedServiceExportCondition(*internalServiceExport, message)
+	return ctrl.Result{}, r.updateInternalServiceExportAuthorizedCondition(ctx, internalServiceExport, desiredCond)
+}
+
+// withdrawFromServiceImport removes the cluster from the serviceImport status, if the serviceImport exists.
+func (r *Reconciler) withdrawFromServiceImport(ctx context.Context, internalServiceExport *fleetnetv1alpha1.InternalServiceExport) error {
+	internalServiceExportKObj := klog.KObj(internalServiceExport)
+	serviceImport := &fleetnetv1alpha1.ServiceImport{}
+	serviceImportName := types.NamespacedName{Namespace: internalServiceExport.Spec.ServiceReference.Namespace, Name: internalServiceExport.Spec.ServiceReference.Name}
+	serviceImportKRef := klog.KRef(serviceImportName.Namespace, serviceImportName.Name)
+
+	err := r.Client.Get(ctx, serviceImportName, serviceImport)
+	switch {
+	case errors.IsNotFound(err):
+		// Nothing to withdraw.
+		return nil
+	case err != nil:
+		klog.ErrorS(err, "Failed to get serviceImport", "serviceImport", serviceImportKRef, "internalServiceExport", internalServiceExportKObj)
+		return err
+	}
+	oldStatus := serviceImport.Status.DeepCopy()
+	serviceimport.RemoveExportingCluster(serviceImport, internalServiceExport.Spec.ServiceReference.ClusterID)
+	return r.updateServiceImportStatus(ctx, serviceImport, oldStatus)
+}
+
 func (r *Reconciler) handleUpdate(ctx context.Context, internalServiceExport *fleetnetv1alpha1.InternalServiceExport) (ctrl.Result, error) {
 	internalServiceExportKObj := klog.KObj(internalServiceExport)
 	// get serviceImport
@@ -194,6 +253,26 @@ func (r *Reconciler) handleUpdate(ctx context.Context, internalServiceExport *fl
 	serviceImportName := types.NamespacedName{Namespace: internalServiceExport.Spec.ServiceReference.Namespace, Name: internalServiceExport.Spec.ServiceReference.Name}
 	serviceImportKRef := klog.KRef(serviceImportName.Namespace, serviceImportName.Name)
 
+	policyCtx, policySpan := tracing.StartSpan(ctx, "EvaluateServiceExportImportPolicies")
+	allowed, message, err := servicepolicy.IsExportAllowed(policyCtx, r.Client, serviceImportName.Namespace, serviceImportName.Name, internalServiceExport.Spec.ServiceReference.ClusterID)
+	tracing.EndSpan(policySpan, err)
+	if err != nil {
+		klog.ErrorS(err, "Failed to evaluate serviceExportImportPolicies", "serviceImport", serviceImportKRef, "internalServiceExport", internalServiceExportKObj)
+		return ctrl.Result{}, err
+	}
+	if !allowed {
+		return r.handleUnauthorized(ctx, internalServiceExport, message)
+	}
+	if err := r.updateInternalServiceExportAuthorizedCondition(ctx, internalServiceExport, condition.AuthorizedServiceExportCondition(*internalServiceExport)); err != nil {
+		return ctrl.Result{}, err
+	}
+	if meta.IsStatusConditionTrue(internalServiceExport.Status.Conditions, string(fleetnetv1alpha1.ServiceExportStale)) {
+		// The member cluster has stopped sending heartbeats; withdraw the cluster from the serviceImport until the
+		// member health controller marks the export as fresh again.
+		klog.V(2).InfoS("Member cluster has stopped sending heartbeats; withdraw the exported service", "serviceImport", serviceImportKRef, "internalServiceExport", internalServiceExportKObj)
+		return ctrl.Result{}, r.withdrawFromServiceImport(ctx, internalServiceExport)
+	}
+
 	if err := r.Client.Get(ctx, serviceImportName, serviceImport); err != nil {
 		if !errors.IsNotFound(err) {
 			klog.ErrorS(err, "Failed to get serviceImport", "serviceImport", serviceImportKRef, "internalServiceExport", internalServiceExportKObj)
@@ -221,10 +300,8 @@ func (r *Reconciler) handleUpdate(ctx context.Context, internalServiceExport *fl
 	oldStatus := serviceImport.Status.DeepCopy()
 	clusterID := internalServiceExport.Spec.ServiceReference.ClusterID
 
-	// To simplify the implementation, we compare the whole ports structure.
-	// TODO, change to compare the ports by ignoring the order and protocol and port are the map keys.
-	if !equality.Semantic.DeepEqual(serviceImport.Status.Ports, internalServiceExport.Spec.Ports) {
-		removeClusterFromServiceImportStatus(serviceImport, clusterID)
+	if !isResolvedSpecEqual(&serviceImport.Status, &internalServiceExport.Spec) {
+		serviceimport.RemoveExportingCluster(serviceImport, clusterID)
 		if err := r.updateServiceImportStatus(ctx, serviceImport, oldStatus); err != nil {
 			return ctrl.Result{}, err
 		}
@@ -247,8 +324,49 @@ func (r *Reconciler) handleUpdate(ctx context.Context, internalServiceExport *fl
 }
 
 // SetupWithManager sets up the controller with the Manager.
-func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
+	// add index to quickly find the internalServiceExports of the services in a namespace
+	extractFunc := func(o client.Object) []string {
+		return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.Namespace}
+	}
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldNamespace, extractFunc); err != nil {
+		klog.ErrorS(err, "Failed to create index", "field", exportedServiceFieldNamespace)
+		return err
+	}
+	// Re-evaluate the exports of the services in the namespace whenever a policy changes.
+	enqueueInternalServiceExports := handler.EnqueueRequestsFromMapFunc(r.internalServiceExportsForPolicy)
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&fleetnetv1alpha1.InternalServiceExport{}).
-		Complete(r)
+		Watches(&fleetnetv1alpha1.ServiceExportImportPolicy{}, enqueueInternalServiceExports).
+		Complete(metrics.InstrumentReconciler("internalserviceexport", r))
+}
+
+// internalServiceExportsForPolicy returns the requests of the internalServiceExports of the services in the namespace
+// of a serviceExportImportPolicy.
+func (r *Reconciler) internalServiceExportsForPolicy(ctx context.Context, o client.Object) []reconcile.Request {
+	internalServiceExportList := &fleetnetv1alpha1.InternalServiceExportList{}
+	if err := r.Client.List(ctx, internalServiceExportList, client.MatchingFields{exportedServiceFieldNamespace: o.GetNamespace()}); err != nil {
+		klog.ErrorS(err, "Failed to list internalServiceExports", "serviceExportImportPolicy", klog.KObj(o))
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(internalServiceExportList.Items))
+	for i := range internalServiceExportList.Items {
+		export := &internalServiceExportList.Items[i]
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: export.Namespace, Name: export.Name}})
+	}
+	return requests
+}
+
+// isResolvedSpecEqual returns true if the exported service has the same ports, session affinity settings and
+// exported labels and annotations as the ones resolved in the serviceImport.
+func isResolvedSpecEqual(status *fleetnetv1alpha1.ServiceImportStatus, spec *fleetnetv1alpha1.InternalServiceExportSpec) bool {
+	// To simplify the implementation, we compare the whole ports structure.
+	// TODO, change to compare the ports by ignoring the order and protocol and port are the map keys.
+	if !equality.Semantic.DeepEqual(status.Ports, spec.Ports) {
+		return false
+	}
+	return servicespec.NormalizeSessionAffinity(status.SessionAffinity) == servicespec.NormalizeSessionAffinity(spec.SessionAffinity) &&
+		equality.Semantic.DeepEqual(status.SessionAffinityConfig, spec.SessionAffinityConfig) &&
+		equality.Semantic.DeepEqual(status.ExportedLabels, spec.ExportedLabels) &&
+		equality.Semantic.DeepEqual(status.ExportedAnnotations, spec.ExportedAnnotations)
 }
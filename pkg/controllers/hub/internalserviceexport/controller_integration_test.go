@@ -22,6 +22,7 @@ import (
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/controllers/hub/serviceimport"
 )
 
 var _ = Describe("Test InternalServiceExport Controller", func() {
@@ -239,10 +240,21 @@ var _ = Describe("Test InternalServiceExport Controller", func() {
 
 			By("Checking serviceImport status")
 			Eventually(func() string {
-				want := fleetnetv1alpha1.ServiceImportStatus{}
 				if err := k8sClient.Get(ctx, serviceImportKey, &serviceImport); err != nil {
 					return err.Error()
 				}
+				// The resolved spec is reset and the serviceImport is reported as not ready.
+				want := fleetnetv1alpha1.ServiceImportStatus{
+					Conditions: []metav1.Condition{
+						{
+							Type:               string(fleetnetv1alpha1.ServiceImportReady),
+							Status:             metav1.ConditionFalse,
+							ObservedGeneration: serviceImport.Generation,
+							Reason:             serviceimport.ConditionReasonNoAcceptedExports,
+							Message:            "service spec is not resolved; 0 of 0 exporting cluster(s) are accepted",
+						},
+					},
+				}
 				return cmp.Diff(want, serviceImport.Status, options...)
 			}, timeout, interval).Should(BeEmpty())
 		})
@@ -442,10 +454,21 @@ var _ = Describe("Test InternalServiceExport Controller", func() {
 
 			By("Checking serviceImport status")
 			Eventually(func() string {
-				want := fleetnetv1alpha1.ServiceImportStatus{}
 				if err := k8sClient.Get(ctx, serviceImportKey, &serviceImport); err != nil {
 					return err.Error()
 				}
+				// The resolved spec is reset and the serviceImport is reported as not ready.
+				want := fleetnetv1alpha1.ServiceImportStatus{
+					Conditions: []metav1.Condition{
+						{
+							Type:               string(fleetnetv1alpha1.ServiceImportReady),
+							Status:             metav1.ConditionFalse,
+							ObservedGeneration: serviceImport.Generation,
+							Reason:             serviceimport.ConditionReasonNoAcceptedExports,
+							Message:            "service spec is not resolved; 0 of 0 exporting cluster(s) are accepted",
+						},
+					},
+				}
 				return cmp.Diff(want, serviceImport.Status, options...)
 			}, timeout, interval).Should(BeEmpty())
 		})
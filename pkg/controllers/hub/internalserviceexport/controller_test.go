@@ -21,9 +21,11 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/controllers/hub/serviceimport"
 )
 
 const (
@@ -114,6 +116,28 @@ func conflictedServiceExportConflictCondition(svcNamespace string, svcName strin
 	}
 }
 
+func authorizedServiceExportCondition(svcNamespace string, svcName string) metav1.Condition {
+	return metav1.Condition{
+		Type:               string(fleetnetv1alpha1.ServiceExportAuthorized),
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: 0,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "ExportAllowed",
+		Message:            fmt.Sprintf("service %s/%s is allowed to be exported", svcNamespace, svcName),
+	}
+}
+
+func noAcceptedExportsServiceImportCondition() metav1.Condition {
+	return metav1.Condition{
+		Type:               string(fleetnetv1alpha1.ServiceImportReady),
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: 0,
+		LastTransitionTime: metav1.Now(),
+		Reason:             serviceimport.ConditionReasonNoAcceptedExports,
+		Message:            "service spec is not resolved; 0 of 0 exporting cluster(s) are accepted",
+	}
+}
+
 func TestReconciler_NotFound(t *testing.T) {
 	ctx := context.Background()
 	fakeClient := fake.NewClientBuilder().
@@ -182,6 +206,11 @@ func TestHandleDelete(t *testing.T) {
 					Name:      testServiceName,
 					Namespace: testNamespace,
 				},
+				Status: fleetnetv1alpha1.ServiceImportStatus{
+					Conditions: []metav1.Condition{
+						noAcceptedExportsServiceImportCondition(),
+					},
+				},
 			},
 		},
 		{
@@ -459,6 +488,11 @@ func TestHandleUpdate(t *testing.T) {
 						UID:             "0",
 					},
 				},
+				Status: fleetnetv1alpha1.InternalServiceExportStatus{
+					Conditions: []metav1.Condition{
+						authorizedServiceExportCondition(testNamespace, testServiceName),
+					},
+				},
 			},
 			wantServiceImport: &fleetnetv1alpha1.ServiceImport{
 				ObjectMeta: metav1.ObjectMeta{
@@ -523,6 +557,7 @@ func TestHandleUpdate(t *testing.T) {
 				Status: fleetnetv1alpha1.InternalServiceExportStatus{
 					Conditions: []metav1.Condition{
 						unconflictedServiceExportConflictCondition(testNamespace, testServiceName),
+						authorizedServiceExportCondition(testNamespace, testServiceName),
 					},
 				},
 			},
@@ -617,6 +652,7 @@ func TestHandleUpdate(t *testing.T) {
 				Status: fleetnetv1alpha1.InternalServiceExportStatus{
 					Conditions: []metav1.Condition{
 						conflictedServiceExportConflictCondition(testNamespace, testServiceName),
+						authorizedServiceExportCondition(testNamespace, testServiceName),
 					},
 				},
 			},
@@ -716,6 +752,7 @@ func TestHandleUpdate(t *testing.T) {
 				Status: fleetnetv1alpha1.InternalServiceExportStatus{
 					Conditions: []metav1.Condition{
 						conflictedServiceExportConflictCondition(testNamespace, testServiceName),
+						authorizedServiceExportCondition(testNamespace, testServiceName),
 					},
 				},
 			},
@@ -796,6 +833,7 @@ func TestHandleUpdate(t *testing.T) {
 				Status: fleetnetv1alpha1.InternalServiceExportStatus{
 					Conditions: []metav1.Condition{
 						unconflictedServiceExportConflictCondition(testNamespace, testServiceName),
+						authorizedServiceExportCondition(testNamespace, testServiceName),
 					},
 				},
 			},
@@ -895,6 +933,7 @@ func TestHandleUpdate(t *testing.T) {
 				Status: fleetnetv1alpha1.InternalServiceExportStatus{
 					Conditions: []metav1.Condition{
 						unconflictedServiceExportConflictCondition(testNamespace, testServiceName),
+						authorizedServiceExportCondition(testNamespace, testServiceName),
 					},
 				},
 			},
@@ -903,7 +942,11 @@ func TestHandleUpdate(t *testing.T) {
 					Name:      testServiceName,
 					Namespace: testNamespace,
 				},
-				Status: fleetnetv1alpha1.ServiceImportStatus{},
+				Status: fleetnetv1alpha1.ServiceImportStatus{
+					Conditions: []metav1.Condition{
+						noAcceptedExportsServiceImportCondition(),
+					},
+				},
 			},
 		},
 	}
@@ -933,6 +976,7 @@ func TestHandleUpdate(t *testing.T) {
 			options := []cmp.Option{
 				cmpopts.IgnoreFields(metav1.ObjectMeta{}, "ResourceVersion"),
 				cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime"),
+				cmpopts.SortSlices(func(a, b metav1.Condition) bool { return a.Type < b.Type }),
 			}
 			internalSvcExport := fleetnetv1alpha1.InternalServiceExport{}
 			if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: testMemberNamespace, Name: testName}, &internalSvcExport); err != nil {
@@ -956,3 +1000,209 @@ func TestHandleUpdate(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleUpdate_Unauthorized(t *testing.T) {
+	ctx := context.Background()
+	internalSvcExport := internalServiceExportForTest()
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testServiceName,
+			Namespace: testNamespace,
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Ports: internalSvcExport.Spec.Ports,
+			Clusters: []fleetnetv1alpha1.ClusterStatus{
+				{Cluster: testClusterID},
+				{Cluster: "member-2"},
+			},
+		},
+	}
+	policy := &fleetnetv1alpha1.ServiceExportImportPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "tenant",
+			Namespace: testNamespace,
+		},
+		Spec: fleetnetv1alpha1.ServiceExportImportPolicySpec{
+			Export: &fleetnetv1alpha1.ClusterAccessRule{AllowedClusters: []string{"member-2"}},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(internalServiceExportScheme(t)).
+		WithObjects(internalSvcExport, serviceImport, policy).
+		WithStatusSubresource(internalSvcExport, serviceImport).
+		Build()
+
+	r := internalServiceExportReconciler(fakeClient)
+	got, err := r.handleUpdate(ctx, internalSvcExport)
+	if err != nil {
+		t.Fatalf("handleUpdate() got error %v, want no error", err)
+	}
+	if want := (ctrl.Result{}); !cmp.Equal(got, want) {
+		t.Errorf("handleUpdate() = %+v, want %+v", got, want)
+	}
+
+	gotServiceImport := fleetnetv1alpha1.ServiceImport{}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: testNamespace, Name: testServiceName}, &gotServiceImport); err != nil {
+		t.Fatalf("ServiceImport Get() got error %v, want no error", err)
+	}
+	wantClusters := []fleetnetv1alpha1.ClusterStatus{{Cluster: "member-2"}}
+	if diff := cmp.Diff(wantClusters, gotServiceImport.Status.Clusters); diff != "" {
+		t.Errorf("ServiceImport clusters mismatch (-want, +got):\n%s", diff)
+	}
+
+	gotInternalSvcExport := fleetnetv1alpha1.InternalServiceExport{}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: testMemberNamespace, Name: testName}, &gotInternalSvcExport); err != nil {
+		t.Fatalf("InternalServiceExport Get() got error %v, want no error", err)
+	}
+	wantConditions := []metav1.Condition{
+		{
+			Type:    string(fleetnetv1alpha1.ServiceExportAuthorized),
+			Status:  metav1.ConditionFalse,
+			Reason:  "ExportNotAllowed",
+			Message: "member cluster member-1 is not allowed to export service my-ns/my-svc by serviceExportImportPolicy tenant",
+		},
+	}
+	if diff := cmp.Diff(wantConditions, gotInternalSvcExport.Status.Conditions, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime")); diff != "" {
+		t.Errorf("InternalServiceExport conditions mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestHandleUpdate_Stale(t *testing.T) {
+	ctx := context.Background()
+	internalSvcExport := internalServiceExportForTest()
+	internalSvcExport.Status.Conditions = []metav1.Condition{
+		{
+			Type:   string(fleetnetv1alpha1.ServiceExportStale),
+			Status: metav1.ConditionTrue,
+			Reason: "HeartbeatLost",
+		},
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testServiceName,
+			Namespace: testNamespace,
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Ports: internalSvcExport.Spec.Ports,
+			Clusters: []fleetnetv1alpha1.ClusterStatus{
+				{Cluster: testClusterID},
+				{Cluster: "member-2"},
+			},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(internalServiceExportScheme(t)).
+		WithObjects(internalSvcExport, serviceImport).
+		WithStatusSubresource(internalSvcExport, serviceImport).
+		Build()
+
+	r := internalServiceExportReconciler(fakeClient)
+	got, err := r.handleUpdate(ctx, internalSvcExport)
+	if err != nil {
+		t.Fatalf("handleUpdate() got error %v, want no error", err)
+	}
+	if want := (ctrl.Result{}); !cmp.Equal(got, want) {
+		t.Errorf("handleUpdate() = %+v, want %+v", got, want)
+	}
+
+	gotServiceImport := fleetnetv1alpha1.ServiceImport{}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: testNamespace, Name: testServiceName}, &gotServiceImport); err != nil {
+		t.Fatalf("ServiceImport Get() got error %v, want no error", err)
+	}
+	wantClusters := []fleetnetv1alpha1.ClusterStatus{{Cluster: "member-2"}}
+	if diff := cmp.Diff(wantClusters, gotServiceImport.Status.Clusters); diff != "" {
+		t.Errorf("ServiceImport clusters mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestInternalServiceExportsForPolicy(t *testing.T) {
+	exportInNamespace := internalServiceExportForTest()
+	exportInOtherNamespace := internalServiceExportForTest()
+	exportInOtherNamespace.Name = "other-ns-" + testServiceName
+	exportInOtherNamespace.Spec.ServiceReference.Namespace = "other-ns"
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(internalServiceExportScheme(t)).
+		WithObjects(exportInNamespace, exportInOtherNamespace).
+		WithIndex(&fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldNamespace, func(o client.Object) []string {
+			return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.Namespace}
+		}).
+		Build()
+	r := internalServiceExportReconciler(fakeClient)
+
+	policy := &fleetnetv1alpha1.ServiceExportImportPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "tenant"},
+	}
+	got := r.internalServiceExportsForPolicy(context.Background(), policy)
+	want := []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: testMemberNamespace, Name: testName}}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("internalServiceExportsForPolicy() mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestIsResolvedSpecEqual(t *testing.T) {
+	timeoutSeconds := int32(600)
+	clientIPConfig := &corev1.SessionAffinityConfig{
+		ClientIP: &corev1.ClientIPConfig{TimeoutSeconds: &timeoutSeconds},
+	}
+	tests := []struct {
+		name   string
+		status fleetnetv1alpha1.ServiceImportStatus
+		spec   fleetnetv1alpha1.InternalServiceExportSpec
+		want   bool
+	}{
+		{
+			name: "same ports and no session affinity",
+			status: fleetnetv1alpha1.ServiceImportStatus{
+				Ports: internalServiceExportForTest().Spec.Ports,
+			},
+			spec: internalServiceExportForTest().Spec,
+			want: true,
+		},
+		{
+			name: "empty session affinity is treated as None",
+			status: fleetnetv1alpha1.ServiceImportStatus{
+				Ports:           internalServiceExportForTest().Spec.Ports,
+				SessionAffinity: corev1.ServiceAffinityNone,
+			},
+			spec: internalServiceExportForTest().Spec,
+			want: true,
+		},
+		{
+			name: "different ports",
+			status: fleetnetv1alpha1.ServiceImportStatus{
+				Ports: internalServiceExportForTest().Spec.Ports[:1],
+			},
+			spec: internalServiceExportForTest().Spec,
+		},
+		{
+			name: "different session affinity",
+			status: fleetnetv1alpha1.ServiceImportStatus{
+				Ports:                 internalServiceExportForTest().Spec.Ports,
+				SessionAffinity:       corev1.ServiceAffinityClientIP,
+				SessionAffinityConfig: clientIPConfig,
+			},
+			spec: internalServiceExportForTest().Spec,
+		},
+		{
+			name: "same session affinity",
+			status: fleetnetv1alpha1.ServiceImportStatus{
+				Ports:                 internalServiceExportForTest().Spec.Ports,
+				SessionAffinity:       corev1.ServiceAffinityClientIP,
+				SessionAffinityConfig: clientIPConfig,
+			},
+			spec: fleetnetv1alpha1.InternalServiceExportSpec{
+				Ports:                 internalServiceExportForTest().Spec.Ports,
+				SessionAffinity:       corev1.ServiceAffinityClientIP,
+				SessionAffinityConfig: clientIPConfig.DeepCopy(),
+			},
+			want: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isResolvedSpecEqual(&tc.status, &tc.spec); got != tc.want {
+				t.Errorf("isResolvedSpecEqual() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -113,7 +113,7 @@ var _ = BeforeSuite(func() {
 	err = (&Reconciler{
 		Client:        mgr.GetClient(),
 		RetryInternal: 10 * time.Millisecond,
-	}).SetupWithManager(mgr)
+	}).SetupWithManager(ctx, mgr)
 	Expect(err).ToNot(HaveOccurred())
 
 	ctx, cancel = context.WithCancel(context.TODO())
@@ -9,41 +9,81 @@ package internalserviceimport
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"reflect"
+	"slices"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	clusterv1beta1 "go.goms.io/fleet/apis/cluster/v1beta1"
+
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/clusterselector"
+	"go.goms.io/fleet-networking/pkg/common/events"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
 	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/servicepolicy"
+	"go.goms.io/fleet-networking/pkg/controllers/hub/serviceimport"
 )
 
 const (
+	// ControllerName is the name of the Reconciler.
+	ControllerName = "internalserviceimport-controller"
+
 	internalSvcImportCleanupFinalizer = "networking.fleet.azure.com/internalsvcimport-cleanup"
-	svcImportCleanupFinalizer         = "networking.fleet.azure.com/serviceimport-cleanup"
+	svcImportCleanupFinalizer         = objectmeta.ServiceImportCleanupFinalizer
 
 	internalSvcImportSvcRefNamespacedNameFieldKey = ".spec.serviceImportReference.namespacedName"
+	internalSvcImportSvcRefNamespaceFieldKey      = ".spec.serviceImportReference.namespace"
 
 	internalSvcImportRetryInterval = time.Second * 2
+
+	conditionReasonImportNotAllowed  = "ImportNotAllowed"
+	conditionReasonAlreadyImported   = "AlreadyImported"
+	conditionReasonNoSelectedExports = "NoSelectedExports"
+)
+
+var (
+	// memberClusterLabelChangedPredicate filters out the MemberCluster events which do not change its labels, e.g.
+	// the frequent status updates; creations and deletions are always handled.
+	memberClusterLabelChangedPredicate = predicate.LabelChangedPredicate{}
 )
 
 // Reconciler reconciles an InternalServiceImport object.
 type Reconciler struct {
 	HubClient client.Client
+	// Recorder records the claims of the ServiceImports by the member clusters, on both the InternalServiceImports
+	// and the ServiceImports.
+	Recorder record.EventRecorder
+	// WatchMemberClusters enables re-evaluating the cluster selectors of the imports when the labels of a
+	// MemberCluster change; it requires the MemberCluster API to be installed in the hub cluster.
+	WatchMemberClusters bool
+	// MigrationDone, if set, is closed once the deprecated ServiceInUseBy annotations have been migrated; no
+	// InternalServiceImport is reconciled before then.
+	MigrationDone <-chan struct{}
 }
 
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalserviceimports,verbs=get;list;watch
-//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports,verbs=get;list
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports,verbs=get;list;update
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceexportimportpolicies,verbs=get;list;watch
+//+kubebuilder:rbac:groups=cluster.kubernetes-fleet.io,resources=memberclusters,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile checks if a member cluster can import a Service from the hub cluster and fulfills the import.
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -55,6 +95,15 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		klog.V(2).InfoS("Reconciliation ends", "internalServiceImport", internalSvcImportRef, "latency", latency)
 	}()
 
+	if r.MigrationDone != nil {
+		select {
+		case <-r.MigrationDone:
+		default:
+			klog.V(2).InfoS("Waiting for the ServiceInUseBy annotations to be migrated", "internalServiceImport", internalSvcImportRef)
+			return ctrl.Result{RequeueAfter: internalSvcImportRetryInterval}, nil
+		}
+	}
+
 	// Retrieve the InternalServiceImport object.
 	internalSvcImport := &fleetnetv1alpha1.InternalServiceImport{}
 	if err := r.HubClient.Get(ctx, req.NamespacedName, internalSvcImport); err != nil {
@@ -90,10 +139,15 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		klog.ErrorS(err, "Failed to get ServiceImport", "serviceImport", svcImportRef, "internalServiceImport", internalSvcImportRef)
 		return ctrl.Result{}, err
 	case svcImport.DeletionTimestamp == nil && len(svcImport.Status.Clusters) == 0:
-		// The ServiceImport is being processed; requeue the InternalServiceImport for later processing.
+		// The ServiceImport is being processed, or none of its exporting clusters is accepted; report the import as
+		// not ready, so that no stale Service spec is kept, and requeue the InternalServiceImport for later processing.
 		klog.V(2).InfoS("ServiceImport is being processed; requeue for later processing",
 			"serviceImport", svcImportRef,
 			"internalServiceImport", internalSvcImportRef)
+		if _, err := r.reportNotReady(ctx, internalSvcImport, serviceimport.ConditionReasonNoAcceptedExports, noAcceptedExportsMessage(svcImport)); err != nil {
+			klog.ErrorS(err, "Failed to report the unresolved Service in InternalServiceImport status", "internalServiceImport", internalSvcImportRef)
+			return ctrl.Result{}, err
+		}
 		return ctrl.Result{RequeueAfter: internalSvcImportRetryInterval}, nil
 	}
 
@@ -114,10 +168,23 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	clusterNamespace := fleetnetv1alpha1.ClusterNamespace(internalSvcImport.Namespace)
 	clusterID := fleetnetv1alpha1.ClusterID(internalSvcImport.Spec.ServiceImportReference.ClusterID)
 
+	// Check if the member cluster is allowed to import the Service by the ServiceExportImportPolicies.
+	allowed, message, err := servicepolicy.IsImportAllowed(ctx, r.HubClient, svcNS, svcName, string(clusterID))
+	if err != nil {
+		klog.ErrorS(err, "Failed to evaluate serviceExportImportPolicies", "serviceImport", svcImportRef, "internalServiceImport", internalSvcImportRef)
+		return ctrl.Result{}, err
+	}
+	if !allowed {
+		klog.V(2).InfoS("The member cluster is not allowed to import the Service",
+			"serviceImport", svcImportRef,
+			"internalServiceImport", internalSvcImportRef,
+			"reason", message)
+		return r.rejectInternalServiceImport(ctx, svcImport, internalSvcImport, message)
+	}
+
 	// Find out which member clusters have imported the Service.
-	svcInUseBy := extractServiceInUseByInfoFromServiceImport(svcImport)
-	if len(svcInUseBy.MemberClusters) > 0 {
-		if _, ok := svcInUseBy.MemberClusters[clusterNamespace]; ok {
+	if len(svcImport.Status.ImportedBy) > 0 {
+		if findClusterImport(svcImport, clusterNamespace) >= 0 {
 			// The current member cluster has already imported Service; fulfill the import (i.e. update the Service
 			// spec kept in InternalServiceImport status).
 			klog.V(2).InfoS("The member cluster has imported the Service; will sync the imported Service spec",
@@ -136,8 +203,21 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		// by the current member cluster will be aborted.
 		klog.V(2).InfoS("A member cluster has already imported the Service",
 			"internalServiceImport", internalSvcImportRef,
-			"serviceInUseBy", svcInUseBy)
-		return r.clearInternalServiceImportStatus(ctx, internalSvcImport)
+			"importedBy", svcImport.Status.ImportedBy)
+		if err := r.removeInternalServiceImportCleanupFinalizer(ctx, internalSvcImport); err != nil {
+			klog.ErrorS(err, "Failed to remove cleanup finalizer from InternalServiceImport", "internalServiceImport", internalSvcImportRef)
+			return ctrl.Result{}, err
+		}
+		message := fmt.Sprintf("Service %s has already been imported by member cluster %s", svcImportKey, svcImport.Status.ImportedBy[0].Cluster)
+		transitioned, err := r.reportNotReady(ctx, internalSvcImport, conditionReasonAlreadyImported, message)
+		if err != nil {
+			klog.ErrorS(err, "Failed to report the conflicting import in InternalServiceImport status", "internalServiceImport", internalSvcImportRef)
+			return ctrl.Result{}, err
+		}
+		if transitioned {
+			r.Recorder.Event(internalSvcImport, corev1.EventTypeWarning, events.ReasonConflict, message)
+		}
+		return ctrl.Result{}, nil
 	}
 
 	klog.V(2).InfoS("The Service can be imported; will sync the Service spec",
@@ -150,14 +230,24 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return ctrl.Result{}, err
 	}
 
-	// Update the ServiceInUseBy annotation, which claims the Service for the current member cluster to import.
-	svcInUseBy.MemberClusters[clusterNamespace] = clusterID
-	if err := r.annotateServiceImportWithServiceInUseByInfo(ctx, svcImport, svcInUseBy); err != nil {
-		klog.ErrorS(err, "Failed to annotate ServiceImport with ServiceInUseBy info",
+	// Claim the Service for the current member cluster to import.
+	if err := r.claimServiceImport(ctx, svcImport, clusterNamespace, clusterID); err != nil {
+		// The claim fails with a conflict if the ServiceImport has changed since it was read, e.g. another member
+		// cluster has claimed the Service in the meantime; the InternalServiceImport is requeued and re-processed
+		// with the latest ServiceImport.
+		if errors.IsConflict(err) {
+			klog.V(2).InfoS("ServiceImport has changed since it was read; requeue for later processing",
+				"serviceImport", svcImportRef,
+				"internalServiceImport", internalSvcImportRef)
+			return ctrl.Result{Requeue: true}, nil
+		}
+		klog.ErrorS(err, "Failed to claim ServiceImport for the member cluster",
 			"serviceImport", svcImportRef,
-			"serviceInUseBy", svcInUseBy)
+			"internalServiceImport", internalSvcImportRef)
 		return ctrl.Result{}, err
 	}
+	r.Recorder.Eventf(internalSvcImport, corev1.EventTypeNormal, events.ReasonClaimed, "Claimed the import of Service %s", svcImportKey)
+	r.Recorder.Eventf(svcImport, corev1.EventTypeNormal, events.ReasonClaimed, "Member cluster %s claimed the import of the Service", clusterID)
 
 	// Fulfill the import (i.e. update the Service spec kept in InternalServiceImport status).
 	if err := r.fulfillInternalServiceImport(ctx, svcImport, internalSvcImport); err != nil {
@@ -187,6 +277,21 @@ func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) err
 		klog.ErrorS(err, "Failed to set up InternalServiceImport index")
 		return err
 	}
+	internalSvcImportNamespaceIndexerFunc := func(o client.Object) []string {
+		internalSvcImport, ok := o.(*fleetnetv1alpha1.InternalServiceImport)
+		if !ok {
+			return []string{}
+		}
+		return []string{internalSvcImport.Spec.ServiceImportReference.Namespace}
+	}
+	if err := mgr.GetFieldIndexer().IndexField(ctx,
+		&fleetnetv1alpha1.InternalServiceImport{},
+		internalSvcImportSvcRefNamespaceFieldKey,
+		internalSvcImportNamespaceIndexerFunc,
+	); err != nil {
+		klog.ErrorS(err, "Failed to set up InternalServiceImport index")
+		return err
+	}
 
 	// Enqueue InternalServiceImports for processing when a ServiceImport changes.
 	eventHandlers := handler.EnqueueRequestsFromMapFunc(func(_ context.Context, o client.Object) []reconcile.Request {
@@ -216,10 +321,125 @@ func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) err
 		return reqs
 	})
 
-	return ctrl.NewControllerManagedBy(mgr).
+	// Enqueue InternalServiceImports for processing when a policy in the namespace of the imported Services changes.
+	policyEventHandlers := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, o client.Object) []reconcile.Request {
+		internalSvcImportList := &fleetnetv1alpha1.InternalServiceImportList{}
+		fieldMatcher := client.MatchingFields{
+			internalSvcImportSvcRefNamespaceFieldKey: o.GetNamespace(),
+		}
+		if err := r.HubClient.List(ctx, internalSvcImportList, fieldMatcher); err != nil {
+			klog.ErrorS(err, "Failed to list InternalServiceImports for an ServiceExportImportPolicy", "serviceExportImportPolicy", klog.KObj(o))
+			return []reconcile.Request{}
+		}
+
+		reqs := make([]reconcile.Request, 0, len(internalSvcImportList.Items))
+		for _, internalSvcImport := range internalSvcImportList.Items {
+			reqs = append(reqs, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: internalSvcImport.Namespace,
+					Name:      internalSvcImport.Name,
+				},
+			})
+		}
+		return reqs
+	})
+
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&fleetnetv1alpha1.InternalServiceImport{}).
 		Watches(&fleetnetv1alpha1.ServiceImport{}, eventHandlers).
-		Complete(r)
+		Watches(&fleetnetv1alpha1.ServiceExportImportPolicy{}, policyEventHandlers)
+	if r.WatchMemberClusters {
+		// Enqueue InternalServiceImports which select member clusters by labels for processing when a MemberCluster
+		// changes.
+		memberClusterEventHandlers := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, o client.Object) []reconcile.Request {
+			internalSvcImportList := &fleetnetv1alpha1.InternalServiceImportList{}
+			if err := r.HubClient.List(ctx, internalSvcImportList); err != nil {
+				klog.ErrorS(err, "Failed to list InternalServiceImports for a MemberCluster", "memberCluster", klog.KObj(o))
+				return []reconcile.Request{}
+			}
+
+			reqs := make([]reconcile.Request, 0, len(internalSvcImportList.Items))
+			for _, internalSvcImport := range internalSvcImportList.Items {
+				selector := internalSvcImport.Spec.ClusterSelector
+				if selector == nil || selector.LabelSelector == nil {
+					continue
+				}
+				reqs = append(reqs, reconcile.Request{
+					NamespacedName: types.NamespacedName{
+						Namespace: internalSvcImport.Namespace,
+						Name:      internalSvcImport.Name,
+					},
+				})
+			}
+			return reqs
+		})
+		b = b.Watches(&clusterv1beta1.MemberCluster{}, memberClusterEventHandlers, builder.WithPredicates(memberClusterLabelChangedPredicate))
+	}
+	return b.Complete(metrics.InstrumentReconciler("internalserviceimport", r))
+}
+
+// rejectInternalServiceImport withdraws the import of a Service, if any, by a member cluster which is not allowed to
+// import it, and reports the rejection as a not-ready condition in the InternalServiceImport status.
+func (r *Reconciler) rejectInternalServiceImport(ctx context.Context,
+	svcImport *fleetnetv1alpha1.ServiceImport,
+	internalSvcImport *fleetnetv1alpha1.InternalServiceImport,
+	message string) (ctrl.Result, error) {
+	if controllerutil.ContainsFinalizer(internalSvcImport, internalSvcImportCleanupFinalizer) {
+		if res, err := r.withdrawServiceImport(ctx, svcImport, internalSvcImport); err != nil {
+			return res, err
+		}
+	}
+
+	transitioned, err := r.reportNotReady(ctx, internalSvcImport, conditionReasonImportNotAllowed, message)
+	if err != nil {
+		klog.ErrorS(err, "Failed to report the rejected import in InternalServiceImport status", "internalServiceImport", klog.KObj(internalSvcImport))
+		return ctrl.Result{}, err
+	}
+	if transitioned {
+		r.Recorder.Eventf(internalSvcImport, corev1.EventTypeWarning, events.ReasonClaimRejected,
+			"The import of Service %s/%s is not allowed: %s", svcImport.Namespace, svcImport.Name, message)
+	}
+	return ctrl.Result{}, nil
+}
+
+// reportNotReady replaces the status of an InternalServiceImport, whose import of a Service is not fulfilled, with a
+// not-ready condition; it returns true if the condition transitions, i.e. it is new or its reason changes, so that the
+// Events are emitted only once rather than on every reconcile.
+func (r *Reconciler) reportNotReady(ctx context.Context,
+	internalSvcImport *fleetnetv1alpha1.InternalServiceImport,
+	reason, message string) (bool, error) {
+	previous := meta.FindStatusCondition(internalSvcImport.Status.Conditions, string(fleetnetv1alpha1.ServiceImportReady)).DeepCopy()
+	// Keep the existing condition, if any, so that its last transition time is preserved.
+	notReadyStatus := fleetnetv1alpha1.ServiceImportStatus{}
+	if previous != nil {
+		notReadyStatus.Conditions = []metav1.Condition{*previous}
+	}
+	desired := metav1.Condition{
+		Type:    string(fleetnetv1alpha1.ServiceImportReady),
+		Status:  metav1.ConditionFalse,
+		Reason:  reason,
+		Message: message,
+	}
+	meta.SetStatusCondition(&notReadyStatus.Conditions, desired)
+	if reflect.DeepEqual(internalSvcImport.Status, notReadyStatus) {
+		// The state has stablized; skip the update.
+		return false, nil
+	}
+	internalSvcImport.Status = notReadyStatus
+	if err := r.HubClient.Status().Update(ctx, internalSvcImport); err != nil {
+		return false, err
+	}
+	return events.IsTransition(previous, desired), nil
+}
+
+// noAcceptedExportsMessage returns the message of the not-ready condition of an InternalServiceImport whose
+// ServiceImport has no accepted exporting cluster.
+func noAcceptedExportsMessage(svcImport *fleetnetv1alpha1.ServiceImport) string {
+	cond := meta.FindStatusCondition(svcImport.Status.Conditions, string(fleetnetv1alpha1.ServiceImportReady))
+	if cond != nil && cond.Reason == serviceimport.ConditionReasonNoAcceptedExports {
+		return cond.Message
+	}
+	return "service spec is not resolved yet"
 }
 
 // withdrawServiceImport withdraws the request to import a Service to a member cluster.
@@ -229,36 +449,18 @@ func (r *Reconciler) withdrawServiceImport(ctx context.Context,
 	// The cluster namespace of the member cluster which imports the Service.
 	clusterNamespace := fleetnetv1alpha1.ClusterNamespace(internalSvcImport.Namespace)
 
-	// Update the annotated ServiceInUseBy information.
-	svcInUseBy := extractServiceInUseByInfoFromServiceImport(svcImport)
-	if _, ok := svcInUseBy.MemberClusters[clusterNamespace]; ok {
-		delete(svcInUseBy.MemberClusters, clusterNamespace)
-		switch {
-		case len(svcInUseBy.MemberClusters) > 0:
-			// There are still member clusters importing the Service after the withdrawal; the ServiceInUseBy
-			// annotation will be updated. Note that with current semantics (one import only across the fleet)
-			// this branch should not run.
-			if err := r.annotateServiceImportWithServiceInUseByInfo(ctx, svcImport, svcInUseBy); err != nil {
-				klog.ErrorS(err, "Failed to annotate ServiceImport with ServiceInUseBy info",
-					"serviceImport", klog.KObj(svcImport),
-					"serviceInUseBy", svcInUseBy)
-				return ctrl.Result{}, err
-			}
-		case len(svcInUseBy.MemberClusters) == 0:
-			// No member cluster imports the Service after the withdrawal; the ServiceInUseBy annotation (and
-			// the cleanup finalizer) on ServiceImport will be cleared.
-			if err := r.clearServiceInUseByInfoFromServiceImport(ctx, svcImport); err != nil {
-				klog.ErrorS(err, "Failed to clear ServiceImport ServiceInUseBy annotation",
-					"serviceImport", klog.KObj(svcImport),
-					"serviceInUseBy", svcInUseBy)
-				return ctrl.Result{}, err
-			}
-		}
-	}
+	// Remove the member cluster from the importing clusters of the ServiceImport.
+	//
 	// A rare occurrence as it is, it could happen that the InternalServiceImport has the cleanup finalizer,
-	// yet the import is not annotated on the ServiceImport. This is usually caused by data corruption, or
-	// direct ServiceInUseBy annotation manipulation by the user; and in this case the controller will skip
-	// the updating.
+	// yet the member cluster is not found in the ServiceImport status, e.g. the claim has failed after the
+	// finalizer is added; and in this case only the cleanup finalizer on the ServiceImport, if no longer needed,
+	// is removed.
+	if err := r.releaseServiceImport(ctx, svcImport, clusterNamespace); err != nil {
+		klog.ErrorS(err, "Failed to release ServiceImport from the member cluster",
+			"serviceImport", klog.KObj(svcImport),
+			"internalServiceImport", klog.KObj(internalSvcImport))
+		return ctrl.Result{}, err
+	}
 
 	// Remove the cleanup finalizer.
 	if err := r.removeInternalServiceImportCleanupFinalizer(ctx, internalSvcImport); err != nil {
@@ -299,49 +501,116 @@ func (r *Reconciler) removeInternalServiceImportCleanupFinalizer(ctx context.Con
 	return nil
 }
 
-// addInternalServiceImportCleanupFinalizer adds the cleanup finalizer to an InternalServiceImport.
+// addInternalServiceImportCleanupFinalizer adds the cleanup finalizer to an InternalServiceImport; the update is
+// retried with the latest InternalServiceImport on conflicts, e.g. when the member cluster has updated its spec in
+// the meantime.
 func (r *Reconciler) addInternalServiceImportCleanupFinalizer(ctx context.Context, internalSvcImport *fleetnetv1alpha1.InternalServiceImport) error {
-	if !controllerutil.ContainsFinalizer(internalSvcImport, internalSvcImportCleanupFinalizer) {
-		controllerutil.AddFinalizer(internalSvcImport, internalSvcImportCleanupFinalizer)
-		return r.HubClient.Update(ctx, internalSvcImport)
+	if controllerutil.ContainsFinalizer(internalSvcImport, internalSvcImportCleanupFinalizer) {
+		return nil
 	}
-	return nil
+	isLatest := true
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if !isLatest {
+			if err := r.HubClient.Get(ctx, client.ObjectKeyFromObject(internalSvcImport), internalSvcImport); err != nil {
+				return err
+			}
+		}
+		isLatest = false
+		if !controllerutil.AddFinalizer(internalSvcImport, internalSvcImportCleanupFinalizer) {
+			return nil
+		}
+		return r.HubClient.Update(ctx, internalSvcImport)
+	})
 }
 
-// annotateServiceImportWithServiceInUseByInfo annotates ServiceInUseBy information on a ServiceImport.
-func (r *Reconciler) annotateServiceImportWithServiceInUseByInfo(ctx context.Context,
+// claimServiceImport claims a ServiceImport for a member cluster to import by adding the member cluster to the
+// ServiceImport status; the cleanup finalizer is added to the ServiceImport first.
+//
+// The status update is rejected if the ServiceImport has changed since it was read, so that two member clusters
+// cannot claim the same ServiceImport at the same time.
+func (r *Reconciler) claimServiceImport(ctx context.Context,
 	svcImport *fleetnetv1alpha1.ServiceImport,
-	svcInUseBy *fleetnetv1alpha1.ServiceInUseBy) error {
-	if svcImport.Annotations == nil {
-		// Initialize the annoation map if no annotations have been added before.
-		svcImport.Annotations = map[string]string{}
-	}
-
-	data, err := json.Marshal(svcInUseBy)
-	if err != nil {
-		return err
+	clusterNamespace fleetnetv1alpha1.ClusterNamespace,
+	clusterID fleetnetv1alpha1.ClusterID) error {
+	if !controllerutil.ContainsFinalizer(svcImport, svcImportCleanupFinalizer) {
+		controllerutil.AddFinalizer(svcImport, svcImportCleanupFinalizer)
+		if err := r.HubClient.Update(ctx, svcImport); err != nil {
+			return err
+		}
 	}
 
-	svcImport.Annotations[objectmeta.ServiceImportAnnotationServiceInUseBy] = string(data)
-	controllerutil.AddFinalizer(svcImport, svcImportCleanupFinalizer)
-	return r.HubClient.Update(ctx, svcImport)
+	svcImport.Status.ImportedBy = append(svcImport.Status.ImportedBy, fleetnetv1alpha1.ClusterImportStatus{
+		ClusterNamespace: clusterNamespace,
+		Cluster:          clusterID,
+		ImportedSince:    metav1.Now(),
+	})
+	return r.HubClient.Status().Update(ctx, svcImport)
 }
 
-// clearServiceInUseByInfoFromServiceImport clears the ServiceInUseBy annotation from a ServiceImport, and its
-// cleanup finalizer.
-func (r *Reconciler) clearServiceInUseByInfoFromServiceImport(ctx context.Context, svcImport *fleetnetv1alpha1.ServiceImport) error {
-	delete(svcImport.Annotations, objectmeta.ServiceImportAnnotationServiceInUseBy)
-	controllerutil.RemoveFinalizer(svcImport, svcImportCleanupFinalizer)
-	return r.HubClient.Update(ctx, svcImport)
+// releaseServiceImport removes a member cluster from the importing clusters in the status of a ServiceImport; the
+// cleanup finalizer is removed from the ServiceImport as well if no member cluster imports it any more.
+func (r *Reconciler) releaseServiceImport(ctx context.Context,
+	svcImport *fleetnetv1alpha1.ServiceImport,
+	clusterNamespace fleetnetv1alpha1.ClusterNamespace) error {
+	if idx := findClusterImport(svcImport, clusterNamespace); idx >= 0 {
+		clusterID := svcImport.Status.ImportedBy[idx].Cluster
+		svcImport.Status.ImportedBy = slices.Delete(svcImport.Status.ImportedBy, idx, idx+1)
+		if len(svcImport.Status.ImportedBy) == 0 {
+			svcImport.Status.ImportedBy = nil
+		}
+		if err := r.HubClient.Status().Update(ctx, svcImport); err != nil {
+			return err
+		}
+		r.Recorder.Eventf(svcImport, corev1.EventTypeNormal, events.ReasonReleased, "Member cluster %s released the import of the Service", clusterID)
+	}
+
+	if len(svcImport.Status.ImportedBy) == 0 && controllerutil.ContainsFinalizer(svcImport, svcImportCleanupFinalizer) {
+		controllerutil.RemoveFinalizer(svcImport, svcImportCleanupFinalizer)
+		return r.HubClient.Update(ctx, svcImport)
+	}
+	return nil
 }
 
 // fulfillInternalServiceImport fulfills an import of a Service by syncing the Service spec to the status of an
-// InternalServiceImport.
+// InternalServiceImport; only the exporting clusters selected by the cluster selector of the InternalServiceImport
+// are kept, and the import is reported as not ready if none of them is selected.
 func (r *Reconciler) fulfillInternalServiceImport(ctx context.Context,
 	svcImport *fleetnetv1alpha1.ServiceImport,
 	internalSvcImport *fleetnetv1alpha1.InternalServiceImport) error {
 	updatedInternalSvcImportStatus := svcImport.Status.DeepCopy()
-	if reflect.DeepEqual(internalSvcImport.Status, updatedInternalSvcImportStatus) {
+	// The importing clusters are tracked on the hub cluster only.
+	updatedInternalSvcImportStatus.ImportedBy = nil
+	if selector := internalSvcImport.Spec.ClusterSelector; selector != nil {
+		var selectedClusters []fleetnetv1alpha1.ClusterStatus
+		for _, cluster := range updatedInternalSvcImportStatus.Clusters {
+			selected, err := clusterselector.IsClusterSelected(ctx, r.HubClient, selector, cluster.Cluster)
+			if err != nil {
+				return err
+			}
+			if selected {
+				selectedClusters = append(selectedClusters, cluster)
+			}
+		}
+		updatedInternalSvcImportStatus.Clusters = selectedClusters
+		if len(selectedClusters) == 0 {
+			// Start from the current condition of the InternalServiceImport so that its last transition time is
+			// preserved.
+			var conditions []metav1.Condition
+			if cond := meta.FindStatusCondition(internalSvcImport.Status.Conditions, string(fleetnetv1alpha1.ServiceImportReady)); cond != nil {
+				conditions = append(conditions, *cond)
+			}
+			meta.SetStatusCondition(&conditions, metav1.Condition{
+				Type:               string(fleetnetv1alpha1.ServiceImportReady),
+				Status:             metav1.ConditionFalse,
+				Reason:             conditionReasonNoSelectedExports,
+				ObservedGeneration: internalSvcImport.Generation,
+				Message: fmt.Sprintf("none of the %d accepted exporting cluster(s) is selected by the cluster selector",
+					len(svcImport.Status.Clusters)),
+			})
+			meta.SetStatusCondition(&updatedInternalSvcImportStatus.Conditions, conditions[0])
+		}
+	}
+	if reflect.DeepEqual(internalSvcImport.Status, *updatedInternalSvcImportStatus) {
 		// The state has stablized; skip the fulfillment.
 		return nil
 	}
@@ -350,31 +619,10 @@ func (r *Reconciler) fulfillInternalServiceImport(ctx context.Context,
 	return r.HubClient.Status().Update(ctx, internalSvcImport)
 }
 
-// extractServiceInUseByInfoFromServiceImport extracts ServiceInUseBy information from annotations on a ServiceImport.
-func extractServiceInUseByInfoFromServiceImport(svcImport *fleetnetv1alpha1.ServiceImport) *fleetnetv1alpha1.ServiceInUseBy {
-	data, ok := svcImport.ObjectMeta.Annotations[objectmeta.ServiceImportAnnotationServiceInUseBy]
-	if !ok {
-		// The ServiceInUseBy annotation is absent on ServiceImport.
-		return &fleetnetv1alpha1.ServiceInUseBy{
-			MemberClusters: map[fleetnetv1alpha1.ClusterNamespace]fleetnetv1alpha1.ClusterID{},
-		}
-	}
-
-	svcInUseBy := &fleetnetv1alpha1.ServiceInUseBy{}
-	if err := json.Unmarshal([]byte(data), svcInUseBy); err != nil {
-		// The data cannot be unmarshalled; normally this should never happen, unless data corruption occurs,
-		// or the annotations are manipulated directly by the user. The controller will have to overwrite
-		// the data to fix the problem.
-		//
-		// Note that this situation, should it ever happens, can lead to an inconsistent state where one or more
-		// member clusters believe that it has successfully imported a Service, yet the import itself is not
-		// recognized by the fleet networking control plane (more specifically, the import is not documented
-		// as ServiceImport annotations). Resync can eventually address this inconsistency, but it may take a long
-		// while for the system to recover.
-		klog.ErrorS(err, "Failed to unmarshal ServiceInUseBy data", "serviceImport", klog.KObj(svcImport), "data", data)
-		return &fleetnetv1alpha1.ServiceInUseBy{
-			MemberClusters: map[fleetnetv1alpha1.ClusterNamespace]fleetnetv1alpha1.ClusterID{},
-		}
-	}
-	return svcInUseBy
+// findClusterImport returns the index of the import by a member cluster in the status of a ServiceImport, or -1 if
+// the member cluster has not imported the ServiceImport.
+func findClusterImport(svcImport *fleetnetv1alpha1.ServiceImport, clusterNamespace fleetnetv1alpha1.ClusterNamespace) int {
+	return slices.IndexFunc(svcImport.Status.ImportedBy, func(clusterImport fleetnetv1alpha1.ClusterImportStatus) bool {
+		return clusterImport.ClusterNamespace == clusterNamespace
+	})
 }
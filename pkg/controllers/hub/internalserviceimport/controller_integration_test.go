@@ -6,10 +6,10 @@ Licensed under the MIT license.
 package internalserviceimport
 
 import (
-	"encoding/json"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -18,7 +18,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
-	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/controllers/hub/serviceimport"
 )
 
 const (
@@ -79,20 +79,62 @@ func fulfillInternalServiceImport(internalSvcImport *fleetnetv1alpha1.InternalSe
 	}
 }
 
-// unfulfilledAndRequestedServiceImport returns an empty ServiceImport annotated with ServiceInUseBy data.
+// unfulfilledAndRequestedServiceImport returns an empty ServiceImport with the cleanup finalizer; the import itself is
+// added to its status by requestServiceImport.
 func unfulfilledAndRequestedServiceImport() *fleetnetv1alpha1.ServiceImport {
 	return &fleetnetv1alpha1.ServiceImport{
 		ObjectMeta: metav1.ObjectMeta{
-			Namespace: memberUserNS,
-			Name:      svcName,
-			Annotations: map[string]string{
-				objectmeta.ServiceImportAnnotationServiceInUseBy: fulfilledSvcInUseByAnnotationString(),
-			},
+			Namespace:  memberUserNS,
+			Name:       svcName,
 			Finalizers: []string{svcImportCleanupFinalizer},
 		},
 	}
 }
 
+// requestServiceImport marks a ServiceImport as imported by member cluster A in its status.
+func requestServiceImport(svcImport *fleetnetv1alpha1.ServiceImport) {
+	svcImport.Status.ImportedBy = fulfilledImportedBy()
+}
+
+// importingClusterNamespaces returns the namespaces of the member clusters which have imported a ServiceImport.
+func importingClusterNamespaces(svcImport *fleetnetv1alpha1.ServiceImport) []fleetnetv1alpha1.ClusterNamespace {
+	var clusterNamespaces []fleetnetv1alpha1.ClusterNamespace
+	for _, clusterImport := range svcImport.Status.ImportedBy {
+		clusterNamespaces = append(clusterNamespaces, clusterImport.ClusterNamespace)
+	}
+	return clusterNamespaces
+}
+
+// isReportedAsAlreadyImported returns true if the status of an InternalServiceImport only reports that the Service has
+// been imported by another member cluster.
+func isReportedAsAlreadyImported(status fleetnetv1alpha1.ServiceImportStatus) bool {
+	wantStatus := fleetnetv1alpha1.ServiceImportStatus{
+		Conditions: []metav1.Condition{
+			{
+				Type:   string(fleetnetv1alpha1.ServiceImportReady),
+				Status: metav1.ConditionFalse,
+				Reason: conditionReasonAlreadyImported,
+			},
+		},
+	}
+	return cmp.Equal(status, wantStatus, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime", "Message"))
+}
+
+// isReportedAsUnresolved returns true if the status of an InternalServiceImport only reports that the Service spec
+// has not been resolved from the exporting clusters.
+func isReportedAsUnresolved(status fleetnetv1alpha1.ServiceImportStatus) bool {
+	wantStatus := fleetnetv1alpha1.ServiceImportStatus{
+		Conditions: []metav1.Condition{
+			{
+				Type:   string(fleetnetv1alpha1.ServiceImportReady),
+				Status: metav1.ConditionFalse,
+				Reason: serviceimport.ConditionReasonNoAcceptedExports,
+			},
+		},
+	}
+	return cmp.Equal(status, wantStatus, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime", "Message"))
+}
+
 // fulfillServiceImport fulfills a ServiceImport by updating its status.
 func fulfillServiceImport(svcImport *fleetnetv1alpha1.ServiceImport) {
 	svcImport.Status = fleetnetv1alpha1.ServiceImportStatus{
@@ -230,6 +272,7 @@ var _ = Describe("internalserviceimport controller", Ordered, func() {
 			svcImport = unfulfilledAndRequestedServiceImport()
 			Expect(hubClient.Create(ctx, svcImport)).Should(Succeed())
 			fulfillServiceImport(svcImport)
+			requestServiceImport(svcImport)
 			Expect(hubClient.Status().Update(ctx, svcImport))
 
 			internalSvcImport = unfulfilledInternalServiceImport()
@@ -281,7 +324,7 @@ var _ = Describe("internalserviceimport controller", Ordered, func() {
 					return false
 				}
 
-				if _, ok := svcImport.Annotations[objectmeta.ServiceImportAnnotationServiceInUseBy]; ok {
+				if len(svcImport.Status.ImportedBy) != 0 {
 					return false
 				}
 
@@ -307,11 +350,10 @@ var _ = Describe("internalserviceimport controller", Ordered, func() {
 		fulfillInternalServiceImport(fulfilledInternalSvcImport)
 		expectedInternalSvcImportStatus := fulfilledInternalSvcImport.Status
 
-		expectedSvcInUseAnnotationData := fulfilledSvcInUseByAnnotationString()
+		expectedImportingClusterNamespaces := []fleetnetv1alpha1.ClusterNamespace{hubNSForMemberA}
 
 		BeforeEach(func() {
 			svcImport = unfulfilledAndRequestedServiceImport()
-			svcImport.Annotations = nil
 			svcImport.Finalizers = []string{}
 			Expect(hubClient.Create(ctx, svcImport)).Should(Succeed())
 			fulfillServiceImport(svcImport)
@@ -365,8 +407,7 @@ var _ = Describe("internalserviceimport controller", Ordered, func() {
 					return false
 				}
 
-				data, ok := svcImport.Annotations[objectmeta.ServiceImportAnnotationServiceInUseBy]
-				if !ok || !cmp.Equal(data, expectedSvcInUseAnnotationData) {
+				if !cmp.Equal(importingClusterNamespaces(svcImport), expectedImportingClusterNamespaces) {
 					return false
 				}
 
@@ -379,12 +420,13 @@ var _ = Describe("internalserviceimport controller", Ordered, func() {
 		var internalSvcImport *fleetnetv1alpha1.InternalServiceImport
 		var svcImport *fleetnetv1alpha1.ServiceImport
 
-		expectedSvcInUseAnnotationData := fulfilledSvcInUseByAnnotationString()
+		expectedImportingClusterNamespaces := []fleetnetv1alpha1.ClusterNamespace{hubNSForMemberA}
 
 		BeforeEach(func() {
 			svcImport = unfulfilledAndRequestedServiceImport()
 			Expect(hubClient.Create(ctx, svcImport)).Should(Succeed())
 			fulfillServiceImport(svcImport)
+			requestServiceImport(svcImport)
 			Expect(hubClient.Status().Update(ctx, svcImport))
 
 			internalSvcImport = unfulfilledInternalServiceImport()
@@ -420,18 +462,18 @@ var _ = Describe("internalserviceimport controller", Ordered, func() {
 			}, eventuallyTimeout, eventuallyInterval).Should(BeTrue())
 		})
 
-		It("should ignore the import + should clear internalserviceimport status", func() {
-			// Check if ServiceInUseBy information on ServiceImport has not changed.
+		It("should ignore the import + should report the conflict in internalserviceimport status", func() {
+			// Check if the importing clusters of ServiceImport have not changed.
 			Consistently(func() bool {
 				svcImport := &fleetnetv1alpha1.ServiceImport{}
 				if err := hubClient.Get(ctx, svcImportKey, svcImport); err != nil {
 					return false
 				}
 
-				return cmp.Equal(svcImport.Annotations[objectmeta.ServiceImportAnnotationServiceInUseBy], expectedSvcInUseAnnotationData)
+				return cmp.Equal(importingClusterNamespaces(svcImport), expectedImportingClusterNamespaces)
 			}, consistentlyDuration, consistentlyInterval).Should(BeTrue())
 
-			// Check if InternalServiceImport is cleared.
+			// Check if InternalServiceImport is cleared and reports the conflict.
 			Eventually(func() bool {
 				internalSvcImport := &fleetnetv1alpha1.InternalServiceImport{}
 				if err := hubClient.Get(ctx, internalSvcImportBKey, internalSvcImport); err != nil {
@@ -442,7 +484,7 @@ var _ = Describe("internalserviceimport controller", Ordered, func() {
 					return false
 				}
 
-				return cmp.Equal(internalSvcImport.Status, fleetnetv1alpha1.ServiceImportStatus{})
+				return isReportedAsAlreadyImported(internalSvcImport.Status)
 			}, eventuallyTimeout, eventuallyInterval).Should(BeTrue())
 		})
 	})
@@ -466,7 +508,6 @@ var _ = Describe("internalserviceimport controller", Ordered, func() {
 			Expect(hubClient.Create(ctx, internalSvcImportC)).Should(Succeed())
 
 			svcImport = unfulfilledAndRequestedServiceImport()
-			svcImport.Annotations = nil
 			svcImport.Finalizers = nil
 			Expect(hubClient.Create(ctx, svcImport)).Should(Succeed())
 			fulfillServiceImport(svcImport)
@@ -514,23 +555,12 @@ var _ = Describe("internalserviceimport controller", Ordered, func() {
 					return false
 				}
 
-				data, ok := svcImport.Annotations[objectmeta.ServiceImportAnnotationServiceInUseBy]
-				if !ok {
-					return false
-				}
-
-				svcInUseBy := &fleetnetv1alpha1.ServiceInUseBy{}
-				if err := json.Unmarshal([]byte(data), svcInUseBy); err != nil {
-					return false
-				}
-
-				if len(svcInUseBy.MemberClusters) != 1 {
+				clusterNamespaces := importingClusterNamespaces(svcImport)
+				if len(clusterNamespaces) != 1 {
 					return false
 				}
 
-				for k := range svcInUseBy.MemberClusters {
-					claimer = k
-				}
+				claimer = clusterNamespaces[0]
 				return true
 			}, eventuallyTimeout, eventuallyInterval).Should(BeTrue())
 
@@ -540,22 +570,7 @@ var _ = Describe("internalserviceimport controller", Ordered, func() {
 					return false
 				}
 
-				data, ok := svcImport.Annotations[objectmeta.ServiceImportAnnotationServiceInUseBy]
-				if !ok {
-					return false
-				}
-
-				svcInUseBy := &fleetnetv1alpha1.ServiceInUseBy{}
-				if err := json.Unmarshal([]byte(data), svcInUseBy); err != nil {
-					return false
-				}
-
-				if len(svcInUseBy.MemberClusters) != 1 {
-					return false
-				}
-
-				_, ok = svcInUseBy.MemberClusters[claimer]
-				return ok
+				return cmp.Equal(importingClusterNamespaces(svcImport), []fleetnetv1alpha1.ClusterNamespace{claimer})
 			}, consistentlyDuration, consistentlyInterval).Should(BeTrue())
 
 			// Exactly one InternalServiceImport should be fulfilled.
@@ -592,7 +607,7 @@ var _ = Describe("internalserviceimport controller", Ordered, func() {
 					return false
 				}
 
-				return cmp.Equal(idleInternalSvcImport.Status, fleetnetv1alpha1.ServiceImportStatus{})
+				return isReportedAsAlreadyImported(idleInternalSvcImport.Status)
 			}, consistentlyDuration, consistentlyInterval).Should(BeTrue())
 		})
 	})
@@ -608,20 +623,18 @@ var _ = Describe("internalserviceimport controller", Ordered, func() {
 		fulfillInternalServiceImport(fulfilledInternalSvcImport)
 		expectedInternalSvcImportStatus := fulfilledInternalSvcImport.Status
 
-		expectedSvcInUseAnnotationData := fulfilledSvcInUseByAnnotationString()
+		expectedImportingClusterNamespaces := []fleetnetv1alpha1.ClusterNamespace{hubNSForMemberA}
 
 		BeforeEach(func() {
 			svcImport = unfulfilledAndRequestedServiceImport()
-			svcInUseBy := &fleetnetv1alpha1.ServiceInUseBy{
-				MemberClusters: map[fleetnetv1alpha1.ClusterNamespace]fleetnetv1alpha1.ClusterID{
-					hubNSForMemberA: clusterIDForMemberA,
-					hubNSForMemberB: clusterIDForMemberB,
-				},
-			}
-			svcInUseByData, _ := json.Marshal(svcInUseBy)
-			svcImport.Annotations[objectmeta.ServiceImportAnnotationServiceInUseBy] = string(svcInUseByData)
 			Expect(hubClient.Create(ctx, svcImport)).Should(Succeed())
 			fulfillServiceImport(svcImport)
+			requestServiceImport(svcImport)
+			svcImport.Status.ImportedBy = append(svcImport.Status.ImportedBy, fleetnetv1alpha1.ClusterImportStatus{
+				ClusterNamespace: hubNSForMemberB,
+				Cluster:          clusterIDForMemberB,
+				ImportedSince:    importedSince,
+			})
 			Expect(hubClient.Status().Update(ctx, svcImport)).Should(Succeed())
 
 			internalSvcImportA = unfulfilledInternalServiceImport()
@@ -702,8 +715,7 @@ var _ = Describe("internalserviceimport controller", Ordered, func() {
 					return false
 				}
 
-				data, ok := svcImport.Annotations[objectmeta.ServiceImportAnnotationServiceInUseBy]
-				if !ok || data != expectedSvcInUseAnnotationData {
+				if !cmp.Equal(importingClusterNamespaces(svcImport), expectedImportingClusterNamespaces) {
 					return false
 				}
 
@@ -730,11 +742,10 @@ var _ = Describe("internalserviceimport controller", Ordered, func() {
 		fulfillInternalServiceImport(fulfilledInternalSvcImport)
 		expectedInternalSvcImportStatus := fulfilledInternalSvcImport.Status
 
-		expectedSvcInUseAnnotationData := fulfilledSvcInUseByAnnotationString()
+		expectedImportingClusterNamespaces := []fleetnetv1alpha1.ClusterNamespace{hubNSForMemberA}
 
 		BeforeEach(func() {
 			svcImport = unfulfilledAndRequestedServiceImport()
-			svcImport.Annotations = nil
 			Expect(hubClient.Create(ctx, svcImport)).Should(Succeed())
 			fulfillServiceImport(svcImport)
 			Expect(hubClient.Status().Update(ctx, svcImport)).Should(Succeed())
@@ -794,7 +805,7 @@ var _ = Describe("internalserviceimport controller", Ordered, func() {
 					return false
 				}
 
-				return cmp.Equal(internalSvcImportA.Status, fleetnetv1alpha1.ServiceImportStatus{})
+				return isReportedAsAlreadyImported(internalSvcImportA.Status)
 			}, eventuallyTimeout, eventuallyInterval).Should(BeTrue())
 
 			Eventually(func() bool {
@@ -803,24 +814,7 @@ var _ = Describe("internalserviceimport controller", Ordered, func() {
 					return false
 				}
 
-				data, ok := svcImport.Annotations[objectmeta.ServiceImportAnnotationServiceInUseBy]
-				if !ok {
-					return false
-				}
-
-				svcInUseBy := &fleetnetv1alpha1.ServiceInUseBy{}
-				if err := json.Unmarshal([]byte(data), svcInUseBy); err != nil {
-					return false
-				}
-
-				if len(svcInUseBy.MemberClusters) != 1 {
-					return false
-				}
-
-				if _, ok := svcInUseBy.MemberClusters[hubNSForMemberB]; !ok {
-					return false
-				}
-				return true
+				return cmp.Equal(importingClusterNamespaces(svcImport), []fleetnetv1alpha1.ClusterNamespace{hubNSForMemberB})
 			}, eventuallyTimeout, eventuallyInterval).Should(BeTrue())
 
 			// Delete the claimer InternalServiceImport.
@@ -853,8 +847,7 @@ var _ = Describe("internalserviceimport controller", Ordered, func() {
 					return false
 				}
 
-				data, ok := svcImport.Annotations[objectmeta.ServiceImportAnnotationServiceInUseBy]
-				if !ok || data != expectedSvcInUseAnnotationData {
+				if !cmp.Equal(importingClusterNamespaces(svcImport), expectedImportingClusterNamespaces) {
 					return false
 				}
 				return true
@@ -875,7 +868,6 @@ var _ = Describe("internalserviceimport controller", Ordered, func() {
 
 		BeforeEach(func() {
 			svcImport = unfulfilledAndRequestedServiceImport()
-			svcImport.Annotations = nil
 			Expect(hubClient.Create(ctx, svcImport)).Should(Succeed())
 			fulfillServiceImport(svcImport)
 			Expect(hubClient.Status().Update(ctx, svcImport)).Should(Succeed())
@@ -936,6 +928,7 @@ var _ = Describe("internalserviceimport controller", Ordered, func() {
 						Cluster: clusterIDForMemberC,
 					},
 				},
+				ImportedBy: svcImport.Status.ImportedBy,
 			}
 			svcImport.Status = updatedSvcImportStatus
 			Expect(hubClient.Status().Update(ctx, svcImport)).Should(Succeed())
@@ -975,13 +968,11 @@ var _ = Describe("internalserviceimport controller", Ordered, func() {
 		var internalSvcImport *fleetnetv1alpha1.InternalServiceImport
 		var svcImport *fleetnetv1alpha1.ServiceImport
 
-		fulfilledInternalSvcImport := unfulfilledInternalServiceImport()
-		fulfillInternalServiceImport(fulfilledInternalSvcImport)
-		expectedInternalSvcImportStatus := fulfilledInternalSvcImport.Status
-
 		BeforeEach(func() {
 			svcImport = unfulfilledAndRequestedServiceImport()
 			Expect(hubClient.Create(ctx, svcImport)).Should(Succeed())
+			requestServiceImport(svcImport)
+			Expect(hubClient.Status().Update(ctx, svcImport)).Should(Succeed())
 
 			internalSvcImport = unfulfilledInternalServiceImport()
 			internalSvcImport.Finalizers = []string{internalSvcImportCleanupFinalizer}
@@ -1013,8 +1004,8 @@ var _ = Describe("internalserviceimport controller", Ordered, func() {
 			}, eventuallyTimeout, eventuallyInterval).Should(BeTrue())
 		})
 
-		It("should requeue until serviceimport is processed + should clear internalserviceimport when serviceimport is deleted", func() {
-			// Check if no action is taken on InternalServiceImport when ServiceImport is being processed.
+		It("should report internalserviceimport as not ready until serviceimport is processed + should clear internalserviceimport when serviceimport is deleted", func() {
+			// Check if the stale Service spec is cleared from InternalServiceImport when ServiceImport is being processed.
 			Eventually(func() bool {
 				internalSvcImport := &fleetnetv1alpha1.InternalServiceImport{}
 				if err := hubClient.Get(ctx, internalSvcImportAKey, internalSvcImport); err != nil {
@@ -1025,7 +1016,7 @@ var _ = Describe("internalserviceimport controller", Ordered, func() {
 					return false
 				}
 
-				return cmp.Equal(internalSvcImport.Status, expectedInternalSvcImportStatus)
+				return isReportedAsUnresolved(internalSvcImport.Status)
 			}, eventuallyTimeout, eventuallyInterval).Should(BeTrue())
 
 			Consistently(func() bool {
@@ -1038,7 +1029,7 @@ var _ = Describe("internalserviceimport controller", Ordered, func() {
 					return false
 				}
 
-				return cmp.Equal(internalSvcImport.Status, expectedInternalSvcImportStatus)
+				return isReportedAsUnresolved(internalSvcImport.Status)
 			}, consistentlyDuration, consistentlyInterval).Should(BeTrue())
 
 			// Process (delete) ServiceImport.
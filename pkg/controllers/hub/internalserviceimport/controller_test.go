@@ -8,20 +8,29 @@ package internalserviceimport
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	clusterv1beta1 "go.goms.io/fleet/apis/cluster/v1beta1"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/controllers/hub/serviceimport"
 )
 
 const (
@@ -50,32 +59,29 @@ var (
 	tcpPort             = int32(81)
 	tcpPortProtocol     = corev1.ProtocolTCP
 	tcpPortAppProtocol  = "example.com/custom"
+
+	importedSince = metav1.NewTime(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC))
+
+	ignoreImportedSince = cmpopts.IgnoreFields(fleetnetv1alpha1.ClusterImportStatus{}, "ImportedSince")
 )
 
-// fulfilledSvcInUseByAnnotation returns a fulfilled ServiceInUseBy for annotation use.
-func fulfilledServiceInUseByAnnotation() *fleetnetv1alpha1.ServiceInUseBy {
-	return &fleetnetv1alpha1.ServiceInUseBy{
-		MemberClusters: map[fleetnetv1alpha1.ClusterNamespace]fleetnetv1alpha1.ClusterID{
-			hubNSForMemberA: clusterIDForMemberA,
+// fulfilledImportedBy returns the importing clusters of a fulfilled ServiceImport.
+func fulfilledImportedBy() []fleetnetv1alpha1.ClusterImportStatus {
+	return []fleetnetv1alpha1.ClusterImportStatus{
+		{
+			ClusterNamespace: hubNSForMemberA,
+			Cluster:          clusterIDForMemberA,
+			ImportedSince:    importedSince,
 		},
 	}
 }
 
-// fulfilledSvcInUseByAnnotationString returns marshalled ServiceInUseBy data in the string form.
-func fulfilledSvcInUseByAnnotationString() string {
-	data, _ := json.Marshal(fulfilledServiceInUseByAnnotation())
-	return string(data)
-}
-
 // fulfilledSvcImport returns a fulfilled ServiceImport.
 func fulfilledServiceImport() *fleetnetv1alpha1.ServiceImport {
 	return &fleetnetv1alpha1.ServiceImport{
 		ObjectMeta: metav1.ObjectMeta{
-			Namespace: memberUserNS,
-			Name:      svcName,
-			Annotations: map[string]string{
-				objectmeta.ServiceImportAnnotationServiceInUseBy: fulfilledSvcInUseByAnnotationString(),
-			},
+			Namespace:  memberUserNS,
+			Name:       svcName,
 			Finalizers: []string{svcImportCleanupFinalizer},
 		},
 		Status: fleetnetv1alpha1.ServiceImportStatus{
@@ -105,6 +111,7 @@ func fulfilledServiceImport() *fleetnetv1alpha1.ServiceImport {
 					Cluster: clusterIDForMemberC,
 				},
 			},
+			ImportedBy: fulfilledImportedBy(),
 		},
 	}
 }
@@ -119,74 +126,57 @@ func TestMain(m *testing.M) {
 	os.Exit(m.Run())
 }
 
-// TestExtractServiceInUseByInfoFromServiceImport tests the extractServiceInUseByInfoFromServiceImport function.
-func TestExtractServiceInUseByInfoFromServiceImport(t *testing.T) {
+// TestFindClusterImport tests the findClusterImport function.
+func TestFindClusterImport(t *testing.T) {
 	testCases := []struct {
-		name           string
-		svcImport      *fleetnetv1alpha1.ServiceImport
-		wantSvcInUseBy *fleetnetv1alpha1.ServiceInUseBy
+		name             string
+		svcImport        *fleetnetv1alpha1.ServiceImport
+		clusterNamespace fleetnetv1alpha1.ClusterNamespace
+		want             int
 	}{
 		{
-			name: "should return empty service in use by info (no annotation)",
+			name: "should return -1 (no imports)",
 			svcImport: &fleetnetv1alpha1.ServiceImport{
 				ObjectMeta: metav1.ObjectMeta{
 					Namespace: memberUserNS,
 					Name:      svcName,
 				},
 			},
-			wantSvcInUseBy: &fleetnetv1alpha1.ServiceInUseBy{
-				MemberClusters: map[fleetnetv1alpha1.ClusterNamespace]fleetnetv1alpha1.ClusterID{},
-			},
+			clusterNamespace: hubNSForMemberA,
+			want:             -1,
 		},
 		{
-			name: "should return empty service in use by info (bad annotation)",
-			svcImport: &fleetnetv1alpha1.ServiceImport{
-				ObjectMeta: metav1.ObjectMeta{
-					Namespace: memberUserNS,
-					Name:      svcName,
-					Annotations: map[string]string{
-						objectmeta.ServiceImportAnnotationServiceInUseBy: "xyz",
-					},
-				},
-			},
-			wantSvcInUseBy: &fleetnetv1alpha1.ServiceInUseBy{
-				MemberClusters: map[fleetnetv1alpha1.ClusterNamespace]fleetnetv1alpha1.ClusterID{},
-			},
+			name:             "should return -1 (imported by another cluster)",
+			svcImport:        fulfilledServiceImport(),
+			clusterNamespace: hubNSForMemberB,
+			want:             -1,
 		},
 		{
-			name: "should return valid service in use by info",
-			svcImport: &fleetnetv1alpha1.ServiceImport{
-				ObjectMeta: metav1.ObjectMeta{
-					Namespace: memberUserNS,
-					Name:      svcName,
-					Annotations: map[string]string{
-						objectmeta.ServiceImportAnnotationServiceInUseBy: fulfilledSvcInUseByAnnotationString(),
-					},
-				},
-			},
-			wantSvcInUseBy: fulfilledServiceInUseByAnnotation(),
+			name:             "should return the index of the import",
+			svcImport:        fulfilledServiceImport(),
+			clusterNamespace: hubNSForMemberA,
+			want:             0,
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			svcInUseBy := extractServiceInUseByInfoFromServiceImport(tc.svcImport)
-			if diff := cmp.Diff(svcInUseBy, tc.wantSvcInUseBy); diff != "" {
-				t.Fatalf("extractServiceInUseByInfoFromServiceImport(%+v), mismatch (-got, +want)\n%s", tc.svcImport, diff)
+			if got := findClusterImport(tc.svcImport, tc.clusterNamespace); got != tc.want {
+				t.Fatalf("findClusterImport(%+v, %s) = %d, want %d", tc.svcImport, tc.clusterNamespace, got, tc.want)
 			}
 		})
 	}
 }
 
-// TestWithdrawServiceImport_AnnotationMatches tests the Reconciler.withdrawServiceImport method.
-func TestWithdrawServiceImport_AnnotationMatches(t *testing.T) {
+// TestWithdrawServiceImport_ImportMatches tests the Reconciler.withdrawServiceImport method.
+func TestWithdrawServiceImport_ImportMatches(t *testing.T) {
 	testCases := []struct {
 		name              string
 		svcImport         *fleetnetv1alpha1.ServiceImport
 		internalSvcImport *fleetnetv1alpha1.InternalServiceImport
 	}{
 		{
-			name:      "should withdraw service import (annotation matches)",
+			name:      "should withdraw service import (import matches)",
 			svcImport: fulfilledServiceImport(),
 			internalSvcImport: &fleetnetv1alpha1.InternalServiceImport{
 				ObjectMeta: metav1.ObjectMeta{
@@ -205,15 +195,28 @@ func TestWithdrawServiceImport_AnnotationMatches(t *testing.T) {
 			fakeHubClient := fake.NewClientBuilder().
 				WithScheme(scheme.Scheme).
 				WithObjects(tc.svcImport, tc.internalSvcImport).
+				WithStatusSubresource(tc.svcImport).
 				Build()
+			recorder := record.NewFakeRecorder(10)
 			reconciler := Reconciler{
 				HubClient: fakeHubClient,
+				Recorder:  recorder,
 			}
 
 			if res, err := reconciler.withdrawServiceImport(ctx, tc.svcImport, tc.internalSvcImport); !cmp.Equal(res, ctrl.Result{}) || err != nil {
 				t.Fatalf("withdrawServiceImport(%+v, %+v) = %+v, %v, want %v, no error", tc.svcImport, tc.internalSvcImport, res, err, ctrl.Result{})
 			}
 
+			wantEvent := fmt.Sprintf("Normal Released Member cluster %s released the import of the Service", clusterIDForMemberA)
+			select {
+			case gotEvent := <-recorder.Events:
+				if gotEvent != wantEvent {
+					t.Errorf("event = %q, want %q", gotEvent, wantEvent)
+				}
+			default:
+				t.Errorf("no event is recorded, want %q", wantEvent)
+			}
+
 			svcImport := &fleetnetv1alpha1.ServiceImport{}
 			if err := fakeHubClient.Get(ctx, svcImportKey, svcImport); err != nil {
 				t.Fatalf("serviceImport Get(%+v), got %v, want no error", svcImportKey, err)
@@ -223,9 +226,8 @@ func TestWithdrawServiceImport_AnnotationMatches(t *testing.T) {
 				t.Fatalf("serviceImport finalizers, got %v, want no finalizers", svcImport.Finalizers)
 			}
 
-			_, ok := svcImport.Annotations[objectmeta.ServiceImportAnnotationServiceInUseBy]
-			if ok {
-				t.Fatalf("serviceInUseBy annotation is present, want absence")
+			if len(svcImport.Status.ImportedBy) != 0 {
+				t.Fatalf("serviceImport importedBy, got %v, want no imports", svcImport.Status.ImportedBy)
 			}
 
 			internalSvcImport := &fleetnetv1alpha1.InternalServiceImport{}
@@ -241,16 +243,16 @@ func TestWithdrawServiceImport_AnnotationMatches(t *testing.T) {
 	}
 }
 
-// TestWithdrawServiceImport_AnnotationMismatches tests the Reconciler.withdrawServiceImport method.
-func TestWithdrawServiceImport_AnnotationMismatches(t *testing.T) {
+// TestWithdrawServiceImport_ImportMismatches tests the Reconciler.withdrawServiceImport method.
+func TestWithdrawServiceImport_ImportMismatches(t *testing.T) {
 	testCases := []struct {
-		name               string
-		svcImport          *fleetnetv1alpha1.ServiceImport
-		internalSvcImport  *fleetnetv1alpha1.InternalServiceImport
-		wantSvcInUseByData string
+		name              string
+		svcImport         *fleetnetv1alpha1.ServiceImport
+		internalSvcImport *fleetnetv1alpha1.InternalServiceImport
+		wantImportedBy    []fleetnetv1alpha1.ClusterImportStatus
 	}{
 		{
-			name:      "should withdraw service import (annotation mismatches)",
+			name:      "should withdraw service import (import mismatches)",
 			svcImport: fulfilledServiceImport(),
 			internalSvcImport: &fleetnetv1alpha1.InternalServiceImport{
 				ObjectMeta: metav1.ObjectMeta{
@@ -259,7 +261,7 @@ func TestWithdrawServiceImport_AnnotationMismatches(t *testing.T) {
 					Finalizers: []string{internalSvcImportCleanupFinalizer},
 				},
 			},
-			wantSvcInUseByData: fulfilledServiceImport().Annotations[objectmeta.ServiceImportAnnotationServiceInUseBy],
+			wantImportedBy: fulfilledImportedBy(),
 		},
 	}
 
@@ -270,9 +272,11 @@ func TestWithdrawServiceImport_AnnotationMismatches(t *testing.T) {
 			fakeHubClient := fake.NewClientBuilder().
 				WithScheme(scheme.Scheme).
 				WithObjects(tc.svcImport, tc.internalSvcImport).
+				WithStatusSubresource(tc.svcImport).
 				Build()
 			reconciler := Reconciler{
 				HubClient: fakeHubClient,
+				Recorder:  record.NewFakeRecorder(10),
 			}
 
 			if res, err := reconciler.withdrawServiceImport(ctx, tc.svcImport, tc.internalSvcImport); !cmp.Equal(res, ctrl.Result{}) || err != nil {
@@ -288,12 +292,8 @@ func TestWithdrawServiceImport_AnnotationMismatches(t *testing.T) {
 				t.Fatalf("serviceImport finalizers, got %v, want %v", svcImport.Finalizers, []string{svcImportCleanupFinalizer})
 			}
 
-			data, ok := svcImport.Annotations[objectmeta.ServiceImportAnnotationServiceInUseBy]
-			if !ok {
-				t.Fatalf("serviceInUseBy annotation is absent, want presence")
-			}
-			if !cmp.Equal(data, tc.wantSvcInUseByData) {
-				t.Fatalf("serviceInUseBy annotation, got %s, want %s", data, tc.wantSvcInUseByData)
+			if diff := cmp.Diff(svcImport.Status.ImportedBy, tc.wantImportedBy); diff != "" {
+				t.Fatalf("serviceImport importedBy mismatch (-got, +want)\n%s", diff)
 			}
 
 			internalSvcImport := &fleetnetv1alpha1.InternalServiceImport{}
@@ -311,20 +311,18 @@ func TestWithdrawServiceImport_AnnotationMismatches(t *testing.T) {
 
 // TestWithdrawServiceImport_MultiImports tests the Reconciler.withdrawServiceImport method.
 func TestWithdrawServiceImport_MultiImports(t *testing.T) {
-	svcInUseBy := fulfilledServiceInUseByAnnotation()
-	svcInUseBy.MemberClusters[hubNSForMemberB] = clusterIDForMemberB
-	svcInUseByData, err := json.Marshal(svcInUseBy)
-	if err != nil {
-		panic(err)
-	}
 	svcImport := fulfilledServiceImport()
-	svcImport.Annotations[objectmeta.ServiceImportAnnotationServiceInUseBy] = string(svcInUseByData)
+	svcImport.Status.ImportedBy = append(svcImport.Status.ImportedBy, fleetnetv1alpha1.ClusterImportStatus{
+		ClusterNamespace: hubNSForMemberB,
+		Cluster:          clusterIDForMemberB,
+		ImportedSince:    importedSince,
+	})
 
 	testCases := []struct {
-		name               string
-		svcImport          *fleetnetv1alpha1.ServiceImport
-		internalSvcImport  *fleetnetv1alpha1.InternalServiceImport
-		wantSvcInUseByData string
+		name              string
+		svcImport         *fleetnetv1alpha1.ServiceImport
+		internalSvcImport *fleetnetv1alpha1.InternalServiceImport
+		wantImportedBy    []fleetnetv1alpha1.ClusterImportStatus
 	}{
 		{
 			name:      "should withdraw service import (multiple imports)",
@@ -336,7 +334,7 @@ func TestWithdrawServiceImport_MultiImports(t *testing.T) {
 					Finalizers: []string{internalSvcImportCleanupFinalizer},
 				},
 			},
-			wantSvcInUseByData: fulfilledServiceImport().Annotations[objectmeta.ServiceImportAnnotationServiceInUseBy],
+			wantImportedBy: fulfilledImportedBy(),
 		},
 	}
 
@@ -347,9 +345,11 @@ func TestWithdrawServiceImport_MultiImports(t *testing.T) {
 			fakeHubClient := fake.NewClientBuilder().
 				WithScheme(scheme.Scheme).
 				WithObjects(tc.svcImport, tc.internalSvcImport).
+				WithStatusSubresource(tc.svcImport).
 				Build()
 			reconciler := Reconciler{
 				HubClient: fakeHubClient,
+				Recorder:  record.NewFakeRecorder(10),
 			}
 
 			if res, err := reconciler.withdrawServiceImport(ctx, tc.svcImport, tc.internalSvcImport); !cmp.Equal(res, ctrl.Result{}) || err != nil {
@@ -365,12 +365,8 @@ func TestWithdrawServiceImport_MultiImports(t *testing.T) {
 				t.Fatalf("serviceImport finalizers, got %v, want %v", svcImport.Finalizers, []string{svcImportCleanupFinalizer})
 			}
 
-			data, ok := svcImport.Annotations[objectmeta.ServiceImportAnnotationServiceInUseBy]
-			if !ok {
-				t.Fatalf("serviceInUseBy annotation is absent, want presence")
-			}
-			if !cmp.Equal(data, tc.wantSvcInUseByData) {
-				t.Fatalf("serviceInUseBy annotation, got %s, want %s", data, tc.wantSvcInUseByData)
+			if diff := cmp.Diff(svcImport.Status.ImportedBy, tc.wantImportedBy); diff != "" {
+				t.Fatalf("serviceImport importedBy mismatch (-got, +want)\n%s", diff)
 			}
 
 			internalSvcImport := &fleetnetv1alpha1.InternalServiceImport{}
@@ -427,6 +423,7 @@ func TestClearInternalServiceImportStatus(t *testing.T) {
 				Build()
 			reconciler := Reconciler{
 				HubClient: fakeHubClient,
+				Recorder:  record.NewFakeRecorder(10),
 			}
 
 			if res, err := reconciler.clearInternalServiceImportStatus(ctx, tc.internalSvcImport); !cmp.Equal(res, ctrl.Result{}) || err != nil {
@@ -474,6 +471,7 @@ func TestRemoveInternalServiceImportCleanupFinalizer(t *testing.T) {
 			fakeHubClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(tc.internalSvcImport).Build()
 			reconciler := Reconciler{
 				HubClient: fakeHubClient,
+				Recorder:  record.NewFakeRecorder(10),
 			}
 
 			if err := reconciler.removeInternalServiceImportCleanupFinalizer(ctx, tc.internalSvcImport); err != nil {
@@ -516,6 +514,7 @@ func TestAddInternalServiceImportCleanupFinalizer(t *testing.T) {
 			fakeHubClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(tc.internalSvcImport).Build()
 			reconciler := Reconciler{
 				HubClient: fakeHubClient,
+				Recorder:  record.NewFakeRecorder(10),
 			}
 
 			if err := reconciler.addInternalServiceImportCleanupFinalizer(ctx, tc.internalSvcImport); err != nil {
@@ -534,35 +533,71 @@ func TestAddInternalServiceImportCleanupFinalizer(t *testing.T) {
 	}
 }
 
-// TestAnnotateServiceImportWithServiceInUseByInfo tests the Reconciler.annotateServiceImportWithServiceInUseByInfo method.
-func TestAnnotateServiceImportWithServiceInUseByInfo(t *testing.T) {
+// TestAddInternalServiceImportCleanupFinalizer_Conflict tests that the finalizer is added to the latest
+// InternalServiceImport when the one read has become stale.
+func TestAddInternalServiceImportCleanupFinalizer_Conflict(t *testing.T) {
+	ctx := context.Background()
+	fakeHubClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(&fleetnetv1alpha1.InternalServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: hubNSForMemberA,
+			Name:      internalSvcImportName,
+		},
+	}).Build()
+	reconciler := Reconciler{
+		HubClient: fakeHubClient,
+		Recorder:  record.NewFakeRecorder(10),
+	}
+
+	staleInternalSvcImport := &fleetnetv1alpha1.InternalServiceImport{}
+	if err := fakeHubClient.Get(ctx, internalSvcImportAKey, staleInternalSvcImport); err != nil {
+		t.Fatalf("internalServiceImport Get(%+v), got %v, want no error", internalSvcImportAKey, err)
+	}
+	latestInternalSvcImport := staleInternalSvcImport.DeepCopy()
+	latestInternalSvcImport.Labels = map[string]string{"app": svcName}
+	if err := fakeHubClient.Update(ctx, latestInternalSvcImport); err != nil {
+		t.Fatalf("internalServiceImport Update(%+v), got %v, want no error", latestInternalSvcImport, err)
+	}
+
+	if err := reconciler.addInternalServiceImportCleanupFinalizer(ctx, staleInternalSvcImport); err != nil {
+		t.Fatalf("addInternalServiceImportCleanupFinalizer(%+v), got %v, want no error", staleInternalSvcImport, err)
+	}
+
+	internalSvcImport := &fleetnetv1alpha1.InternalServiceImport{}
+	if err := fakeHubClient.Get(ctx, internalSvcImportAKey, internalSvcImport); err != nil {
+		t.Fatalf("internalServiceImport Get(%+v), got %v, want no error", internalSvcImportAKey, err)
+	}
+	if !cmp.Equal(internalSvcImport.Finalizers, []string{internalSvcImportCleanupFinalizer}) {
+		t.Fatalf("internalServiceImport finalizers, got %v, want %v", internalSvcImport.Finalizers, []string{internalSvcImportCleanupFinalizer})
+	}
+	if !cmp.Equal(internalSvcImport.Labels, latestInternalSvcImport.Labels) {
+		t.Fatalf("internalServiceImport labels, got %v, want %v", internalSvcImport.Labels, latestInternalSvcImport.Labels)
+	}
+}
+
+// TestClaimServiceImport tests the Reconciler.claimServiceImport method.
+func TestClaimServiceImport(t *testing.T) {
 	testCases := []struct {
-		name       string
-		svcImport  *fleetnetv1alpha1.ServiceImport
-		svcInUseBy *fleetnetv1alpha1.ServiceInUseBy
+		name      string
+		svcImport *fleetnetv1alpha1.ServiceImport
 	}{
 		{
-			name: "should add service in use by info",
+			name: "should claim service import (no finalizer)",
 			svcImport: &fleetnetv1alpha1.ServiceImport{
 				ObjectMeta: metav1.ObjectMeta{
 					Namespace: memberUserNS,
 					Name:      svcName,
 				},
 			},
-			svcInUseBy: fulfilledServiceInUseByAnnotation(),
 		},
 		{
-			name: "should overwrite service in use by info",
+			name: "should claim service import (finalizer set)",
 			svcImport: &fleetnetv1alpha1.ServiceImport{
 				ObjectMeta: metav1.ObjectMeta{
-					Namespace: memberUserNS,
-					Name:      svcName,
-					Annotations: map[string]string{
-						objectmeta.ServiceImportAnnotationServiceInUseBy: "xyz",
-					},
+					Namespace:  memberUserNS,
+					Name:       svcName,
+					Finalizers: []string{svcImportCleanupFinalizer},
 				},
 			},
-			svcInUseBy: fulfilledServiceInUseByAnnotation(),
 		},
 	}
 
@@ -573,13 +608,15 @@ func TestAnnotateServiceImportWithServiceInUseByInfo(t *testing.T) {
 			fakeHubClient := fake.NewClientBuilder().
 				WithScheme(scheme.Scheme).
 				WithObjects(tc.svcImport).
+				WithStatusSubresource(tc.svcImport).
 				Build()
 			reconciler := Reconciler{
 				HubClient: fakeHubClient,
+				Recorder:  record.NewFakeRecorder(10),
 			}
 
-			if err := reconciler.annotateServiceImportWithServiceInUseByInfo(ctx, tc.svcImport, tc.svcInUseBy); err != nil {
-				t.Fatalf("annotateServiceImportWithServiceInUseByInfo(%+v, %+v), got %v, want no error", tc.svcImport, tc.svcInUseBy, err)
+			if err := reconciler.claimServiceImport(ctx, tc.svcImport, hubNSForMemberA, clusterIDForMemberA); err != nil {
+				t.Fatalf("claimServiceImport(%+v), got %v, want no error", tc.svcImport, err)
 			}
 
 			svcImport := &fleetnetv1alpha1.ServiceImport{}
@@ -587,15 +624,44 @@ func TestAnnotateServiceImportWithServiceInUseByInfo(t *testing.T) {
 				t.Fatalf("serviceImport Get(%+v), got %v, want no error", svcImportKey, err)
 			}
 
-			wantSvcInUseByAnnotation := fulfilledSvcInUseByAnnotationString()
-			if !cmp.Equal(svcImport.Annotations[objectmeta.ServiceImportAnnotationServiceInUseBy], wantSvcInUseByAnnotation) {
-				t.Fatalf("serviceImport ServiceInUseBy annotation, got %s, want %s",
-					svcImport.Annotations[objectmeta.ServiceImportAnnotationServiceInUseBy], wantSvcInUseByAnnotation)
+			if !cmp.Equal(svcImport.Finalizers, []string{svcImportCleanupFinalizer}) {
+				t.Fatalf("serviceImport finalizers, got %v, want %v", svcImport.Finalizers, []string{svcImportCleanupFinalizer})
+			}
+			if diff := cmp.Diff(svcImport.Status.ImportedBy, fulfilledImportedBy(), ignoreImportedSince); diff != "" {
+				t.Fatalf("serviceImport importedBy mismatch (-got, +want)\n%s", diff)
 			}
 		})
 	}
 }
 
+// TestClaimServiceImport_Conflict tests the Reconciler.claimServiceImport method when the ServiceImport has been
+// claimed by another member cluster since it was read.
+func TestClaimServiceImport_Conflict(t *testing.T) {
+	ctx := context.Background()
+	fakeHubClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(fulfilledServiceImport()).
+		WithStatusSubresource(&fleetnetv1alpha1.ServiceImport{}).
+		Build()
+	reconciler := Reconciler{
+		HubClient: fakeHubClient,
+		Recorder:  record.NewFakeRecorder(10),
+	}
+
+	staleSvcImport := &fleetnetv1alpha1.ServiceImport{}
+	if err := fakeHubClient.Get(ctx, svcImportKey, staleSvcImport); err != nil {
+		t.Fatalf("serviceImport Get(%+v), got %v, want no error", svcImportKey, err)
+	}
+	// Another member cluster claims the ServiceImport.
+	if err := reconciler.claimServiceImport(ctx, staleSvcImport.DeepCopy(), hubNSForMemberC, clusterIDForMemberC); err != nil {
+		t.Fatalf("claimServiceImport(), got %v, want no error", err)
+	}
+
+	if err := reconciler.claimServiceImport(ctx, staleSvcImport, hubNSForMemberB, clusterIDForMemberB); !errors.IsConflict(err) {
+		t.Fatalf("claimServiceImport() with a stale ServiceImport, got %v, want a conflict", err)
+	}
+}
+
 // TestFulfillInternalServiceImport tests the Reconciler.fulfillInternalServiceImport method.
 func TestFulfillInternalServiceImport(t *testing.T) {
 	testCases := []struct {
@@ -626,6 +692,7 @@ func TestFulfillInternalServiceImport(t *testing.T) {
 				Build()
 			reconciler := Reconciler{
 				HubClient: fakeHubClient,
+				Recorder:  record.NewFakeRecorder(10),
 			}
 
 			if err := reconciler.fulfillInternalServiceImport(ctx, tc.svcImport, tc.internalSvcImport); err != nil {
@@ -637,23 +704,32 @@ func TestFulfillInternalServiceImport(t *testing.T) {
 				t.Fatalf("internalServiceImport Get(%+v), got %v, want no error", internalSvcImportAKey, err)
 			}
 
-			if diff := cmp.Diff(internalSvcImport.Status, tc.svcImport.Status); diff != "" {
+			wantStatus := tc.svcImport.Status.DeepCopy()
+			wantStatus.ImportedBy = nil
+			if diff := cmp.Diff(internalSvcImport.Status, *wantStatus); diff != "" {
 				t.Fatalf("internalServiceImport status mismatch (-got, +want)\n%s", diff)
 			}
 		})
 	}
 }
 
-// TestClearServiceInUseByInfoFromServiceImport tests the Reconciler.clearServiceInUseByInfoFromServiceImport method.
-func TestClearServiceInUseByInfoFromServiceImport(t *testing.T) {
+// TestReleaseServiceImport tests the Reconciler.releaseServiceImport method.
+func TestReleaseServiceImport(t *testing.T) {
+	orphanedSvcImport := fulfilledServiceImport()
+	orphanedSvcImport.Status.ImportedBy = nil
+
 	testCases := []struct {
 		name      string
 		svcImport *fleetnetv1alpha1.ServiceImport
 	}{
 		{
-			name:      "should clear service in use by info + cleanup finalizer",
+			name:      "should release service import + remove cleanup finalizer",
 			svcImport: fulfilledServiceImport(),
 		},
+		{
+			name:      "should remove cleanup finalizer (no imports)",
+			svcImport: orphanedSvcImport,
+		},
 	}
 
 	ctx := context.Background()
@@ -663,13 +739,15 @@ func TestClearServiceInUseByInfoFromServiceImport(t *testing.T) {
 			fakeHubClient := fake.NewClientBuilder().
 				WithScheme(scheme.Scheme).
 				WithObjects(tc.svcImport).
+				WithStatusSubresource(tc.svcImport).
 				Build()
 			reconciler := Reconciler{
 				HubClient: fakeHubClient,
+				Recorder:  record.NewFakeRecorder(10),
 			}
 
-			if err := reconciler.clearServiceInUseByInfoFromServiceImport(ctx, tc.svcImport); err != nil {
-				t.Fatalf("clearServiceInUseByInfoFromServiceImport(%+v), got %v, want no error", tc.svcImport, err)
+			if err := reconciler.releaseServiceImport(ctx, tc.svcImport, hubNSForMemberA); err != nil {
+				t.Fatalf("releaseServiceImport(%+v), got %v, want no error", tc.svcImport, err)
 			}
 
 			svcImport := &fleetnetv1alpha1.ServiceImport{}
@@ -680,10 +758,493 @@ func TestClearServiceInUseByInfoFromServiceImport(t *testing.T) {
 			if len(svcImport.Finalizers) != 0 {
 				t.Fatalf("serviceImport finalizers, got %v, want no finalizers", svcImport.Finalizers)
 			}
+			if len(svcImport.Status.ImportedBy) != 0 {
+				t.Fatalf("serviceImport importedBy, got %v, want no imports", svcImport.Status.ImportedBy)
+			}
+		})
+	}
+}
+
+// TestFulfillInternalServiceImport_ClusterSelector tests the Reconciler.fulfillInternalServiceImport method with
+// a cluster selector.
+func TestFulfillInternalServiceImport_ClusterSelector(t *testing.T) {
+	svcImport := fulfilledServiceImport()
+	internalSvcImport := &fleetnetv1alpha1.InternalServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: hubNSForMemberA,
+			Name:      internalSvcImportName,
+		},
+		Spec: fleetnetv1alpha1.InternalServiceImportSpec{
+			ClusterSelector: &fleetnetv1alpha1.ClusterSelector{
+				ClusterNames: []string{clusterIDForMemberB},
+			},
+		},
+	}
+
+	ctx := context.Background()
+	fakeHubClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(internalSvcImport).
+		WithStatusSubresource(internalSvcImport).
+		Build()
+	reconciler := Reconciler{
+		HubClient: fakeHubClient,
+		Recorder:  record.NewFakeRecorder(10),
+	}
+
+	if err := reconciler.fulfillInternalServiceImport(ctx, svcImport, internalSvcImport); err != nil {
+		t.Fatalf("fulfillInternalServiceImport(%+v, %+v), got %v, want no error", svcImport, internalSvcImport, err)
+	}
+
+	gotInternalSvcImport := &fleetnetv1alpha1.InternalServiceImport{}
+	if err := fakeHubClient.Get(ctx, internalSvcImportAKey, gotInternalSvcImport); err != nil {
+		t.Fatalf("internalServiceImport Get(%+v), got %v, want no error", internalSvcImportAKey, err)
+	}
+
+	wantStatus := svcImport.Status.DeepCopy()
+	wantStatus.Clusters = []fleetnetv1alpha1.ClusterStatus{{Cluster: clusterIDForMemberB}}
+	wantStatus.ImportedBy = nil
+	if diff := cmp.Diff(*wantStatus, gotInternalSvcImport.Status); diff != "" {
+		t.Fatalf("internalServiceImport status mismatch (-want, +got)\n%s", diff)
+	}
+}
+
+// TestFulfillInternalServiceImport_NoSelectedClusters tests the Reconciler.fulfillInternalServiceImport method with
+// a cluster selector which selects none of the exporting clusters.
+func TestFulfillInternalServiceImport_NoSelectedClusters(t *testing.T) {
+	svcImport := fulfilledServiceImport()
+	svcImport.Status.Conditions = []metav1.Condition{
+		{
+			Type:    string(fleetnetv1alpha1.ServiceImportReady),
+			Status:  metav1.ConditionTrue,
+			Reason:  "ServiceResolved",
+			Message: "service spec is resolved; 3 of 3 exporting cluster(s) are accepted",
+		},
+	}
+	internalSvcImport := &fleetnetv1alpha1.InternalServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: hubNSForMemberA,
+			Name:      internalSvcImportName,
+		},
+		Spec: fleetnetv1alpha1.InternalServiceImportSpec{
+			ClusterSelector: &fleetnetv1alpha1.ClusterSelector{
+				ClusterNames: []string{"unknown"},
+			},
+		},
+	}
+
+	ctx := context.Background()
+	fakeHubClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(internalSvcImport).
+		WithStatusSubresource(internalSvcImport).
+		Build()
+	reconciler := Reconciler{
+		HubClient: fakeHubClient,
+		Recorder:  record.NewFakeRecorder(10),
+	}
+
+	if err := reconciler.fulfillInternalServiceImport(ctx, svcImport, internalSvcImport); err != nil {
+		t.Fatalf("fulfillInternalServiceImport(%+v, %+v), got %v, want no error", svcImport, internalSvcImport, err)
+	}
+	gotInternalSvcImport := &fleetnetv1alpha1.InternalServiceImport{}
+	if err := fakeHubClient.Get(ctx, internalSvcImportAKey, gotInternalSvcImport); err != nil {
+		t.Fatalf("internalServiceImport Get(%+v), got %v, want no error", internalSvcImportAKey, err)
+	}
+	resourceVersion := gotInternalSvcImport.ResourceVersion
+
+	// The state is stable; fulfilling the import again does not update the InternalServiceImport.
+	if err := reconciler.fulfillInternalServiceImport(ctx, svcImport, gotInternalSvcImport); err != nil {
+		t.Fatalf("fulfillInternalServiceImport() again, got %v, want no error", err)
+	}
+	if err := fakeHubClient.Get(ctx, internalSvcImportAKey, gotInternalSvcImport); err != nil {
+		t.Fatalf("internalServiceImport Get(%+v), got %v, want no error", internalSvcImportAKey, err)
+	}
+	if gotInternalSvcImport.ResourceVersion != resourceVersion {
+		t.Errorf("internalServiceImport resourceVersion, got %s, want %s", gotInternalSvcImport.ResourceVersion, resourceVersion)
+	}
+
+	wantStatus := svcImport.Status.DeepCopy()
+	wantStatus.Clusters = nil
+	wantStatus.ImportedBy = nil
+	wantStatus.Conditions = []metav1.Condition{
+		{
+			Type:    string(fleetnetv1alpha1.ServiceImportReady),
+			Status:  metav1.ConditionFalse,
+			Reason:  conditionReasonNoSelectedExports,
+			Message: "none of the 3 accepted exporting cluster(s) is selected by the cluster selector",
+		},
+	}
+	if diff := cmp.Diff(*wantStatus, gotInternalSvcImport.Status, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime")); diff != "" {
+		t.Fatalf("internalServiceImport status mismatch (-want, +got)\n%s", diff)
+	}
+}
+
+// TestReconcile_NoAcceptedExports tests that an import of a Service without accepted exporting clusters is reported
+// as not ready, with the previously imported Service spec cleared.
+func TestReconcile_NoAcceptedExports(t *testing.T) {
+	svcImport := fulfilledServiceImport()
+	message := "service spec is not resolved; 0 of 1 exporting cluster(s) are accepted"
+	svcImport.Status = fleetnetv1alpha1.ServiceImportStatus{
+		Conditions: []metav1.Condition{
+			{
+				Type:    string(fleetnetv1alpha1.ServiceImportReady),
+				Status:  metav1.ConditionFalse,
+				Reason:  serviceimport.ConditionReasonNoAcceptedExports,
+				Message: message,
+			},
+		},
+		ImportedBy: fulfilledImportedBy(),
+	}
+	internalSvcImport := &fleetnetv1alpha1.InternalServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  hubNSForMemberA,
+			Name:       internalSvcImportName,
+			Finalizers: []string{internalSvcImportCleanupFinalizer},
+		},
+		Spec: fleetnetv1alpha1.InternalServiceImportSpec{
+			ServiceImportReference: fleetnetv1alpha1.ExportedObjectReference{
+				ClusterID: clusterIDForMemberA,
+				Namespace: memberUserNS,
+				Name:      svcName,
+			},
+		},
+		Status: fulfilledServiceImport().Status,
+	}
+
+	ctx := context.Background()
+	fakeHubClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(svcImport, internalSvcImport).
+		WithStatusSubresource(svcImport, internalSvcImport).
+		Build()
+	reconciler := Reconciler{
+		HubClient: fakeHubClient,
+		Recorder:  record.NewFakeRecorder(10),
+	}
+
+	want := ctrl.Result{RequeueAfter: internalSvcImportRetryInterval}
+	if res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: internalSvcImportAKey}); !cmp.Equal(res, want) || err != nil {
+		t.Fatalf("Reconcile() = %+v, %v, want %v, no error", res, err, want)
+	}
+
+	gotInternalSvcImport := &fleetnetv1alpha1.InternalServiceImport{}
+	if err := fakeHubClient.Get(ctx, internalSvcImportAKey, gotInternalSvcImport); err != nil {
+		t.Fatalf("internalServiceImport Get(%+v), got %v, want no error", internalSvcImportAKey, err)
+	}
+	wantStatus := fleetnetv1alpha1.ServiceImportStatus{
+		Conditions: []metav1.Condition{
+			{
+				Type:    string(fleetnetv1alpha1.ServiceImportReady),
+				Status:  metav1.ConditionFalse,
+				Reason:  serviceimport.ConditionReasonNoAcceptedExports,
+				Message: message,
+			},
+		},
+	}
+	if diff := cmp.Diff(wantStatus, gotInternalSvcImport.Status, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime")); diff != "" {
+		t.Fatalf("internalServiceImport status (-want, +got):\n%s", diff)
+	}
+}
+
+// TestRejectInternalServiceImport tests the Reconciler.rejectInternalServiceImport method.
+func TestRejectInternalServiceImport(t *testing.T) {
+	svcImport := fulfilledServiceImport()
+	internalSvcImport := &fleetnetv1alpha1.InternalServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  hubNSForMemberA,
+			Name:       internalSvcImportName,
+			Finalizers: []string{internalSvcImportCleanupFinalizer},
+		},
+		Status: svcImport.Status,
+	}
+	message := "member cluster 0 is not allowed to import service work/app by serviceExportImportPolicy tenant"
+
+	ctx := context.Background()
+	fakeHubClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(svcImport, internalSvcImport).
+		WithStatusSubresource(svcImport, internalSvcImport).
+		Build()
+	recorder := record.NewFakeRecorder(10)
+	reconciler := Reconciler{
+		HubClient: fakeHubClient,
+		Recorder:  recorder,
+	}
+
+	if res, err := reconciler.rejectInternalServiceImport(ctx, svcImport, internalSvcImport, message); !cmp.Equal(res, ctrl.Result{}) || err != nil {
+		t.Fatalf("rejectInternalServiceImport() = %+v, %v, want %v, no error", res, err, ctrl.Result{})
+	}
+	// The rejection is reported once; rejecting the import again does not emit the event again.
+	if res, err := reconciler.rejectInternalServiceImport(ctx, svcImport, internalSvcImport, message); !cmp.Equal(res, ctrl.Result{}) || err != nil {
+		t.Fatalf("rejectInternalServiceImport() again = %+v, %v, want %v, no error", res, err, ctrl.Result{})
+	}
+	wantEvents := []string{
+		fmt.Sprintf("Normal Released Member cluster %s released the import of the Service", clusterIDForMemberA),
+		fmt.Sprintf("Warning ClaimRejected The import of Service %s/%s is not allowed: %s", memberUserNS, svcName, message),
+	}
+	if diff := cmp.Diff(wantEvents, drainEvents(recorder)); diff != "" {
+		t.Errorf("events mismatch (-want, +got):\n%s", diff)
+	}
+
+	gotSvcImport := &fleetnetv1alpha1.ServiceImport{}
+	if err := fakeHubClient.Get(ctx, svcImportKey, gotSvcImport); err != nil {
+		t.Fatalf("serviceImport Get(%+v), got %v, want no error", svcImportKey, err)
+	}
+	if len(gotSvcImport.Status.ImportedBy) != 0 {
+		t.Fatalf("serviceImport importedBy, got %v, want no imports", gotSvcImport.Status.ImportedBy)
+	}
+
+	gotInternalSvcImport := &fleetnetv1alpha1.InternalServiceImport{}
+	if err := fakeHubClient.Get(ctx, internalSvcImportAKey, gotInternalSvcImport); err != nil {
+		t.Fatalf("internalServiceImport Get(%+v), got %v, want no error", internalSvcImportAKey, err)
+	}
+	if len(gotInternalSvcImport.Finalizers) != 0 {
+		t.Fatalf("internalServiceImport finalizers, got %v, want no finalizer", gotInternalSvcImport.Finalizers)
+	}
+	wantStatus := fleetnetv1alpha1.ServiceImportStatus{
+		Conditions: []metav1.Condition{
+			{
+				Type:    string(fleetnetv1alpha1.ServiceImportReady),
+				Status:  metav1.ConditionFalse,
+				Reason:  conditionReasonImportNotAllowed,
+				Message: message,
+			},
+		},
+	}
+	if diff := cmp.Diff(wantStatus, gotInternalSvcImport.Status, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime")); diff != "" {
+		t.Fatalf("internalServiceImport status (-want, +got):\n%s", diff)
+	}
+}
+
+// TestReconcile_AlreadyImported tests that an import of a Service which has been imported by another member cluster
+// is reported as not ready, and the conflict is recorded only once.
+func TestReconcile_AlreadyImported(t *testing.T) {
+	svcImport := fulfilledServiceImport()
+	internalSvcImport := &fleetnetv1alpha1.InternalServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  hubNSForMemberB,
+			Name:       internalSvcImportName,
+			Finalizers: []string{internalSvcImportCleanupFinalizer},
+		},
+		Spec: fleetnetv1alpha1.InternalServiceImportSpec{
+			ServiceImportReference: fleetnetv1alpha1.ExportedObjectReference{
+				ClusterID: clusterIDForMemberB,
+				Namespace: memberUserNS,
+				Name:      svcName,
+			},
+		},
+		Status: svcImport.Status,
+	}
+
+	ctx := context.Background()
+	fakeHubClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(svcImport, internalSvcImport).
+		WithStatusSubresource(svcImport, internalSvcImport).
+		Build()
+	recorder := record.NewFakeRecorder(10)
+	reconciler := Reconciler{
+		HubClient: fakeHubClient,
+		Recorder:  recorder,
+	}
+
+	req := ctrl.Request{NamespacedName: internalSvcImportBKey}
+	for i := 0; i < 2; i++ {
+		if res, err := reconciler.Reconcile(ctx, req); !cmp.Equal(res, ctrl.Result{}) || err != nil {
+			t.Fatalf("Reconcile() = %+v, %v, want %v, no error", res, err, ctrl.Result{})
+		}
+	}
+
+	gotInternalSvcImport := &fleetnetv1alpha1.InternalServiceImport{}
+	if err := fakeHubClient.Get(ctx, internalSvcImportBKey, gotInternalSvcImport); err != nil {
+		t.Fatalf("internalServiceImport Get(%+v), got %v, want no error", internalSvcImportBKey, err)
+	}
+	if len(gotInternalSvcImport.Finalizers) != 0 {
+		t.Fatalf("internalServiceImport finalizers, got %v, want no finalizer", gotInternalSvcImport.Finalizers)
+	}
+	message := fmt.Sprintf("Service %s has already been imported by member cluster %s", svcImportKey, clusterIDForMemberA)
+	wantStatus := fleetnetv1alpha1.ServiceImportStatus{
+		Conditions: []metav1.Condition{
+			{
+				Type:    string(fleetnetv1alpha1.ServiceImportReady),
+				Status:  metav1.ConditionFalse,
+				Reason:  conditionReasonAlreadyImported,
+				Message: message,
+			},
+		},
+	}
+	if diff := cmp.Diff(wantStatus, gotInternalSvcImport.Status, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime")); diff != "" {
+		t.Fatalf("internalServiceImport status (-want, +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"Warning Conflict " + message}, drainEvents(recorder)); diff != "" {
+		t.Errorf("events mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+// drainEvents returns the events recorded so far.
+func drainEvents(recorder *record.FakeRecorder) []string {
+	var got []string
+	for {
+		select {
+		case e := <-recorder.Events:
+			got = append(got, e)
+		default:
+			return got
+		}
+	}
+}
+
+// TestMigrateServiceInUseByAnnotations tests the MigrateServiceInUseByAnnotations function.
+func TestMigrateServiceInUseByAnnotations(t *testing.T) {
+	svcImportForTest := func(name, svcInUseByData string, importedBy []fleetnetv1alpha1.ClusterImportStatus) *fleetnetv1alpha1.ServiceImport {
+		svcImport := &fleetnetv1alpha1.ServiceImport{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: memberUserNS,
+				Name:      name,
+			},
+			Status: fleetnetv1alpha1.ServiceImportStatus{
+				ImportedBy: importedBy,
+			},
+		}
+		if svcInUseByData != "" {
+			svcImport.Annotations = map[string]string{
+				objectmeta.ServiceImportAnnotationServiceInUseBy: svcInUseByData,
+			}
+		}
+		return svcImport
+	}
+	svcInUseByData, err := json.Marshal(&fleetnetv1alpha1.ServiceInUseBy{
+		MemberClusters: map[fleetnetv1alpha1.ClusterNamespace]fleetnetv1alpha1.ClusterID{
+			hubNSForMemberA: clusterIDForMemberA,
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal ServiceInUseBy: %v", err)
+	}
+
+	testCases := []struct {
+		name           string
+		svcImport      *fleetnetv1alpha1.ServiceImport
+		wantImportedBy []fleetnetv1alpha1.ClusterImportStatus
+	}{
+		{
+			name:           "should migrate the annotation",
+			svcImport:      svcImportForTest("migrate", string(svcInUseByData), nil),
+			wantImportedBy: fulfilledImportedBy(),
+		},
+		{
+			name:           "should keep the imports already in status",
+			svcImport:      svcImportForTest("migrated", string(svcInUseByData), fulfilledImportedBy()),
+			wantImportedBy: fulfilledImportedBy(),
+		},
+		{
+			name:      "should drop the corrupted annotation",
+			svcImport: svcImportForTest("corrupted", "xyz", nil),
+		},
+		{
+			name:           "should skip the service import without the annotation",
+			svcImport:      svcImportForTest("skip", "", fulfilledImportedBy()),
+			wantImportedBy: fulfilledImportedBy(),
+		},
+	}
+
+	ctx := context.Background()
+	fakeHubClientBuilder := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithStatusSubresource(&fleetnetv1alpha1.ServiceImport{})
+	for _, tc := range testCases {
+		fakeHubClientBuilder = fakeHubClientBuilder.WithObjects(tc.svcImport)
+	}
+	fakeHubClient := fakeHubClientBuilder.Build()
+
+	if err := MigrateServiceInUseByAnnotations(ctx, fakeHubClient); err != nil {
+		t.Fatalf("MigrateServiceInUseByAnnotations(), got %v, want no error", err)
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svcImport := &fleetnetv1alpha1.ServiceImport{}
+			key := types.NamespacedName{Namespace: tc.svcImport.Namespace, Name: tc.svcImport.Name}
+			if err := fakeHubClient.Get(ctx, key, svcImport); err != nil {
+				t.Fatalf("serviceImport Get(%+v), got %v, want no error", key, err)
+			}
 
 			if _, ok := svcImport.Annotations[objectmeta.ServiceImportAnnotationServiceInUseBy]; ok {
-				t.Fatalf("serviceImport ServiceInUseBy annotation is present")
+				t.Fatalf("serviceInUseBy annotation is present, want absence")
+			}
+			if diff := cmp.Diff(svcImport.Status.ImportedBy, tc.wantImportedBy, ignoreImportedSince); diff != "" {
+				t.Fatalf("serviceImport importedBy mismatch (-got, +want)\n%s", diff)
 			}
 		})
 	}
 }
+
+// TestMigrator tests that the InternalServiceImports are reconciled only after the migration completes.
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+	fakeHubClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	migrator := NewMigrator(fakeHubClient)
+	reconciler := Reconciler{
+		HubClient:     fakeHubClient,
+		Recorder:      record.NewFakeRecorder(10),
+		MigrationDone: migrator.Done(),
+	}
+	req := ctrl.Request{NamespacedName: internalSvcImportAKey}
+
+	res, err := reconciler.Reconcile(ctx, req)
+	if err != nil {
+		t.Fatalf("Reconcile() before migration, got %v, want no error", err)
+	}
+	if want := (ctrl.Result{RequeueAfter: internalSvcImportRetryInterval}); !cmp.Equal(res, want) {
+		t.Fatalf("Reconcile() before migration, got %+v, want %+v", res, want)
+	}
+
+	if !migrator.NeedLeaderElection() {
+		t.Fatalf("NeedLeaderElection(), got false, want true")
+	}
+	if err := migrator.Start(ctx); err != nil {
+		t.Fatalf("Start(), got %v, want no error", err)
+	}
+	res, err = reconciler.Reconcile(ctx, req)
+	if err != nil {
+		t.Fatalf("Reconcile() after migration, got %v, want no error", err)
+	}
+	if !cmp.Equal(res, ctrl.Result{}) {
+		t.Fatalf("Reconcile() after migration, got %+v, want %+v", res, ctrl.Result{})
+	}
+}
+
+// TestMemberClusterLabelChangedPredicate tests that only the MemberCluster events which may change the selected
+// clusters are handled.
+func TestMemberClusterLabelChangedPredicate(t *testing.T) {
+	memberCluster := &clusterv1beta1.MemberCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "member-1",
+			Labels: map[string]string{"region": "eastus"},
+		},
+	}
+	statusUpdated := memberCluster.DeepCopy()
+	statusUpdated.Status.Conditions = []metav1.Condition{
+		{
+			Type:   string(clusterv1beta1.ConditionTypeMemberClusterJoined),
+			Status: metav1.ConditionTrue,
+			Reason: "Joined",
+		},
+	}
+	labelsUpdated := memberCluster.DeepCopy()
+	labelsUpdated.Labels["region"] = "westus"
+
+	if memberClusterLabelChangedPredicate.Update(event.UpdateEvent{ObjectOld: memberCluster, ObjectNew: statusUpdated}) {
+		t.Error("Update() = true for a status-only update, want false")
+	}
+	if !memberClusterLabelChangedPredicate.Update(event.UpdateEvent{ObjectOld: memberCluster, ObjectNew: labelsUpdated}) {
+		t.Error("Update() = false for a label update, want true")
+	}
+	if !memberClusterLabelChangedPredicate.Create(event.CreateEvent{Object: memberCluster}) {
+		t.Error("Create() = false, want true")
+	}
+	if !memberClusterLabelChangedPredicate.Delete(event.DeleteEvent{Object: memberCluster}) {
+		t.Error("Delete() = false, want true")
+	}
+}
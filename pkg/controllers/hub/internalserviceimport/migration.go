@@ -0,0 +1,133 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package internalserviceimport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+// Migrator migrates the deprecated ServiceInUseBy annotations on ServiceImports once the manager is elected as the
+// leader; see MigrateServiceInUseByAnnotations.
+//
+// The InternalServiceImport controller waits for the migration to complete, so that an imported Service cannot be
+// claimed by another member cluster in the meantime.
+type Migrator struct {
+	HubClient client.Client
+
+	done chan struct{}
+}
+
+// NewMigrator returns a Migrator which migrates the ServiceImports with the given client.
+func NewMigrator(hubClient client.Client) *Migrator {
+	return &Migrator{
+		HubClient: hubClient,
+		done:      make(chan struct{}),
+	}
+}
+
+// Start runs the migration once; an error stops the manager.
+func (m *Migrator) Start(ctx context.Context) error {
+	klog.V(2).InfoS("Starting to migrate the ServiceInUseBy annotations")
+	if err := MigrateServiceInUseByAnnotations(ctx, m.HubClient); err != nil {
+		return err
+	}
+	klog.V(2).InfoS("Migrated the ServiceInUseBy annotations")
+	close(m.done)
+	return nil
+}
+
+// NeedLeaderElection implements the LeaderElectionRunnable interface, so that only the leader migrates.
+func (m *Migrator) NeedLeaderElection() bool {
+	return true
+}
+
+// Done returns a channel which is closed once the migration completes.
+func (m *Migrator) Done() <-chan struct{} {
+	return m.done
+}
+
+// MigrateServiceInUseByAnnotations moves the importing member clusters kept in the deprecated ServiceInUseBy
+// annotations on ServiceImports to the ServiceImport status, and removes the annotations.
+//
+// The migration is idempotent; it should complete before any InternalServiceImport is reconciled.
+func MigrateServiceInUseByAnnotations(ctx context.Context, hubClient client.Client) error {
+	svcImportList := &fleetnetv1alpha1.ServiceImportList{}
+	if err := hubClient.List(ctx, svcImportList); err != nil {
+		return fmt.Errorf("failed to list ServiceImports: %w", err)
+	}
+
+	for idx := range svcImportList.Items {
+		svcImport := &svcImportList.Items[idx]
+		if _, ok := svcImport.Annotations[objectmeta.ServiceImportAnnotationServiceInUseBy]; !ok {
+			continue
+		}
+		svcImportKey := types.NamespacedName{Namespace: svcImport.Namespace, Name: svcImport.Name}
+		if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			return migrateServiceInUseByAnnotation(ctx, hubClient, svcImportKey)
+		}); err != nil {
+			return fmt.Errorf("failed to migrate the ServiceInUseBy annotation on ServiceImport %s: %w", svcImportKey, err)
+		}
+		klog.V(2).InfoS("Migrated the ServiceInUseBy annotation to the ServiceImport status", "serviceImport", klog.KObj(svcImport))
+	}
+	return nil
+}
+
+// migrateServiceInUseByAnnotation migrates the ServiceInUseBy annotation on a ServiceImport. The status is updated
+// before the annotation is removed, so that an interrupted migration can be resumed.
+func migrateServiceInUseByAnnotation(ctx context.Context, hubClient client.Client, svcImportKey types.NamespacedName) error {
+	svcImport := &fleetnetv1alpha1.ServiceImport{}
+	if err := hubClient.Get(ctx, svcImportKey, svcImport); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	data, ok := svcImport.Annotations[objectmeta.ServiceImportAnnotationServiceInUseBy]
+	if !ok {
+		return nil
+	}
+
+	svcInUseBy := &fleetnetv1alpha1.ServiceInUseBy{}
+	if err := json.Unmarshal([]byte(data), svcInUseBy); err != nil {
+		// The data cannot be unmarshalled, which is usually caused by data corruption; the annotation is dropped,
+		// and the member clusters will claim the Service again.
+		klog.ErrorS(err, "Failed to unmarshal ServiceInUseBy data; dropping the annotation", "serviceImport", klog.KObj(svcImport), "data", data)
+	} else {
+		importedSince := metav1.Now()
+		migrated := false
+		for clusterNamespace, clusterID := range svcInUseBy.MemberClusters {
+			if findClusterImport(svcImport, clusterNamespace) >= 0 {
+				continue
+			}
+			svcImport.Status.ImportedBy = append(svcImport.Status.ImportedBy, fleetnetv1alpha1.ClusterImportStatus{
+				ClusterNamespace: clusterNamespace,
+				Cluster:          clusterID,
+				ImportedSince:    importedSince,
+			})
+			migrated = true
+		}
+		if migrated {
+			sort.Slice(svcImport.Status.ImportedBy, func(i, j int) bool {
+				return svcImport.Status.ImportedBy[i].ClusterNamespace < svcImport.Status.ImportedBy[j].ClusterNamespace
+			})
+			if err := hubClient.Status().Update(ctx, svcImport); err != nil {
+				return err
+			}
+		}
+	}
+
+	delete(svcImport.Annotations, objectmeta.ServiceImportAnnotationServiceInUseBy)
+	return hubClient.Update(ctx, svcImport)
+}
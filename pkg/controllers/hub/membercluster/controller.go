@@ -4,34 +4,53 @@ Licensed under the MIT license.
 */
 
 // Package membercluster features the MemberCluster controller for watching
-// update/delete events to the MemberCluster object and removes finalizers
-// on all fleet networking resources in the fleet member cluster namespace.
+// update/delete events to the MemberCluster object, which withdraws the
+// contribution of a force deleted member cluster to the fleet and removes
+// finalizers on all fleet networking resources in the fleet member cluster namespace.
 package membercluster
 
 import (
 	"context"
 	"fmt"
+	"slices"
 	"time"
 
 	"golang.org/x/sync/errgroup"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	clusterv1beta1 "go.goms.io/fleet/apis/cluster/v1beta1"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/events"
 	"go.goms.io/fleet-networking/pkg/common/hubconfig"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/controllers/hub/serviceimport"
 )
 
 const (
 	ControllerName = "membercluster-controller"
 )
 
+// withdrawalSummary counts the networking resources of a member cluster withdrawn from the fleet.
+type withdrawalSummary struct {
+	exportedServices       int
+	importedServices       int
+	exportedEndpointSlices int
+}
+
 // Reconciler reconciles a MemberCluster object.
 type Reconciler struct {
 	client.Client
@@ -40,8 +59,17 @@ type Reconciler struct {
 	ForceDeleteWaitTime time.Duration
 }
 
-// Reconcile watches the deletion of the member cluster and removes finalizers on fleet networking resources in the
-// member cluster namespace.
+//+kubebuilder:rbac:groups=cluster.kubernetes-fleet.io,resources=memberclusters,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalserviceexports,verbs=get;list;watch;update
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalserviceimports,verbs=get;list;watch;update
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=endpointsliceexports,verbs=get;list;watch;update
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=endpointsliceimports,verbs=get;list;watch;update;delete
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports,verbs=get;list;watch;update
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Reconcile watches the deletion of the member cluster; once the force delete wait time has passed, it withdraws the
+// member cluster from the fleet and removes finalizers on fleet networking resources in the member cluster namespace.
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	mcObjRef := klog.KRef(req.Namespace, req.Name)
 	startTime := time.Now()
@@ -67,41 +95,221 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	// Handle deleting member cluster, removes finalizers on all the resources in the cluster namespace
 	// after member cluster force delete wait time.
 	if !mc.DeletionTimestamp.IsZero() && time.Since(mc.DeletionTimestamp.Time) >= r.ForceDeleteWaitTime {
-		klog.V(2).InfoS("The member cluster deletion is stuck removing the "+
+		klog.V(2).InfoS("The member cluster deletion is stuck withdrawing the member cluster and removing the "+
 			"finalizers from  all the resources in member cluster namespace", "memberCluster", mcObjRef)
-		return r.removeFinalizer(ctx, mc)
+		return r.forceCleanup(ctx, &mc)
 	}
 	// we need to only wait for force delete wait time, if the update/delete member cluster event takes
 	// longer to be reconciled we need to account for that time.
 	return ctrl.Result{RequeueAfter: r.ForceDeleteWaitTime - time.Since(mc.DeletionTimestamp.Time)}, nil
 }
 
-// removeFinalizer removes finalizers on the resources in the member cluster namespace.
-// For EndpointSliceExport, InternalServiceImport & InternalServiceExport resources, the finalizers should be
-// removed by other hub networking controllers when leaving. So this MemberCluster controller only handles
-// EndpointSliceImports here.
+// forceCleanup withdraws a force deleted member cluster from the fleet and then removes the finalizers on the
+// resources in the member cluster namespace; the outcome is recorded as an event on the member cluster.
+func (r *Reconciler) forceCleanup(ctx context.Context, mc *clusterv1beta1.MemberCluster) (ctrl.Result, error) {
+	summary, err := r.withdrawMemberCluster(ctx, mc)
+	if err != nil {
+		r.Recorder.Eventf(mc, corev1.EventTypeWarning, events.ReasonForceDeleteCleanupFailed,
+			"Failed to withdraw the networking resources of the member cluster from the fleet: %v", err)
+		return ctrl.Result{}, err
+	}
+	if _, err := r.removeFinalizer(ctx, *mc); err != nil {
+		r.Recorder.Eventf(mc, corev1.EventTypeWarning, events.ReasonForceDeleteCleanupFailed,
+			"Failed to remove the finalizers of the networking resources of the member cluster: %v", err)
+		return ctrl.Result{}, err
+	}
+	r.Recorder.Eventf(mc, corev1.EventTypeNormal, events.ReasonForceDeleteCleanupSucceeded,
+		"Withdrew %d exported service(s), %d imported service(s) and %d exported endpointSlice(s) of the member cluster from the fleet",
+		summary.exportedServices, summary.importedServices, summary.exportedEndpointSlices)
+	return ctrl.Result{}, nil
+}
+
+// withdrawMemberCluster unwinds the contribution of a member cluster to the fleet, which the other hub networking
+// controllers cannot complete on their own when the member agents are gone:
+//   - the member cluster is removed from the exporting clusters in the status of the ServiceImports it exports;
+//   - the member cluster is removed from the importing clusters in the status of the ServiceImports it imports; and
+//   - the EndpointSliceImports distributed from the EndpointSlices it exports are withdrawn from the other member
+//     clusters.
+func (r *Reconciler) withdrawMemberCluster(ctx context.Context, mc *clusterv1beta1.MemberCluster) (withdrawalSummary, error) {
+	mcObjRef := klog.KObj(mc)
+	mcNamespace := fmt.Sprintf(hubconfig.HubNamespaceNameFormat, mc.Name)
+	summary := withdrawalSummary{}
+
+	var internalServiceExportList fleetnetv1alpha1.InternalServiceExportList
+	if err := r.Client.List(ctx, &internalServiceExportList, client.InNamespace(mcNamespace)); err != nil {
+		klog.ErrorS(err, "Failed to list internalServiceExports", "memberCluster", mcObjRef)
+		return summary, err
+	}
+	for i := range internalServiceExportList.Items {
+		svcRef := internalServiceExportList.Items[i].Spec.ServiceReference
+		serviceImportKey := types.NamespacedName{Namespace: svcRef.Namespace, Name: svcRef.Name}
+		if err := r.updateServiceImportStatus(ctx, serviceImportKey, func(serviceImport *fleetnetv1alpha1.ServiceImport) {
+			serviceimport.RemoveExportingCluster(serviceImport, svcRef.ClusterID)
+		}); err != nil {
+			klog.ErrorS(err, "Failed to remove the exporting cluster from serviceImport", "memberCluster", mcObjRef, "serviceImport", serviceImportKey)
+			return summary, err
+		}
+		summary.exportedServices++
+	}
+
+	var internalServiceImportList fleetnetv1alpha1.InternalServiceImportList
+	if err := r.Client.List(ctx, &internalServiceImportList, client.InNamespace(mcNamespace)); err != nil {
+		klog.ErrorS(err, "Failed to list internalServiceImports", "memberCluster", mcObjRef)
+		return summary, err
+	}
+	for i := range internalServiceImportList.Items {
+		svcImportRef := internalServiceImportList.Items[i].Spec.ServiceImportReference
+		serviceImportKey := types.NamespacedName{Namespace: svcImportRef.Namespace, Name: svcImportRef.Name}
+		if err := r.updateServiceImportStatus(ctx, serviceImportKey, func(serviceImport *fleetnetv1alpha1.ServiceImport) {
+			removeImportingCluster(serviceImport, fleetnetv1alpha1.ClusterNamespace(mcNamespace))
+		}); err != nil {
+			klog.ErrorS(err, "Failed to remove the importing cluster from serviceImport", "memberCluster", mcObjRef, "serviceImport", serviceImportKey)
+			return summary, err
+		}
+		if err := r.removeServiceImportCleanupFinalizer(ctx, serviceImportKey); err != nil {
+			klog.ErrorS(err, "Failed to remove the cleanup finalizer from serviceImport", "memberCluster", mcObjRef, "serviceImport", serviceImportKey)
+			return summary, err
+		}
+		summary.importedServices++
+	}
+
+	var endpointSliceExportList fleetnetv1alpha1.EndpointSliceExportList
+	if err := r.Client.List(ctx, &endpointSliceExportList, client.InNamespace(mcNamespace)); err != nil {
+		klog.ErrorS(err, "Failed to list endpointSliceExports", "memberCluster", mcObjRef)
+		return summary, err
+	}
+	if len(endpointSliceExportList.Items) == 0 {
+		return summary, nil
+	}
+	// An EndpointSliceImport has the same fleet-scoped unique name as the EndpointSliceExport it is distributed from.
+	exportedEndpointSlices := sets.New[string]()
+	for i := range endpointSliceExportList.Items {
+		exportedEndpointSlices.Insert(endpointSliceExportList.Items[i].Name)
+	}
+	summary.exportedEndpointSlices = exportedEndpointSlices.Len()
+	var endpointSliceImportList fleetnetv1alpha1.EndpointSliceImportList
+	if err := r.Client.List(ctx, &endpointSliceImportList); err != nil {
+		klog.ErrorS(err, "Failed to list endpointSliceImports", "memberCluster", mcObjRef)
+		return summary, err
+	}
+	for i := range endpointSliceImportList.Items {
+		esi := &endpointSliceImportList.Items[i]
+		if esi.Namespace == mcNamespace || !exportedEndpointSlices.Has(esi.Name) {
+			continue
+		}
+		if err := r.Client.Delete(ctx, esi); err != nil && !errors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to withdraw endpointSliceImport", "memberCluster", mcObjRef, "endpointSliceImport", klog.KObj(esi))
+			return summary, err
+		}
+		klog.V(2).InfoS("Withdrew endpointSliceImport", "memberCluster", mcObjRef, "endpointSliceImport", klog.KObj(esi))
+	}
+	return summary, nil
+}
+
+// updateServiceImportStatus applies a change to the status of a serviceImport, retrying on conflicts; it is a no-op
+// if the serviceImport does not exist or the change does not modify the status.
+func (r *Reconciler) updateServiceImportStatus(ctx context.Context, key types.NamespacedName, mutate func(*fleetnetv1alpha1.ServiceImport)) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		serviceImport := &fleetnetv1alpha1.ServiceImport{}
+		if err := r.Client.Get(ctx, key, serviceImport); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		oldStatus := serviceImport.Status.DeepCopy()
+		mutate(serviceImport)
+		if equality.Semantic.DeepEqual(oldStatus, &serviceImport.Status) {
+			return nil
+		}
+		return r.Client.Status().Update(ctx, serviceImport)
+	})
+}
+
+// removeServiceImportCleanupFinalizer removes the cleanup finalizer from a serviceImport which is no longer imported
+// by any member cluster.
+func (r *Reconciler) removeServiceImportCleanupFinalizer(ctx context.Context, key types.NamespacedName) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		serviceImport := &fleetnetv1alpha1.ServiceImport{}
+		if err := r.Client.Get(ctx, key, serviceImport); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if len(serviceImport.Status.ImportedBy) != 0 || !controllerutil.ContainsFinalizer(serviceImport, objectmeta.ServiceImportCleanupFinalizer) {
+			return nil
+		}
+		controllerutil.RemoveFinalizer(serviceImport, objectmeta.ServiceImportCleanupFinalizer)
+		return r.Client.Update(ctx, serviceImport)
+	})
+}
+
+// removeImportingCluster removes a member cluster from the importing clusters in the status of a serviceImport.
+func removeImportingCluster(serviceImport *fleetnetv1alpha1.ServiceImport, clusterNamespace fleetnetv1alpha1.ClusterNamespace) {
+	serviceImport.Status.ImportedBy = slices.DeleteFunc(serviceImport.Status.ImportedBy, func(c fleetnetv1alpha1.ClusterImportStatus) bool {
+		return c.ClusterNamespace == clusterNamespace
+	})
+	if len(serviceImport.Status.ImportedBy) == 0 {
+		serviceImport.Status.ImportedBy = nil
+	}
+}
+
+// removeFinalizer removes finalizers on the networking resources in the member cluster namespace, i.e.
+// EndpointSliceImports, EndpointSliceExports, InternalServiceImports and InternalServiceExports; it must be called
+// after the member cluster has been withdrawn from the fleet, as the finalizers guard the withdrawal.
 func (r *Reconciler) removeFinalizer(ctx context.Context, mc clusterv1beta1.MemberCluster) (ctrl.Result, error) {
-	// Remove finalizer for EndpointSliceImport resources in the cluster namespace.
 	mcObjRef := klog.KRef(mc.Namespace, mc.Name)
 	mcNamespace := fmt.Sprintf(hubconfig.HubNamespaceNameFormat, mc.Name)
+
+	// The kind is tracked explicitly, as the type meta is not populated on the listed objects.
+	type finalizedObject struct {
+		kind string
+		obj  client.Object
+	}
+	var objs []finalizedObject
 	var endpointSliceImportList fleetnetv1alpha1.EndpointSliceImportList
 	if err := r.Client.List(ctx, &endpointSliceImportList, client.InNamespace(mcNamespace)); err != nil {
 		klog.ErrorS(err, "Failed to list endpointSliceImports", "memberCluster", mcObjRef)
 		return ctrl.Result{}, err
 	}
-	errs, ctx := errgroup.WithContext(ctx)
 	for i := range endpointSliceImportList.Items {
-		esi := &endpointSliceImportList.Items[i]
+		objs = append(objs, finalizedObject{kind: "EndpointSliceImport", obj: &endpointSliceImportList.Items[i]})
+	}
+	var endpointSliceExportList fleetnetv1alpha1.EndpointSliceExportList
+	if err := r.Client.List(ctx, &endpointSliceExportList, client.InNamespace(mcNamespace)); err != nil {
+		klog.ErrorS(err, "Failed to list endpointSliceExports", "memberCluster", mcObjRef)
+		return ctrl.Result{}, err
+	}
+	for i := range endpointSliceExportList.Items {
+		objs = append(objs, finalizedObject{kind: "EndpointSliceExport", obj: &endpointSliceExportList.Items[i]})
+	}
+	var internalServiceImportList fleetnetv1alpha1.InternalServiceImportList
+	if err := r.Client.List(ctx, &internalServiceImportList, client.InNamespace(mcNamespace)); err != nil {
+		klog.ErrorS(err, "Failed to list internalServiceImports", "memberCluster", mcObjRef)
+		return ctrl.Result{}, err
+	}
+	for i := range internalServiceImportList.Items {
+		objs = append(objs, finalizedObject{kind: "InternalServiceImport", obj: &internalServiceImportList.Items[i]})
+	}
+	var internalServiceExportList fleetnetv1alpha1.InternalServiceExportList
+	if err := r.Client.List(ctx, &internalServiceExportList, client.InNamespace(mcNamespace)); err != nil {
+		klog.ErrorS(err, "Failed to list internalServiceExports", "memberCluster", mcObjRef)
+		return ctrl.Result{}, err
+	}
+	for i := range internalServiceExportList.Items {
+		objs = append(objs, finalizedObject{kind: "InternalServiceExport", obj: &internalServiceExportList.Items[i]})
+	}
+
+	errs, ctx := errgroup.WithContext(ctx)
+	for i := range objs {
+		kind, obj := objs[i].kind, objs[i].obj
+		if len(obj.GetFinalizers()) == 0 {
+			continue
+		}
 		errs.Go(func() error {
-			esiObjRef := klog.KRef(esi.Namespace, esi.Name)
-			esi.SetFinalizers(nil)
-			if err := r.Client.Update(ctx, esi); err != nil {
-				klog.ErrorS(err, "Failed to remove finalizers for endpointSliceImport",
-					"memberCluster", mcObjRef, "endpointSliceImport", esiObjRef)
+			obj.SetFinalizers(nil)
+			if err := r.Client.Update(ctx, obj); err != nil {
+				klog.ErrorS(err, "Failed to remove finalizers",
+					"memberCluster", mcObjRef, "kind", kind, "object", klog.KObj(obj))
 				return err
 			}
-			klog.V(2).InfoS("Removed finalizers for endpointSliceImport",
-				"memberCluster", mcObjRef, "endpointSliceImport", esiObjRef)
+			klog.V(2).InfoS("Removed finalizers",
+				"memberCluster", mcObjRef, "kind", kind, "object", klog.KObj(obj))
 			return nil
 		})
 	}
@@ -128,5 +336,5 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&clusterv1beta1.MemberCluster{}).
 		WithEventFilter(customPredicate).
-		Complete(r)
+		Complete(metrics.InstrumentReconciler("membercluster", r))
 }
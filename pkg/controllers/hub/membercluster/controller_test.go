@@ -8,9 +8,12 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -18,6 +21,8 @@ import (
 	clusterv1beta1 "go.goms.io/fleet/apis/cluster/v1beta1"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/controllers/hub/serviceimport"
 )
 
 const (
@@ -105,6 +110,7 @@ func TestReconcile(t *testing.T) {
 
 			r := Reconciler{
 				Client:              errorFakeClient,
+				Recorder:            record.NewFakeRecorder(10),
 				ForceDeleteWaitTime: forceDeleteWaitTime,
 			}
 
@@ -181,6 +187,211 @@ func TestRemoveFinalizer(t *testing.T) {
 	}
 }
 
+func TestForceCleanup(t *testing.T) {
+	memberNamespace := "fleet-member-" + testMemberClusterName
+	otherMemberNamespace := "fleet-member-other-mc"
+	mc := &clusterv1beta1.MemberCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: testMemberClusterName,
+		},
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  otherMemberNamespace,
+			Name:       "app",
+			Finalizers: []string{objectmeta.ServiceImportCleanupFinalizer},
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Type:  fleetnetv1alpha1.ClusterSetIP,
+			Ports: []fleetnetv1alpha1.ServicePort{{Port: 80}},
+			Clusters: []fleetnetv1alpha1.ClusterStatus{
+				{Cluster: testMemberClusterName},
+				{Cluster: "other-mc"},
+			},
+			ImportedBy: []fleetnetv1alpha1.ClusterImportStatus{
+				{ClusterNamespace: fleetnetv1alpha1.ClusterNamespace(memberNamespace)},
+			},
+		},
+	}
+	internalSvcExport := &fleetnetv1alpha1.InternalServiceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  memberNamespace,
+			Name:       "work-app",
+			Finalizers: []string{"test-finalizer"},
+		},
+		Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+			ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+				ClusterID: testMemberClusterName,
+				Namespace: otherMemberNamespace,
+				Name:      "app",
+			},
+		},
+	}
+	internalSvcImport := &fleetnetv1alpha1.InternalServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  memberNamespace,
+			Name:       "work-app",
+			Finalizers: []string{"test-finalizer"},
+		},
+		Spec: fleetnetv1alpha1.InternalServiceImportSpec{
+			ServiceImportReference: fleetnetv1alpha1.ExportedObjectReference{
+				ClusterID: testMemberClusterName,
+				Namespace: otherMemberNamespace,
+				Name:      "app",
+			},
+		},
+	}
+	endpointSliceExport := &fleetnetv1alpha1.EndpointSliceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  memberNamespace,
+			Name:       "work-app-abcde",
+			Finalizers: []string{"test-finalizer"},
+		},
+	}
+	distributedESI := &fleetnetv1alpha1.EndpointSliceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: otherMemberNamespace,
+			Name:      "work-app-abcde",
+		},
+	}
+	unrelatedESI := &fleetnetv1alpha1.EndpointSliceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: otherMemberNamespace,
+			Name:      "work-app-fghij",
+		},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(testScheme(t)).
+		WithObjects(mc, serviceImport, internalSvcExport, internalSvcImport, endpointSliceExport, distributedESI, unrelatedESI).
+		WithStatusSubresource(serviceImport).
+		Build()
+	recorder := record.NewFakeRecorder(10)
+	r := Reconciler{
+		Client:   fakeClient,
+		Recorder: recorder,
+	}
+
+	ctx := context.Background()
+	if _, err := r.forceCleanup(ctx, mc); err != nil {
+		t.Fatalf("forceCleanup() = %v, want no error", err)
+	}
+
+	gotServiceImport := &fleetnetv1alpha1.ServiceImport{}
+	if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(serviceImport), gotServiceImport); err != nil {
+		t.Fatalf("serviceImport Get() = %v, want no error", err)
+	}
+	wantStatus := fleetnetv1alpha1.ServiceImportStatus{
+		Type:     fleetnetv1alpha1.ClusterSetIP,
+		Ports:    []fleetnetv1alpha1.ServicePort{{Port: 80}},
+		Clusters: []fleetnetv1alpha1.ClusterStatus{{Cluster: "other-mc"}},
+	}
+	if diff := cmp.Diff(wantStatus, gotServiceImport.Status); diff != "" {
+		t.Errorf("serviceImport status mismatch (-want, +got):\n%s", diff)
+	}
+	if len(gotServiceImport.Finalizers) != 0 {
+		t.Errorf("serviceImport finalizers = %v, want none", gotServiceImport.Finalizers)
+	}
+
+	if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(distributedESI), &fleetnetv1alpha1.EndpointSliceImport{}); !apierrors.IsNotFound(err) {
+		t.Errorf("distributed endpointSliceImport Get() = %v, want not found", err)
+	}
+	if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(unrelatedESI), &fleetnetv1alpha1.EndpointSliceImport{}); err != nil {
+		t.Errorf("unrelated endpointSliceImport Get() = %v, want no error", err)
+	}
+
+	for _, obj := range []client.Object{internalSvcExport, internalSvcImport, endpointSliceExport} {
+		if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+			t.Fatalf("%T Get() = %v, want no error", obj, err)
+		}
+		if len(obj.GetFinalizers()) != 0 {
+			t.Errorf("%T finalizers = %v, want none", obj, obj.GetFinalizers())
+		}
+	}
+
+	wantEvent := "Normal ForceDeleteCleanupSucceeded Withdrew 1 exported service(s), 1 imported service(s) and 1 exported endpointSlice(s) of the member cluster from the fleet"
+	select {
+	case gotEvent := <-recorder.Events:
+		if gotEvent != wantEvent {
+			t.Errorf("event = %q, want %q", gotEvent, wantEvent)
+		}
+	default:
+		t.Errorf("no event is recorded, want %q", wantEvent)
+	}
+}
+
+// TestWithdrawMemberCluster_LastExportingCluster tests that a serviceImport is reported as not ready once its last
+// exporting cluster is withdrawn from the fleet.
+func TestWithdrawMemberCluster_LastExportingCluster(t *testing.T) {
+	memberNamespace := "fleet-member-" + testMemberClusterName
+	otherMemberNamespace := "fleet-member-other-mc"
+	mc := &clusterv1beta1.MemberCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: testMemberClusterName,
+		},
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: otherMemberNamespace,
+			Name:      "app",
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Type:           fleetnetv1alpha1.ClusterSetIP,
+			Ports:          []fleetnetv1alpha1.ServicePort{{Port: 80}},
+			IPs:            []string{"10.0.0.1"},
+			Clusters:       []fleetnetv1alpha1.ClusterStatus{{Cluster: testMemberClusterName}},
+			ClusterExports: []fleetnetv1alpha1.ClusterExportStatus{{Cluster: testMemberClusterName}},
+			ImportedBy:     []fleetnetv1alpha1.ClusterImportStatus{{ClusterNamespace: fleetnetv1alpha1.ClusterNamespace(otherMemberNamespace)}},
+		},
+	}
+	internalSvcExport := &fleetnetv1alpha1.InternalServiceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: memberNamespace,
+			Name:      "work-app",
+		},
+		Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+			ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+				ClusterID: testMemberClusterName,
+				Namespace: otherMemberNamespace,
+				Name:      "app",
+			},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(testScheme(t)).
+		WithObjects(mc, serviceImport, internalSvcExport).
+		WithStatusSubresource(serviceImport).
+		Build()
+	r := Reconciler{
+		Client:   fakeClient,
+		Recorder: record.NewFakeRecorder(10),
+	}
+
+	ctx := context.Background()
+	if _, err := r.withdrawMemberCluster(ctx, mc); err != nil {
+		t.Fatalf("withdrawMemberCluster() = %v, want no error", err)
+	}
+
+	gotServiceImport := &fleetnetv1alpha1.ServiceImport{}
+	if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(serviceImport), gotServiceImport); err != nil {
+		t.Fatalf("serviceImport Get() = %v, want no error", err)
+	}
+	wantStatus := fleetnetv1alpha1.ServiceImportStatus{
+		IPs:        []string{"10.0.0.1"},
+		ImportedBy: []fleetnetv1alpha1.ClusterImportStatus{{ClusterNamespace: fleetnetv1alpha1.ClusterNamespace(otherMemberNamespace)}},
+		Conditions: []metav1.Condition{
+			{
+				Type:    string(fleetnetv1alpha1.ServiceImportReady),
+				Status:  metav1.ConditionFalse,
+				Reason:  serviceimport.ConditionReasonNoAcceptedExports,
+				Message: "service spec is not resolved; 0 of 0 exporting cluster(s) are accepted",
+			},
+		},
+	}
+	if diff := cmp.Diff(wantStatus, gotServiceImport.Status, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime")); diff != "" {
+		t.Errorf("serviceImport status mismatch (-want, +got):\n%s", diff)
+	}
+}
+
 func testScheme(t *testing.T) *runtime.Scheme {
 	scheme := runtime.NewScheme()
 	if err := clusterv1beta1.AddToScheme(scheme); err != nil {
@@ -87,6 +87,7 @@ var _ = BeforeSuite(func() {
 
 	err = (&Reconciler{
 		Client:              hubClient,
+		Recorder:            hubCtrlMgr.GetEventRecorderFor(ControllerName),
 		ForceDeleteWaitTime: 1 * time.Minute,
 	}).SetupWithManager(hubCtrlMgr)
 	Expect(err).NotTo(HaveOccurred())
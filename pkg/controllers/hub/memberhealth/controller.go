@@ -0,0 +1,197 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package memberhealth features the member health controller, which watches the heartbeats of the fleet networking
+// agents in the member clusters and marks the services exported from a member cluster as stale when its agents
+// stop sending heartbeats, so that the other hub controllers withdraw its endpoints from the fleet.
+package memberhealth
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	clusterv1beta1 "go.goms.io/fleet/apis/cluster/v1beta1"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/condition"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+)
+
+const (
+	ControllerName = "memberhealth-controller"
+)
+
+// networkingAgentTypes are the types of the fleet networking agents which send heartbeats.
+var networkingAgentTypes = []clusterv1beta1.AgentType{
+	clusterv1beta1.MultiClusterServiceAgent,
+	clusterv1beta1.ServiceExportImportAgent,
+}
+
+// Reconciler reconciles an InternalMemberCluster object.
+type Reconciler struct {
+	client.Client
+	// HeartbeatGracePeriod is the duration the controller waits after the last heartbeat received from the
+	// networking agents of a member cluster before it marks the services exported from the cluster as stale.
+	HeartbeatGracePeriod time.Duration
+}
+
+//+kubebuilder:rbac:groups=cluster.kubernetes-fleet.io,resources=internalmemberclusters,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalserviceexports,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalserviceexports/status,verbs=get;update;patch
+
+// Reconcile marks the internalServiceExports of a member cluster as stale when none of its networking agents has
+// sent a heartbeat within the grace period, and as fresh again once the heartbeats resume.
+//
+// The stale condition is picked up by the InternalServiceExport and ServiceImport controllers, which withdraw the
+// cluster from the serviceImport (and thus from the Traffic Manager backends), and by the EndpointSliceExport
+// controller, which withdraws the endpointSliceImports distributed from the cluster.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	imcRef := klog.KRef(req.Namespace, req.Name)
+	startTime := time.Now()
+	klog.V(2).InfoS("Reconciliation starts", "internalMemberCluster", imcRef)
+	defer func() {
+		latency := time.Since(startTime).Milliseconds()
+		klog.V(2).InfoS("Reconciliation ends", "internalMemberCluster", imcRef, "latency", latency)
+	}()
+
+	var imc clusterv1beta1.InternalMemberCluster
+	if err := r.Client.Get(ctx, req.NamespacedName, &imc); err != nil {
+		if errors.IsNotFound(err) {
+			klog.V(4).InfoS("Ignoring NotFound internalMemberCluster", "internalMemberCluster", imcRef)
+			return ctrl.Result{}, nil
+		}
+		klog.ErrorS(err, "Failed to get internalMemberCluster", "internalMemberCluster", imcRef)
+		return ctrl.Result{}, err
+	}
+	if imc.Spec.State != clusterv1beta1.ClusterStateJoin {
+		// The member agents withdraw the exports themselves when the member cluster leaves the fleet; the
+		// MemberCluster controller takes over if they fail to do so.
+		klog.V(3).InfoS("The member cluster is leaving the fleet, ignore it", "internalMemberCluster", imcRef)
+		return ctrl.Result{}, nil
+	}
+
+	lastHeartbeat := lastNetworkingHeartbeat(&imc)
+	if lastHeartbeat.IsZero() {
+		klog.V(3).InfoS("No heartbeat has been received from the networking agents yet", "internalMemberCluster", imcRef)
+		return ctrl.Result{}, nil
+	}
+	staleSince := lastHeartbeat.Add(r.HeartbeatGracePeriod)
+	now := time.Now()
+	stale := !now.Before(staleSince)
+	if stale {
+		klog.V(2).InfoS("The networking agents have stopped sending heartbeats", "internalMemberCluster", imcRef, "lastHeartbeat", lastHeartbeat)
+	}
+	if err := r.updateInternalServiceExports(ctx, &imc, stale, lastHeartbeat); err != nil {
+		return ctrl.Result{}, err
+	}
+	if stale {
+		// The next heartbeat updates the internalMemberCluster status, which triggers another reconciliation.
+		return ctrl.Result{}, nil
+	}
+	// Check again when the grace period expires, in case no more heartbeats are received by then.
+	return ctrl.Result{RequeueAfter: staleSince.Sub(now)}, nil
+}
+
+// updateInternalServiceExports sets the stale condition of all the internalServiceExports in the namespace of the
+// member cluster. The fresh condition is only set on the internalServiceExports which have been marked as stale
+// before, so that healthy member clusters do not incur extra writes.
+func (r *Reconciler) updateInternalServiceExports(ctx context.Context, imc *clusterv1beta1.InternalMemberCluster, stale bool, lastHeartbeat time.Time) error {
+	imcKObj := klog.KObj(imc)
+	var internalServiceExportList fleetnetv1alpha1.InternalServiceExportList
+	if err := r.Client.List(ctx, &internalServiceExportList, client.InNamespace(imc.Namespace)); err != nil {
+		klog.ErrorS(err, "Failed to list internalServiceExports", "internalMemberCluster", imcKObj)
+		return err
+	}
+	staleMessage := fmt.Sprintf("member cluster %s has not sent heartbeats since %s", imc.Name, lastHeartbeat.UTC().Format(time.RFC3339))
+
+	errs, ctx := errgroup.WithContext(ctx)
+	for i := range internalServiceExportList.Items {
+		internalServiceExport := &internalServiceExportList.Items[i]
+		if internalServiceExport.DeletionTimestamp != nil {
+			continue
+		}
+		currentCond := meta.FindStatusCondition(internalServiceExport.Status.Conditions, string(fleetnetv1alpha1.ServiceExportStale))
+		if !stale && currentCond == nil {
+			continue
+		}
+		desiredCond := condition.FreshServiceExportCondition(*internalServiceExport)
+		if stale {
+			desiredCond = condition.StaleServiceExportCondition(*internalServiceExport, staleMessage)
+		}
+		if condition.EqualCondition(currentCond, &desiredCond) {
+			continue
+		}
+		errs.Go(func() error {
+			exportKObj := klog.KObj(internalServiceExport)
+			meta.SetStatusCondition(&internalServiceExport.Status.Conditions, desiredCond)
+			klog.V(2).InfoS("Updating internalServiceExport stale condition", "internalMemberCluster", imcKObj, "internalServiceExport", exportKObj, "stale", stale)
+			if err := r.Client.Status().Update(ctx, internalServiceExport); err != nil {
+				klog.ErrorS(err, "Failed to update internalServiceExport stale condition", "internalMemberCluster", imcKObj, "internalServiceExport", exportKObj)
+				return err
+			}
+			return nil
+		})
+	}
+	return errs.Wait()
+}
+
+// lastNetworkingHeartbeat returns the time of the latest heartbeat received from the networking agents of a member
+// cluster, or zero if none of them has sent a heartbeat yet.
+//
+// The latest heartbeat (rather than the earliest) is used, so that the member cluster is only considered gone dark
+// when all of its networking agents have stopped sending heartbeats; the failure of a single agent is reported
+// on its own agent status.
+func lastNetworkingHeartbeat(imc *clusterv1beta1.InternalMemberCluster) time.Time {
+	var lastHeartbeat time.Time
+	for _, agentStatus := range imc.Status.AgentStatus {
+		if slices.Contains(networkingAgentTypes, agentStatus.Type) && agentStatus.LastReceivedHeartbeat.Time.After(lastHeartbeat) {
+			lastHeartbeat = agentStatus.LastReceivedHeartbeat.Time
+		}
+	}
+	return lastHeartbeat
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// Evaluate the new internalServiceExports of a member cluster which is known to be stale right away, instead of
+	// waiting for the next heartbeat.
+	enqueueInternalMemberClusters := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, o client.Object) []reconcile.Request {
+		var imcList clusterv1beta1.InternalMemberClusterList
+		if err := r.Client.List(ctx, &imcList, client.InNamespace(o.GetNamespace())); err != nil {
+			klog.ErrorS(err, "Failed to list internalMemberClusters", "internalServiceExport", klog.KObj(o))
+			return nil
+		}
+		requests := make([]reconcile.Request, 0, len(imcList.Items))
+		for i := range imcList.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: imcList.Items[i].Namespace, Name: imcList.Items[i].Name}})
+		}
+		return requests
+	})
+	createOnly := predicate.Funcs{
+		CreateFunc:  func(_ event.CreateEvent) bool { return true },
+		UpdateFunc:  func(_ event.UpdateEvent) bool { return false },
+		DeleteFunc:  func(_ event.DeleteEvent) bool { return false },
+		GenericFunc: func(_ event.GenericEvent) bool { return false },
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&clusterv1beta1.InternalMemberCluster{}).
+		Watches(&fleetnetv1alpha1.InternalServiceExport{}, enqueueInternalMemberClusters, builder.WithPredicates(createOnly)).
+		Complete(metrics.InstrumentReconciler("internalmembercluster", r))
+}
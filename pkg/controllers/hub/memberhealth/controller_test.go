@@ -0,0 +1,233 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package memberhealth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1beta1 "go.goms.io/fleet/apis/cluster/v1beta1"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+const (
+	testMemberClusterName      = "test-mc"
+	testMemberClusterNamespace = "fleet-member-test-mc"
+	testInternalSvcExportName  = "work-app"
+	gracePeriod                = 5 * time.Minute
+)
+
+func testScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	if err := clusterv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	return scheme
+}
+
+func internalMemberCluster(state clusterv1beta1.ClusterState, heartbeats map[clusterv1beta1.AgentType]time.Time) *clusterv1beta1.InternalMemberCluster {
+	imc := &clusterv1beta1.InternalMemberCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testMemberClusterNamespace,
+			Name:      testMemberClusterName,
+		},
+		Spec: clusterv1beta1.InternalMemberClusterSpec{
+			State: state,
+		},
+	}
+	for agentType, heartbeat := range heartbeats {
+		imc.Status.AgentStatus = append(imc.Status.AgentStatus, clusterv1beta1.AgentStatus{
+			Type:                  agentType,
+			LastReceivedHeartbeat: metav1.NewTime(heartbeat),
+		})
+	}
+	return imc
+}
+
+func internalServiceExport(conds ...metav1.Condition) *fleetnetv1alpha1.InternalServiceExport {
+	return &fleetnetv1alpha1.InternalServiceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testMemberClusterNamespace,
+			Name:      testInternalSvcExportName,
+		},
+		Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+			ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+				ClusterID:      testMemberClusterName,
+				Kind:           "Service",
+				Namespace:      "work",
+				Name:           "app",
+				NamespacedName: "work/app",
+				Generation:     1,
+			},
+		},
+		Status: fleetnetv1alpha1.InternalServiceExportStatus{
+			Conditions: conds,
+		},
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	now := time.Now()
+	staleCond := metav1.Condition{
+		Type:               string(fleetnetv1alpha1.ServiceExportStale),
+		Status:             metav1.ConditionTrue,
+		Reason:             "HeartbeatLost",
+		ObservedGeneration: 1,
+	}
+	freshCond := metav1.Condition{
+		Type:               string(fleetnetv1alpha1.ServiceExportStale),
+		Status:             metav1.ConditionFalse,
+		Reason:             "HeartbeatReceived",
+		ObservedGeneration: 1,
+	}
+
+	testCases := []struct {
+		name              string
+		imc               *clusterv1beta1.InternalMemberCluster
+		internalSvcExport *fleetnetv1alpha1.InternalServiceExport
+		wantRequeue       bool
+		wantCond          *metav1.Condition
+	}{
+		{
+			name:              "internalMemberCluster is not found",
+			internalSvcExport: internalServiceExport(),
+		},
+		{
+			name: "member cluster is leaving",
+			imc: internalMemberCluster(clusterv1beta1.ClusterStateLeave, map[clusterv1beta1.AgentType]time.Time{
+				clusterv1beta1.ServiceExportImportAgent: now.Add(-time.Hour),
+			}),
+			internalSvcExport: internalServiceExport(),
+		},
+		{
+			name:              "no heartbeat from the networking agents yet",
+			imc:               internalMemberCluster(clusterv1beta1.ClusterStateJoin, map[clusterv1beta1.AgentType]time.Time{clusterv1beta1.MemberAgent: now.Add(-time.Hour)}),
+			internalSvcExport: internalServiceExport(),
+		},
+		{
+			name: "healthy member cluster",
+			imc: internalMemberCluster(clusterv1beta1.ClusterStateJoin, map[clusterv1beta1.AgentType]time.Time{
+				clusterv1beta1.ServiceExportImportAgent: now.Add(-time.Minute),
+			}),
+			internalSvcExport: internalServiceExport(),
+			wantRequeue:       true,
+		},
+		{
+			name: "stale member cluster",
+			imc: internalMemberCluster(clusterv1beta1.ClusterStateJoin, map[clusterv1beta1.AgentType]time.Time{
+				clusterv1beta1.MultiClusterServiceAgent: now.Add(-time.Hour),
+				clusterv1beta1.ServiceExportImportAgent: now.Add(-2 * gracePeriod),
+			}),
+			internalSvcExport: internalServiceExport(),
+			wantCond:          &staleCond,
+		},
+		{
+			name: "only one networking agent has stopped sending heartbeats",
+			imc: internalMemberCluster(clusterv1beta1.ClusterStateJoin, map[clusterv1beta1.AgentType]time.Time{
+				clusterv1beta1.MultiClusterServiceAgent: now.Add(-time.Hour),
+				clusterv1beta1.ServiceExportImportAgent: now.Add(-time.Minute),
+			}),
+			internalSvcExport: internalServiceExport(),
+			wantRequeue:       true,
+		},
+		{
+			name: "heartbeats resume",
+			imc: internalMemberCluster(clusterv1beta1.ClusterStateJoin, map[clusterv1beta1.AgentType]time.Time{
+				clusterv1beta1.ServiceExportImportAgent: now,
+			}),
+			internalSvcExport: internalServiceExport(staleCond),
+			wantRequeue:       true,
+			wantCond:          &freshCond,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClientBuilder := fake.NewClientBuilder().
+				WithScheme(testScheme(t)).
+				WithObjects(tc.internalSvcExport).
+				WithStatusSubresource(tc.internalSvcExport)
+			if tc.imc != nil {
+				fakeClientBuilder = fakeClientBuilder.WithObjects(tc.imc)
+			}
+			fakeClient := fakeClientBuilder.Build()
+			r := Reconciler{
+				Client:               fakeClient,
+				HeartbeatGracePeriod: gracePeriod,
+			}
+
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: testMemberClusterNamespace, Name: testMemberClusterName}}
+			got, err := r.Reconcile(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+			if gotRequeue := got.RequeueAfter > 0; gotRequeue != tc.wantRequeue {
+				t.Errorf("Reconcile() requeueAfter = %v, want requeue %t", got.RequeueAfter, tc.wantRequeue)
+			}
+			if got.RequeueAfter > gracePeriod {
+				t.Errorf("Reconcile() requeueAfter = %v, want no longer than the grace period %v", got.RequeueAfter, gracePeriod)
+			}
+
+			internalSvcExport := &fleetnetv1alpha1.InternalServiceExport{}
+			if err := fakeClient.Get(context.Background(), types.NamespacedName{Namespace: testMemberClusterNamespace, Name: testInternalSvcExportName}, internalSvcExport); err != nil {
+				t.Fatalf("internalServiceExport Get() = %v, want no error", err)
+			}
+			gotCond := meta.FindStatusCondition(internalSvcExport.Status.Conditions, string(fleetnetv1alpha1.ServiceExportStale))
+			if diff := cmp.Diff(tc.wantCond, gotCond, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime", "Message")); diff != "" {
+				t.Errorf("internalServiceExport stale condition mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLastNetworkingHeartbeat(t *testing.T) {
+	now := time.Now().Round(time.Second)
+	testCases := []struct {
+		name       string
+		heartbeats map[clusterv1beta1.AgentType]time.Time
+		want       time.Time
+	}{
+		{
+			name: "no agent status",
+		},
+		{
+			name: "member agent only",
+			heartbeats: map[clusterv1beta1.AgentType]time.Time{
+				clusterv1beta1.MemberAgent: now,
+			},
+		},
+		{
+			name: "networking agents",
+			heartbeats: map[clusterv1beta1.AgentType]time.Time{
+				clusterv1beta1.MemberAgent:              now,
+				clusterv1beta1.MultiClusterServiceAgent: now.Add(-time.Hour),
+				clusterv1beta1.ServiceExportImportAgent: now.Add(-time.Minute),
+			},
+			want: now.Add(-time.Minute),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			imc := internalMemberCluster(clusterv1beta1.ClusterStateJoin, tc.heartbeats)
+			if got := lastNetworkingHeartbeat(imc); !got.Equal(tc.want) {
+				t.Errorf("lastNetworkingHeartbeat() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -9,37 +9,59 @@ package serviceimport
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"sort"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 	"go.goms.io/fleet-networking/pkg/common/apiretry"
+	"go.goms.io/fleet-networking/pkg/common/clustersetip"
 	"go.goms.io/fleet-networking/pkg/common/condition"
+	"go.goms.io/fleet-networking/pkg/common/events"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
 	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/servicepolicy"
+	"go.goms.io/fleet-networking/pkg/common/servicespec"
 )
 
 const (
 	// fields name used to filter resources
 	exportedServiceFieldNamespacedName = ".spec.serviceReference.namespacedName"
+	serviceImportClusterSetIPField     = ".status.ips"
 
 	// ControllerName is the name of the Reconciler.
 	ControllerName = "serviceimport-controller"
+
+	conditionReasonServiceResolved = "ServiceResolved"
+	// ConditionReasonNoAcceptedExports is the reason of the Ready condition of a serviceImport when none of its
+	// exporting clusters is accepted.
+	ConditionReasonNoAcceptedExports = "NoAcceptedExports"
+
+	clusterExportReasonAccepted = "Accepted"
+	clusterExportReasonPending  = "Pending"
 )
 
 // Reconciler reconciles a ServiceImport object.
 type Reconciler struct {
 	client.Client
 	Recorder record.EventRecorder
+	// ClusterSetIPAllocator assigns ClusterSetIPs to ServiceImports; the allocation is disabled when it is nil.
+	ClusterSetIPAllocator *clustersetip.Allocator
 }
 
 // statusChange stores the internalServiceExports list whose status needs to be updated.
@@ -53,6 +75,7 @@ type statusChange struct {
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports/finalizers,verbs=update
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalserviceexports,verbs=get;watch;list
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalserviceexports/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceexportimportpolicies,verbs=get;list;watch
 //+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile resolves the service spec when the serviceImport status is empty and updates the status of internalServiceExports.
@@ -68,17 +91,19 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	if err := r.Client.Get(ctx, req.NamespacedName, &serviceImport); err != nil {
 		if errors.IsNotFound(err) {
 			klog.V(4).InfoS("Ignoring NotFound serviceImport", "serviceImport", serviceImportKRef)
+			if r.ClusterSetIPAllocator != nil {
+				r.ClusterSetIPAllocator.Release(req.NamespacedName.String())
+			}
 			return ctrl.Result{}, nil
 		}
 		klog.ErrorS(err, "Failed to get serviceImport", "serviceImport", serviceImportKRef)
 		return ctrl.Result{}, err
 	}
-	// If the spec has already present, no need to resolve the service spec.
-	if len(serviceImport.Status.Clusters) != 0 {
-		klog.V(4).InfoS("Already resolved the service spec and skipping", "serviceImport", serviceImportKRef)
-		return ctrl.Result{}, nil
+	if serviceImport.DeletionTimestamp == nil && r.ClusterSetIPAllocator != nil {
+		if err := r.ensureClusterSetIP(ctx, &serviceImport); err != nil {
+			return ctrl.Result{}, err
+		}
 	}
-
 	internalServiceExportList := &fleetnetv1alpha1.InternalServiceExportList{}
 	namespaceName := types.NamespacedName{Namespace: serviceImport.Namespace, Name: serviceImport.Name}
 	listOpts := client.MatchingFields{
@@ -88,6 +113,22 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		klog.ErrorS(err, "Failed to list internalServiceExports used by the serviceImport", "serviceImport", serviceImportKRef)
 		return ctrl.Result{}, err
 	}
+
+	// If the spec has already present, no need to resolve the service spec; only the status of the exporting
+	// clusters is refreshed.
+	if len(serviceImport.Status.Clusters) != 0 {
+		klog.V(4).InfoS("Already resolved the service spec and skipping", "serviceImport", serviceImportKRef)
+		if serviceImport.DeletionTimestamp != nil {
+			return ctrl.Result{}, nil
+		}
+		internalServiceExports := make([]*fleetnetv1alpha1.InternalServiceExport, 0, len(internalServiceExportList.Items))
+		for i := range internalServiceExportList.Items {
+			if internalServiceExportList.Items[i].DeletionTimestamp == nil {
+				internalServiceExports = append(internalServiceExports, &internalServiceExportList.Items[i])
+			}
+		}
+		return ctrl.Result{}, r.updateClusterExportStatus(ctx, &serviceImport, internalServiceExports)
+	}
 	if len(internalServiceExportList.Items) == 0 {
 		klog.V(2).InfoS("No internalServiceExport found and deleting serviceImport", "serviceImport", serviceImportKRef)
 		return r.deleteServiceImport(ctx, &serviceImport)
@@ -97,32 +138,60 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		noConflict: []*fleetnetv1alpha1.InternalServiceExport{},
 	}
 
-	var resolvedPortsSpec *[]fleetnetv1alpha1.ServicePort
+	var resolvedSpec *fleetnetv1alpha1.InternalServiceExportSpec
+	hasStaleExports := false
+	// exports are all the internalServiceExports reported in the status of the exporting clusters, the same as the ones
+	// refreshed once the service spec is resolved.
+	exports := make([]*fleetnetv1alpha1.InternalServiceExport, 0, len(internalServiceExportList.Items))
 	for i := range internalServiceExportList.Items {
 		v := internalServiceExportList.Items[i]
 		if v.DeletionTimestamp != nil { // skip if the resource is in the deleting state
 			klog.V(4).InfoS("Skipping the internalServiceExport which is in the deleting state", serviceImport, serviceImportKRef, "internalServiceExport", klog.KObj(&v))
 			continue
 		}
+		exports = append(exports, &v)
 		// skip if the resource is just added which has not been handled by the internalServiceExport controller yet
 		if !controllerutil.ContainsFinalizer(&v, objectmeta.InternalServiceExportFinalizer) {
 			klog.V(3).InfoS("Skipping the internalServiceExport because of missing finalizer", "serviceImport", serviceImportKRef, "internalServiceExport", klog.KObj(&v))
 			continue
 		}
+		// skip if the cluster is not allowed to export the service; the internalServiceExport controller will report
+		// it back to the member cluster
+		allowed, _, err := servicepolicy.IsExportAllowed(ctx, r.Client, serviceImport.Namespace, serviceImport.Name, v.Spec.ServiceReference.ClusterID)
+		if err != nil {
+			klog.ErrorS(err, "Failed to evaluate serviceExportImportPolicies", "serviceImport", serviceImportKRef, "internalServiceExport", klog.KObj(&v))
+			return ctrl.Result{}, err
+		}
+		if !allowed {
+			klog.V(3).InfoS("Skipping the internalServiceExport because the export is not allowed", "serviceImport", serviceImportKRef, "internalServiceExport", klog.KObj(&v))
+			continue
+		}
+		// skip if the cluster has stopped sending heartbeats; the export will be picked up again once the heartbeats
+		// resume
+		if meta.IsStatusConditionTrue(v.Status.Conditions, string(fleetnetv1alpha1.ServiceExportStale)) {
+			klog.V(3).InfoS("Skipping the internalServiceExport because the exporting cluster is stale", "serviceImport", serviceImportKRef, "internalServiceExport", klog.KObj(&v))
+			hasStaleExports = true
+			continue
+		}
 
-		if resolvedPortsSpec == nil {
+		if resolvedSpec == nil {
 			// pick the first internalServiceExport spec
-			resolvedPortsSpec = &v.Spec.Ports
+			resolvedSpec = &v.Spec
 		}
-		// TODO: ideally we should ignore the order when comparing the serviceImports; port and protocol are the key.
-		if !equality.Semantic.DeepEqual(*resolvedPortsSpec, v.Spec.Ports) {
+		if !isExportedSpecEqual(resolvedSpec, &v.Spec) {
 			change.conflict = append(change.conflict, &v)
 			continue
 		}
 		change.noConflict = append(change.noConflict, &v)
 	}
 
-	if resolvedPortsSpec == nil {
+	if resolvedSpec == nil && hasStaleExports {
+		// Keep the serviceImport (and its importing clusters and allocated ClusterSetIP) while the exporting
+		// clusters are stale, so that the service can be resolved again as soon as the heartbeats resume.
+		klog.V(2).InfoS("All the valid internalServiceExports are stale; keep the serviceImport", "serviceImport", serviceImportKRef)
+		return ctrl.Result{}, r.updateClusterExportStatus(ctx, &serviceImport, exports)
+	}
+	if resolvedSpec == nil {
 		// All of internalServicesExports are in the deleting state or waiting for the internalserviceexport controller to process it.
 		// We could safely delete the serviceImport if exists.
 		// When the internalserviceexport controller starts processing the object, it will create the serviceImport at
@@ -156,10 +225,21 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		}
 	}
 	serviceImport.Status = fleetnetv1alpha1.ServiceImportStatus{
-		Ports:    *resolvedPortsSpec,
+		IPs:      serviceImport.Status.IPs, // keep the allocated ClusterSetIP
+		Ports:    resolvedSpec.Ports,
 		Clusters: clusters,
 		Type:     fleetnetv1alpha1.ClusterSetIP, // may support headless in the future
+		// The session affinity settings and the exported labels and annotations are part of the conflict resolution,
+		// so that all the exporting clusters agree on them.
+		SessionAffinity:       servicespec.NormalizeSessionAffinity(resolvedSpec.SessionAffinity),
+		SessionAffinityConfig: resolvedSpec.SessionAffinityConfig.DeepCopy(),
+		ExportedLabels:        resolvedSpec.ExportedLabels,
+		ExportedAnnotations:   resolvedSpec.ExportedAnnotations,
+		// The importing clusters are managed by the InternalServiceImport controller.
+		ImportedBy: serviceImport.Status.ImportedBy,
+		Conditions: serviceImport.Status.Conditions,
 	}
+	setClusterExportStatus(&serviceImport, exports)
 	updateFunc := func() error {
 		return r.Status().Update(ctx, &serviceImport)
 	}
@@ -182,6 +262,8 @@ func (r *Reconciler) updateInternalServiceExportWithRetry(ctx context.Context, i
 		return nil
 	}
 	exportKObj := klog.KObj(internalServiceExport)
+	// The export falls into conflict, or recovers from one it was in.
+	isTransition := events.IsTransition(currentCond, desiredCond) && (conflict || currentCond != nil)
 	meta.SetStatusCondition(&internalServiceExport.Status.Conditions, desiredCond)
 
 	updateFunc := func() error {
@@ -191,6 +273,53 @@ func (r *Reconciler) updateInternalServiceExportWithRetry(ctx context.Context, i
 		klog.ErrorS(err, "Failed to update internalServiceExport status with retry", "internalServiceExport", exportKObj)
 		return err
 	}
+	if isTransition {
+		if conflict {
+			r.Recorder.Event(internalServiceExport, corev1.EventTypeWarning, events.ReasonConflict, desiredCond.Message)
+		} else {
+			r.Recorder.Event(internalServiceExport, corev1.EventTypeNormal, events.ReasonConflictResolved, desiredCond.Message)
+		}
+	}
+	return nil
+}
+
+// ensureClusterSetIP allocates a ClusterSetIP for the serviceImport if it has not been assigned a valid one yet.
+func (r *Reconciler) ensureClusterSetIP(ctx context.Context, serviceImport *fleetnetv1alpha1.ServiceImport) error {
+	serviceImportKObj := klog.KObj(serviceImport)
+	if len(serviceImport.Status.IPs) == 1 && r.ClusterSetIPAllocator.Contains(serviceImport.Status.IPs[0]) {
+		return nil
+	}
+
+	// Look up the candidate IPs in the index of the allocated IPs instead of listing all the serviceImports.
+	inUse := func(ip string) (bool, error) {
+		serviceImportList := &fleetnetv1alpha1.ServiceImportList{}
+		if err := r.Client.List(ctx, serviceImportList, client.MatchingFields{serviceImportClusterSetIPField: ip}); err != nil {
+			klog.ErrorS(err, "Failed to list serviceImports to find out if the ClusterSetIP is in use", "serviceImport", serviceImportKObj, "ip", ip)
+			return false, err
+		}
+		for i := range serviceImportList.Items {
+			if other := &serviceImportList.Items[i]; other.Namespace != serviceImport.Namespace || other.Name != serviceImport.Name {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	key := types.NamespacedName{Namespace: serviceImport.Namespace, Name: serviceImport.Name}.String()
+	ip, err := r.ClusterSetIPAllocator.Allocate(key, inUse)
+	if err != nil {
+		klog.ErrorS(err, "Failed to allocate ClusterSetIP", "serviceImport", serviceImportKObj, "cidr", r.ClusterSetIPAllocator.CIDR())
+		r.Recorder.Eventf(serviceImport, corev1.EventTypeWarning, "ClusterSetIPAllocationFailed", "Failed to allocate ClusterSetIP from %s: %v", r.ClusterSetIPAllocator.CIDR(), err)
+		return err
+	}
+
+	serviceImport.Status.IPs = []string{ip}
+	klog.V(2).InfoS("Assigning ClusterSetIP to the serviceImport", "serviceImport", serviceImportKObj, "ip", ip)
+	if err := r.Client.Status().Update(ctx, serviceImport); err != nil {
+		klog.ErrorS(err, "Failed to update serviceImport status with the ClusterSetIP", "serviceImport", serviceImportKObj, "ip", ip)
+		return err
+	}
+	r.Recorder.Eventf(serviceImport, corev1.EventTypeNormal, "ClusterSetIPAllocated", "Allocated ClusterSetIP %s", ip)
 	return nil
 }
 
@@ -217,8 +346,160 @@ func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) err
 		klog.ErrorS(err, "Failed to create index", "field", exportedServiceFieldNamespacedName)
 		return err
 	}
+	// add index to quickly find out if a ClusterSetIP is in use
+	ipExtractFunc := func(o client.Object) []string {
+		return o.(*fleetnetv1alpha1.ServiceImport).Status.IPs
+	}
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &fleetnetv1alpha1.ServiceImport{}, serviceImportClusterSetIPField, ipExtractFunc); err != nil {
+		klog.ErrorS(err, "Failed to create index", "field", serviceImportClusterSetIPField)
+		return err
+	}
 
+	// Refresh the status of the exporting clusters whenever the internalServiceExports change.
+	enqueueServiceImport := handler.EnqueueRequestsFromMapFunc(func(_ context.Context, o client.Object) []reconcile.Request {
+		ref := o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}}}
+	})
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&fleetnetv1alpha1.ServiceImport{}).
-		Complete(r)
+		Watches(&fleetnetv1alpha1.InternalServiceExport{}, enqueueServiceImport).
+		Complete(metrics.InstrumentReconciler("serviceimport", r))
+}
+
+// updateClusterExportStatus refreshes the status of the exporting clusters and the Ready condition of a resolved
+// serviceImport.
+func (r *Reconciler) updateClusterExportStatus(ctx context.Context, serviceImport *fleetnetv1alpha1.ServiceImport, internalServiceExports []*fleetnetv1alpha1.InternalServiceExport) error {
+	oldStatus := serviceImport.Status.DeepCopy()
+	setClusterExportStatus(serviceImport, internalServiceExports)
+	if equality.Semantic.DeepEqual(oldStatus, &serviceImport.Status) {
+		return nil
+	}
+	serviceImportKObj := klog.KObj(serviceImport)
+	klog.V(2).InfoS("Updating the status of the exporting clusters", "serviceImport", serviceImportKObj, "clusterExports", serviceImport.Status.ClusterExports)
+	if err := r.Status().Update(ctx, serviceImport); err != nil {
+		klog.ErrorS(err, "Failed to update the status of the exporting clusters", "serviceImport", serviceImportKObj)
+		return err
+	}
+	return nil
+}
+
+// setClusterExportStatus sets the status of every exporting cluster and the Ready condition of a serviceImport.
+// The clusters which are not authorized to export the service are rejected; the clusters which have stopped sending
+// heartbeats are stale; the clusters listed in the resolved status are accepted; the others are conflicted or pending, depending on the conflict condition reported on their
+// internalServiceExports.
+func setClusterExportStatus(serviceImport *fleetnetv1alpha1.ServiceImport, internalServiceExports []*fleetnetv1alpha1.InternalServiceExport) {
+	accepted := make(map[string]bool, len(serviceImport.Status.Clusters))
+	for _, cluster := range serviceImport.Status.Clusters {
+		accepted[cluster.Cluster] = true
+	}
+
+	clusterExports := make([]fleetnetv1alpha1.ClusterExportStatus, 0, len(internalServiceExports))
+	for _, export := range internalServiceExports {
+		clusterID := export.Spec.ServiceReference.ClusterID
+		conflictCond := meta.FindStatusCondition(export.Status.Conditions, string(fleetnetv1alpha1.ServiceExportConflict))
+		authorizedCond := meta.FindStatusCondition(export.Status.Conditions, string(fleetnetv1alpha1.ServiceExportAuthorized))
+		staleCond := meta.FindStatusCondition(export.Status.Conditions, string(fleetnetv1alpha1.ServiceExportStale))
+		switch {
+		case authorizedCond != nil && authorizedCond.Status == metav1.ConditionFalse:
+			clusterExports = append(clusterExports, fleetnetv1alpha1.ClusterExportStatus{
+				Cluster: clusterID,
+				State:   fleetnetv1alpha1.ClusterExportStateRejected,
+				Reason:  authorizedCond.Reason,
+				Message: authorizedCond.Message,
+			})
+		case staleCond != nil && staleCond.Status == metav1.ConditionTrue:
+			clusterExports = append(clusterExports, fleetnetv1alpha1.ClusterExportStatus{
+				Cluster: clusterID,
+				State:   fleetnetv1alpha1.ClusterExportStateStale,
+				Reason:  staleCond.Reason,
+				Message: staleCond.Message,
+			})
+		case accepted[clusterID]:
+			clusterExports = append(clusterExports, fleetnetv1alpha1.ClusterExportStatus{
+				Cluster: clusterID,
+				State:   fleetnetv1alpha1.ClusterExportStateAccepted,
+				Reason:  clusterExportReasonAccepted,
+				Message: "exported service is accepted",
+			})
+		case conflictCond != nil && conflictCond.Status == metav1.ConditionTrue:
+			clusterExports = append(clusterExports, fleetnetv1alpha1.ClusterExportStatus{
+				Cluster: clusterID,
+				State:   fleetnetv1alpha1.ClusterExportStateConflicted,
+				Reason:  conflictCond.Reason,
+				Message: conflictCond.Message,
+			})
+		default:
+			clusterExports = append(clusterExports, fleetnetv1alpha1.ClusterExportStatus{
+				Cluster: clusterID,
+				State:   fleetnetv1alpha1.ClusterExportStatePending,
+				Reason:  clusterExportReasonPending,
+				Message: "exported service is waiting to be processed",
+			})
+		}
+	}
+	sort.Slice(clusterExports, func(i, j int) bool {
+		return clusterExports[i].Cluster < clusterExports[j].Cluster
+	})
+	serviceImport.Status.ClusterExports = clusterExports
+	setReadyCondition(serviceImport)
+}
+
+// setReadyCondition sets the Ready condition of a serviceImport based on its accepted and reported exporting clusters.
+func setReadyCondition(serviceImport *fleetnetv1alpha1.ServiceImport) {
+	if len(serviceImport.Status.Clusters) == 0 {
+		meta.SetStatusCondition(&serviceImport.Status.Conditions, metav1.Condition{
+			Type:               string(fleetnetv1alpha1.ServiceImportReady),
+			Status:             metav1.ConditionFalse,
+			Reason:             ConditionReasonNoAcceptedExports,
+			ObservedGeneration: serviceImport.Generation,
+			Message: fmt.Sprintf("service spec is not resolved; 0 of %d exporting cluster(s) are accepted",
+				len(serviceImport.Status.ClusterExports)),
+		})
+		return
+	}
+	meta.SetStatusCondition(&serviceImport.Status.Conditions, metav1.Condition{
+		Type:               string(fleetnetv1alpha1.ServiceImportReady),
+		Status:             metav1.ConditionTrue,
+		Reason:             conditionReasonServiceResolved,
+		ObservedGeneration: serviceImport.Generation,
+		Message: fmt.Sprintf("service spec is resolved; %d of %d exporting cluster(s) are accepted",
+			len(serviceImport.Status.Clusters), len(serviceImport.Status.ClusterExports)),
+	})
+}
+
+// RemoveExportingCluster removes a member cluster from the exporting clusters in the status of a serviceImport.
+// If no exporting cluster is left, the resolved service spec is reset so that it can be resolved again from the
+// remaining exports, and the serviceImport is reported as not ready; the allocated ClusterSetIP, the importing
+// clusters and the status of the other exporting clusters are kept.
+func RemoveExportingCluster(serviceImport *fleetnetv1alpha1.ServiceImport, clusterID string) {
+	serviceImport.Status.Clusters = slices.DeleteFunc(serviceImport.Status.Clusters, func(c fleetnetv1alpha1.ClusterStatus) bool {
+		return c.Cluster == clusterID
+	})
+	serviceImport.Status.ClusterExports = slices.DeleteFunc(serviceImport.Status.ClusterExports, func(c fleetnetv1alpha1.ClusterExportStatus) bool {
+		return c.Cluster == clusterID
+	})
+	if len(serviceImport.Status.ClusterExports) == 0 {
+		serviceImport.Status.ClusterExports = nil
+	}
+	if len(serviceImport.Status.Clusters) != 0 {
+		return
+	}
+	serviceImport.Status = fleetnetv1alpha1.ServiceImportStatus{
+		IPs:            serviceImport.Status.IPs,
+		ImportedBy:     serviceImport.Status.ImportedBy,
+		ClusterExports: serviceImport.Status.ClusterExports,
+		Conditions:     serviceImport.Status.Conditions,
+	}
+	setReadyCondition(serviceImport)
+}
+
+// isExportedSpecEqual returns true if two exported services have the same ports, session affinity settings and
+// exported labels and annotations.
+func isExportedSpecEqual(a, b *fleetnetv1alpha1.InternalServiceExportSpec) bool {
+	// TODO: ideally we should ignore the order when comparing the ports; port and protocol are the key.
+	return equality.Semantic.DeepEqual(a.Ports, b.Ports) &&
+		servicespec.NormalizeSessionAffinity(a.SessionAffinity) == servicespec.NormalizeSessionAffinity(b.SessionAffinity) &&
+		equality.Semantic.DeepEqual(a.SessionAffinityConfig, b.SessionAffinityConfig) &&
+		equality.Semantic.DeepEqual(a.ExportedLabels, b.ExportedLabels) &&
+		equality.Semantic.DeepEqual(a.ExportedAnnotations, b.ExportedAnnotations)
 }
@@ -13,6 +13,7 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -107,6 +108,7 @@ var _ = Describe("Test ServiceImport Controller", func() {
 		options = []cmp.Option{
 			cmpopts.IgnoreFields(metav1.ObjectMeta{}, "ResourceVersion"),
 			cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime"),
+			cmpopts.IgnoreFields(fleetnetv1alpha1.ServiceImportStatus{}, "Conditions", "ClusterExports"),
 			cmpopts.IgnoreFields(metav1.ObjectMeta{}, "ManagedFields"),
 		}
 	)
@@ -276,8 +278,9 @@ var _ = Describe("Test ServiceImport Controller", func() {
 							Cluster: testClusterID,
 						},
 					},
-					Type:  fleetnetv1alpha1.ClusterSetIP,
-					Ports: internalServiceExportA.Spec.Ports,
+					Type:            fleetnetv1alpha1.ClusterSetIP,
+					Ports:           internalServiceExportA.Spec.Ports,
+					SessionAffinity: corev1.ServiceAffinityNone,
 				}
 				if len(serviceImport.Status.Clusters) != 1 {
 					return fmt.Sprintf("got %v cluster, want 1", len(serviceImport.Status.Clusters))
@@ -290,8 +293,9 @@ var _ = Describe("Test ServiceImport Controller", func() {
 								Cluster: "member-cluster-b",
 							},
 						},
-						Type:  fleetnetv1alpha1.ClusterSetIP,
-						Ports: internalServiceExportB.Spec.Ports,
+						Type:            fleetnetv1alpha1.ClusterSetIP,
+						Ports:           internalServiceExportB.Spec.Ports,
+						SessionAffinity: corev1.ServiceAffinityNone,
 					}
 				}
 				return cmp.Diff(want, serviceImport.Status, options...)
@@ -431,7 +435,8 @@ var _ = Describe("Test ServiceImport Controller", func() {
 							Cluster: testClusterID,
 						},
 					},
-					Type: fleetnetv1alpha1.ClusterSetIP,
+					Type:            fleetnetv1alpha1.ClusterSetIP,
+					SessionAffinity: corev1.ServiceAffinityNone,
 				}
 				return cmp.Diff(want, serviceImport.Status, options...)
 			}, timeout, interval).Should(BeEmpty())
@@ -0,0 +1,388 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package serviceimport
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/clustersetip"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+func internalServiceExportForTest(clusterID string, conditions ...metav1.Condition) *fleetnetv1alpha1.InternalServiceExport {
+	return &fleetnetv1alpha1.InternalServiceExport{
+		Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+			ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+				ClusterID: clusterID,
+				Namespace: "work",
+				Name:      "app",
+			},
+		},
+		Status: fleetnetv1alpha1.InternalServiceExportStatus{
+			Conditions: conditions,
+		},
+	}
+}
+
+func TestSetClusterExportStatus(t *testing.T) {
+	conflictCond := metav1.Condition{
+		Type:    string(fleetnetv1alpha1.ServiceExportConflict),
+		Status:  metav1.ConditionTrue,
+		Reason:  "ConflictFound",
+		Message: "service work/app is in conflict with other exported services",
+	}
+	unauthorizedCond := metav1.Condition{
+		Type:    string(fleetnetv1alpha1.ServiceExportAuthorized),
+		Status:  metav1.ConditionFalse,
+		Reason:  "ExportNotAllowed",
+		Message: "member cluster member-4 is not allowed to export service work/app by serviceExportImportPolicy tenant",
+	}
+	staleCond := metav1.Condition{
+		Type:    string(fleetnetv1alpha1.ServiceExportStale),
+		Status:  metav1.ConditionTrue,
+		Reason:  "HeartbeatLost",
+		Message: "member cluster member-5 has not sent heartbeats since 2024-01-01T00:00:00Z",
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Clusters: []fleetnetv1alpha1.ClusterStatus{{Cluster: "member-2"}},
+		},
+	}
+	setClusterExportStatus(serviceImport, []*fleetnetv1alpha1.InternalServiceExport{
+		internalServiceExportForTest("member-5", staleCond),
+		internalServiceExportForTest("member-4", unauthorizedCond),
+		internalServiceExportForTest("member-3"),
+		internalServiceExportForTest("member-2"),
+		internalServiceExportForTest("member-1", conflictCond),
+	})
+
+	wantClusterExports := []fleetnetv1alpha1.ClusterExportStatus{
+		{
+			Cluster: "member-1",
+			State:   fleetnetv1alpha1.ClusterExportStateConflicted,
+			Reason:  conflictCond.Reason,
+			Message: conflictCond.Message,
+		},
+		{
+			Cluster: "member-2",
+			State:   fleetnetv1alpha1.ClusterExportStateAccepted,
+			Reason:  clusterExportReasonAccepted,
+			Message: "exported service is accepted",
+		},
+		{
+			Cluster: "member-3",
+			State:   fleetnetv1alpha1.ClusterExportStatePending,
+			Reason:  clusterExportReasonPending,
+			Message: "exported service is waiting to be processed",
+		},
+		{
+			Cluster: "member-4",
+			State:   fleetnetv1alpha1.ClusterExportStateRejected,
+			Reason:  unauthorizedCond.Reason,
+			Message: unauthorizedCond.Message,
+		},
+		{
+			Cluster: "member-5",
+			State:   fleetnetv1alpha1.ClusterExportStateStale,
+			Reason:  staleCond.Reason,
+			Message: staleCond.Message,
+		},
+	}
+	if diff := cmp.Diff(wantClusterExports, serviceImport.Status.ClusterExports); diff != "" {
+		t.Errorf("setClusterExportStatus() clusterExports mismatch (-want, +got):\n%s", diff)
+	}
+
+	wantConditions := []metav1.Condition{
+		{
+			Type:    string(fleetnetv1alpha1.ServiceImportReady),
+			Status:  metav1.ConditionTrue,
+			Reason:  conditionReasonServiceResolved,
+			Message: "service spec is resolved; 1 of 5 exporting cluster(s) are accepted",
+		},
+	}
+	if diff := cmp.Diff(wantConditions, serviceImport.Status.Conditions, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime")); diff != "" {
+		t.Errorf("setClusterExportStatus() conditions mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestSetClusterExportStatus_NoAcceptedExports(t *testing.T) {
+	unauthorizedCond := metav1.Condition{
+		Type:    string(fleetnetv1alpha1.ServiceExportAuthorized),
+		Status:  metav1.ConditionFalse,
+		Reason:  "ExportNotAllowed",
+		Message: "member cluster member-1 is not allowed to export service work/app by serviceExportImportPolicy tenant",
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{}
+	setClusterExportStatus(serviceImport, []*fleetnetv1alpha1.InternalServiceExport{
+		internalServiceExportForTest("member-1", unauthorizedCond),
+	})
+
+	wantStatus := fleetnetv1alpha1.ServiceImportStatus{
+		ClusterExports: []fleetnetv1alpha1.ClusterExportStatus{
+			{
+				Cluster: "member-1",
+				State:   fleetnetv1alpha1.ClusterExportStateRejected,
+				Reason:  unauthorizedCond.Reason,
+				Message: unauthorizedCond.Message,
+			},
+		},
+		Conditions: []metav1.Condition{
+			{
+				Type:    string(fleetnetv1alpha1.ServiceImportReady),
+				Status:  metav1.ConditionFalse,
+				Reason:  ConditionReasonNoAcceptedExports,
+				Message: "service spec is not resolved; 0 of 1 exporting cluster(s) are accepted",
+			},
+		},
+	}
+	if diff := cmp.Diff(wantStatus, serviceImport.Status, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime")); diff != "" {
+		t.Errorf("setClusterExportStatus() status mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestRemoveExportingCluster(t *testing.T) {
+	readyCond := metav1.Condition{
+		Type:    string(fleetnetv1alpha1.ServiceImportReady),
+		Status:  metav1.ConditionTrue,
+		Reason:  conditionReasonServiceResolved,
+		Message: "service spec is resolved; 1 of 2 exporting cluster(s) are accepted",
+	}
+	staleExport := fleetnetv1alpha1.ClusterExportStatus{
+		Cluster: "member-2",
+		State:   fleetnetv1alpha1.ClusterExportStateStale,
+		Reason:  "HeartbeatLost",
+	}
+	testCases := []struct {
+		name       string
+		status     fleetnetv1alpha1.ServiceImportStatus
+		wantStatus fleetnetv1alpha1.ServiceImportStatus
+	}{
+		{
+			name: "other exporting clusters are left",
+			status: fleetnetv1alpha1.ServiceImportStatus{
+				Type:           fleetnetv1alpha1.ClusterSetIP,
+				Clusters:       []fleetnetv1alpha1.ClusterStatus{{Cluster: "member-1"}, {Cluster: "member-3"}},
+				ClusterExports: []fleetnetv1alpha1.ClusterExportStatus{{Cluster: "member-1"}, {Cluster: "member-3"}},
+				Conditions:     []metav1.Condition{readyCond},
+			},
+			wantStatus: fleetnetv1alpha1.ServiceImportStatus{
+				Type:           fleetnetv1alpha1.ClusterSetIP,
+				Clusters:       []fleetnetv1alpha1.ClusterStatus{{Cluster: "member-3"}},
+				ClusterExports: []fleetnetv1alpha1.ClusterExportStatus{{Cluster: "member-3"}},
+				Conditions:     []metav1.Condition{readyCond},
+			},
+		},
+		{
+			name: "last accepted exporting cluster",
+			status: fleetnetv1alpha1.ServiceImportStatus{
+				Type:           fleetnetv1alpha1.ClusterSetIP,
+				Ports:          []fleetnetv1alpha1.ServicePort{{Port: 80}},
+				IPs:            []string{"10.0.0.1"},
+				Clusters:       []fleetnetv1alpha1.ClusterStatus{{Cluster: "member-1"}},
+				ClusterExports: []fleetnetv1alpha1.ClusterExportStatus{{Cluster: "member-1"}, staleExport},
+				ImportedBy:     []fleetnetv1alpha1.ClusterImportStatus{{ClusterNamespace: "fleet-member-member-3"}},
+				Conditions:     []metav1.Condition{readyCond},
+			},
+			wantStatus: fleetnetv1alpha1.ServiceImportStatus{
+				IPs:            []string{"10.0.0.1"},
+				ClusterExports: []fleetnetv1alpha1.ClusterExportStatus{staleExport},
+				ImportedBy:     []fleetnetv1alpha1.ClusterImportStatus{{ClusterNamespace: "fleet-member-member-3"}},
+				Conditions: []metav1.Condition{
+					{
+						Type:    string(fleetnetv1alpha1.ServiceImportReady),
+						Status:  metav1.ConditionFalse,
+						Reason:  ConditionReasonNoAcceptedExports,
+						Message: "service spec is not resolved; 0 of 1 exporting cluster(s) are accepted",
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			serviceImport := &fleetnetv1alpha1.ServiceImport{Status: tc.status}
+			RemoveExportingCluster(serviceImport, "member-1")
+			if diff := cmp.Diff(tc.wantStatus, serviceImport.Status, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime")); diff != "" {
+				t.Errorf("RemoveExportingCluster() status mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestReconcile_ClusterExports tests that the status of all the exporting clusters is reported when the service spec
+// is resolved, the same as once it is resolved.
+func TestReconcile_ClusterExports(t *testing.T) {
+	staleCond := metav1.Condition{
+		Type:    string(fleetnetv1alpha1.ServiceExportStale),
+		Status:  metav1.ConditionTrue,
+		Reason:  "HeartbeatLost",
+		Message: "member cluster member-2 has not sent heartbeats since 2024-01-01T00:00:00Z",
+	}
+	exportFor := func(clusterID string, conditions ...metav1.Condition) *fleetnetv1alpha1.InternalServiceExport {
+		export := internalServiceExportForTest(clusterID, conditions...)
+		export.Namespace = "fleet-member-" + clusterID
+		export.Name = "work-app"
+		export.Finalizers = []string{objectmeta.InternalServiceExportFinalizer}
+		export.Spec.Ports = []fleetnetv1alpha1.ServicePort{{Name: "http", Port: 80}}
+		export.Spec.ServiceReference.NamespacedName = "work/app"
+		return export
+	}
+	testCases := []struct {
+		name       string
+		exports    []*fleetnetv1alpha1.InternalServiceExport
+		wantStatus fleetnetv1alpha1.ServiceImportStatus
+	}{
+		{
+			name:    "resolved with a stale export",
+			exports: []*fleetnetv1alpha1.InternalServiceExport{exportFor("member-1"), exportFor("member-2", staleCond)},
+			wantStatus: fleetnetv1alpha1.ServiceImportStatus{
+				Type:            fleetnetv1alpha1.ClusterSetIP,
+				SessionAffinity: corev1.ServiceAffinityNone,
+				Ports:           []fleetnetv1alpha1.ServicePort{{Name: "http", Port: 80}},
+				Clusters:        []fleetnetv1alpha1.ClusterStatus{{Cluster: "member-1"}},
+				ClusterExports: []fleetnetv1alpha1.ClusterExportStatus{
+					{
+						Cluster: "member-1",
+						State:   fleetnetv1alpha1.ClusterExportStateAccepted,
+						Reason:  clusterExportReasonAccepted,
+						Message: "exported service is accepted",
+					},
+					{
+						Cluster: "member-2",
+						State:   fleetnetv1alpha1.ClusterExportStateStale,
+						Reason:  staleCond.Reason,
+						Message: staleCond.Message,
+					},
+				},
+				Conditions: []metav1.Condition{
+					{
+						Type:    string(fleetnetv1alpha1.ServiceImportReady),
+						Status:  metav1.ConditionTrue,
+						Reason:  conditionReasonServiceResolved,
+						Message: "service spec is resolved; 1 of 2 exporting cluster(s) are accepted",
+					},
+				},
+			},
+		},
+		{
+			name:    "all exports are stale",
+			exports: []*fleetnetv1alpha1.InternalServiceExport{exportFor("member-2", staleCond)},
+			wantStatus: fleetnetv1alpha1.ServiceImportStatus{
+				ClusterExports: []fleetnetv1alpha1.ClusterExportStatus{
+					{
+						Cluster: "member-2",
+						State:   fleetnetv1alpha1.ClusterExportStateStale,
+						Reason:  staleCond.Reason,
+						Message: staleCond.Message,
+					},
+				},
+				Conditions: []metav1.Condition{
+					{
+						Type:    string(fleetnetv1alpha1.ServiceImportReady),
+						Status:  metav1.ConditionFalse,
+						Reason:  ConditionReasonNoAcceptedExports,
+						Message: "service spec is not resolved; 0 of 1 exporting cluster(s) are accepted",
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("AddToScheme() = %v", err)
+			}
+			serviceImport := &fleetnetv1alpha1.ServiceImport{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "work", Name: "app"},
+			}
+			objects := []client.Object{serviceImport}
+			for _, export := range tc.exports {
+				objects = append(objects, export)
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(objects...).
+				WithStatusSubresource(objects...).
+				WithIndex(&fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldNamespacedName, func(o client.Object) []string {
+					return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.NamespacedName}
+				}).
+				Build()
+			r := &Reconciler{
+				Client:   fakeClient,
+				Recorder: record.NewFakeRecorder(10),
+			}
+
+			if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "work", Name: "app"}}); err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+			got := &fleetnetv1alpha1.ServiceImport{}
+			if err := fakeClient.Get(context.Background(), types.NamespacedName{Namespace: "work", Name: "app"}, got); err != nil {
+				t.Fatalf("Get() = %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.wantStatus, got.Status, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime")); diff != "" {
+				t.Errorf("Reconcile() status mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestEnsureClusterSetIP tests the Reconciler.ensureClusterSetIP method.
+func TestEnsureClusterSetIP(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("AddToScheme() = %v", err)
+	}
+	// A /30 CIDR has 2 usable addresses, one of which is in use.
+	allocator, err := clustersetip.NewAllocator("10.100.0.0/30")
+	if err != nil {
+		t.Fatalf("NewAllocator() = %v", err)
+	}
+	allocated := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "work", Name: "allocated"},
+		Status:     fleetnetv1alpha1.ServiceImportStatus{IPs: []string{"10.100.0.1"}},
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "work", Name: "app"},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(allocated, serviceImport).
+		WithStatusSubresource(serviceImport).
+		WithIndex(&fleetnetv1alpha1.ServiceImport{}, serviceImportClusterSetIPField, func(o client.Object) []string {
+			return o.(*fleetnetv1alpha1.ServiceImport).Status.IPs
+		}).
+		Build()
+	r := &Reconciler{
+		Client:                fakeClient,
+		Recorder:              record.NewFakeRecorder(10),
+		ClusterSetIPAllocator: allocator,
+	}
+
+	if err := r.ensureClusterSetIP(context.Background(), serviceImport); err != nil {
+		t.Fatalf("ensureClusterSetIP() = %v, want no error", err)
+	}
+	got := &fleetnetv1alpha1.ServiceImport{}
+	if err := fakeClient.Get(context.Background(), types.NamespacedName{Namespace: "work", Name: "app"}, got); err != nil {
+		t.Fatalf("Get() = %v, want no error", err)
+	}
+	if want := []string{"10.100.0.2"}; !cmp.Equal(got.Status.IPs, want) {
+		t.Errorf("ensureClusterSetIP() assigned %v, want %v", got.Status.IPs, want)
+	}
+}
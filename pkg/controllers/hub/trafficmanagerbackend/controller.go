@@ -15,10 +15,12 @@ import (
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/trafficmanager/armtrafficmanager"
 	"golang.org/x/sync/errgroup"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -31,11 +33,16 @@ import (
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 	"go.goms.io/fleet-networking/pkg/common/azureerrors"
+	"go.goms.io/fleet-networking/pkg/common/events"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
 	"go.goms.io/fleet-networking/pkg/common/objectmeta"
 	"go.goms.io/fleet-networking/pkg/controllers/hub/trafficmanagerprofile"
 )
 
 const (
+	// ControllerName is the name of the Reconciler.
+	ControllerName = "trafficmanagerbackend-controller"
+
 	trafficManagerBackendProfileFieldKey = ".spec.profile.name"
 	trafficManagerBackendBackendFieldKey = ".spec.backend.name"
 
@@ -68,6 +75,9 @@ type Reconciler struct {
 	ProfilesClient    *armtrafficmanager.ProfilesClient
 	EndpointsClient   *armtrafficmanager.EndpointsClient
 	ResourceGroupName string // default resource group name to create azure traffic manager resources
+	// Recorder records the changes of the Azure Traffic Manager endpoints and the transitions of the Accepted
+	// condition on the TrafficManagerBackends.
+	Recorder record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=trafficmanagerbackends,verbs=get;list;watch;create;update;patch;delete
@@ -111,7 +121,33 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 			return ctrl.Result{}, controller.NewUpdateIgnoreConflictError(err)
 		}
 	}
-	return r.handleUpdate(ctx, backend)
+
+	acceptedCondType := string(fleetnetv1alpha1.TrafficManagerBackendConditionAccepted)
+	previous := meta.FindStatusCondition(backend.Status.Conditions, acceptedCondType).DeepCopy()
+	resourceVersion := backend.ResourceVersion
+	res, err := r.handleUpdate(ctx, backend)
+	// The status has been written when the resource version changes.
+	if current := meta.FindStatusCondition(backend.Status.Conditions, acceptedCondType); backend.ResourceVersion != resourceVersion &&
+		current != nil && events.IsTransition(previous, *current) {
+		eventType := corev1.EventTypeNormal
+		if current.Status != metav1.ConditionTrue {
+			eventType = corev1.EventTypeWarning
+		}
+		r.Recorder.Event(backend, eventType, acceptedEventReason(current), current.Message)
+	}
+	return res, err
+}
+
+// acceptedEventReason returns the reason of the Event emitted when the Accepted condition transitions.
+func acceptedEventReason(cond *metav1.Condition) string {
+	switch fleetnetv1alpha1.TrafficManagerBackendConditionReason(cond.Reason) {
+	case fleetnetv1alpha1.TrafficManagerBackendReasonAccepted:
+		return events.ReasonAccepted
+	case fleetnetv1alpha1.TrafficManagerBackendReasonInvalid:
+		return events.ReasonInvalid
+	default:
+		return events.ReasonPending
+	}
 }
 
 func (r *Reconciler) handleDelete(ctx context.Context, backend *fleetnetv1alpha1.TrafficManagerBackend) (ctrl.Result, error) {
@@ -190,9 +226,12 @@ func (r *Reconciler) cleanupEndpoints(ctx context.Context, backend *fleetnetv1al
 					return nil
 				}
 				klog.ErrorS(err, "Failed to delete the endpoint", "trafficManagerBackend", backendKObj, "atmProfileName", atmProfileName, "azureEndpointName", *endpoint.Name)
+				r.Recorder.Eventf(backend, corev1.EventTypeWarning, events.ReasonDeleteFailed,
+					"Failed to delete Azure Traffic Manager endpoint %s of profile %s: %s", *endpoint.Name, atmProfileName, azureerrors.Summary(err))
 				return err
 			}
 			klog.V(2).InfoS("Deleted Azure Traffic Manager endpoint", "trafficManagerBackend", backendKObj, "atmProfileName", atmProfileName, "azureEndpointName", *endpoint.Name)
+			r.Recorder.Eventf(backend, corev1.EventTypeNormal, events.ReasonDeleted, "Deleted Azure Traffic Manager endpoint %s of profile %s", *endpoint.Name, atmProfileName)
 			return nil
 		})
 	}
@@ -402,7 +441,7 @@ func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) err
 			&fleetnetv1alpha1.ServiceImport{},
 			handler.EnqueueRequestsFromMapFunc(r.serviceImportEventHandler()),
 		).
-		Complete(r)
+		Complete(metrics.InstrumentReconciler("trafficmanagerbackend", r))
 }
 
 func (r *Reconciler) trafficManagerProfileEventHandler() handler.MapFunc {
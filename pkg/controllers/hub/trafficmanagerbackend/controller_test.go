@@ -0,0 +1,55 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package trafficmanagerbackend
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/events"
+)
+
+func TestAcceptedEventReason(t *testing.T) {
+	testCases := []struct {
+		name   string
+		reason fleetnetv1alpha1.TrafficManagerBackendConditionReason
+		want   string
+	}{
+		{
+			name:   "accepted",
+			reason: fleetnetv1alpha1.TrafficManagerBackendReasonAccepted,
+			want:   events.ReasonAccepted,
+		},
+		{
+			name:   "invalid",
+			reason: fleetnetv1alpha1.TrafficManagerBackendReasonInvalid,
+			want:   events.ReasonInvalid,
+		},
+		{
+			name:   "pending",
+			reason: fleetnetv1alpha1.TrafficManagerBackendReasonPending,
+			want:   events.ReasonPending,
+		},
+		{
+			name:   "unknown reason",
+			reason: "Unknown",
+			want:   events.ReasonPending,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cond := &metav1.Condition{
+				Type:   string(fleetnetv1alpha1.TrafficManagerBackendConditionAccepted),
+				Reason: string(tc.reason),
+			}
+			if got := acceptedEventReason(cond); got != tc.want {
+				t.Errorf("acceptedEventReason() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
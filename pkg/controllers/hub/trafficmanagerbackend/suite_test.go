@@ -112,6 +112,7 @@ var _ = BeforeSuite(func() {
 		ProfilesClient:    profileClient,
 		EndpointsClient:   endpointClient,
 		ResourceGroupName: fakeprovider.DefaultResourceGroupName,
+		Recorder:          mgr.GetEventRecorderFor(ControllerName),
 	}).SetupWithManager(ctx, mgr)
 	Expect(err).ToNot(HaveOccurred())
 
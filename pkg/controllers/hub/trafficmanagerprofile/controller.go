@@ -14,11 +14,13 @@ import (
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/trafficmanager/armtrafficmanager"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -30,10 +32,15 @@ import (
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 	"go.goms.io/fleet-networking/pkg/common/azureerrors"
 	"go.goms.io/fleet-networking/pkg/common/defaulter"
+	"go.goms.io/fleet-networking/pkg/common/events"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
 	"go.goms.io/fleet-networking/pkg/common/objectmeta"
 )
 
 const (
+	// ControllerName is the name of the Reconciler.
+	ControllerName = "trafficmanagerprofile-controller"
+
 	// DNSRelativeNameFormat consists of "Profile-Namespace" and "Profile-Name".
 	DNSRelativeNameFormat = "%s-%s"
 	// AzureResourceProfileNameFormat is the name format of the Azure Traffic Manager Profile created by the fleet controller.
@@ -58,6 +65,8 @@ type Reconciler struct {
 
 	ProfilesClient    *armtrafficmanager.ProfilesClient
 	ResourceGroupName string // default resource group name to create azure traffic manager profiles
+	// Recorder records the changes of the Azure Traffic Manager profiles on the TrafficManagerProfiles.
+	Recorder record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=trafficmanagerprofiles,verbs=get;list;watch;create;update;patch;delete
@@ -119,10 +128,13 @@ func (r *Reconciler) handleDelete(ctx context.Context, profile *fleetnetv1alpha1
 	if _, err := r.ProfilesClient.Delete(ctx, r.ResourceGroupName, atmProfileName, nil); err != nil {
 		if !azureerrors.IsNotFound(err) {
 			klog.ErrorS(err, "Failed to delete Azure Traffic Manager profile", "trafficManagerProfile", profileKObj, "atmProfileName", atmProfileName)
+			r.Recorder.Eventf(profile, corev1.EventTypeWarning, events.ReasonDeleteFailed,
+				"Failed to delete Azure Traffic Manager profile %s: %s", atmProfileName, azureerrors.Summary(err))
 			return ctrl.Result{}, err
 		}
 	}
 	klog.V(2).InfoS("Deleted Azure Traffic Manager profile", "trafficManagerProfile", profileKObj, "atmProfileName", atmProfileName)
+	r.Recorder.Eventf(profile, corev1.EventTypeNormal, events.ReasonDeleted, "Deleted Azure Traffic Manager profile %s", atmProfileName)
 
 	controllerutil.RemoveFinalizer(profile, objectmeta.TrafficManagerProfileFinalizer)
 	if err := r.Client.Update(ctx, profile); err != nil {
@@ -153,8 +165,15 @@ func (r *Reconciler) handleUpdate(ctx context.Context, profile *fleetnetv1alpha1
 		}
 	}
 
+	// The profile is created when it is not found, and updated otherwise.
+	reason, failedReason, action := events.ReasonUpdated, events.ReasonUpdateFailed, "update"
+	if getErr != nil {
+		reason, failedReason, action = events.ReasonCreated, events.ReasonCreateFailed, "create"
+	}
 	res, updateErr := r.ProfilesClient.CreateOrUpdate(ctx, r.ResourceGroupName, atmProfileName, generateAzureTrafficManagerProfile(profile), nil)
 	if updateErr != nil {
+		r.Recorder.Eventf(profile, corev1.EventTypeWarning, failedReason,
+			"Failed to %s Azure Traffic Manager profile %s: %s", action, atmProfileName, azureerrors.Summary(updateErr))
 		if !errors.As(updateErr, &responseError) {
 			klog.ErrorS(updateErr, "Failed to send the createOrUpdate request", "trafficManagerProfile", profileKObj, "atmProfileName", atmProfileName)
 			return ctrl.Result{}, updateErr
@@ -162,6 +181,9 @@ func (r *Reconciler) handleUpdate(ctx context.Context, profile *fleetnetv1alpha1
 		klog.ErrorS(updateErr, "Failed to create or update a profile", "trafficManagerProfile", profileKObj,
 			"atmProfileName", atmProfileName,
 			"errorCode", responseError.ErrorCode, "statusCode", responseError.StatusCode)
+	} else {
+		// The reasons are the past tense of the actions.
+		r.Recorder.Eventf(profile, corev1.EventTypeNormal, reason, "%s Azure Traffic Manager profile %s", reason, atmProfileName)
 	}
 	klog.V(2).InfoS("Created or updated Azure Traffic Manager Profile", "trafficManagerProfile", profileKObj, "atmProfileName", atmProfileName)
 	return r.updateProfileStatus(ctx, profile, res.Profile, updateErr)
@@ -274,5 +296,5 @@ func generateAzureTrafficManagerProfile(profile *fleetnetv1alpha1.TrafficManager
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&fleetnetv1alpha1.TrafficManagerProfile{}).
-		Complete(r)
+		Complete(metrics.InstrumentReconciler("trafficmanagerprofile", r))
 }
@@ -103,6 +103,7 @@ var _ = BeforeSuite(func() {
 		Client:            mgr.GetClient(),
 		ProfilesClient:    profileClient,
 		ResourceGroupName: fakeprovider.DefaultResourceGroupName,
+		Recorder:          mgr.GetEventRecorderFor(ControllerName),
 	}).SetupWithManager(mgr)
 	Expect(err).ToNot(HaveOccurred())
 
@@ -0,0 +1,208 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package clustersetdns features the ClusterSetDNS controller for serving the clusterset.local DNS records of the
+// imported services in a member cluster.
+//
+// The controller renders the cluster IPs of the derived Services behind all the ServiceImports in the member cluster,
+// which are the ClusterSetIPs allocated by the hub cluster unless they cannot be programmed, into a CoreDNS server
+// block and writes it into a ConfigMap, which is expected to be imported by the cluster DNS (e.g. the coredns-custom
+// ConfigMap on AKS, whose keys ending with ".server" are loaded as additional server blocks).
+package clustersetdns
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+const (
+	// ControllerName is the name of the Reconciler.
+	ControllerName = "clusterset-dns-controller"
+
+	// ClusterSetDomain is the DNS zone under which the imported services are resolvable.
+	ClusterSetDomain = "clusterset.local"
+
+	// ConfigMapDataKey is the key in the ConfigMap under which the CoreDNS server block is written.
+	ConfigMapDataKey = "clusterset.server"
+)
+
+// Reconciler reconciles the ConfigMap which holds the clusterset.local DNS records.
+type Reconciler struct {
+	client.Client
+	// ConfigMapNamespace and ConfigMapName identify the ConfigMap the DNS records are written into.
+	// Other keys in the ConfigMap are left untouched.
+	ConfigMapNamespace string
+	ConfigMapName      string
+	// FleetSystemNamespace is the namespace of the derived Services.
+	FleetSystemNamespace string
+}
+
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=multiclusterservices,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch
+
+// Reconcile renders the cluster IPs of the derived Services behind all the ServiceImports into the DNS ConfigMap.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	cmKRef := klog.KRef(req.Namespace, req.Name)
+	startTime := time.Now()
+	klog.V(2).InfoS("Reconciliation starts", "configMap", cmKRef)
+	defer func() {
+		latency := time.Since(startTime).Milliseconds()
+		klog.V(2).InfoS("Reconciliation ends", "configMap", cmKRef, "latency", latency)
+	}()
+
+	serviceImportList := &fleetnetv1alpha1.ServiceImportList{}
+	if err := r.Client.List(ctx, serviceImportList); err != nil {
+		klog.ErrorS(err, "Failed to list serviceImports")
+		return ctrl.Result{}, err
+	}
+
+	derivedServices, err := r.derivedServices(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	serverBlock := buildServerBlock(serviceImportList.Items, derivedServices)
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: req.Namespace,
+			Name:      req.Name,
+		},
+	}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, cm, func() error {
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[ConfigMapDataKey] = serverBlock
+		return nil
+	})
+	if err != nil {
+		klog.ErrorS(err, "Failed to create or update the clusterset DNS configMap", "configMap", cmKRef, "op", op)
+		return ctrl.Result{}, err
+	}
+	klog.V(2).InfoS("Synced the clusterset DNS configMap", "configMap", cmKRef, "op", op)
+	return ctrl.Result{}, nil
+}
+
+// derivedServices returns the derived Services keyed by the ServiceImports they are derived from.
+func (r *Reconciler) derivedServices(ctx context.Context) (map[types.NamespacedName]*corev1.Service, error) {
+	mcsList := &fleetnetv1alpha1.MultiClusterServiceList{}
+	if err := r.Client.List(ctx, mcsList, client.HasLabels{objectmeta.MultiClusterServiceLabelDerivedService}); err != nil {
+		klog.ErrorS(err, "Failed to list multiClusterServices")
+		return nil, err
+	}
+	serviceList := &corev1.ServiceList{}
+	if err := r.Client.List(ctx, serviceList, client.InNamespace(r.FleetSystemNamespace),
+		client.HasLabels{objectmeta.ServiceLabelMultiClusterServiceName, objectmeta.ServiceLabelMultiClusterServiceNamespace}); err != nil {
+		klog.ErrorS(err, "Failed to list derived services", "namespace", r.FleetSystemNamespace)
+		return nil, err
+	}
+	servicesByName := make(map[string]*corev1.Service, len(serviceList.Items))
+	for i := range serviceList.Items {
+		servicesByName[serviceList.Items[i].Name] = &serviceList.Items[i]
+	}
+
+	derivedServices := make(map[types.NamespacedName]*corev1.Service, len(mcsList.Items))
+	for i := range mcsList.Items {
+		mcs := &mcsList.Items[i]
+		service, ok := servicesByName[mcs.Labels[objectmeta.MultiClusterServiceLabelDerivedService]]
+		if !ok || !isDerivedServiceOwnedBy(service, mcs) {
+			// The derived service has not been created yet or is being replaced.
+			continue
+		}
+		derivedServices[types.NamespacedName{Namespace: mcs.Namespace, Name: mcs.Spec.ServiceImport.Name}] = service
+	}
+	return derivedServices, nil
+}
+
+func isDerivedServiceOwnedBy(service *corev1.Service, mcs *fleetnetv1alpha1.MultiClusterService) bool {
+	return service.Labels[objectmeta.ServiceLabelMultiClusterServiceName] == mcs.Name &&
+		service.Labels[objectmeta.ServiceLabelMultiClusterServiceNamespace] == mcs.Namespace
+}
+
+// buildServerBlock returns a CoreDNS server block which resolves <service>.<namespace>.svc.clusterset.local to the
+// cluster IPs of the derived Services of the ServiceImports.
+//
+// The cluster IPs of the derived Services are used instead of the ClusterSetIPs reported on the ServiceImports, as
+// the ClusterSetIP is not programmed on a derived Service if it falls outside the service CIDR of the cluster or
+// the Service was created before the ClusterSetIP was allocated.
+func buildServerBlock(serviceImports []fleetnetv1alpha1.ServiceImport, derivedServices map[types.NamespacedName]*corev1.Service) string {
+	var records []string
+	for i := range serviceImports {
+		svcImport := &serviceImports[i]
+		if svcImport.DeletionTimestamp != nil {
+			continue
+		}
+		service, ok := derivedServices[types.NamespacedName{Namespace: svcImport.Namespace, Name: svcImport.Name}]
+		if !ok || service.DeletionTimestamp != nil {
+			continue
+		}
+		hostname := fmt.Sprintf("%s.%s.svc.%s", svcImport.Name, svcImport.Namespace, ClusterSetDomain)
+		for _, ip := range service.Spec.ClusterIPs {
+			if ip == "" || ip == corev1.ClusterIPNone {
+				continue
+			}
+			records = append(records, fmt.Sprintf("%s %s", ip, hostname))
+		}
+	}
+	// Keep the output stable so that the ConfigMap is not rewritten when nothing changes.
+	sort.Strings(records)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s:53 {\n", ClusterSetDomain)
+	b.WriteString("    errors\n")
+	b.WriteString("    cache 30\n")
+	b.WriteString("    hosts {\n")
+	for _, record := range records {
+		fmt.Fprintf(&b, "        %s\n", record)
+	}
+	b.WriteString("    }\n")
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	isDNSConfigMap := predicate.NewPredicateFuncs(func(object client.Object) bool {
+		return object.GetNamespace() == r.ConfigMapNamespace && object.GetName() == r.ConfigMapName
+	})
+	isDerivedService := predicate.NewPredicateFuncs(func(object client.Object) bool {
+		_, ok := object.GetLabels()[objectmeta.ServiceLabelMultiClusterServiceName]
+		return object.GetNamespace() == r.FleetSystemNamespace && ok
+	})
+	// All the ServiceImports are rendered into the same ConfigMap.
+	enqueueDNSConfigMap := handler.EnqueueRequestsFromMapFunc(func(_ context.Context, _ client.Object) []reconcile.Request {
+		return []reconcile.Request{
+			{NamespacedName: types.NamespacedName{Namespace: r.ConfigMapNamespace, Name: r.ConfigMapName}},
+		}
+	})
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(ControllerName).
+		For(&corev1.ConfigMap{}, builder.WithPredicates(isDNSConfigMap)).
+		Watches(&fleetnetv1alpha1.ServiceImport{}, enqueueDNSConfigMap).
+		Watches(&fleetnetv1alpha1.MultiClusterService{}, enqueueDNSConfigMap).
+		Watches(&corev1.Service{}, enqueueDNSConfigMap, builder.WithPredicates(isDerivedService)).
+		Complete(metrics.InstrumentReconciler(ControllerName, r))
+}
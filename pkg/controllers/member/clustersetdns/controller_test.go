@@ -0,0 +1,217 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package clustersetdns
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+const (
+	testNamespace      = "work"
+	systemNamespace    = "kube-system"
+	fleetNamespace     = "fleet-system"
+	dnsConfigMapName   = "coredns-custom"
+	otherDataKey       = "log.override"
+	otherDataValue     = "log"
+	wantEmptyBlock     = "clusterset.local:53 {\n    errors\n    cache 30\n    hosts {\n    }\n}\n"
+	wantPopulatedBlock = "clusterset.local:53 {\n    errors\n    cache 30\n    hosts {\n" +
+		"        10.100.0.10 app.work.svc.clusterset.local\n" +
+		"        10.100.0.11 db.work.svc.clusterset.local\n" +
+		"    }\n}\n"
+)
+
+func serviceImportForTest(name string, ips ...string) *fleetnetv1alpha1.ServiceImport {
+	return &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      name,
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			IPs: ips,
+		},
+	}
+}
+
+func multiClusterServiceForTest(name, derivedServiceName string) *fleetnetv1alpha1.MultiClusterService {
+	return &fleetnetv1alpha1.MultiClusterService{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      name,
+			Labels: map[string]string{
+				objectmeta.MultiClusterServiceLabelDerivedService: derivedServiceName,
+			},
+		},
+		Spec: fleetnetv1alpha1.MultiClusterServiceSpec{
+			ServiceImport: fleetnetv1alpha1.ServiceImportRef{Name: name},
+		},
+	}
+}
+
+func derivedServiceForTest(name, mcsName string, clusterIPs ...string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: fleetNamespace,
+			Name:      name,
+			Labels: map[string]string{
+				objectmeta.ServiceLabelMultiClusterServiceName:      mcsName,
+				objectmeta.ServiceLabelMultiClusterServiceNamespace: testNamespace,
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIPs: clusterIPs,
+		},
+	}
+}
+
+// TestBuildServerBlock tests the buildServerBlock function.
+func TestBuildServerBlock(t *testing.T) {
+	testCases := []struct {
+		name            string
+		serviceImports  []fleetnetv1alpha1.ServiceImport
+		derivedServices map[types.NamespacedName]*corev1.Service
+		want            string
+	}{
+		{
+			name: "no serviceImports",
+			want: wantEmptyBlock,
+		},
+		{
+			name: "serviceImports without derived services",
+			serviceImports: []fleetnetv1alpha1.ServiceImport{
+				*serviceImportForTest("app", "10.100.0.10"),
+			},
+			want: wantEmptyBlock,
+		},
+		{
+			name: "serviceImports with derived services, in a stable order",
+			serviceImports: []fleetnetv1alpha1.ServiceImport{
+				*serviceImportForTest("db", "10.100.0.11"),
+				*serviceImportForTest("app", "10.100.0.10"),
+				*serviceImportForTest("pending"),
+				*serviceImportForTest("headless"),
+			},
+			derivedServices: map[types.NamespacedName]*corev1.Service{
+				{Namespace: testNamespace, Name: "db"}:       derivedServiceForTest("db-derived", "db", "10.100.0.11"),
+				{Namespace: testNamespace, Name: "app"}:      derivedServiceForTest("app-derived", "app", "10.100.0.10"),
+				{Namespace: testNamespace, Name: "pending"}:  derivedServiceForTest("pending-derived", "pending"),
+				{Namespace: testNamespace, Name: "headless"}: derivedServiceForTest("headless-derived", "headless", corev1.ClusterIPNone),
+			},
+			want: wantPopulatedBlock,
+		},
+		{
+			name: "derived service does not hold the ClusterSetIP",
+			serviceImports: []fleetnetv1alpha1.ServiceImport{
+				*serviceImportForTest("app", "192.168.0.10"),
+			},
+			derivedServices: map[types.NamespacedName]*corev1.Service{
+				{Namespace: testNamespace, Name: "app"}: derivedServiceForTest("app-derived", "app", "10.100.0.10"),
+			},
+			want: "clusterset.local:53 {\n    errors\n    cache 30\n    hosts {\n" +
+				"        10.100.0.10 app.work.svc.clusterset.local\n" +
+				"    }\n}\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := buildServerBlock(tc.serviceImports, tc.derivedServices); got != tc.want {
+				t.Errorf("buildServerBlock() mismatch (-want, +got):\n%s", cmp.Diff(tc.want, got))
+			}
+		})
+	}
+}
+
+// TestReconcile tests the Reconcile method.
+func TestReconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		existing *corev1.ConfigMap
+		want     map[string]string
+	}{
+		{
+			name: "should create the configMap",
+			want: map[string]string{ConfigMapDataKey: wantPopulatedBlock},
+		},
+		{
+			name: "should keep the other keys in the configMap",
+			existing: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: systemNamespace,
+					Name:      dnsConfigMapName,
+				},
+				Data: map[string]string{
+					otherDataKey:     otherDataValue,
+					ConfigMapDataKey: wantEmptyBlock,
+				},
+			},
+			want: map[string]string{
+				otherDataKey:     otherDataValue,
+				ConfigMapDataKey: wantPopulatedBlock,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+				serviceImportForTest("app", "10.100.0.10"),
+				serviceImportForTest("db", "10.100.0.11"),
+				multiClusterServiceForTest("app", "app-derived"),
+				multiClusterServiceForTest("db", "db-derived"),
+				derivedServiceForTest("app-derived", "app", "10.100.0.10"),
+				derivedServiceForTest("db-derived", "db", "10.100.0.11"),
+				// The previous derived service of a MCS is not resolved.
+				derivedServiceForTest("db-previous", "db", "10.100.0.12"),
+				// A derived service which is not owned by the MCS is not resolved.
+				multiClusterServiceForTest("other", "app-derived"),
+				serviceImportForTest("other"),
+			)
+			if tc.existing != nil {
+				builder = builder.WithObjects(tc.existing)
+			}
+			fakeClient := builder.Build()
+			r := &Reconciler{
+				Client:               fakeClient,
+				ConfigMapNamespace:   systemNamespace,
+				ConfigMapName:        dnsConfigMapName,
+				FleetSystemNamespace: fleetNamespace,
+			}
+			name := types.NamespacedName{Namespace: systemNamespace, Name: dnsConfigMapName}
+			ctx := context.Background()
+			if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: name}); err != nil {
+				t.Fatalf("Reconcile() = %v, want nil", err)
+			}
+
+			got := &corev1.ConfigMap{}
+			if err := fakeClient.Get(ctx, name, got); err != nil {
+				t.Fatalf("configMap Get() = %v, want nil", err)
+			}
+			if diff := cmp.Diff(tc.want, got.Data); diff != "" {
+				t.Errorf("configMap data mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -29,6 +30,7 @@ import (
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 	"go.goms.io/fleet-networking/pkg/common/metrics"
 	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/tracing"
 	"go.goms.io/fleet-networking/pkg/common/uniquename"
 )
 
@@ -68,6 +70,14 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		klog.V(2).InfoS("Reconciliation ends", "endpointSlice", endpointSliceRef, "latency", latency)
 	}()
 
+	// Start a trace for the export; the trace is continued by the controllers which distribute and import the
+	// exported EndpointSlice, if the export changes.
+	ctx, span := tracing.StartSpan(ctx, "ExportEndpointSlice",
+		tracing.AttributeKeyNamespace.String(req.Namespace),
+		tracing.AttributeKeyName.String(req.Name),
+		tracing.AttributeKeyClusterID.String(r.MemberClusterID))
+	defer span.End()
+
 	// Retrieve the EndpointSlice object.
 	var endpointSlice discoveryv1.EndpointSlice
 	if err := r.MemberClient.Get(ctx, req.NamespacedName, &endpointSlice); err != nil {
@@ -146,7 +156,9 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	klog.V(2).InfoS("Endpoint slice will be exported",
 		"endpointSlice", endpointSliceRef,
 		"endpointSliceExport", klog.KObj(&endpointSliceExport))
-	createOrUpdateOp, err := controllerutil.CreateOrUpdate(ctx, r.HubClient, &endpointSliceExport, func() error {
+	exportCtx, exportSpan := tracing.StartSpan(ctx, "CreateOrUpdateEndpointSliceExport")
+	createOrUpdateOp, err := controllerutil.CreateOrUpdate(exportCtx, r.HubClient, &endpointSliceExport, func() error {
+		oldSpec := endpointSliceExport.Spec.DeepCopy()
 		// Set up an EndpointSliceReference and only when an EndpointSliceExport is first created; this is because
 		// most fields in EndpointSliceReference should be immutable after creation.
 		if endpointSliceExport.CreationTimestamp.IsZero() {
@@ -176,8 +188,13 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		}
 
 		endpointSliceExport.Spec.EndpointSliceReference.UpdateFromMetaObject(endpointSlice.ObjectMeta, metav1.NewTime(exportedSince))
+		// Stamp the trace context only when the export changes, so that an unchanged export is not updated.
+		if !equality.Semantic.DeepEqual(oldSpec, &endpointSliceExport.Spec) {
+			tracing.Inject(exportCtx, &endpointSliceExport)
+		}
 		return nil
 	})
+	tracing.EndSpan(exportSpan, err)
 	switch {
 	case errors.IsAlreadyExists(err):
 		// Remove the unique name annotation; a new one will be assigned in future reciliation attempts.
@@ -231,7 +248,7 @@ func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) err
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&discoveryv1.EndpointSlice{}).
 		Watches(&fleetnetv1alpha1.ServiceExport{}, eventHandlers).
-		Complete(r)
+		Complete(metrics.InstrumentReconciler("endpointslice", r))
 }
 
 // shouldSkipOrUnexportEndpointSlice returns the op the controller should take on an EndpointSlice, specifically
@@ -19,6 +19,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
 	"go.goms.io/fleet-networking/pkg/common/objectmeta"
 )
 
@@ -104,7 +105,7 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 		// The EndpointSliceExport controller watches over EndpointSliceExport objects.
 		// TO-DO (chenyu1): use predicates to filter out some events.
 		For(&fleetnetv1alpha1.EndpointSliceExport{}).
-		Complete(r)
+		Complete(metrics.InstrumentReconciler("endpointsliceexport", r))
 }
 
 // deleteEndpointSliceExport deletes an EndpointSliceExport from the hub cluster.
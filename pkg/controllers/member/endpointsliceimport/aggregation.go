@@ -0,0 +1,337 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package endpointsliceimport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/tracing"
+)
+
+const (
+	// maxEndpointsPerAggregatedSlice is the maximum number of endpoints packed into an aggregated EndpointSlice;
+	// it matches the default maximum number of endpoints per EndpointSlice of the Kubernetes EndpointSlice controller.
+	maxEndpointsPerAggregatedSlice = 100
+)
+
+// aggregatedEndpoint is an endpoint to import in the aggregation mode.
+type aggregatedEndpoint struct {
+	// source is the name of the EndpointSliceImport the endpoint comes from.
+	source string
+	// format identifies the address type and the ports of the endpoint; only endpoints of the same format can be
+	// packed into the same EndpointSlice.
+	format      string
+	addressType discoveryv1.AddressType
+	ports       []discoveryv1.EndpointPort
+	endpoint    discoveryv1.Endpoint
+}
+
+// aggregatedSlice is an aggregated EndpointSlice planned by the controller.
+type aggregatedSlice struct {
+	name        string
+	format      string
+	addressType discoveryv1.AddressType
+	ports       []discoveryv1.EndpointPort
+	endpoints   []discoveryv1.Endpoint
+	sources     sets.Set[string]
+}
+
+// importAggregatedEndpointSlices packs the endpoints of all the EndpointSliceImports of a Service in the member
+// cluster into aggregated EndpointSlices associated with the derived Service, and returns the references to the
+// aggregated EndpointSlices which carry the endpoints of the given EndpointSliceImport. If derivedSvcName is empty,
+// all the aggregated EndpointSlices of the Service are removed.
+func (r *Reconciler) importAggregatedEndpointSlices(ctx context.Context,
+	endpointSliceImport *fleetnetv1alpha1.EndpointSliceImport,
+	derivedSvcName string,
+	distribution *fleetnetv1alpha1.TrafficDistribution) ([]fleetnetv1alpha1.ImportedEndpointSliceReference, error) {
+	ownerSvcRef := endpointSliceImport.Spec.OwnerServiceReference
+
+	var desired []aggregatedEndpoint
+	if derivedSvcName != "" {
+		endpointSliceImportList := &fleetnetv1alpha1.EndpointSliceImportList{}
+		if err := r.HubClient.List(ctx, endpointSliceImportList,
+			client.InNamespace(endpointSliceImport.Namespace),
+			client.MatchingFields{endpointSliceImportOwnerSvcNamespacedNameFieldKey: ownerSvcRef.NamespacedName},
+		); err != nil {
+			return nil, fmt.Errorf("failed to list endpointSliceImports of service %s: %w", ownerSvcRef.NamespacedName, err)
+		}
+		desired = aggregatedEndpointsToImport(distribution, r.MemberClusterID, endpointSliceImportList.Items)
+	}
+
+	endpointSliceList, err := r.listAggregatedEndpointSlices(ctx, ownerSvcRef)
+	if err != nil {
+		return nil, err
+	}
+	existingEndpointSlices := make(map[string]*discoveryv1.EndpointSlice, len(endpointSliceList.Items))
+	for i := range endpointSliceList.Items {
+		existingEndpointSlices[endpointSliceList.Items[i].Name] = &endpointSliceList.Items[i]
+	}
+
+	var importedEndpointSlices []fleetnetv1alpha1.ImportedEndpointSliceReference
+	slices, staleSlices := planAggregatedEndpointSlices(endpointSliceList.Items, desired, derivedSvcName)
+	for _, slice := range slices {
+		if slice.sources.Has(endpointSliceImport.Name) {
+			importedEndpointSlices = append(importedEndpointSlices, fleetnetv1alpha1.ImportedEndpointSliceReference{
+				Namespace: r.FleetSystemNamespace,
+				Name:      slice.name,
+			})
+		}
+		endpointSlice := formatAggregatedEndpointSlice(slice, r.FleetSystemNamespace, derivedSvcName, ownerSvcRef)
+		if existing, ok := existingEndpointSlices[slice.name]; ok &&
+			isEndpointSliceUpToDate(existing, endpointSlice) &&
+			existing.Annotations[objectmeta.EndpointSliceAnnotationAggregatedSources] == endpointSlice.Annotations[objectmeta.EndpointSliceAnnotationAggregatedSources] {
+			continue
+		}
+		klog.V(2).InfoS("Apply aggregated EndpointSlice", "endpointSlice", klog.KObj(endpointSlice), "endpointCount", len(slice.endpoints))
+		applyCtx, applySpan := tracing.StartSpan(ctx, "ApplyAggregatedEndpointSlice")
+		err := r.MemberClient.Patch(applyCtx, endpointSlice, client.Apply, client.FieldOwner(ControllerID), client.ForceOwnership)
+		tracing.EndSpan(applySpan, err)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply aggregated endpointSlice %s: %w", slice.name, err)
+		}
+	}
+	for _, staleSlice := range staleSlices {
+		klog.V(2).InfoS("Delete aggregated EndpointSlice", "endpointSlice", klog.KObj(staleSlice))
+		if err := r.MemberClient.Delete(ctx, staleSlice); err != nil && !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to delete aggregated endpointSlice %s: %w", staleSlice.Name, err)
+		}
+	}
+
+	// Remove the EndpointSlice imported from the EndpointSliceImport before the aggregation mode is enabled (if any).
+	if err := r.deleteEndpointSliceIfExists(ctx, endpointSliceImport.Name, func(endpointSlice *discoveryv1.EndpointSlice) bool {
+		_, ok := endpointSlice.Labels[objectmeta.EndpointSliceLabelAggregatedServiceName]
+		return !ok
+	}); err != nil {
+		return nil, err
+	}
+	return importedEndpointSlices, nil
+}
+
+// removeAggregatedEndpointSlices removes all the aggregated EndpointSlices of a Service; it is used when the
+// aggregation mode is disabled.
+func (r *Reconciler) removeAggregatedEndpointSlices(ctx context.Context, ownerSvcRef fleetnetv1alpha1.OwnerServiceReference) error {
+	endpointSliceList, err := r.listAggregatedEndpointSlices(ctx, ownerSvcRef)
+	if err != nil {
+		return err
+	}
+	for i := range endpointSliceList.Items {
+		endpointSlice := &endpointSliceList.Items[i]
+		klog.V(2).InfoS("Delete aggregated EndpointSlice", "endpointSlice", klog.KObj(endpointSlice))
+		if err := r.MemberClient.Delete(ctx, endpointSlice); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete aggregated endpointSlice %s: %w", endpointSlice.Name, err)
+		}
+	}
+	return nil
+}
+
+// listAggregatedEndpointSlices lists the aggregated EndpointSlices of a Service.
+func (r *Reconciler) listAggregatedEndpointSlices(ctx context.Context, ownerSvcRef fleetnetv1alpha1.OwnerServiceReference) (*discoveryv1.EndpointSliceList, error) {
+	endpointSliceList := &discoveryv1.EndpointSliceList{}
+	if err := r.MemberClient.List(ctx, endpointSliceList,
+		client.InNamespace(r.FleetSystemNamespace),
+		client.MatchingLabels{
+			discoveryv1.LabelManagedBy:                              ControllerID,
+			objectmeta.EndpointSliceLabelAggregatedServiceNamespace: ownerSvcRef.Namespace,
+			objectmeta.EndpointSliceLabelAggregatedServiceName:      ownerSvcRef.Name,
+		},
+	); err != nil {
+		return nil, fmt.Errorf("failed to list aggregated endpointSlices of service %s: %w", ownerSvcRef.NamespacedName, err)
+	}
+	return endpointSliceList, nil
+}
+
+// deleteEndpointSliceIfExists deletes an EndpointSlice in the fleet system namespace if it exists and satisfies
+// the given condition.
+func (r *Reconciler) deleteEndpointSliceIfExists(ctx context.Context, name string, shouldDelete func(*discoveryv1.EndpointSlice) bool) error {
+	endpointSlice := &discoveryv1.EndpointSlice{}
+	if err := r.MemberClient.Get(ctx, types.NamespacedName{Namespace: r.FleetSystemNamespace, Name: name}, endpointSlice); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !shouldDelete(endpointSlice) {
+		return nil
+	}
+	klog.V(2).InfoS("Delete EndpointSlice", "endpointSlice", klog.KObj(endpointSlice))
+	return client.IgnoreNotFound(r.MemberClient.Delete(ctx, endpointSlice))
+}
+
+// aggregatedEndpointsToImport returns the endpoints to import from a list of EndpointSliceImports of the same
+// Service, as per the traffic distribution, in the order of the names of the EndpointSliceImports.
+func aggregatedEndpointsToImport(distribution *fleetnetv1alpha1.TrafficDistribution, localClusterID string,
+	endpointSliceImports []fleetnetv1alpha1.EndpointSliceImport) []aggregatedEndpoint {
+	sorted := make([]*fleetnetv1alpha1.EndpointSliceImport, 0, len(endpointSliceImports))
+	for i := range endpointSliceImports {
+		if endpointSliceImports[i].DeletionTimestamp == nil {
+			sorted = append(sorted, &endpointSliceImports[i])
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	var endpoints []aggregatedEndpoint
+	for _, endpointSliceImport := range sorted {
+		format := aggregatedSliceFormat(endpointSliceImport.Spec.AddressType, endpointSliceImport.Spec.Ports)
+		endpointCount := endpointsToImport(distribution, localClusterID, endpointSliceImport, endpointSliceImports)
+		for _, importedEndpoint := range endpointSliceImport.Spec.Endpoints[:endpointCount] {
+			endpoints = append(endpoints, aggregatedEndpoint{
+				source:      endpointSliceImport.Name,
+				format:      format,
+				addressType: endpointSliceImport.Spec.AddressType,
+				ports:       endpointSliceImport.Spec.Ports,
+				endpoint: discoveryv1.Endpoint{
+					Addresses: importedEndpoint.Addresses,
+				},
+			})
+		}
+	}
+	return endpoints
+}
+
+// planAggregatedEndpointSlices packs endpoints into aggregated EndpointSlices of up to
+// maxEndpointsPerAggregatedSlice endpoints each, and returns the EndpointSlices to keep (create or update) and the
+// EndpointSlices to delete.
+//
+// To limit churn, an endpoint already carried by an existing EndpointSlice stays in that EndpointSlice; new
+// endpoints fill the free capacity of the existing EndpointSlices first, before new EndpointSlices, named after
+// the derived Service, are created.
+func planAggregatedEndpointSlices(existing []discoveryv1.EndpointSlice, desired []aggregatedEndpoint, derivedSvcName string) (
+	slices []*aggregatedSlice, staleSlices []*discoveryv1.EndpointSlice) {
+	desiredByKey := make(map[string]*aggregatedEndpoint, len(desired))
+	for i := range desired {
+		desiredByKey[aggregatedEndpointKey(desired[i].format, desired[i].endpoint)] = &desired[i]
+	}
+
+	sortedExisting := make([]*discoveryv1.EndpointSlice, 0, len(existing))
+	for i := range existing {
+		sortedExisting = append(sortedExisting, &existing[i])
+	}
+	sort.Slice(sortedExisting, func(i, j int) bool {
+		return sortedExisting[i].Name < sortedExisting[j].Name
+	})
+
+	// Keep the endpoints which are still desired in their current EndpointSlices.
+	usedNames := sets.New[string]()
+	placed := sets.New[string]()
+	for _, endpointSlice := range sortedExisting {
+		usedNames.Insert(endpointSlice.Name)
+		format := aggregatedSliceFormat(endpointSlice.AddressType, endpointSlice.Ports)
+		slice := &aggregatedSlice{
+			name:        endpointSlice.Name,
+			format:      format,
+			addressType: endpointSlice.AddressType,
+			ports:       endpointSlice.Ports,
+			sources:     sets.New[string](),
+		}
+		for _, endpoint := range endpointSlice.Endpoints {
+			key := aggregatedEndpointKey(format, endpoint)
+			desiredEndpoint, ok := desiredByKey[key]
+			if !ok || placed.Has(key) || len(slice.endpoints) >= maxEndpointsPerAggregatedSlice {
+				continue
+			}
+			slice.endpoints = append(slice.endpoints, desiredEndpoint.endpoint)
+			slice.sources.Insert(desiredEndpoint.source)
+			placed.Insert(key)
+		}
+		if len(slice.endpoints) == 0 {
+			staleSlices = append(staleSlices, endpointSlice)
+			continue
+		}
+		slices = append(slices, slice)
+	}
+
+	// Pack the new endpoints.
+	nextIdx := 0
+	for i := range desired {
+		desiredEndpoint := &desired[i]
+		key := aggregatedEndpointKey(desiredEndpoint.format, desiredEndpoint.endpoint)
+		if placed.Has(key) {
+			continue
+		}
+		placed.Insert(key)
+
+		var target *aggregatedSlice
+		for _, slice := range slices {
+			if slice.format == desiredEndpoint.format && len(slice.endpoints) < maxEndpointsPerAggregatedSlice {
+				target = slice
+				break
+			}
+		}
+		if target == nil {
+			name := fmt.Sprintf("%s-%d", derivedSvcName, nextIdx)
+			for usedNames.Has(name) {
+				nextIdx++
+				name = fmt.Sprintf("%s-%d", derivedSvcName, nextIdx)
+			}
+			usedNames.Insert(name)
+			target = &aggregatedSlice{
+				name:        name,
+				format:      desiredEndpoint.format,
+				addressType: desiredEndpoint.addressType,
+				ports:       desiredEndpoint.ports,
+				sources:     sets.New[string](),
+			}
+			slices = append(slices, target)
+		}
+		target.endpoints = append(target.endpoints, desiredEndpoint.endpoint)
+		target.sources.Insert(desiredEndpoint.source)
+	}
+	return slices, staleSlices
+}
+
+// formatAggregatedEndpointSlice formats an aggregated EndpointSlice for server-side apply.
+func formatAggregatedEndpointSlice(slice *aggregatedSlice, namespace, derivedSvcName string, ownerSvcRef fleetnetv1alpha1.OwnerServiceReference) *discoveryv1.EndpointSlice {
+	return &discoveryv1.EndpointSlice{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: discoveryv1.SchemeGroupVersion.String(),
+			Kind:       "EndpointSlice",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      slice.name,
+			Labels: map[string]string{
+				discoveryv1.LabelServiceName:                            derivedSvcName,
+				discoveryv1.LabelManagedBy:                              ControllerID,
+				objectmeta.EndpointSliceLabelAggregatedServiceNamespace: ownerSvcRef.Namespace,
+				objectmeta.EndpointSliceLabelAggregatedServiceName:      ownerSvcRef.Name,
+			},
+			Annotations: map[string]string{
+				objectmeta.EndpointSliceAnnotationAggregatedSources: strings.Join(sets.List(slice.sources), ","),
+			},
+		},
+		AddressType: slice.addressType,
+		Ports:       slice.ports,
+		Endpoints:   slice.endpoints,
+	}
+}
+
+// aggregatedSliceFormat returns the format of an EndpointSlice, which identifies its address type and ports.
+func aggregatedSliceFormat(addressType discoveryv1.AddressType, ports []discoveryv1.EndpointPort) string {
+	if len(ports) == 0 {
+		ports = nil
+	}
+	// Marshalling the ports never fails.
+	data, _ := json.Marshal(ports)
+	return string(addressType) + "/" + string(data)
+}
+
+// aggregatedEndpointKey returns the key which identifies an endpoint of a given format.
+func aggregatedEndpointKey(format string, endpoint discoveryv1.Endpoint) string {
+	return format + "/" + strings.Join(endpoint.Addresses, ",")
+}
@@ -15,7 +15,9 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	corev1 "k8s.io/api/core/v1"
 	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/validation"
@@ -23,21 +25,33 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/condition"
 	"go.goms.io/fleet-networking/pkg/common/metrics"
 	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/tracing"
 )
 
 const (
-	// controllerID helps identify that imported EndpointSlices are managed by this controller.
-	controllerID                        = "endpointsliceimport-controller.networking.fleet.azure.com"
+	// ControllerID helps identify that imported EndpointSlices are managed by this controller.
+	ControllerID = "endpointsliceimport-controller.networking.fleet.azure.com"
+
 	endpointSliceImportCleanupFinalizer = "networking.fleet.azure.com/endpointsliceimport-cleanup"
 
-	mcsServiceImportRefFieldKey = ".spec.serviceImport.name"
+	mcsServiceImportRefFieldKey                       = ".spec.serviceImport.name"
+	endpointSliceImportOwnerSvcNamespacedNameFieldKey = ".spec.ownerServiceReference.namespacedName"
 
 	endpointSliceImportRetryInterval = time.Second * 2
+
+	// The reasons of the Imported and ImportPending conditions of an EndpointSliceImport.
+	importReasonImported                      = "Imported"
+	importReasonNoMatchingMultiClusterService = "NoMatchingMultiClusterService"
+	importReasonNoValidDerivedService         = "NoValidDerivedService"
 )
 
 var (
@@ -82,10 +96,14 @@ type Reconciler struct {
 	HubClient       client.Client
 	// The namespace reserved for fleet resources in the member cluster.
 	FleetSystemNamespace string
+	// AggregateEndpointSlices enables the aggregation mode, in which the endpoints imported for a Service are packed
+	// into EndpointSlices of up to 100 endpoints each, instead of one EndpointSlice per EndpointSliceImport.
+	AggregateEndpointSlices bool
 }
 
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=endpointsliceimports,verbs=get;list;watch;update;patch
-//+kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;create;update;patch;delete
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=endpointsliceimports/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=multiclusterservices,verbs=get;list;watch
 //+kubebuilder:rbac:groups="",resources=services,verbs=get;list
 
@@ -113,6 +131,11 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return ctrl.Result{}, err
 	}
 
+	// Continue the trace of the export, as passed on by the hub cluster when distributing the EndpointSlice.
+	ctx, span := tracing.StartSpanFromObject(ctx, endpointSliceImport, "ImportEndpointSlice",
+		tracing.AttributeKeyClusterID.String(endpointSliceImport.Spec.EndpointSliceReference.ClusterID))
+	defer span.End()
+
 	// Check if the EndpointSliceImport has been deleted and needs cleanup (unimport EndpointSlice).
 	// An EndpointSliceImport needs cleanup when it has the EndpointSliceImport cleanup finalizer added;
 	// the absence of this finalizer guarantees that the EndpointSliceImport has never been imported.
@@ -158,6 +181,11 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		klog.V(2).InfoS("No matching MCS is found; EndpointSlice will not be imported",
 			"serviceImport", klog.KRef(ownerSvcNS, ownerSvcName),
 			"endpointSliceImport", endpointSliceImportRef)
+		conds := importConditions(endpointSliceImport, false, importReasonNoMatchingMultiClusterService,
+			fmt.Sprintf("no multiClusterService imports service %s", endpointSliceImport.Spec.OwnerServiceReference.NamespacedName))
+		if err := r.updateEndpointSliceImportStatus(ctx, endpointSliceImport, conds, endpointSliceImport.Status.ImportedEndpointSlices); err != nil {
+			return ctrl.Result{}, err
+		}
 		return ctrl.Result{}, nil
 	}
 
@@ -191,18 +219,17 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		klog.V(2).InfoS("No valid derived Service; will retry importing EndpointSlice later",
 			"derivedServiceName", derivedSvcName,
 			"endpointSliceImport", endpointSliceImportRef)
+		conds := importConditions(endpointSliceImport, false, importReasonNoValidDerivedService,
+			fmt.Sprintf("derived service %q of service %s is not valid", derivedSvcName, endpointSliceImport.Spec.OwnerServiceReference.NamespacedName))
+		if err := r.updateEndpointSliceImportStatus(ctx, endpointSliceImport, conds, endpointSliceImport.Status.ImportedEndpointSlices); err != nil {
+			return ctrl.Result{}, err
+		}
 		return ctrl.Result{RequeueAfter: endpointSliceImportRetryInterval}, nil
 	}
 
-	// Special note:
-	// There exists a corner case where an MCS that imports a specific Service have multiple derived Services created;
-	// this is usually the result of direct label manipulation on the user's end. Ideally, this controller should watch
-	// for changes on MCS resources and (re)associate imported EndpointSlices to the latest dervied Service in use;
-	// however, unfortunately, with the current implementation of controller-runtime package, it is not possible
-	// for the controller to watch for resources on two different directions (member cluster and hub cluster),
-	// and as a result, an imported EndpointSlice could be bound to a derived Service that is no longer in use.
-	// Periodic resyncs can help address this issue, but it may take a quite long while before the situation is
-	// corrected, should this corner case happens.
+	// Note that the controller watches for changes on MCS resources in the member cluster as well; whenever the
+	// derived Service of an MCS changes (e.g. the derived Service is migrated, or the label is manipulated on
+	// the user's end), the imported EndpointSlices are (re)associated with the latest derived Service in use.
 
 	// Add the cleanup finalizer (if one has not been added earlier); this must happen before
 	// the EndpointSlice is imported.
@@ -212,25 +239,31 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return ctrl.Result{}, err
 	}
 
-	// Associate the EndpointSlice with the Service.
-	klog.V(2).InfoS("Import the EndpointSlice", "endpointSlice", endpointSliceRef)
-	endpointSlice := &discoveryv1.EndpointSlice{
-		ObjectMeta: metav1.ObjectMeta{
-			Namespace: r.FleetSystemNamespace,
-			Name:      endpointSliceImport.Name,
-		},
-	}
-	if op, err := controllerutil.CreateOrUpdate(ctx, r.MemberClient, endpointSlice, func() error {
-		formatEndpointSliceFromImport(endpointSlice, derivedSvcName, endpointSliceImport)
-		return nil
-	}); err != nil {
-		klog.ErrorS(err, "Failed to create/update EndpointSlice",
+	distribution := scanForTrafficDistribution(multiClusterSvcList, derivedSvcName)
+	var importedEndpointSlices []fleetnetv1alpha1.ImportedEndpointSliceReference
+	if r.AggregateEndpointSlices {
+		// Pack the endpoints of all the EndpointSliceImports of the Service into aggregated EndpointSlices.
+		klog.V(2).InfoS("Import the EndpointSlice in the aggregation mode", "endpointSliceImport", endpointSliceImportRef)
+		if importedEndpointSlices, err = r.importAggregatedEndpointSlices(ctx, endpointSliceImport, derivedSvcName, distribution); err != nil {
+			klog.ErrorS(err, "Failed to import aggregated EndpointSlices",
+				"derivedServiceName", derivedSvcName,
+				"endpointSliceImport", endpointSliceImportRef)
+			return ctrl.Result{}, err
+		}
+	} else if importedEndpointSlices, err = r.importEndpointSlice(ctx, endpointSliceImport, derivedSvcName, distribution); err != nil {
+		klog.ErrorS(err, "Failed to import EndpointSlice",
 			"endpointSlice", endpointSliceRef,
-			"op", op,
 			"endpointSliceImport", endpointSliceImportRef)
 		return ctrl.Result{}, err
 	}
 
+	// Report the import outcome back to the hub cluster.
+	conds := importConditions(endpointSliceImport, true, importReasonImported,
+		fmt.Sprintf("endpointSlice has been imported and associated with derived service %s", derivedSvcName))
+	if err := r.updateEndpointSliceImportStatus(ctx, endpointSliceImport, conds, importedEndpointSlices); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Observe a data point for the EndpointSliceExportImportDuration metric.
 	if err := r.observeMetrics(ctx, endpointSliceImport, time.Now()); err != nil {
 		klog.Warning("Failed to observe metrics", "error", err, "endpointSliceImport", endpointSliceImportRef)
@@ -240,6 +273,62 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	return ctrl.Result{}, nil
 }
 
+// importEndpointSlice imports an EndpointSlice from an EndpointSliceImport, or updates an imported EndpointSlice;
+// it returns the reference to the imported EndpointSlice.
+func (r *Reconciler) importEndpointSlice(ctx context.Context,
+	endpointSliceImport *fleetnetv1alpha1.EndpointSliceImport,
+	derivedSvcName string,
+	distribution *fleetnetv1alpha1.TrafficDistribution) ([]fleetnetv1alpha1.ImportedEndpointSliceReference, error) {
+	endpointSliceImportRef := klog.KObj(endpointSliceImport)
+	endpointSliceRef := klog.KRef(r.FleetSystemNamespace, endpointSliceImport.Name)
+
+	// Find out how many endpoints should be imported from the EndpointSliceImport, as per the traffic
+	// distribution of the MCS.
+	endpointCount, err := r.endpointsToImport(ctx, endpointSliceImport, distribution)
+	if err != nil {
+		return nil, err
+	}
+
+	// Associate the EndpointSlice with the Service.
+	endpointSlice := &discoveryv1.EndpointSlice{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: discoveryv1.SchemeGroupVersion.String(),
+			Kind:       "EndpointSlice",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: r.FleetSystemNamespace,
+			Name:      endpointSliceImport.Name,
+		},
+	}
+	formatEndpointSliceFromImport(endpointSlice, derivedSvcName, endpointSliceImport, endpointCount)
+
+	// Skip the write if the imported EndpointSlice is already up to date.
+	existingEndpointSlice := &discoveryv1.EndpointSlice{}
+	err = r.MemberClient.Get(ctx, types.NamespacedName{Namespace: r.FleetSystemNamespace, Name: endpointSliceImport.Name}, existingEndpointSlice)
+	switch {
+	case err != nil && !errors.IsNotFound(err):
+		return nil, err
+	case err == nil && isEndpointSliceUpToDate(existingEndpointSlice, endpointSlice):
+		klog.V(4).InfoS("Imported EndpointSlice is up to date", "endpointSlice", endpointSliceRef, "endpointSliceImport", endpointSliceImportRef)
+	default:
+		klog.V(2).InfoS("Import the EndpointSlice", "endpointSlice", endpointSliceRef)
+		applyCtx, applySpan := tracing.StartSpan(ctx, "ApplyEndpointSlice")
+		err := r.MemberClient.Patch(applyCtx, endpointSlice, client.Apply, client.FieldOwner(ControllerID), client.ForceOwnership)
+		tracing.EndSpan(applySpan, err)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Remove the aggregated EndpointSlices of the Service imported before the aggregation mode is disabled (if any).
+	if err := r.removeAggregatedEndpointSlices(ctx, endpointSliceImport.Spec.OwnerServiceReference); err != nil {
+		return nil, err
+	}
+	return []fleetnetv1alpha1.ImportedEndpointSliceReference{
+		{Namespace: r.FleetSystemNamespace, Name: endpointSliceImport.Name},
+	}, nil
+}
+
 // SetupWithManager builds a controller with Reconciler and sets it up with a controller manager.
 func (r *Reconciler) SetupWithManager(ctx context.Context, memberCtrlMgr, hubCtrlMgr ctrl.Manager) error {
 	// Set up an index for efficient MCS lookup **on the controller manager for member cluster controllers**.
@@ -258,11 +347,120 @@ func (r *Reconciler) SetupWithManager(ctx context.Context, memberCtrlMgr, hubCtr
 		return err
 	}
 
+	// Set up an index for efficient EndpointSliceImport lookup **on the controller manager for hub cluster
+	// controllers**.
+	endpointSliceImportIndexerFunc := func(o client.Object) []string {
+		endpointSliceImport, ok := o.(*fleetnetv1alpha1.EndpointSliceImport)
+		if !ok {
+			return []string{}
+		}
+		return []string{endpointSliceImport.Spec.OwnerServiceReference.NamespacedName}
+	}
+	if err := hubCtrlMgr.GetFieldIndexer().IndexField(ctx,
+		&fleetnetv1alpha1.EndpointSliceImport{},
+		endpointSliceImportOwnerSvcNamespacedNameFieldKey,
+		endpointSliceImportIndexerFunc,
+	); err != nil {
+		return err
+	}
+
 	// The controller itself is managed by the controller manager for hub cluster controllers.
 	return ctrl.NewControllerManagedBy(hubCtrlMgr).
 		// The EndpointSliceImport controller watches over EndpointSliceImport objects.
 		For(&fleetnetv1alpha1.EndpointSliceImport{}).
-		Complete(r)
+		// When the traffic is not distributed evenly, the endpoints imported from an EndpointSliceImport depend on
+		// the other EndpointSliceImports of the same Service; re-process them all, with the representative request
+		// of the Service, when one of them changes.
+		Watches(&fleetnetv1alpha1.EndpointSliceImport{}, handler.EnqueueRequestsFromMapFunc(r.siblingEndpointSliceImportRequests)).
+		// The controller also watches over MCS objects **in the member cluster**, using the cache of the controller
+		// manager for member cluster controllers, so that the imported EndpointSlices are re-processed as soon as
+		// the MCS importing their Service changes, e.g. its derived Service or traffic distribution is updated.
+		// On updates, the EndpointSliceImports of the Services referenced by both the old and the new MCS
+		// objects are enqueued.
+		WatchesRawSource(source.Kind(memberCtrlMgr.GetCache(),
+			&fleetnetv1alpha1.MultiClusterService{},
+			handler.TypedEnqueueRequestsFromMapFunc(r.multiClusterServiceEndpointSliceImportRequests))).
+		Complete(metrics.InstrumentReconciler("endpointsliceimport", r))
+}
+
+// multiClusterServiceEndpointSliceImportRequests returns the requests for the EndpointSliceImports of the Service
+// imported by an MCS.
+func (r *Reconciler) multiClusterServiceEndpointSliceImportRequests(ctx context.Context, multiClusterSvc *fleetnetv1alpha1.MultiClusterService) []reconcile.Request {
+	ownerSvcNamespacedName := types.NamespacedName{Namespace: multiClusterSvc.Namespace, Name: multiClusterSvc.Spec.ServiceImport.Name}.String()
+	endpointSliceImportList := &fleetnetv1alpha1.EndpointSliceImportList{}
+	if err := r.HubClient.List(ctx, endpointSliceImportList,
+		client.MatchingFields{endpointSliceImportOwnerSvcNamespacedNameFieldKey: ownerSvcNamespacedName},
+	); err != nil {
+		klog.ErrorS(err, "Failed to list endpointSliceImports", "multiClusterService", klog.KObj(multiClusterSvc), "service", ownerSvcNamespacedName)
+		return []reconcile.Request{}
+	}
+	requests := make([]reconcile.Request, 0, len(endpointSliceImportList.Items))
+	for i := range endpointSliceImportList.Items {
+		endpointSliceImport := &endpointSliceImportList.Items[i]
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: endpointSliceImport.Namespace, Name: endpointSliceImport.Name}})
+	}
+	return requests
+}
+
+// endpointsToImport returns the number of endpoints, counting from the start, to import from an
+// EndpointSliceImport as per the traffic distribution.
+func (r *Reconciler) endpointsToImport(ctx context.Context, endpointSliceImport *fleetnetv1alpha1.EndpointSliceImport, distribution *fleetnetv1alpha1.TrafficDistribution) (int, error) {
+	if isEvenDistribution(distribution) {
+		return len(endpointSliceImport.Spec.Endpoints), nil
+	}
+	endpointSliceImportList := &fleetnetv1alpha1.EndpointSliceImportList{}
+	if err := r.HubClient.List(ctx, endpointSliceImportList,
+		client.InNamespace(endpointSliceImport.Namespace),
+		client.MatchingFields{endpointSliceImportOwnerSvcNamespacedNameFieldKey: endpointSliceImport.Spec.OwnerServiceReference.NamespacedName},
+	); err != nil {
+		return 0, fmt.Errorf("failed to list endpointSliceImports of service %s: %w", endpointSliceImport.Spec.OwnerServiceReference.NamespacedName, err)
+	}
+	return endpointsToImport(distribution, r.MemberClusterID, endpointSliceImport, endpointSliceImportList.Items), nil
+}
+
+// siblingEndpointSliceImportRequests returns the requests for the EndpointSliceImports of the same Service in the
+// same namespace as an EndpointSliceImport when the traffic of the Service is not distributed evenly, as the number
+// of endpoints each of them imports depends on the others.
+func (r *Reconciler) siblingEndpointSliceImportRequests(ctx context.Context, o client.Object) []reconcile.Request {
+	endpointSliceImport, ok := o.(*fleetnetv1alpha1.EndpointSliceImport)
+	if !ok {
+		return []reconcile.Request{}
+	}
+	// In the aggregation mode, the endpoints of all the EndpointSliceImports of a Service are re-packed whenever
+	// one of them is processed.
+	if r.AggregateEndpointSlices {
+		return []reconcile.Request{}
+	}
+	ownerSvcRef := endpointSliceImport.Spec.OwnerServiceReference
+	multiClusterSvcList := &fleetnetv1alpha1.MultiClusterServiceList{}
+	if err := r.MemberClient.List(ctx,
+		multiClusterSvcList,
+		client.InNamespace(ownerSvcRef.Namespace),
+		client.MatchingFields{mcsServiceImportRefFieldKey: ownerSvcRef.Name}); err != nil {
+		klog.ErrorS(err, "Failed to list MCS", "serviceImport", klog.KRef(ownerSvcRef.Namespace, ownerSvcRef.Name))
+		return []reconcile.Request{}
+	}
+	if isEvenDistribution(scanForTrafficDistribution(multiClusterSvcList, scanForDerivedServiceName(multiClusterSvcList))) {
+		return []reconcile.Request{}
+	}
+	endpointSliceImportList := &fleetnetv1alpha1.EndpointSliceImportList{}
+	if err := r.HubClient.List(ctx, endpointSliceImportList,
+		client.InNamespace(endpointSliceImport.Namespace),
+		client.MatchingFields{endpointSliceImportOwnerSvcNamespacedNameFieldKey: ownerSvcRef.NamespacedName},
+	); err != nil {
+		klog.ErrorS(err, "Failed to list endpointSliceImports", "endpointSliceImport", klog.KObj(endpointSliceImport), "service", ownerSvcRef.NamespacedName)
+		return []reconcile.Request{}
+	}
+	requests := make([]reconcile.Request, 0, len(endpointSliceImportList.Items))
+	for i := range endpointSliceImportList.Items {
+		sibling := &endpointSliceImportList.Items[i]
+		if sibling.Name == endpointSliceImport.Name {
+			// The EndpointSliceImport itself is enqueued by the primary watch.
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: sibling.Namespace, Name: sibling.Name}})
+	}
+	return requests
 }
 
 // unimportEndpointSlice unimports an EndpointSlice.
@@ -273,6 +471,18 @@ func (r *Reconciler) unimportEndpointSlice(ctx context.Context, endpointSliceImp
 		return nil
 	}
 
+	// In the aggregation mode, re-pack the endpoints of the other EndpointSliceImports of the same Service, which
+	// withdraws the endpoints imported from the EndpointSliceImport.
+	if r.AggregateEndpointSlices {
+		derivedSvcName, distribution, err := r.derivedServiceOf(ctx, endpointSliceImport)
+		if err != nil {
+			return err
+		}
+		if _, err := r.importAggregatedEndpointSlices(ctx, endpointSliceImport, derivedSvcName, distribution); err != nil {
+			return err
+		}
+	}
+
 	// Unimport the EndpointSlice.
 	endpointSlice := &discoveryv1.EndpointSlice{
 		ObjectMeta: metav1.ObjectMeta{
@@ -293,6 +503,25 @@ func (r *Reconciler) unimportEndpointSlice(ctx context.Context, endpointSliceImp
 	return r.HubClient.Update(ctx, endpointSliceImport)
 }
 
+// derivedServiceOf returns the name of the valid derived Service, if any, with which the EndpointSlices imported
+// from an EndpointSliceImport are associated, and the traffic distribution of the MCS owning it.
+func (r *Reconciler) derivedServiceOf(ctx context.Context, endpointSliceImport *fleetnetv1alpha1.EndpointSliceImport) (string, *fleetnetv1alpha1.TrafficDistribution, error) {
+	ownerSvcRef := endpointSliceImport.Spec.OwnerServiceReference
+	multiClusterSvcList := &fleetnetv1alpha1.MultiClusterServiceList{}
+	if err := r.MemberClient.List(ctx,
+		multiClusterSvcList,
+		client.InNamespace(ownerSvcRef.Namespace),
+		client.MatchingFields{mcsServiceImportRefFieldKey: ownerSvcRef.Name}); err != nil {
+		return "", nil, err
+	}
+	derivedSvcName := scanForDerivedServiceName(multiClusterSvcList)
+	isValid, err := r.isDerivedServiceValid(ctx, derivedSvcName)
+	if err != nil || !isValid {
+		return "", nil, err
+	}
+	return derivedSvcName, scanForTrafficDistribution(multiClusterSvcList, derivedSvcName), nil
+}
+
 // addEndpointSliceImportCleanupFinalizer adds the cleanup finalizer to an EndpointSliceImport.
 func (r *Reconciler) addEndpointSliceImportCleanupFinalizer(ctx context.Context, endpointSliceImport *fleetnetv1alpha1.EndpointSliceImport) error {
 	if !controllerutil.ContainsFinalizer(endpointSliceImport, endpointSliceImportCleanupFinalizer) {
@@ -337,17 +566,18 @@ func scanForDerivedServiceName(multiClusterSvcList *fleetnetv1alpha1.MultiCluste
 	return derivedSvcName
 }
 
-// formatEndpointSliceFromImport formats an EndpointSlice using an EndpointSliceImport.
-func formatEndpointSliceFromImport(endpointSlice *discoveryv1.EndpointSlice, derivedSvcName string, endpointSliceImport *fleetnetv1alpha1.EndpointSliceImport) {
+// formatEndpointSliceFromImport formats an EndpointSlice using the first endpointCount endpoints of an
+// EndpointSliceImport.
+func formatEndpointSliceFromImport(endpointSlice *discoveryv1.EndpointSlice, derivedSvcName string, endpointSliceImport *fleetnetv1alpha1.EndpointSliceImport, endpointCount int) {
 	endpointSlice.AddressType = endpointSliceImport.Spec.AddressType
 	endpointSlice.Labels = map[string]string{
 		discoveryv1.LabelServiceName: derivedSvcName,
-		discoveryv1.LabelManagedBy:   controllerID,
+		discoveryv1.LabelManagedBy:   ControllerID,
 	}
 	endpointSlice.Ports = endpointSliceImport.Spec.Ports
 
 	endpoints := []discoveryv1.Endpoint{}
-	for _, importedEndpoint := range endpointSliceImport.Spec.Endpoints {
+	for _, importedEndpoint := range endpointSliceImport.Spec.Endpoints[:endpointCount] {
 		endpoints = append(endpoints, discoveryv1.Endpoint{
 			Addresses: importedEndpoint.Addresses,
 		})
@@ -355,6 +585,73 @@ func formatEndpointSliceFromImport(endpointSlice *discoveryv1.EndpointSlice, der
 	endpointSlice.Endpoints = endpoints
 }
 
+// isEndpointSliceUpToDate returns if an existing EndpointSlice already has the fields that the controller manages
+// set as desired.
+func isEndpointSliceUpToDate(existing, desired *discoveryv1.EndpointSlice) bool {
+	for key, val := range desired.Labels {
+		if existing.Labels[key] != val {
+			return false
+		}
+	}
+	return existing.AddressType == desired.AddressType &&
+		equality.Semantic.DeepEqual(existing.Ports, desired.Ports) &&
+		equality.Semantic.DeepEqual(existing.Endpoints, desired.Endpoints)
+}
+
+// updateEndpointSliceImportStatus updates the import conditions and the imported EndpointSlice references of an
+// EndpointSliceImport in the hub cluster, if they have changed.
+func (r *Reconciler) updateEndpointSliceImportStatus(ctx context.Context,
+	endpointSliceImport *fleetnetv1alpha1.EndpointSliceImport,
+	conds []metav1.Condition,
+	importedEndpointSlices []fleetnetv1alpha1.ImportedEndpointSliceReference) error {
+	isUpToDate := equality.Semantic.DeepEqual(endpointSliceImport.Status.ImportedEndpointSlices, importedEndpointSlices)
+	for i := range conds {
+		currentCond := meta.FindStatusCondition(endpointSliceImport.Status.Conditions, conds[i].Type)
+		isUpToDate = isUpToDate && condition.EqualCondition(currentCond, &conds[i])
+	}
+	if isUpToDate {
+		return nil
+	}
+
+	for _, cond := range conds {
+		meta.SetStatusCondition(&endpointSliceImport.Status.Conditions, cond)
+	}
+	endpointSliceImport.Status.ImportedEndpointSlices = importedEndpointSlices
+	klog.V(4).InfoS("Update endpointSliceImport status",
+		"endpointSliceImport", klog.KObj(endpointSliceImport),
+		"conditions", conds)
+	if err := r.HubClient.Status().Update(ctx, endpointSliceImport); err != nil {
+		klog.ErrorS(err, "Failed to update endpointSliceImport status", "endpointSliceImport", klog.KObj(endpointSliceImport))
+		return err
+	}
+	return nil
+}
+
+// importConditions returns the Imported and ImportPending conditions of an EndpointSliceImport; the two
+// conditions always have opposite statuses and share the same reason.
+func importConditions(endpointSliceImport *fleetnetv1alpha1.EndpointSliceImport, imported bool, reason, message string) []metav1.Condition {
+	importedStatus, pendingStatus := metav1.ConditionTrue, metav1.ConditionFalse
+	if !imported {
+		importedStatus, pendingStatus = metav1.ConditionFalse, metav1.ConditionTrue
+	}
+	return []metav1.Condition{
+		{
+			Type:               string(fleetnetv1alpha1.EndpointSliceImportImported),
+			Status:             importedStatus,
+			Reason:             reason,
+			ObservedGeneration: endpointSliceImport.Generation,
+			Message:            message,
+		},
+		{
+			Type:               string(fleetnetv1alpha1.EndpointSliceImportPending),
+			Status:             pendingStatus,
+			Reason:             reason,
+			ObservedGeneration: endpointSliceImport.Generation,
+			Message:            message,
+		},
+	}
+}
+
 // Observe data points for metrics.
 func (r *Reconciler) observeMetrics(ctx context.Context, endpointSliceImport *fleetnetv1alpha1.EndpointSliceImport, startTime time.Time) error {
 	// Check if a metric data point has been observed for the current generation of the object; this helps guard
@@ -15,13 +15,18 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	corev1 "k8s.io/api/core/v1"
 	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 	"go.goms.io/fleet-networking/pkg/common/metrics"
@@ -136,7 +141,7 @@ func importedIPv4EndpointSlice() *discoveryv1.EndpointSlice {
 			Name:      endpointSliceImportName,
 			Labels: map[string]string{
 				discoveryv1.LabelServiceName: derivedSvcName,
-				discoveryv1.LabelManagedBy:   controllerID,
+				discoveryv1.LabelManagedBy:   ControllerID,
 			},
 		},
 		AddressType: discoveryv1.AddressTypeIPv4,
@@ -258,7 +263,7 @@ func TestFormatEndpointSliceFromImport(t *testing.T) {
 				},
 			}
 
-			formatEndpointSliceFromImport(endpointSlice, derivedSvcName, tc.endpointSliceImport)
+			formatEndpointSliceFromImport(endpointSlice, derivedSvcName, tc.endpointSliceImport, len(tc.endpointSliceImport.Spec.Endpoints))
 			if diff := cmp.Diff(endpointSlice, tc.want); diff != "" {
 				t.Fatalf("formatEndpointSliceImport(), got diff %s", diff)
 			}
@@ -266,6 +271,58 @@ func TestFormatEndpointSliceFromImport(t *testing.T) {
 	}
 }
 
+// TestIsEndpointSliceUpToDate tests the isEndpointSliceUpToDate function.
+func TestIsEndpointSliceUpToDate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		existing func() *discoveryv1.EndpointSlice
+		want     bool
+	}{
+		{
+			name:     "up to date",
+			existing: importedIPv4EndpointSlice,
+			want:     true,
+		},
+		{
+			name: "up to date (extra labels)",
+			existing: func() *discoveryv1.EndpointSlice {
+				endpointSlice := importedIPv4EndpointSlice()
+				endpointSlice.Labels["app"] = "nginx"
+				return endpointSlice
+			},
+			want: true,
+		},
+		{
+			name: "stale service name label",
+			existing: func() *discoveryv1.EndpointSlice {
+				endpointSlice := importedIPv4EndpointSlice()
+				endpointSlice.Labels[discoveryv1.LabelServiceName] = "app"
+				return endpointSlice
+			},
+		},
+		{
+			name:     "stale ports",
+			existing: importedIPv4EndpointSliceWithHybridProtocol,
+		},
+		{
+			name: "stale endpoints",
+			existing: func() *discoveryv1.EndpointSlice {
+				endpointSlice := importedIPv4EndpointSlice()
+				endpointSlice.Endpoints = endpointSlice.Endpoints[:1]
+				return endpointSlice
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isEndpointSliceUpToDate(tc.existing(), importedIPv4EndpointSlice()); got != tc.want {
+				t.Fatalf("isEndpointSliceUpToDate() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
 // TestIsDerivedServiceValid tests the isDerivedServiceValid function.
 func TestIsDerivedServiceValid(t *testing.T) {
 	deletionTimestamp := metav1.Now()
@@ -720,3 +777,597 @@ func TestObserveMetrics(t *testing.T) {
 		})
 	}
 }
+
+// endpointSliceImportForTest returns an EndpointSliceImport with the given number of endpoints exported by a
+// member cluster.
+func endpointSliceImportForTest(name, clusterID string, endpointCount int) fleetnetv1alpha1.EndpointSliceImport {
+	endpoints := make([]fleetnetv1alpha1.Endpoint, 0, endpointCount)
+	for i := 0; i < endpointCount; i++ {
+		endpoints = append(endpoints, fleetnetv1alpha1.Endpoint{Addresses: []string{fmt.Sprintf("10.0.0.%d", i)}})
+	}
+	return fleetnetv1alpha1.EndpointSliceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: hubNSForMember,
+			Name:      name,
+		},
+		Spec: fleetnetv1alpha1.EndpointSliceExportSpec{
+			Endpoints: endpoints,
+			EndpointSliceReference: fleetnetv1alpha1.ExportedObjectReference{
+				ClusterID: clusterID,
+			},
+		},
+	}
+}
+
+// TestEndpointsToImport tests the endpointsToImport function.
+func TestEndpointsToImport(t *testing.T) {
+	remoteClusterID := "member-2"
+	otherRemoteClusterID := "member-3"
+	endpointSliceImports := []fleetnetv1alpha1.EndpointSliceImport{
+		endpointSliceImportForTest("local-1", memberClusterID, 2),
+		endpointSliceImportForTest("remote-1", remoteClusterID, 6),
+		endpointSliceImportForTest("remote-2", remoteClusterID, 4),
+		endpointSliceImportForTest("other-remote-1", otherRemoteClusterID, 1),
+	}
+
+	testCases := []struct {
+		name                 string
+		distribution         *fleetnetv1alpha1.TrafficDistribution
+		endpointSliceImports []fleetnetv1alpha1.EndpointSliceImport
+		want                 map[string]int
+	}{
+		{
+			name:                 "no traffic distribution",
+			endpointSliceImports: endpointSliceImports,
+			want:                 map[string]int{"local-1": 2, "remote-1": 6, "remote-2": 4, "other-remote-1": 1},
+		},
+		{
+			name:                 "even mode",
+			distribution:         &fleetnetv1alpha1.TrafficDistribution{Mode: fleetnetv1alpha1.TrafficDistributionModeEven},
+			endpointSliceImports: endpointSliceImports,
+			want:                 map[string]int{"local-1": 2, "remote-1": 6, "remote-2": 4, "other-remote-1": 1},
+		},
+		{
+			name:                 "prefer local mode with local endpoints",
+			distribution:         &fleetnetv1alpha1.TrafficDistribution{Mode: fleetnetv1alpha1.TrafficDistributionModePreferLocal},
+			endpointSliceImports: endpointSliceImports,
+			want:                 map[string]int{"local-1": 2, "remote-1": 0, "remote-2": 0, "other-remote-1": 0},
+		},
+		{
+			name:         "prefer local mode without local endpoints",
+			distribution: &fleetnetv1alpha1.TrafficDistribution{Mode: fleetnetv1alpha1.TrafficDistributionModePreferLocal},
+			endpointSliceImports: []fleetnetv1alpha1.EndpointSliceImport{
+				endpointSliceImportForTest("local-1", memberClusterID, 0),
+				endpointSliceImportForTest("remote-1", remoteClusterID, 6),
+			},
+			want: map[string]int{"local-1": 0, "remote-1": 6},
+		},
+		{
+			name:                 "weighted mode with default weights",
+			distribution:         &fleetnetv1alpha1.TrafficDistribution{Mode: fleetnetv1alpha1.TrafficDistributionModeWeighted},
+			endpointSliceImports: endpointSliceImports,
+			// member-3 has a single endpoint, which limits the scale to 1.
+			want: map[string]int{"local-1": 1, "remote-1": 1, "remote-2": 0, "other-remote-1": 1},
+		},
+		{
+			name: "weighted mode",
+			distribution: &fleetnetv1alpha1.TrafficDistribution{
+				Mode: fleetnetv1alpha1.TrafficDistributionModeWeighted,
+				ClusterWeights: []fleetnetv1alpha1.ClusterWeight{
+					{Cluster: memberClusterID, Weight: 1},
+					{Cluster: remoteClusterID, Weight: 4},
+					{Cluster: otherRemoteClusterID, Weight: 0},
+				},
+			},
+			endpointSliceImports: endpointSliceImports,
+			// The 2 local endpoints and the 10 endpoints of member-2 allow the scale of 2, i.e. 2 and 8 endpoints.
+			want: map[string]int{"local-1": 2, "remote-1": 6, "remote-2": 2, "other-remote-1": 0},
+		},
+		{
+			name: "weighted mode with fewer endpoints than the weight",
+			distribution: &fleetnetv1alpha1.TrafficDistribution{
+				Mode: fleetnetv1alpha1.TrafficDistributionModeWeighted,
+				ClusterWeights: []fleetnetv1alpha1.ClusterWeight{
+					{Cluster: memberClusterID, Weight: 1},
+					{Cluster: remoteClusterID, Weight: 4},
+				},
+			},
+			endpointSliceImports: []fleetnetv1alpha1.EndpointSliceImport{
+				endpointSliceImportForTest("local-1", memberClusterID, 2),
+				endpointSliceImportForTest("remote-1", remoteClusterID, 3),
+			},
+			// member-2 has fewer endpoints than its weight; the scale is kept at 1 and all of its endpoints are
+			// imported.
+			want: map[string]int{"local-1": 1, "remote-1": 3},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := map[string]int{}
+			for i := range tc.endpointSliceImports {
+				endpointSliceImport := &tc.endpointSliceImports[i]
+				got[endpointSliceImport.Name] = endpointsToImport(tc.distribution, memberClusterID, endpointSliceImport, tc.endpointSliceImports)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("endpointsToImport() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestMultiClusterServiceEndpointSliceImportRequests tests the multiClusterServiceEndpointSliceImportRequests method.
+func TestMultiClusterServiceEndpointSliceImportRequests(t *testing.T) {
+	ownerSvcNamespacedName := fmt.Sprintf("%s/%s", memberUserNS, svcName)
+	withOwnerSvc := func(endpointSliceImport fleetnetv1alpha1.EndpointSliceImport, namespacedName string) *fleetnetv1alpha1.EndpointSliceImport {
+		endpointSliceImport.Spec.OwnerServiceReference.NamespacedName = namespacedName
+		return &endpointSliceImport
+	}
+	fakeHubClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(
+			withOwnerSvc(endpointSliceImportForTest("slice-1", "member-1", 1), ownerSvcNamespacedName),
+			withOwnerSvc(endpointSliceImportForTest("slice-2", "member-2", 1), ownerSvcNamespacedName),
+			withOwnerSvc(endpointSliceImportForTest("slice-3", "member-2", 1), fmt.Sprintf("%s/%s", memberUserNS, "other-app")),
+		).
+		WithIndex(&fleetnetv1alpha1.EndpointSliceImport{}, endpointSliceImportOwnerSvcNamespacedNameFieldKey, func(o client.Object) []string {
+			return []string{o.(*fleetnetv1alpha1.EndpointSliceImport).Spec.OwnerServiceReference.NamespacedName}
+		}).
+		Build()
+	reconciler := Reconciler{
+		HubClient: fakeHubClient,
+	}
+	multiClusterSvc := &fleetnetv1alpha1.MultiClusterService{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: memberUserNS,
+			Name:      "app-mcs",
+		},
+		Spec: fleetnetv1alpha1.MultiClusterServiceSpec{
+			ServiceImport: fleetnetv1alpha1.ServiceImportRef{
+				Name: svcName,
+			},
+		},
+	}
+
+	got := reconciler.multiClusterServiceEndpointSliceImportRequests(context.Background(), multiClusterSvc)
+	want := []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: hubNSForMember, Name: "slice-1"}},
+		{NamespacedName: types.NamespacedName{Namespace: hubNSForMember, Name: "slice-2"}},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.SortSlices(func(a, b reconcile.Request) bool {
+		return a.Name < b.Name
+	})); diff != "" {
+		t.Errorf("multiClusterServiceEndpointSliceImportRequests() mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+// aggregatedEndpointsForTest returns count endpoints from a source, with addresses starting from 10.0.<subnet>.<start>.
+func aggregatedEndpointsForTest(source string, ports []discoveryv1.EndpointPort, subnet, start, count int) []aggregatedEndpoint {
+	endpoints := make([]aggregatedEndpoint, 0, count)
+	for i := start; i < start+count; i++ {
+		endpoints = append(endpoints, aggregatedEndpoint{
+			source:      source,
+			format:      aggregatedSliceFormat(discoveryv1.AddressTypeIPv4, ports),
+			addressType: discoveryv1.AddressTypeIPv4,
+			ports:       ports,
+			endpoint:    discoveryv1.Endpoint{Addresses: []string{fmt.Sprintf("10.0.%d.%d", subnet, i)}},
+		})
+	}
+	return endpoints
+}
+
+// aggregatedSliceEndpointsForTest returns the endpoints of a list of aggregated endpoints.
+func aggregatedSliceEndpointsForTest(aggregatedEndpoints []aggregatedEndpoint) []discoveryv1.Endpoint {
+	endpoints := make([]discoveryv1.Endpoint, 0, len(aggregatedEndpoints))
+	for _, aggregatedEndpoint := range aggregatedEndpoints {
+		endpoints = append(endpoints, aggregatedEndpoint.endpoint)
+	}
+	return endpoints
+}
+
+// TestPlanAggregatedEndpointSlices tests the planAggregatedEndpointSlices function.
+func TestPlanAggregatedEndpointSlices(t *testing.T) {
+	httpPorts := []discoveryv1.EndpointPort{{Name: &httpPortName, Protocol: &httpPortProtocol, Port: &httpPort}}
+	udpPorts := []discoveryv1.EndpointPort{{Name: &udpPortName, Protocol: &udpPortProtocol, Port: &udpPort}}
+	httpFormat := aggregatedSliceFormat(discoveryv1.AddressTypeIPv4, httpPorts)
+	udpFormat := aggregatedSliceFormat(discoveryv1.AddressTypeIPv4, udpPorts)
+
+	sourceA := aggregatedEndpointsForTest("a", httpPorts, 0, 0, 150)
+	sourceB := aggregatedEndpointsForTest("b", httpPorts, 1, 0, 10)
+	sourceC := aggregatedEndpointsForTest("c", udpPorts, 2, 0, 5)
+
+	existingSlice := func(name string, ports []discoveryv1.EndpointPort, endpoints []discoveryv1.Endpoint) discoveryv1.EndpointSlice {
+		return discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: fleetSystemNS,
+				Name:      name,
+			},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Ports:       ports,
+			Endpoints:   endpoints,
+		}
+	}
+
+	testCases := []struct {
+		name           string
+		existing       []discoveryv1.EndpointSlice
+		desired        []aggregatedEndpoint
+		wantSlices     []*aggregatedSlice
+		wantStaleNames []string
+	}{
+		{
+			name:    "no existing slices",
+			desired: append(append([]aggregatedEndpoint{}, sourceA...), sourceB...),
+			wantSlices: []*aggregatedSlice{
+				{
+					name:        derivedSvcName + "-0",
+					format:      httpFormat,
+					addressType: discoveryv1.AddressTypeIPv4,
+					ports:       httpPorts,
+					endpoints:   aggregatedSliceEndpointsForTest(sourceA[:100]),
+					sources:     sets.New("a"),
+				},
+				{
+					name:        derivedSvcName + "-1",
+					format:      httpFormat,
+					addressType: discoveryv1.AddressTypeIPv4,
+					ports:       httpPorts,
+					endpoints:   append(aggregatedSliceEndpointsForTest(sourceA[100:]), aggregatedSliceEndpointsForTest(sourceB)...),
+					sources:     sets.New("a", "b"),
+				},
+			},
+		},
+		{
+			name:    "endpoints of different formats",
+			desired: append(append([]aggregatedEndpoint{}, sourceB...), sourceC...),
+			wantSlices: []*aggregatedSlice{
+				{
+					name:        derivedSvcName + "-0",
+					format:      httpFormat,
+					addressType: discoveryv1.AddressTypeIPv4,
+					ports:       httpPorts,
+					endpoints:   aggregatedSliceEndpointsForTest(sourceB),
+					sources:     sets.New("b"),
+				},
+				{
+					name:        derivedSvcName + "-1",
+					format:      udpFormat,
+					addressType: discoveryv1.AddressTypeIPv4,
+					ports:       udpPorts,
+					endpoints:   aggregatedSliceEndpointsForTest(sourceC),
+					sources:     sets.New("c"),
+				},
+			},
+		},
+		{
+			name: "existing endpoints stay in place",
+			existing: []discoveryv1.EndpointSlice{
+				existingSlice(derivedSvcName+"-1", httpPorts, aggregatedSliceEndpointsForTest(append(append([]aggregatedEndpoint{}, sourceB[5:]...), sourceA[:5]...))),
+			},
+			desired: append(append([]aggregatedEndpoint{}, sourceA[:5]...), sourceB...),
+			wantSlices: []*aggregatedSlice{
+				{
+					name:        derivedSvcName + "-1",
+					format:      httpFormat,
+					addressType: discoveryv1.AddressTypeIPv4,
+					ports:       httpPorts,
+					endpoints: append(
+						aggregatedSliceEndpointsForTest(append(append([]aggregatedEndpoint{}, sourceB[5:]...), sourceA[:5]...)),
+						aggregatedSliceEndpointsForTest(sourceB[:5])...),
+					sources: sets.New("a", "b"),
+				},
+			},
+		},
+		{
+			name: "stale endpoints and slices",
+			existing: []discoveryv1.EndpointSlice{
+				existingSlice(derivedSvcName+"-0", httpPorts, aggregatedSliceEndpointsForTest(sourceA[:100])),
+				existingSlice(derivedSvcName+"-1", httpPorts, aggregatedSliceEndpointsForTest(sourceA[100:])),
+				existingSlice(derivedSvcName+"-2", udpPorts, aggregatedSliceEndpointsForTest(sourceC)),
+			},
+			desired: append(append([]aggregatedEndpoint{}, sourceA[50:60]...), sourceC...),
+			wantSlices: []*aggregatedSlice{
+				{
+					name:        derivedSvcName + "-0",
+					format:      httpFormat,
+					addressType: discoveryv1.AddressTypeIPv4,
+					ports:       httpPorts,
+					endpoints:   aggregatedSliceEndpointsForTest(sourceA[50:60]),
+					sources:     sets.New("a"),
+				},
+				{
+					name:        derivedSvcName + "-2",
+					format:      udpFormat,
+					addressType: discoveryv1.AddressTypeIPv4,
+					ports:       udpPorts,
+					endpoints:   aggregatedSliceEndpointsForTest(sourceC),
+					sources:     sets.New("c"),
+				},
+			},
+			wantStaleNames: []string{derivedSvcName + "-1"},
+		},
+		{
+			name: "no desired endpoints",
+			existing: []discoveryv1.EndpointSlice{
+				existingSlice(derivedSvcName+"-0", httpPorts, aggregatedSliceEndpointsForTest(sourceB)),
+			},
+			wantStaleNames: []string{derivedSvcName + "-0"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			slices, staleSlices := planAggregatedEndpointSlices(tc.existing, tc.desired, derivedSvcName)
+			if diff := cmp.Diff(slices, tc.wantSlices, cmp.AllowUnexported(aggregatedSlice{})); diff != "" {
+				t.Errorf("planAggregatedEndpointSlices() slices mismatch (-got, +want):\n%s", diff)
+			}
+			staleNames := make([]string, 0, len(staleSlices))
+			for _, staleSlice := range staleSlices {
+				staleNames = append(staleNames, staleSlice.Name)
+			}
+			if diff := cmp.Diff(staleNames, tc.wantStaleNames, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("planAggregatedEndpointSlices() stale slices mismatch (-got, +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestAggregatedEndpointsToImport tests the aggregatedEndpointsToImport function.
+func TestAggregatedEndpointsToImport(t *testing.T) {
+	deletionTimestamp := metav1.Now()
+	remote := endpointSliceImportForTest("remote-1", "member-2", 3)
+	for i := range remote.Spec.Endpoints {
+		remote.Spec.Endpoints[i].Addresses = []string{fmt.Sprintf("10.1.0.%d", i)}
+	}
+	deleted := endpointSliceImportForTest("deleted-1", "member-2", 1)
+	deleted.DeletionTimestamp = &deletionTimestamp
+	deleted.Finalizers = []string{endpointSliceImportCleanupFinalizer}
+	endpointSliceImports := []fleetnetv1alpha1.EndpointSliceImport{
+		remote,
+		endpointSliceImportForTest("local-1", memberClusterID, 2),
+		deleted,
+	}
+
+	testCases := []struct {
+		name         string
+		distribution *fleetnetv1alpha1.TrafficDistribution
+		want         map[string]int
+	}{
+		{
+			name: "even distribution",
+			want: map[string]int{"local-1": 2, "remote-1": 3},
+		},
+		{
+			name:         "prefer local mode",
+			distribution: &fleetnetv1alpha1.TrafficDistribution{Mode: fleetnetv1alpha1.TrafficDistributionModePreferLocal},
+			want:         map[string]int{"local-1": 2},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			endpoints := aggregatedEndpointsToImport(tc.distribution, memberClusterID, endpointSliceImports)
+			got := map[string]int{}
+			lastSource := ""
+			for _, endpoint := range endpoints {
+				if endpoint.source < lastSource {
+					t.Fatalf("aggregatedEndpointsToImport() returned endpoints out of order: %s after %s", endpoint.source, lastSource)
+				}
+				lastSource = endpoint.source
+				got[endpoint.source]++
+			}
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("aggregatedEndpointsToImport() mismatch (-got, +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestImportConditions tests the importConditions function.
+func TestImportConditions(t *testing.T) {
+	endpointSliceImport := &fleetnetv1alpha1.EndpointSliceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  hubNSForMember,
+			Name:       endpointSliceImportName,
+			Generation: 3,
+		},
+	}
+
+	testCases := []struct {
+		name      string
+		imported  bool
+		reason    string
+		wantConds []metav1.Condition
+	}{
+		{
+			name:     "imported",
+			imported: true,
+			reason:   importReasonImported,
+			wantConds: []metav1.Condition{
+				{
+					Type:               string(fleetnetv1alpha1.EndpointSliceImportImported),
+					Status:             metav1.ConditionTrue,
+					Reason:             importReasonImported,
+					ObservedGeneration: 3,
+					Message:            "message",
+				},
+				{
+					Type:               string(fleetnetv1alpha1.EndpointSliceImportPending),
+					Status:             metav1.ConditionFalse,
+					Reason:             importReasonImported,
+					ObservedGeneration: 3,
+					Message:            "message",
+				},
+			},
+		},
+		{
+			name:   "pending",
+			reason: importReasonNoValidDerivedService,
+			wantConds: []metav1.Condition{
+				{
+					Type:               string(fleetnetv1alpha1.EndpointSliceImportImported),
+					Status:             metav1.ConditionFalse,
+					Reason:             importReasonNoValidDerivedService,
+					ObservedGeneration: 3,
+					Message:            "message",
+				},
+				{
+					Type:               string(fleetnetv1alpha1.EndpointSliceImportPending),
+					Status:             metav1.ConditionTrue,
+					Reason:             importReasonNoValidDerivedService,
+					ObservedGeneration: 3,
+					Message:            "message",
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conds := importConditions(endpointSliceImport, tc.imported, tc.reason, "message")
+			if diff := cmp.Diff(conds, tc.wantConds); diff != "" {
+				t.Fatalf("importConditions() mismatch (-got, +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestUpdateEndpointSliceImportStatus tests the Reconciler.updateEndpointSliceImportStatus method.
+func TestUpdateEndpointSliceImportStatus(t *testing.T) {
+	ctx := context.Background()
+	endpointSliceImport := &fleetnetv1alpha1.EndpointSliceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: hubNSForMember,
+			Name:      endpointSliceImportName,
+		},
+	}
+	fakeHubClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(endpointSliceImport).
+		WithStatusSubresource(endpointSliceImport).
+		Build()
+	reconciler := Reconciler{
+		MemberClient:         fake.NewClientBuilder().WithScheme(scheme.Scheme).Build(),
+		HubClient:            fakeHubClient,
+		FleetSystemNamespace: fleetSystemNS,
+	}
+
+	conds := importConditions(endpointSliceImport, true, importReasonImported, "imported")
+	importedEndpointSlices := []fleetnetv1alpha1.ImportedEndpointSliceReference{
+		{Namespace: fleetSystemNS, Name: endpointSliceImportName},
+	}
+	if err := reconciler.updateEndpointSliceImportStatus(ctx, endpointSliceImport, conds, importedEndpointSlices); err != nil {
+		t.Fatalf("updateEndpointSliceImportStatus(), got %v, want no error", err)
+	}
+
+	updatedEndpointSliceImport := &fleetnetv1alpha1.EndpointSliceImport{}
+	if err := fakeHubClient.Get(ctx, endpointSliceImportKey, updatedEndpointSliceImport); err != nil {
+		t.Fatalf("endpointSliceImport Get(%+v), got %v, want no error", endpointSliceImportKey, err)
+	}
+	for i := range conds {
+		gotCond := meta.FindStatusCondition(updatedEndpointSliceImport.Status.Conditions, conds[i].Type)
+		if diff := cmp.Diff(gotCond, &conds[i], cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime")); diff != "" {
+			t.Fatalf("condition %s mismatch (-got, +want):\n%s", conds[i].Type, diff)
+		}
+	}
+	if diff := cmp.Diff(updatedEndpointSliceImport.Status.ImportedEndpointSlices, importedEndpointSlices); diff != "" {
+		t.Fatalf("importedEndpointSlices mismatch (-got, +want):\n%s", diff)
+	}
+
+	// Updating the status with the same conditions and references should be a no-op.
+	resourceVersion := updatedEndpointSliceImport.ResourceVersion
+	if err := reconciler.updateEndpointSliceImportStatus(ctx, updatedEndpointSliceImport, conds, importedEndpointSlices); err != nil {
+		t.Fatalf("updateEndpointSliceImportStatus(), got %v, want no error", err)
+	}
+	if updatedEndpointSliceImport.ResourceVersion != resourceVersion {
+		t.Fatalf("endpointSliceImport resourceVersion, got %s, want %s (no update)", updatedEndpointSliceImport.ResourceVersion, resourceVersion)
+	}
+}
+
+// TestSiblingEndpointSliceImportRequests tests the siblingEndpointSliceImportRequests method.
+func TestSiblingEndpointSliceImportRequests(t *testing.T) {
+	ownerSvcNamespacedName := fmt.Sprintf("%s/%s", memberUserNS, svcName)
+	multiClusterSvcFor := func(distribution *fleetnetv1alpha1.TrafficDistribution) *fleetnetv1alpha1.MultiClusterService {
+		return &fleetnetv1alpha1.MultiClusterService{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: memberUserNS,
+				Name:      "app-mcs",
+				Labels:    map[string]string{objectmeta.MultiClusterServiceLabelDerivedService: derivedSvcName},
+			},
+			Spec: fleetnetv1alpha1.MultiClusterServiceSpec{
+				ServiceImport:       fleetnetv1alpha1.ServiceImportRef{Name: svcName},
+				TrafficDistribution: distribution,
+			},
+		}
+	}
+	weighted := &fleetnetv1alpha1.TrafficDistribution{Mode: fleetnetv1alpha1.TrafficDistributionModeWeighted}
+	withOwnerSvc := func(endpointSliceImport fleetnetv1alpha1.EndpointSliceImport, namespacedName string) *fleetnetv1alpha1.EndpointSliceImport {
+		endpointSliceImport.Spec.OwnerServiceReference = fleetnetv1alpha1.OwnerServiceReference{
+			Namespace:      memberUserNS,
+			Name:           strings.TrimPrefix(namespacedName, memberUserNS+"/"),
+			NamespacedName: namespacedName,
+		}
+		return &endpointSliceImport
+	}
+
+	testCases := []struct {
+		name            string
+		multiClusterSvc *fleetnetv1alpha1.MultiClusterService
+		aggregate       bool
+		want            []reconcile.Request
+	}{
+		{
+			name:            "even distribution",
+			multiClusterSvc: multiClusterSvcFor(nil),
+			want:            []reconcile.Request{},
+		},
+		{
+			name:            "weighted distribution",
+			multiClusterSvc: multiClusterSvcFor(weighted),
+			want: []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Namespace: hubNSForMember, Name: "slice-2"}},
+			},
+		},
+		{
+			name:            "weighted distribution in the aggregation mode",
+			multiClusterSvc: multiClusterSvcFor(weighted),
+			aggregate:       true,
+			want:            []reconcile.Request{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeMemberClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(tc.multiClusterSvc).
+				WithIndex(&fleetnetv1alpha1.MultiClusterService{}, mcsServiceImportRefFieldKey, func(o client.Object) []string {
+					return []string{o.(*fleetnetv1alpha1.MultiClusterService).Spec.ServiceImport.Name}
+				}).
+				Build()
+			fakeHubClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(
+					withOwnerSvc(endpointSliceImportForTest("slice-1", "member-1", 1), ownerSvcNamespacedName),
+					withOwnerSvc(endpointSliceImportForTest("slice-2", "member-2", 1), ownerSvcNamespacedName),
+					withOwnerSvc(endpointSliceImportForTest("slice-3", "member-2", 1), fmt.Sprintf("%s/%s", memberUserNS, "other-app")),
+				).
+				WithIndex(&fleetnetv1alpha1.EndpointSliceImport{}, endpointSliceImportOwnerSvcNamespacedNameFieldKey, func(o client.Object) []string {
+					return []string{o.(*fleetnetv1alpha1.EndpointSliceImport).Spec.OwnerServiceReference.NamespacedName}
+				}).
+				Build()
+			r := &Reconciler{
+				MemberClient:            fakeMemberClient,
+				HubClient:               fakeHubClient,
+				AggregateEndpointSlices: tc.aggregate,
+			}
+			// The siblings of slice-1 are enqueued, excluding slice-1 itself and the EndpointSliceImports of other
+			// Services.
+			endpointSliceImport := withOwnerSvc(endpointSliceImportForTest("slice-1", "member-1", 1), ownerSvcNamespacedName)
+
+			got := r.siblingEndpointSliceImportRequests(context.Background(), endpointSliceImport)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("siblingEndpointSliceImportRequests() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
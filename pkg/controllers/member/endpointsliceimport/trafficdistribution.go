@@ -0,0 +1,117 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package endpointsliceimport
+
+import (
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+const (
+	// defaultClusterWeight is the weight of an exporting member cluster which is not listed in the cluster weights.
+	defaultClusterWeight = 1
+)
+
+// isEvenDistribution returns if the traffic is distributed evenly across all the imported endpoints, i.e. all the
+// endpoints in an EndpointSliceImport are imported regardless of the other EndpointSliceImports.
+func isEvenDistribution(distribution *fleetnetv1alpha1.TrafficDistribution) bool {
+	return distribution == nil || distribution.Mode == "" || distribution.Mode == fleetnetv1alpha1.TrafficDistributionModeEven
+}
+
+// scanForTrafficDistribution scans a list of MCSes and returns the traffic distribution of the MCS which owns the
+// given derived Service.
+func scanForTrafficDistribution(multiClusterSvcList *fleetnetv1alpha1.MultiClusterServiceList, derivedSvcName string) *fleetnetv1alpha1.TrafficDistribution {
+	for i := range multiClusterSvcList.Items {
+		multiClusterSvc := &multiClusterSvcList.Items[i]
+		if multiClusterSvc.DeletionTimestamp == nil && multiClusterSvc.Labels[objectmeta.MultiClusterServiceLabelDerivedService] == derivedSvcName {
+			return multiClusterSvc.Spec.TrafficDistribution
+		}
+	}
+	return nil
+}
+
+// endpointsToImport returns the number of endpoints, counting from the start, to import from an
+// EndpointSliceImport; endpointSliceImports are all the EndpointSliceImports of the same Service in the member
+// cluster, including the given one.
+//
+// Kubernetes load-balances evenly across the endpoints of a Service, so the traffic distribution is enforced by
+// importing only a subset of the endpoints from each exporting member cluster:
+//   - in the PreferLocal mode, the endpoints exported by other member clusters are not imported as long as the
+//     importing member cluster itself has exported at least one endpoint; and
+//   - in the Weighted mode, weight*k endpoints are imported from each exporting member cluster with a positive
+//     weight, where the scale k is the largest one with which every such member cluster has enough endpoints, so
+//     that the numbers of the imported endpoints are proportional to the weights. The scale is at least 1, i.e. a
+//     member cluster with fewer endpoints than its weight still has all of its endpoints imported, rather than
+//     none of the endpoints being imported; the traffic is then split only approximately as configured.
+//
+// The endpoints of a member cluster are picked in the order of the names of its EndpointSliceImports, so that
+// each EndpointSliceImport can be processed independently.
+func endpointsToImport(distribution *fleetnetv1alpha1.TrafficDistribution, localClusterID string,
+	endpointSliceImport *fleetnetv1alpha1.EndpointSliceImport, endpointSliceImports []fleetnetv1alpha1.EndpointSliceImport) int {
+	total := len(endpointSliceImport.Spec.Endpoints)
+	if isEvenDistribution(distribution) {
+		return total
+	}
+
+	clusterID := endpointSliceImport.Spec.EndpointSliceReference.ClusterID
+	endpointCounts := map[string]int{}
+	// preceding is the number of endpoints from the same member cluster which are picked before the endpoints
+	// in the given EndpointSliceImport.
+	preceding := 0
+	for i := range endpointSliceImports {
+		sibling := &endpointSliceImports[i]
+		if sibling.DeletionTimestamp != nil {
+			continue
+		}
+		siblingClusterID := sibling.Spec.EndpointSliceReference.ClusterID
+		endpointCounts[siblingClusterID] += len(sibling.Spec.Endpoints)
+		if siblingClusterID == clusterID && sibling.Name < endpointSliceImport.Name {
+			preceding += len(sibling.Spec.Endpoints)
+		}
+	}
+
+	switch distribution.Mode {
+	case fleetnetv1alpha1.TrafficDistributionModePreferLocal:
+		if clusterID != localClusterID && endpointCounts[localClusterID] > 0 {
+			return 0
+		}
+	case fleetnetv1alpha1.TrafficDistributionModeWeighted:
+		quota := weightedEndpointQuota(distribution.ClusterWeights, clusterID, endpointCounts)
+		return min(max(quota-preceding, 0), total)
+	}
+	return total
+}
+
+// weightedEndpointQuota returns the number of endpoints to import from a member cluster in the Weighted mode;
+// endpointCounts are the numbers of endpoints exported by each member cluster.
+func weightedEndpointQuota(clusterWeights []fleetnetv1alpha1.ClusterWeight, clusterID string, endpointCounts map[string]int) int {
+	weights := make(map[string]int, len(clusterWeights))
+	for _, clusterWeight := range clusterWeights {
+		weights[clusterWeight.Cluster] = int(clusterWeight.Weight)
+	}
+	weightOf := func(cluster string) int {
+		if weight, ok := weights[cluster]; ok {
+			return weight
+		}
+		return defaultClusterWeight
+	}
+
+	weight := weightOf(clusterID)
+	if weight == 0 {
+		return 0
+	}
+	// The scale is limited by the member cluster with the fewest endpoints relative to its weight; the member
+	// clusters which have not exported any endpoint are left out.
+	scale := -1
+	for cluster, count := range endpointCounts {
+		if w := weightOf(cluster); w > 0 && count > 0 {
+			if clusterScale := count / w; scale < 0 || clusterScale < scale {
+				scale = clusterScale
+			}
+		}
+	}
+	return min(max(scale, 1)*weight, endpointCounts[clusterID])
+}
@@ -0,0 +1,136 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package gc features the orphan finder of the garbage collection sweeper, which periodically removes orphaned
+// fleet networking objects from a member cluster.
+package gc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/gc"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/controllers/member/endpointsliceimport"
+)
+
+const (
+	kindDerivedService        = "DerivedService"
+	kindImportedEndpointSlice = "ImportedEndpointSlice"
+)
+
+// OrphanFinder finds the orphaned fleet networking objects in a member cluster:
+//   - derived Services in the fleet system namespace whose MCS no longer exists; and
+//   - imported EndpointSlices in the fleet system namespace whose EndpointSliceImports no longer exist in the
+//     hub cluster.
+type OrphanFinder struct {
+	MemberClient client.Client
+	HubClient    client.Client
+	// The namespace reserved for the current member cluster in the hub cluster.
+	HubNamespace string
+	// The namespace reserved for fleet resources in the member cluster.
+	FleetSystemNamespace string
+}
+
+//+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;delete
+//+kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch;delete
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=multiclusterservices,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=endpointsliceimports,verbs=get;list;watch
+
+// FindOrphans implements the gc.OrphanFinder interface; the orphans are deleted from the member cluster.
+func (f *OrphanFinder) FindOrphans(ctx context.Context, now time.Time) ([]gc.Orphans, error) {
+	multiClusterSvcList := &fleetnetv1alpha1.MultiClusterServiceList{}
+	if err := f.MemberClient.List(ctx, multiClusterSvcList); err != nil {
+		return nil, fmt.Errorf("failed to list multiClusterServices: %w", err)
+	}
+	svcList := &corev1.ServiceList{}
+	if err := f.MemberClient.List(ctx, svcList, client.InNamespace(f.FleetSystemNamespace), client.HasLabels{
+		objectmeta.ServiceLabelMultiClusterServiceNamespace,
+		objectmeta.ServiceLabelMultiClusterServiceName,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to list derived services: %w", err)
+	}
+	endpointSliceImportList := &fleetnetv1alpha1.EndpointSliceImportList{}
+	if err := f.HubClient.List(ctx, endpointSliceImportList, client.InNamespace(f.HubNamespace)); err != nil {
+		return nil, fmt.Errorf("failed to list endpointSliceImports: %w", err)
+	}
+	endpointSliceList := &discoveryv1.EndpointSliceList{}
+	if err := f.MemberClient.List(ctx, endpointSliceList, client.InNamespace(f.FleetSystemNamespace), client.MatchingLabels{
+		discoveryv1.LabelManagedBy: endpointsliceimport.ControllerID,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to list imported endpointSlices: %w", err)
+	}
+
+	return []gc.Orphans{
+		{
+			Kind:    kindDerivedService,
+			Objects: gc.ToObjects(findOrphanedDerivedServices(multiClusterSvcList.Items, svcList.Items, now)),
+		},
+		{
+			Kind:    kindImportedEndpointSlice,
+			Objects: gc.ToObjects(findOrphanedImportedEndpointSlices(endpointSliceImportList.Items, endpointSliceList.Items, now)),
+		},
+	}, nil
+}
+
+// findOrphanedDerivedServices returns the derived Services whose MCS no longer exists.
+func findOrphanedDerivedServices(multiClusterSvcs []fleetnetv1alpha1.MultiClusterService, svcs []corev1.Service, now time.Time) []*corev1.Service {
+	existingMultiClusterSvcs := sets.New[types.NamespacedName]()
+	for i := range multiClusterSvcs {
+		existingMultiClusterSvcs.Insert(types.NamespacedName{Namespace: multiClusterSvcs[i].Namespace, Name: multiClusterSvcs[i].Name})
+	}
+
+	var orphans []*corev1.Service
+	for i := range svcs {
+		svc := &svcs[i]
+		if !gc.IsOrphanCandidate(svc, now) {
+			continue
+		}
+		multiClusterSvc := types.NamespacedName{
+			Namespace: svc.Labels[objectmeta.ServiceLabelMultiClusterServiceNamespace],
+			Name:      svc.Labels[objectmeta.ServiceLabelMultiClusterServiceName],
+		}
+		if !existingMultiClusterSvcs.Has(multiClusterSvc) {
+			orphans = append(orphans, svc)
+		}
+	}
+	return orphans
+}
+
+// findOrphanedImportedEndpointSlices returns the imported EndpointSlices whose EndpointSliceImports no longer
+// exist; an imported EndpointSlice has the same name as its EndpointSliceImport, and an aggregated EndpointSlice
+// is an orphan when none of the EndpointSliceImports listed as its sources exists.
+func findOrphanedImportedEndpointSlices(endpointSliceImports []fleetnetv1alpha1.EndpointSliceImport,
+	endpointSlices []discoveryv1.EndpointSlice, now time.Time) []*discoveryv1.EndpointSlice {
+	existingEndpointSliceImports := sets.New[string]()
+	for i := range endpointSliceImports {
+		existingEndpointSliceImports.Insert(endpointSliceImports[i].Name)
+	}
+
+	var orphans []*discoveryv1.EndpointSlice
+	for i := range endpointSlices {
+		endpointSlice := &endpointSlices[i]
+		if !gc.IsOrphanCandidate(endpointSlice, now) {
+			continue
+		}
+		sources := []string{endpointSlice.Name}
+		if _, ok := endpointSlice.Labels[objectmeta.EndpointSliceLabelAggregatedServiceName]; ok {
+			sources = strings.Split(endpointSlice.Annotations[objectmeta.EndpointSliceAnnotationAggregatedSources], ",")
+		}
+		if !existingEndpointSliceImports.HasAny(sources...) {
+			orphans = append(orphans, endpointSlice)
+		}
+	}
+	return orphans
+}
@@ -0,0 +1,191 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package gc
+
+import (
+	"context"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/controllers/member/endpointsliceimport"
+)
+
+const (
+	hubNSForMember = "bravelion"
+	fleetSystemNS  = "fleet-system"
+	memberUserNS   = "work"
+	mcsName        = "app"
+	altMCSName     = "app2"
+)
+
+var (
+	now       = time.Date(2024, 1, 1, 0, 10, 0, 0, time.UTC)
+	oldTime   = metav1.NewTime(now.Add(-time.Hour))
+	youngTime = metav1.NewTime(now.Add(-time.Second))
+)
+
+func TestMain(m *testing.M) {
+	// Add custom APIs to the runtime scheme.
+	if err := fleetnetv1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		log.Fatalf("failed to add custom APIs to the runtime scheme: %v", err)
+	}
+
+	os.Exit(m.Run())
+}
+
+func multiClusterService(name string) fleetnetv1alpha1.MultiClusterService {
+	return fleetnetv1alpha1.MultiClusterService{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: memberUserNS,
+			Name:      name,
+		},
+	}
+}
+
+func derivedService(name, mcsName string, creationTimestamp metav1.Time) corev1.Service {
+	return corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         fleetSystemNS,
+			Name:              name,
+			CreationTimestamp: creationTimestamp,
+			Labels: map[string]string{
+				objectmeta.ServiceLabelMultiClusterServiceNamespace: memberUserNS,
+				objectmeta.ServiceLabelMultiClusterServiceName:      mcsName,
+			},
+		},
+	}
+}
+
+func endpointSliceImport(name string) fleetnetv1alpha1.EndpointSliceImport {
+	return fleetnetv1alpha1.EndpointSliceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: hubNSForMember,
+			Name:      name,
+		},
+	}
+}
+
+func importedEndpointSlice(name string, creationTimestamp metav1.Time) discoveryv1.EndpointSlice {
+	return discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         fleetSystemNS,
+			Name:              name,
+			CreationTimestamp: creationTimestamp,
+			Labels: map[string]string{
+				discoveryv1.LabelManagedBy: endpointsliceimport.ControllerID,
+			},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+	}
+}
+
+func aggregatedEndpointSlice(name, sources string, creationTimestamp metav1.Time) discoveryv1.EndpointSlice {
+	endpointSlice := importedEndpointSlice(name, creationTimestamp)
+	endpointSlice.Labels[objectmeta.EndpointSliceLabelAggregatedServiceNamespace] = memberUserNS
+	endpointSlice.Labels[objectmeta.EndpointSliceLabelAggregatedServiceName] = mcsName
+	endpointSlice.Annotations = map[string]string{
+		objectmeta.EndpointSliceAnnotationAggregatedSources: sources,
+	}
+	return endpointSlice
+}
+
+func objectNames[T client.Object](objs []T) []string {
+	names := make([]string, 0, len(objs))
+	for _, obj := range objs {
+		names = append(names, obj.GetName())
+	}
+	return names
+}
+
+// TestFindOrphanedDerivedServices tests the findOrphanedDerivedServices function.
+func TestFindOrphanedDerivedServices(t *testing.T) {
+	multiClusterSvcs := []fleetnetv1alpha1.MultiClusterService{multiClusterService(mcsName)}
+	svcs := []corev1.Service{
+		derivedService("derived", mcsName, oldTime),
+		derivedService("orphaned", altMCSName, oldTime),
+		derivedService("young", altMCSName, youngTime),
+	}
+
+	got := objectNames(findOrphanedDerivedServices(multiClusterSvcs, svcs, now))
+	want := []string{"orphaned"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("findOrphanedDerivedServices() mismatch (-got, +want):\n%s", diff)
+	}
+}
+
+// TestFindOrphanedImportedEndpointSlices tests the findOrphanedImportedEndpointSlices function.
+func TestFindOrphanedImportedEndpointSlices(t *testing.T) {
+	endpointSliceImports := []fleetnetv1alpha1.EndpointSliceImport{
+		endpointSliceImport("imported"),
+		endpointSliceImport("source-2"),
+	}
+	endpointSlices := []discoveryv1.EndpointSlice{
+		importedEndpointSlice("imported", oldTime),
+		importedEndpointSlice("orphaned", oldTime),
+		importedEndpointSlice("young", youngTime),
+		aggregatedEndpointSlice("aggregated", "source-1,source-2", oldTime),
+		aggregatedEndpointSlice("orphaned-aggregated", "source-1,source-3", oldTime),
+	}
+
+	got := objectNames(findOrphanedImportedEndpointSlices(endpointSliceImports, endpointSlices, now))
+	want := []string{"orphaned", "orphaned-aggregated"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("findOrphanedImportedEndpointSlices() mismatch (-got, +want):\n%s", diff)
+	}
+}
+
+// TestFindOrphans tests the OrphanFinder.FindOrphans method.
+func TestFindOrphans(t *testing.T) {
+	multiClusterSvc := multiClusterService(mcsName)
+	derivedSvc := derivedService("derived", mcsName, oldTime)
+	orphanedSvc := derivedService("orphaned", altMCSName, oldTime)
+	endpointSliceImport := endpointSliceImport("imported")
+	endpointSlice := importedEndpointSlice("imported", oldTime)
+	orphanedEndpointSlice := importedEndpointSlice("orphaned", oldTime)
+
+	fakeMemberClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(&multiClusterSvc, &derivedSvc, &orphanedSvc, &endpointSlice, &orphanedEndpointSlice).
+		Build()
+	fakeHubClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(&endpointSliceImport).
+		Build()
+	finder := &OrphanFinder{
+		MemberClient:         fakeMemberClient,
+		HubClient:            fakeHubClient,
+		HubNamespace:         hubNSForMember,
+		FleetSystemNamespace: fleetSystemNS,
+	}
+
+	orphans, err := finder.FindOrphans(context.Background(), now)
+	if err != nil {
+		t.Fatalf("FindOrphans() = %v, want no error", err)
+	}
+	got := map[string][]string{}
+	for _, o := range orphans {
+		got[o.Kind] = objectNames(o.Objects)
+	}
+	want := map[string][]string{
+		kindDerivedService:        {"orphaned"},
+		kindImportedEndpointSlice: {"orphaned"},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("FindOrphans() mismatch (-got, +want):\n%s", diff)
+	}
+}
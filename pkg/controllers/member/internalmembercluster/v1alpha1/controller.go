@@ -27,12 +27,29 @@ import (
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 	"go.goms.io/fleet-networking/pkg/common/apiretry"
+	"go.goms.io/fleet-networking/pkg/common/condition"
+	"go.goms.io/fleet-networking/pkg/common/membership"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
 )
 
 const (
 	conditionReasonJoined = "AgentJoined"
 	conditionReasonLeft   = "AgentLeft"
 
+	// conditionTypeLeaving is the type of the agent status condition which reports the progress of the leave flow.
+	conditionTypeLeaving = "Leaving"
+
+	// The reasons of the Leaving condition, one per phase of the leave flow.
+	conditionReasonWaitingForImportsDrained = "WaitingForImportsDrained"
+	conditionReasonDrainingImports          = "DrainingImports"
+	conditionReasonDrainingExports          = "DrainingExports"
+	conditionReasonDrained                  = "Drained"
+
+	// importsDrainTimeout is the maximum amount of time the ServiceExportImport agent waits for the MCS agent to
+	// drain the imports before it drains the exports.
+	importsDrainTimeout = 2 * time.Minute
+	leaveRetryInterval  = 5 * time.Second
+
 	// we add +-5% jitter
 	jitterPercent = 10
 )
@@ -42,6 +59,9 @@ type Reconciler struct {
 	MemberClient client.Client
 	HubClient    client.Client
 	AgentType    fleetv1alpha1.AgentType
+	// MembershipGate (if set) is closed when the member cluster leaves the fleet, which stops the export and import
+	// controllers of the agent from processing new resources, and re-opened when the member cluster re-joins the fleet.
+	MembershipGate *membership.Gate
 }
 
 //+kubebuilder:rbac:groups=fleet.azure.com,resources=internalmemberclusters,verbs=get;list;watch
@@ -73,6 +93,9 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 
 	switch imc.Spec.State {
 	case fleetv1alpha1.ClusterStateJoin:
+		if r.MembershipGate != nil && r.MembershipGate.Open() {
+			klog.V(2).InfoS("member cluster has re-joined the fleet; resume exporting and importing resources", "internalMemberCluster", imcKRef)
+		}
 		agentStatus := fleetv1alpha1.AgentStatus{
 			Type: r.AgentType,
 			Conditions: []metav1.Condition{
@@ -85,6 +108,10 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			},
 			LastReceivedHeartbeat: metav1.NewTime(time.Now()),
 		}
+		// Clear the progress of the leave flow, if the member cluster has re-joined the fleet.
+		if existingStatus := findAgentStatus(imc.Status.AgentStatus, r.AgentType); existingStatus != nil {
+			meta.RemoveStatusCondition(&existingStatus.Conditions, conditionTypeLeaving)
+		}
 		if err := r.updateAgentStatus(ctx, &imc, agentStatus); err != nil {
 			return ctrl.Result{}, err
 		}
@@ -94,32 +121,134 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		requeueAfter := time.Millisecond * (time.Duration(hbInterval) + time.Duration(rand.Int63nRange(0, jitterRange)-jitterRange/2))
 		return ctrl.Result{RequeueAfter: requeueAfter}, nil
 	case fleetv1alpha1.ClusterStateLeave:
-		if r.AgentType == fleetv1alpha1.MultiClusterServiceAgent {
-			if err := r.cleanupMCSRelatedResources(ctx); err != nil {
-				return ctrl.Result{}, err
-			}
+		klog.V(2).InfoS("member cluster is leaving the fleet; performing cleanup", "internalMemberCluster", imcKRef)
+		return r.leave(ctx, &imc)
+	default:
+		klog.ErrorS(errors.New("unknown state"), "internalMemberCluster", imcKRef, "state", imc.Spec.State)
+	}
+	return ctrl.Result{}, nil
+}
+
+// leave runs the leave flow of the agent, which
+//  1. stops the agent from exporting or importing new resources, by closing the membership gate;
+//  2. drains the imports (MCSes) first, so that traffic moves away from the member cluster before its exports are
+//     withdrawn; the ServiceExportImport agent waits (up to importsDrainTimeout) for the MCS agent to leave;
+//  3. drains the exports (ServiceExports); and
+//  4. reports that the agent has left the fleet.
+//
+// The progress is reported via the Leaving condition of the agent status.
+func (r *Reconciler) leave(ctx context.Context, imc *fleetv1alpha1.InternalMemberCluster) (ctrl.Result, error) {
+	imcKObj := klog.KObj(imc)
+	if r.MembershipGate != nil && r.MembershipGate.Close() {
+		klog.V(2).InfoS("Stop exporting and importing resources", "internalMemberCluster", imcKObj)
+	}
+
+	if hasLeft(imc, r.AgentType) {
+		klog.V(4).InfoS("The agent has left the fleet", "internalMemberCluster", imcKObj, "agentType", r.AgentType)
+		return ctrl.Result{}, nil
+	}
+
+	switch r.AgentType {
+	case fleetv1alpha1.MultiClusterServiceAgent:
+		if err := r.reportLeaveProgress(ctx, imc, conditionReasonDrainingImports, "deleting multiClusterServices"); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.cleanupMCSRelatedResources(ctx); err != nil {
+			return ctrl.Result{}, err
 		}
-		if r.AgentType == fleetv1alpha1.ServiceExportImportAgent {
-			if err := r.cleanupServiceExportRelatedResources(ctx); err != nil {
+	case fleetv1alpha1.ServiceExportImportAgent:
+		if isWaitingForImportsDrained(imc, time.Now()) {
+			klog.V(2).InfoS("Wait for the imports to be drained before draining the exports", "internalMemberCluster", imcKObj)
+			if err := r.reportLeaveProgress(ctx, imc, conditionReasonWaitingForImportsDrained, "waiting for the multiClusterService agent to leave the fleet"); err != nil {
 				return ctrl.Result{}, err
 			}
+			return ctrl.Result{RequeueAfter: leaveRetryInterval}, nil
 		}
-		agentStatus := fleetv1alpha1.AgentStatus{
-			Type: r.AgentType,
-			Conditions: []metav1.Condition{
-				{
-					Type:               string(fleetv1alpha1.AgentJoined),
-					Status:             metav1.ConditionFalse,
-					Reason:             conditionReasonLeft,
-					ObservedGeneration: imc.GetGeneration(),
-				},
+		if err := r.reportLeaveProgress(ctx, imc, conditionReasonDrainingExports, "deleting serviceExports"); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.cleanupServiceExportRelatedResources(ctx); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	agentStatus := fleetv1alpha1.AgentStatus{
+		Type: r.AgentType,
+		Conditions: []metav1.Condition{
+			{
+				Type:               string(fleetv1alpha1.AgentJoined),
+				Status:             metav1.ConditionFalse,
+				Reason:             conditionReasonLeft,
+				ObservedGeneration: imc.GetGeneration(),
+			},
+			{
+				Type:               conditionTypeLeaving,
+				Status:             metav1.ConditionFalse,
+				Reason:             conditionReasonDrained,
+				ObservedGeneration: imc.GetGeneration(),
+				Message:            "all the exports and imports of the agent have been drained",
 			},
+		},
+	}
+	return ctrl.Result{}, r.updateAgentStatus(ctx, imc, agentStatus)
+}
+
+// hasLeft returns if the agent has completed the leave flow for the current spec of the InternalMemberCluster; a
+// Joined condition reported for an earlier generation does not count.
+func hasLeft(imc *fleetv1alpha1.InternalMemberCluster, agentType fleetv1alpha1.AgentType) bool {
+	agentStatus := findAgentStatus(imc.Status.AgentStatus, agentType)
+	if agentStatus == nil {
+		return false
+	}
+	joinedCond := meta.FindStatusCondition(agentStatus.Conditions, string(fleetv1alpha1.AgentJoined))
+	return joinedCond != nil && joinedCond.Status == metav1.ConditionFalse && joinedCond.ObservedGeneration >= imc.GetGeneration()
+}
+
+// reportLeaveProgress sets the Leaving condition of the agent status to the given phase.
+func (r *Reconciler) reportLeaveProgress(ctx context.Context, imc *fleetv1alpha1.InternalMemberCluster, reason, message string) error {
+	agentStatus := findAgentStatus(imc.Status.AgentStatus, r.AgentType)
+	if agentStatus == nil {
+		imc.Status.AgentStatus = append(imc.Status.AgentStatus, fleetv1alpha1.AgentStatus{Type: r.AgentType})
+		agentStatus = &imc.Status.AgentStatus[len(imc.Status.AgentStatus)-1]
+	}
+	leavingCond := metav1.Condition{
+		Type:               conditionTypeLeaving,
+		Status:             metav1.ConditionTrue,
+		Reason:             reason,
+		ObservedGeneration: imc.GetGeneration(),
+		Message:            message,
+	}
+	if condition.EqualCondition(meta.FindStatusCondition(agentStatus.Conditions, conditionTypeLeaving), &leavingCond) {
+		return nil
+	}
+	meta.SetStatusCondition(&agentStatus.Conditions, leavingCond)
+	klog.V(2).InfoS("Reporting leave progress", "internalMemberCluster", klog.KObj(imc), "agentType", r.AgentType, "reason", reason)
+	if err := r.HubClient.Status().Update(ctx, imc); err != nil {
+		klog.ErrorS(err, "Failed to update internalMemberCluster status", "internalMemberCluster", klog.KObj(imc))
+		return err
+	}
+	return nil
+}
+
+// isWaitingForImportsDrained returns if the ServiceExportImport agent should keep waiting for the MCS agent to
+// drain the imports; it stops waiting when the MCS agent has left, has never reported its status (i.e. it is not
+// deployed), or does not leave within importsDrainTimeout.
+func isWaitingForImportsDrained(imc *fleetv1alpha1.InternalMemberCluster, now time.Time) bool {
+	mcsAgentStatus := findAgentStatus(imc.Status.AgentStatus, fleetv1alpha1.MultiClusterServiceAgent)
+	if mcsAgentStatus == nil {
+		return false
+	}
+	if joinedCond := meta.FindStatusCondition(mcsAgentStatus.Conditions, string(fleetv1alpha1.AgentJoined)); joinedCond == nil || joinedCond.Status != metav1.ConditionTrue {
+		return false
+	}
+	if agentStatus := findAgentStatus(imc.Status.AgentStatus, fleetv1alpha1.ServiceExportImportAgent); agentStatus != nil {
+		leavingCond := meta.FindStatusCondition(agentStatus.Conditions, conditionTypeLeaving)
+		if leavingCond != nil && leavingCond.Status == metav1.ConditionTrue && now.Sub(leavingCond.LastTransitionTime.Time) >= importsDrainTimeout {
+			klog.V(2).InfoS("Timed out waiting for the imports to be drained", "internalMemberCluster", klog.KObj(imc), "timeout", importsDrainTimeout)
+			return false
 		}
-		return ctrl.Result{}, r.updateAgentStatus(ctx, &imc, agentStatus)
-	default:
-		klog.ErrorS(errors.New("unknown state"), "internalMemberCluster", imcKRef, "state", imc.Spec.State)
 	}
-	return ctrl.Result{}, nil
+	return true
 }
 
 func findAgentStatus(status []fleetv1alpha1.AgentStatus, agentType fleetv1alpha1.AgentType) *fleetv1alpha1.AgentStatus {
@@ -250,5 +379,5 @@ func (r *Reconciler) cleanupServiceExportRelatedResources(ctx context.Context) e
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&fleetv1alpha1.InternalMemberCluster{}).
-		Complete(r)
+		Complete(metrics.InstrumentReconciler("internalmembercluster", r))
 }
@@ -192,6 +192,11 @@ var _ = Describe("Test InternalMemberCluster Controller", func() {
 								Status: metav1.ConditionFalse,
 								Reason: conditionReasonLeft,
 							},
+							{
+								Type:   conditionTypeLeaving,
+								Status: metav1.ConditionFalse,
+								Reason: conditionReasonDrained,
+							},
 						},
 					},
 				}
@@ -339,6 +344,11 @@ var _ = Describe("Test InternalMemberCluster Controller", func() {
 								Status: metav1.ConditionTrue,
 								Reason: conditionReasonJoined,
 							},
+							{
+								Type:   conditionTypeLeaving,
+								Status: metav1.ConditionTrue,
+								Reason: conditionReasonDrainingImports,
+							},
 						},
 					},
 				}
@@ -370,6 +380,11 @@ var _ = Describe("Test InternalMemberCluster Controller", func() {
 								Status: metav1.ConditionFalse,
 								Reason: conditionReasonLeft,
 							},
+							{
+								Type:   conditionTypeLeaving,
+								Status: metav1.ConditionFalse,
+								Reason: conditionReasonDrained,
+							},
 						},
 					},
 				}
@@ -549,6 +564,11 @@ var _ = Describe("Test InternalMemberCluster Controller", func() {
 								Status: metav1.ConditionFalse,
 								Reason: conditionReasonLeft,
 							},
+							{
+								Type:   conditionTypeLeaving,
+								Status: metav1.ConditionFalse,
+								Reason: conditionReasonDrained,
+							},
 						},
 					},
 				}
@@ -722,6 +742,11 @@ var _ = Describe("Test InternalMemberCluster Controller", func() {
 								Status: metav1.ConditionTrue,
 								Reason: conditionReasonJoined,
 							},
+							{
+								Type:   conditionTypeLeaving,
+								Status: metav1.ConditionTrue,
+								Reason: conditionReasonDrainingExports,
+							},
 						},
 					},
 				}
@@ -753,6 +778,11 @@ var _ = Describe("Test InternalMemberCluster Controller", func() {
 								Status: metav1.ConditionFalse,
 								Reason: conditionReasonLeft,
 							},
+							{
+								Type:   conditionTypeLeaving,
+								Status: metav1.ConditionFalse,
+								Reason: conditionReasonDrained,
+							},
 						},
 					},
 				}
@@ -6,14 +6,22 @@ Licensed under the MIT license.
 package internalmembercluster
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	fleetv1alpha1 "go.goms.io/fleet/apis/v1alpha1"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/membership"
 )
 
 func joinedCondition() metav1.Condition {
@@ -159,3 +167,224 @@ func TestSetAgentStatus(t *testing.T) {
 		})
 	}
 }
+
+func internalMemberClusterScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	if err := fleetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add fleet APIs to the scheme: %v", err)
+	}
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add fleet networking APIs to the scheme: %v", err)
+	}
+	return scheme
+}
+
+// TestIsWaitingForImportsDrained tests the isWaitingForImportsDrained function.
+func TestIsWaitingForImportsDrained(t *testing.T) {
+	now := time.Now()
+	leavingCondSince := func(since time.Time) metav1.Condition {
+		return metav1.Condition{
+			Type:               conditionTypeLeaving,
+			Status:             metav1.ConditionTrue,
+			Reason:             conditionReasonWaitingForImportsDrained,
+			LastTransitionTime: metav1.NewTime(since),
+		}
+	}
+
+	testCases := []struct {
+		name        string
+		agentStatus []fleetv1alpha1.AgentStatus
+		want        bool
+	}{
+		{
+			name: "no MCS agent",
+			agentStatus: []fleetv1alpha1.AgentStatus{
+				{Type: fleetv1alpha1.ServiceExportImportAgent, Conditions: []metav1.Condition{joinedCondition()}},
+			},
+		},
+		{
+			name: "MCS agent has left",
+			agentStatus: []fleetv1alpha1.AgentStatus{
+				{Type: fleetv1alpha1.MultiClusterServiceAgent, Conditions: []metav1.Condition{leftCondition()}},
+				{Type: fleetv1alpha1.ServiceExportImportAgent, Conditions: []metav1.Condition{joinedCondition()}},
+			},
+		},
+		{
+			name: "MCS agent is still joined",
+			agentStatus: []fleetv1alpha1.AgentStatus{
+				{Type: fleetv1alpha1.MultiClusterServiceAgent, Conditions: []metav1.Condition{joinedCondition()}},
+				{Type: fleetv1alpha1.ServiceExportImportAgent, Conditions: []metav1.Condition{joinedCondition(), leavingCondSince(now.Add(-time.Second))}},
+			},
+			want: true,
+		},
+		{
+			name: "MCS agent is still joined, timed out",
+			agentStatus: []fleetv1alpha1.AgentStatus{
+				{Type: fleetv1alpha1.MultiClusterServiceAgent, Conditions: []metav1.Condition{joinedCondition()}},
+				{Type: fleetv1alpha1.ServiceExportImportAgent, Conditions: []metav1.Condition{joinedCondition(), leavingCondSince(now.Add(-importsDrainTimeout))}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			imc := &fleetv1alpha1.InternalMemberCluster{
+				Status: fleetv1alpha1.InternalMemberClusterStatus{AgentStatus: tc.agentStatus},
+			}
+			if got := isWaitingForImportsDrained(imc, now); got != tc.want {
+				t.Errorf("isWaitingForImportsDrained() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestLeave tests the leave method.
+func TestLeave(t *testing.T) {
+	const (
+		memberClusterNamespace = "fleet-system-member-cluster-a"
+		memberClusterName      = "member-cluster-a"
+		svcExportNamespace     = "work"
+		svcExportName          = "app"
+	)
+	leftAgentStatus := func(agentType fleetv1alpha1.AgentType, observedGeneration int64) fleetv1alpha1.AgentStatus {
+		return fleetv1alpha1.AgentStatus{
+			Type: agentType,
+			Conditions: []metav1.Condition{
+				{
+					Type:               string(fleetv1alpha1.AgentJoined),
+					Status:             metav1.ConditionFalse,
+					Reason:             conditionReasonLeft,
+					ObservedGeneration: observedGeneration,
+				},
+				{
+					Type:               conditionTypeLeaving,
+					Status:             metav1.ConditionFalse,
+					Reason:             conditionReasonDrained,
+					ObservedGeneration: observedGeneration,
+				},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name                 string
+		agentType            fleetv1alpha1.AgentType
+		agentStatus          []fleetv1alpha1.AgentStatus
+		wantRequeue          bool
+		wantConditions       []metav1.Condition
+		wantSvcExportDeleted bool
+	}{
+		{
+			name:           "MCS agent drains the imports and leaves",
+			agentType:      fleetv1alpha1.MultiClusterServiceAgent,
+			agentStatus:    []fleetv1alpha1.AgentStatus{{Type: fleetv1alpha1.MultiClusterServiceAgent, Conditions: []metav1.Condition{joinedCondition()}}},
+			wantConditions: leftAgentStatus(fleetv1alpha1.MultiClusterServiceAgent, 2).Conditions,
+		},
+		{
+			name:      "ServiceExportImport agent waits for the MCS agent",
+			agentType: fleetv1alpha1.ServiceExportImportAgent,
+			agentStatus: []fleetv1alpha1.AgentStatus{
+				{Type: fleetv1alpha1.MultiClusterServiceAgent, Conditions: []metav1.Condition{joinedCondition()}},
+				{Type: fleetv1alpha1.ServiceExportImportAgent, Conditions: []metav1.Condition{joinedCondition()}},
+			},
+			wantRequeue: true,
+			wantConditions: []metav1.Condition{
+				joinedCondition(),
+				{
+					Type:               conditionTypeLeaving,
+					Status:             metav1.ConditionTrue,
+					Reason:             conditionReasonWaitingForImportsDrained,
+					ObservedGeneration: 2,
+				},
+			},
+		},
+		{
+			name:                 "ServiceExportImport agent drains the exports and leaves",
+			agentType:            fleetv1alpha1.ServiceExportImportAgent,
+			agentStatus:          []fleetv1alpha1.AgentStatus{{Type: fleetv1alpha1.ServiceExportImportAgent, Conditions: []metav1.Condition{joinedCondition()}}},
+			wantConditions:       leftAgentStatus(fleetv1alpha1.ServiceExportImportAgent, 2).Conditions,
+			wantSvcExportDeleted: true,
+		},
+		{
+			name:           "ServiceExportImport agent has left",
+			agentType:      fleetv1alpha1.ServiceExportImportAgent,
+			agentStatus:    []fleetv1alpha1.AgentStatus{leftAgentStatus(fleetv1alpha1.ServiceExportImportAgent, 2)},
+			wantConditions: leftAgentStatus(fleetv1alpha1.ServiceExportImportAgent, 2).Conditions,
+		},
+		{
+			name:                 "ServiceExportImport agent drains the exports again when it left at an earlier generation",
+			agentType:            fleetv1alpha1.ServiceExportImportAgent,
+			agentStatus:          []fleetv1alpha1.AgentStatus{leftAgentStatus(fleetv1alpha1.ServiceExportImportAgent, 1)},
+			wantConditions:       leftAgentStatus(fleetv1alpha1.ServiceExportImportAgent, 2).Conditions,
+			wantSvcExportDeleted: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			imc := &fleetv1alpha1.InternalMemberCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       memberClusterName,
+					Namespace:  memberClusterNamespace,
+					Generation: 2,
+				},
+				Spec: fleetv1alpha1.InternalMemberClusterSpec{
+					State: fleetv1alpha1.ClusterStateLeave,
+				},
+				Status: fleetv1alpha1.InternalMemberClusterStatus{
+					AgentStatus: tc.agentStatus,
+				},
+			}
+			svcExport := &fleetnetv1alpha1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      svcExportName,
+					Namespace: svcExportNamespace,
+				},
+			}
+			scheme := internalMemberClusterScheme(t)
+			fakeHubClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(imc).
+				WithStatusSubresource(imc).
+				Build()
+			fakeMemberClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(svcExport).Build()
+			gate := membership.NewGate()
+			gate.Open()
+			reconciler := &Reconciler{
+				MemberClient:   fakeMemberClient,
+				HubClient:      fakeHubClient,
+				AgentType:      tc.agentType,
+				MembershipGate: gate,
+			}
+
+			ctx := context.Background()
+			res, err := reconciler.leave(ctx, imc)
+			if err != nil {
+				t.Fatalf("leave() = %v, want no error", err)
+			}
+			if gotRequeue := res.RequeueAfter > 0; gotRequeue != tc.wantRequeue {
+				t.Errorf("leave() requeue = %t, want %t", gotRequeue, tc.wantRequeue)
+			}
+			if gate.IsOpen() {
+				t.Errorf("membership gate is open, want closed")
+			}
+
+			internalMemberCluster := &fleetv1alpha1.InternalMemberCluster{}
+			if err := fakeHubClient.Get(ctx, types.NamespacedName{Namespace: memberClusterNamespace, Name: memberClusterName}, internalMemberCluster); err != nil {
+				t.Fatalf("Get() internalMemberCluster = %v, want no error", err)
+			}
+			agentStatus := findAgentStatus(internalMemberCluster.Status.AgentStatus, tc.agentType)
+			if agentStatus == nil {
+				t.Fatalf("agent status of %s is not found", tc.agentType)
+			}
+			if diff := cmp.Diff(agentStatus.Conditions, tc.wantConditions, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime", "Message")); diff != "" {
+				t.Errorf("agent conditions diff (-got, +want): %s", diff)
+			}
+
+			err = fakeMemberClient.Get(ctx, types.NamespacedName{Namespace: svcExportNamespace, Name: svcExportName}, &fleetnetv1alpha1.ServiceExport{})
+			if gotSvcExportDeleted := errors.IsNotFound(err); gotSvcExportDeleted != tc.wantSvcExportDeleted {
+				t.Errorf("serviceExport deleted = %t, want %t", gotSvcExportDeleted, tc.wantSvcExportDeleted)
+			}
+		})
+	}
+}
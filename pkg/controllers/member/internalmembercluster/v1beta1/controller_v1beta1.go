@@ -23,12 +23,29 @@ import (
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 	"go.goms.io/fleet-networking/pkg/common/apiretry"
+	"go.goms.io/fleet-networking/pkg/common/condition"
+	"go.goms.io/fleet-networking/pkg/common/membership"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
 )
 
 const (
 	conditionReasonJoined = "AgentJoined"
 	conditionReasonLeft   = "AgentLeft"
 
+	// conditionTypeLeaving is the type of the agent status condition which reports the progress of the leave flow.
+	conditionTypeLeaving = "Leaving"
+
+	// The reasons of the Leaving condition, one per phase of the leave flow.
+	conditionReasonWaitingForImportsDrained = "WaitingForImportsDrained"
+	conditionReasonDrainingImports          = "DrainingImports"
+	conditionReasonDrainingExports          = "DrainingExports"
+	conditionReasonDrained                  = "Drained"
+
+	// importsDrainTimeout is the maximum amount of time the ServiceExportImport agent waits for the MCS agent to
+	// drain the imports before it drains the exports.
+	importsDrainTimeout = 2 * time.Minute
+	leaveRetryInterval  = 5 * time.Second
+
 	// we add +-5% jitter
 	jitterPercent = 10
 )
@@ -38,6 +55,10 @@ type Reconciler struct {
 	MemberClient client.Client
 	HubClient    client.Client
 	AgentType    clusterv1beta1.AgentType
+	// MembershipGate (if set) is closed when the member cluster starts to leave the fleet, which stops the export
+	// and import controllers of the agent from processing new resources, and re-opened when the member cluster
+	// re-joins the fleet.
+	MembershipGate *membership.Gate
 }
 
 //+kubebuilder:rbac:groups=cluster.kubernetes-fleet.io,resources=internalmemberclusters,verbs=get;list;watch
@@ -70,23 +91,14 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	switch imc.Spec.State {
 	case clusterv1beta1.ClusterStateLeave:
 		// The member cluster is leaving the fleet.
-		klog.V(2).InfoS("member cluster has left the fleet; performing cleanup", "internalMemberCluster", imcKRef)
-
-		// Clean up fleet networking related resources.
-		if r.AgentType == clusterv1beta1.MultiClusterServiceAgent {
-			if err := r.cleanupMCSRelatedResources(ctx); err != nil {
-				return ctrl.Result{}, err
-			}
-		}
-		if r.AgentType == clusterv1beta1.ServiceExportImportAgent {
-			if err := r.cleanupServiceExportRelatedResources(ctx); err != nil {
-				return ctrl.Result{}, err
-			}
+		klog.V(2).InfoS("member cluster is leaving the fleet; performing cleanup", "internalMemberCluster", imcKRef)
+		return r.leave(ctx, &imc)
+	case clusterv1beta1.ClusterStateJoin:
+		// Resume exporting and importing resources if the member cluster has re-joined the fleet.
+		if r.MembershipGate != nil && r.MembershipGate.Open() {
+			klog.V(2).InfoS("member cluster has re-joined the fleet; resume exporting and importing resources", "internalMemberCluster", imcKRef)
 		}
 
-		// Update the agent status.
-		return ctrl.Result{}, r.updateAgentStatus(ctx, &imc)
-	case clusterv1beta1.ClusterStateJoin:
 		// The member cluster still has an active membership in the fleet; update the agent status.
 		if err := r.updateAgentStatus(ctx, &imc); err != nil {
 			return ctrl.Result{}, err
@@ -104,6 +116,120 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	return ctrl.Result{}, nil
 }
 
+// leave runs the leave flow of the agent, which
+//  1. stops the agent from exporting or importing new resources, by closing the membership gate;
+//  2. drains the imports (MCSes) first, so that traffic moves away from the member cluster before its exports are
+//     withdrawn; the ServiceExportImport agent waits (up to importsDrainTimeout) for the MCS agent to leave;
+//  3. drains the exports (ServiceExports); and
+//  4. reports that the agent has left the fleet.
+//
+// The progress is reported via the Leaving condition of the agent status.
+func (r *Reconciler) leave(ctx context.Context, imc *clusterv1beta1.InternalMemberCluster) (ctrl.Result, error) {
+	imcKObj := klog.KObj(imc)
+	if r.MembershipGate != nil && r.MembershipGate.Close() {
+		klog.V(2).InfoS("Stop exporting and importing resources", "internalMemberCluster", imcKObj)
+	}
+
+	if hasLeft(imc, r.AgentType) {
+		klog.V(4).InfoS("The agent has left the fleet", "internalMemberCluster", imcKObj, "agentType", r.AgentType)
+		return ctrl.Result{}, nil
+	}
+
+	// Clean up fleet networking related resources.
+	switch r.AgentType {
+	case clusterv1beta1.MultiClusterServiceAgent:
+		if err := r.reportLeaveProgress(ctx, imc, conditionReasonDrainingImports, "deleting multiClusterServices"); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.cleanupMCSRelatedResources(ctx); err != nil {
+			return ctrl.Result{}, err
+		}
+	case clusterv1beta1.ServiceExportImportAgent:
+		if isWaitingForImportsDrained(imc, time.Now()) {
+			klog.V(2).InfoS("Wait for the imports to be drained before draining the exports", "internalMemberCluster", imcKObj)
+			if err := r.reportLeaveProgress(ctx, imc, conditionReasonWaitingForImportsDrained, "waiting for the multiClusterService agent to leave the fleet"); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: leaveRetryInterval}, nil
+		}
+		if err := r.reportLeaveProgress(ctx, imc, conditionReasonDrainingExports, "deleting serviceExports"); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.cleanupServiceExportRelatedResources(ctx); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Update the agent status.
+	return ctrl.Result{}, r.updateAgentStatus(ctx, imc)
+}
+
+// hasLeft returns if the agent has completed the leave flow for the current spec of the InternalMemberCluster; a
+// Joined condition reported for an earlier generation (e.g. the member cluster re-joined and left again before the
+// agent observed it) does not count, as resources may have been exported or imported in the meantime.
+func hasLeft(imc *clusterv1beta1.InternalMemberCluster, agentType clusterv1beta1.AgentType) bool {
+	agentStatus := findAgentStatus(imc.Status.AgentStatus, agentType)
+	if agentStatus == nil {
+		return false
+	}
+	joinedCond := meta.FindStatusCondition(agentStatus.Conditions, string(clusterv1beta1.AgentJoined))
+	return joinedCond != nil && joinedCond.Status == metav1.ConditionFalse && joinedCond.ObservedGeneration >= imc.GetGeneration()
+}
+
+// reportLeaveProgress sets the Leaving condition of the agent status to the given phase.
+func (r *Reconciler) reportLeaveProgress(ctx context.Context, imc *clusterv1beta1.InternalMemberCluster, reason, message string) error {
+	agentStatus := imc.GetAgentStatus(r.AgentType)
+	leavingCond := metav1.Condition{
+		Type:               conditionTypeLeaving,
+		Status:             metav1.ConditionTrue,
+		Reason:             reason,
+		ObservedGeneration: imc.GetGeneration(),
+		Message:            message,
+	}
+	if condition.EqualCondition(meta.FindStatusCondition(agentStatus.Conditions, conditionTypeLeaving), &leavingCond) {
+		return nil
+	}
+	meta.SetStatusCondition(&agentStatus.Conditions, leavingCond)
+	klog.V(2).InfoS("Reporting leave progress", "internalMemberCluster", klog.KObj(imc), "agentType", r.AgentType, "reason", reason)
+	if err := r.HubClient.Status().Update(ctx, imc); err != nil {
+		klog.ErrorS(err, "Failed to update internal member cluster status", "internalMemberCluster", klog.KObj(imc))
+		return err
+	}
+	return nil
+}
+
+// isWaitingForImportsDrained returns if the ServiceExportImport agent should keep waiting for the MCS agent to
+// drain the imports; it stops waiting when the MCS agent has left, has never reported its status (i.e. it is not
+// deployed), or does not leave within importsDrainTimeout.
+func isWaitingForImportsDrained(imc *clusterv1beta1.InternalMemberCluster, now time.Time) bool {
+	mcsAgentStatus := findAgentStatus(imc.Status.AgentStatus, clusterv1beta1.MultiClusterServiceAgent)
+	if mcsAgentStatus == nil {
+		return false
+	}
+	if joinedCond := meta.FindStatusCondition(mcsAgentStatus.Conditions, string(clusterv1beta1.AgentJoined)); joinedCond == nil || joinedCond.Status != metav1.ConditionTrue {
+		return false
+	}
+	if agentStatus := findAgentStatus(imc.Status.AgentStatus, clusterv1beta1.ServiceExportImportAgent); agentStatus != nil {
+		leavingCond := meta.FindStatusCondition(agentStatus.Conditions, conditionTypeLeaving)
+		if leavingCond != nil && leavingCond.Status == metav1.ConditionTrue && now.Sub(leavingCond.LastTransitionTime.Time) >= importsDrainTimeout {
+			klog.V(2).InfoS("Timed out waiting for the imports to be drained", "internalMemberCluster", klog.KObj(imc), "timeout", importsDrainTimeout)
+			return false
+		}
+	}
+	return true
+}
+
+// findAgentStatus returns the status of an agent, or nil if the agent has not reported its status; unlike
+// InternalMemberCluster.GetAgentStatus, it never adds a new agent status.
+func findAgentStatus(status []clusterv1beta1.AgentStatus, agentType clusterv1beta1.AgentType) *clusterv1beta1.AgentStatus {
+	for i := range status {
+		if status[i].Type == agentType {
+			return &status[i]
+		}
+	}
+	return nil
+}
+
 // updateAgentStatus reports the status of the agent via internal member cluster object.
 func (r *Reconciler) updateAgentStatus(ctx context.Context, imc *clusterv1beta1.InternalMemberCluster) error {
 	imcKObj := klog.KObj(imc)
@@ -120,6 +246,9 @@ func (r *Reconciler) updateAgentStatus(ctx context.Context, imc *clusterv1beta1.
 			ObservedGeneration: imc.GetGeneration(),
 		})
 
+		// Clear the progress of the leave flow, if the member cluster has re-joined the fleet.
+		meta.RemoveStatusCondition(&agentStatus.Conditions, conditionTypeLeaving)
+
 		// Update the last received heartbeat value.
 		agentStatus.LastReceivedHeartbeat = metav1.NewTime(time.Now())
 	} else {
@@ -130,6 +259,13 @@ func (r *Reconciler) updateAgentStatus(ctx context.Context, imc *clusterv1beta1.
 			Reason:             conditionReasonLeft,
 			ObservedGeneration: imc.GetGeneration(),
 		})
+		meta.SetStatusCondition(&agentStatus.Conditions, metav1.Condition{
+			Type:               conditionTypeLeaving,
+			Status:             metav1.ConditionFalse,
+			Reason:             conditionReasonDrained,
+			ObservedGeneration: imc.GetGeneration(),
+			Message:            "all the exports and imports of the agent have been drained",
+		})
 
 		// No need to send more heartbeats to the hub cluster as the meber cluster has left.
 	}
@@ -146,9 +282,8 @@ func (r *Reconciler) updateAgentStatus(ctx context.Context, imc *clusterv1beta1.
 	return nil
 }
 
-// cleanupMCSRelatedResources deletes the MCS related resources.
-// Ideally it should stop the controllers.
-// For now, it tries its best to delete the existing MCS and won't handle the newly created resources for now.
+// cleanupMCSRelatedResources deletes the MCS related resources; the MCSes created afterwards are not imported, as
+// the membership gate has been closed.
 func (r *Reconciler) cleanupMCSRelatedResources(ctx context.Context) error {
 	list := &fleetnetv1alpha1.MultiClusterServiceList{}
 	if err := r.MemberClient.List(ctx, list); err != nil {
@@ -185,9 +320,8 @@ func (r *Reconciler) cleanupMCSRelatedResources(ctx context.Context) error {
 	return nil
 }
 
-// cleanupServiceExportRelatedResources deletes the serviceExport related resources.
-// Ideally it should stop the controllers.
-// For now, it tries its best to delete the existing serviceExport and won't handle the newly created resources for now.
+// cleanupServiceExportRelatedResources deletes the serviceExport related resources; the serviceExports created
+// afterwards are not exported, as the membership gate has been closed.
 func (r *Reconciler) cleanupServiceExportRelatedResources(ctx context.Context) error {
 	list := &fleetnetv1alpha1.ServiceExportList{}
 	if err := r.MemberClient.List(ctx, list); err != nil {
@@ -227,5 +361,5 @@ func (r *Reconciler) cleanupServiceExportRelatedResources(ctx context.Context) e
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&clusterv1beta1.InternalMemberCluster{}).
-		Complete(r)
+		Complete(metrics.InstrumentReconciler("internalmembercluster", r))
 }
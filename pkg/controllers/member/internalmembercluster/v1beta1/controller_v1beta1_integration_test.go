@@ -190,6 +190,12 @@ var _ = Describe("Test InternalMemberCluster Controllers", Ordered, func() {
 							Reason:             conditionReasonLeft,
 							ObservedGeneration: internalMemberCluster.GetGeneration(),
 						},
+						{
+							Type:               conditionTypeLeaving,
+							Status:             metav1.ConditionFalse,
+							Reason:             conditionReasonDrained,
+							ObservedGeneration: internalMemberCluster.GetGeneration(),
+						},
 					},
 				},
 				{
@@ -201,6 +207,12 @@ var _ = Describe("Test InternalMemberCluster Controllers", Ordered, func() {
 							Reason:             conditionReasonLeft,
 							ObservedGeneration: internalMemberCluster.GetGeneration(),
 						},
+						{
+							Type:               conditionTypeLeaving,
+							Status:             metav1.ConditionFalse,
+							Reason:             conditionReasonDrained,
+							ObservedGeneration: internalMemberCluster.GetGeneration(),
+						},
 					},
 				},
 			}
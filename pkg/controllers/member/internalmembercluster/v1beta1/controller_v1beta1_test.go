@@ -21,6 +21,7 @@ import (
 	clusterv1beta1 "go.goms.io/fleet/apis/cluster/v1beta1"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/membership"
 )
 
 const (
@@ -146,6 +147,12 @@ func TestUpdateAgentStatus(t *testing.T) {
 							Reason:             conditionReasonLeft,
 							ObservedGeneration: 2,
 						},
+						{
+							Type:               conditionTypeLeaving,
+							Status:             metav1.ConditionFalse,
+							Reason:             conditionReasonDrained,
+							ObservedGeneration: 2,
+						},
 					},
 				},
 			},
@@ -284,3 +291,263 @@ func TestCleanupServiceExportRelatedResources(t *testing.T) {
 		}
 	}
 }
+
+// TestIsWaitingForImportsDrained tests the isWaitingForImportsDrained function.
+func TestIsWaitingForImportsDrained(t *testing.T) {
+	now := time.Now()
+	joinedCond := metav1.Condition{
+		Type:   string(clusterv1beta1.AgentJoined),
+		Status: metav1.ConditionTrue,
+		Reason: conditionReasonJoined,
+	}
+	leftCond := metav1.Condition{
+		Type:   string(clusterv1beta1.AgentJoined),
+		Status: metav1.ConditionFalse,
+		Reason: conditionReasonLeft,
+	}
+	leavingCondSince := func(since time.Time) metav1.Condition {
+		return metav1.Condition{
+			Type:               conditionTypeLeaving,
+			Status:             metav1.ConditionTrue,
+			Reason:             conditionReasonWaitingForImportsDrained,
+			LastTransitionTime: metav1.NewTime(since),
+		}
+	}
+
+	testCases := []struct {
+		name        string
+		agentStatus []clusterv1beta1.AgentStatus
+		want        bool
+	}{
+		{
+			name: "no MCS agent",
+			agentStatus: []clusterv1beta1.AgentStatus{
+				{Type: serviceExportImportAgentType, Conditions: []metav1.Condition{joinedCond}},
+			},
+		},
+		{
+			name: "MCS agent has left",
+			agentStatus: []clusterv1beta1.AgentStatus{
+				{Type: mcsAgentType, Conditions: []metav1.Condition{leftCond}},
+				{Type: serviceExportImportAgentType, Conditions: []metav1.Condition{joinedCond}},
+			},
+		},
+		{
+			name: "MCS agent is still joined",
+			agentStatus: []clusterv1beta1.AgentStatus{
+				{Type: mcsAgentType, Conditions: []metav1.Condition{joinedCond}},
+				{Type: serviceExportImportAgentType, Conditions: []metav1.Condition{joinedCond, leavingCondSince(now.Add(-time.Second))}},
+			},
+			want: true,
+		},
+		{
+			name: "MCS agent is still joined, timed out",
+			agentStatus: []clusterv1beta1.AgentStatus{
+				{Type: mcsAgentType, Conditions: []metav1.Condition{joinedCond}},
+				{Type: serviceExportImportAgentType, Conditions: []metav1.Condition{joinedCond, leavingCondSince(now.Add(-importsDrainTimeout))}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			imc := &clusterv1beta1.InternalMemberCluster{
+				Status: clusterv1beta1.InternalMemberClusterStatus{AgentStatus: tc.agentStatus},
+			}
+			if got := isWaitingForImportsDrained(imc, now); got != tc.want {
+				t.Errorf("isWaitingForImportsDrained() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestLeave tests the leave method.
+func TestLeave(t *testing.T) {
+	joinedAgentStatus := func(agentType clusterv1beta1.AgentType) clusterv1beta1.AgentStatus {
+		return clusterv1beta1.AgentStatus{
+			Type: agentType,
+			Conditions: []metav1.Condition{
+				{
+					Type:               string(clusterv1beta1.AgentJoined),
+					Status:             metav1.ConditionTrue,
+					Reason:             conditionReasonJoined,
+					ObservedGeneration: 1,
+				},
+			},
+		}
+	}
+	leftAgentStatus := func(agentType clusterv1beta1.AgentType, observedGeneration int64) clusterv1beta1.AgentStatus {
+		return clusterv1beta1.AgentStatus{
+			Type: agentType,
+			Conditions: []metav1.Condition{
+				{
+					Type:               string(clusterv1beta1.AgentJoined),
+					Status:             metav1.ConditionFalse,
+					Reason:             conditionReasonLeft,
+					ObservedGeneration: observedGeneration,
+				},
+				{
+					Type:               conditionTypeLeaving,
+					Status:             metav1.ConditionFalse,
+					Reason:             conditionReasonDrained,
+					ObservedGeneration: observedGeneration,
+				},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name                 string
+		agentType            clusterv1beta1.AgentType
+		agentStatus          []clusterv1beta1.AgentStatus
+		wantRequeue          bool
+		wantConditions       []metav1.Condition
+		wantSvcExportDeleted bool
+	}{
+		{
+			name:        "MCS agent drains the imports and leaves",
+			agentType:   mcsAgentType,
+			agentStatus: []clusterv1beta1.AgentStatus{joinedAgentStatus(mcsAgentType)},
+			wantConditions: []metav1.Condition{
+				{
+					Type:               string(clusterv1beta1.AgentJoined),
+					Status:             metav1.ConditionFalse,
+					Reason:             conditionReasonLeft,
+					ObservedGeneration: 2,
+				},
+				{
+					Type:               conditionTypeLeaving,
+					Status:             metav1.ConditionFalse,
+					Reason:             conditionReasonDrained,
+					ObservedGeneration: 2,
+				},
+			},
+		},
+		{
+			name:      "ServiceExportImport agent waits for the MCS agent",
+			agentType: serviceExportImportAgentType,
+			agentStatus: []clusterv1beta1.AgentStatus{
+				joinedAgentStatus(mcsAgentType),
+				joinedAgentStatus(serviceExportImportAgentType),
+			},
+			wantRequeue: true,
+			wantConditions: []metav1.Condition{
+				{
+					Type:               string(clusterv1beta1.AgentJoined),
+					Status:             metav1.ConditionTrue,
+					Reason:             conditionReasonJoined,
+					ObservedGeneration: 1,
+				},
+				{
+					Type:               conditionTypeLeaving,
+					Status:             metav1.ConditionTrue,
+					Reason:             conditionReasonWaitingForImportsDrained,
+					ObservedGeneration: 2,
+				},
+			},
+		},
+		{
+			name:        "ServiceExportImport agent drains the exports and leaves",
+			agentType:   serviceExportImportAgentType,
+			agentStatus: []clusterv1beta1.AgentStatus{joinedAgentStatus(serviceExportImportAgentType)},
+			wantConditions: []metav1.Condition{
+				{
+					Type:               string(clusterv1beta1.AgentJoined),
+					Status:             metav1.ConditionFalse,
+					Reason:             conditionReasonLeft,
+					ObservedGeneration: 2,
+				},
+				{
+					Type:               conditionTypeLeaving,
+					Status:             metav1.ConditionFalse,
+					Reason:             conditionReasonDrained,
+					ObservedGeneration: 2,
+				},
+			},
+			wantSvcExportDeleted: true,
+		},
+		{
+			name:      "ServiceExportImport agent has left",
+			agentType: serviceExportImportAgentType,
+			agentStatus: []clusterv1beta1.AgentStatus{
+				leftAgentStatus(serviceExportImportAgentType, 2),
+			},
+			wantConditions: leftAgentStatus(serviceExportImportAgentType, 2).Conditions,
+		},
+		{
+			name:      "ServiceExportImport agent drains the exports again when it left at an earlier generation",
+			agentType: serviceExportImportAgentType,
+			agentStatus: []clusterv1beta1.AgentStatus{
+				leftAgentStatus(serviceExportImportAgentType, 1),
+			},
+			wantConditions:       leftAgentStatus(serviceExportImportAgentType, 2).Conditions,
+			wantSvcExportDeleted: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			imc := &clusterv1beta1.InternalMemberCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       memberClusterName,
+					Namespace:  memberClusterNamespace,
+					Generation: 2,
+				},
+				Spec: clusterv1beta1.InternalMemberClusterSpec{
+					State: clusterv1beta1.ClusterStateLeave,
+				},
+				Status: clusterv1beta1.InternalMemberClusterStatus{
+					AgentStatus: tc.agentStatus,
+				},
+			}
+			svcExport := &fleetnetv1alpha1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      svcExportName1,
+					Namespace: workNamespaceName,
+				},
+			}
+			fakeHubClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(imc).
+				WithStatusSubresource(imc).
+				Build()
+			fakeMemberClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(svcExport).Build()
+			gate := membership.NewGate()
+			reconciler := &Reconciler{
+				MemberClient:   fakeMemberClient,
+				HubClient:      fakeHubClient,
+				AgentType:      tc.agentType,
+				MembershipGate: gate,
+			}
+
+			ctx := context.Background()
+			res, err := reconciler.leave(ctx, imc)
+			if err != nil {
+				t.Fatalf("leave() = %v, want no error", err)
+			}
+			if gotRequeue := res.RequeueAfter > 0; gotRequeue != tc.wantRequeue {
+				t.Errorf("leave() requeue = %t, want %t", gotRequeue, tc.wantRequeue)
+			}
+			if gate.IsOpen() {
+				t.Errorf("membership gate is open, want closed")
+			}
+
+			internalMemberCluster := &clusterv1beta1.InternalMemberCluster{}
+			if err := fakeHubClient.Get(ctx, types.NamespacedName{Namespace: memberClusterNamespace, Name: memberClusterName}, internalMemberCluster); err != nil {
+				t.Fatalf("Get() internalMemberCluster = %v, want no error", err)
+			}
+			agentStatus := findAgentStatus(internalMemberCluster.Status.AgentStatus, tc.agentType)
+			if agentStatus == nil {
+				t.Fatalf("agent status of %s is not found", tc.agentType)
+			}
+			if diff := cmp.Diff(agentStatus.Conditions, tc.wantConditions, ignoreConditionLTTAndMessageFields); diff != "" {
+				t.Errorf("agent conditions diff (-got, +want): %s", diff)
+			}
+
+			err = fakeMemberClient.Get(ctx, types.NamespacedName{Namespace: workNamespaceName, Name: svcExportName1}, &fleetnetv1alpha1.ServiceExport{})
+			if gotSvcExportDeleted := errors.IsNotFound(err); gotSvcExportDeleted != tc.wantSvcExportDeleted {
+				t.Errorf("serviceExport deleted = %t, want %t", gotSvcExportDeleted, tc.wantSvcExportDeleted)
+			}
+		})
+	}
+}
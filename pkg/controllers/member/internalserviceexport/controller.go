@@ -26,6 +26,7 @@ import (
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 	"go.goms.io/fleet-networking/pkg/common/metrics"
+	"go.goms.io/fleet-networking/pkg/common/tracing"
 )
 
 const (
@@ -101,6 +102,10 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return ctrl.Result{}, err
 	}
 
+	// Continue the trace of the export, which ends when the result is reported back to the ServiceExport.
+	ctx, span := tracing.StartSpanFromObject(ctx, &internalSvcExport, "ReportBackServiceExportStatus")
+	defer span.End()
+
 	// Check if the exported Service exists.
 	svcNS := internalSvcExport.Spec.ServiceReference.Namespace
 	svcName := internalSvcExport.Spec.ServiceReference.Name
@@ -129,6 +134,13 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return ctrl.Result{}, err
 	}
 
+	// Report back whether the export is allowed by the ServiceExportImportPolicies in the hub cluster.
+	klog.V(4).InfoS("Report back export authorization result", "internalServiceExport", internalSvcExportRef)
+	if err := r.reportBackAuthorizedCondition(ctx, &svcExport, &internalSvcExport); err != nil {
+		klog.ErrorS(err, "Failed to report back export authorization result", "serviceExport", svcExportRef)
+		return ctrl.Result{}, err
+	}
+
 	// Report back conflict resolution result.
 	klog.V(4).InfoS("Report back conflict resolution result", "internalServiceExport", internalSvcExportRef)
 	reported, err := r.reportBackConflictCondition(ctx, &svcExport, &internalSvcExport)
@@ -152,7 +164,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 // SetupWithManager builds a controller with InternalSvcExportReconciler and sets it up with a
 // (multi-namespaced) controller manager.
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).For(&fleetnetv1alpha1.InternalServiceExport{}).Complete(r)
+	return ctrl.NewControllerManagedBy(mgr).For(&fleetnetv1alpha1.InternalServiceExport{}).Complete(metrics.InstrumentReconciler("internalserviceexport", r))
 }
 
 // reportBackConflictCond reports the ServiceExportConflict condition added to the InternalServiceExport object in the
@@ -191,6 +203,30 @@ func (r *Reconciler) reportBackConflictCondition(ctx context.Context,
 	return true, r.MemberClient.Status().Update(ctx, svcExport)
 }
 
+// reportBackAuthorizedCondition reports the ServiceExportAuthorized condition added to the InternalServiceExport
+// object in the hub cluster back to the ServiceExport object in the member cluster.
+func (r *Reconciler) reportBackAuthorizedCondition(ctx context.Context,
+	svcExport *fleetnetv1alpha1.ServiceExport,
+	internalSvcExport *fleetnetv1alpha1.InternalServiceExport) error {
+	internalSvcExportAuthorizedCond := meta.FindStatusCondition(internalSvcExport.Status.Conditions,
+		string(fleetnetv1alpha1.ServiceExportAuthorized))
+	if internalSvcExportAuthorizedCond == nil {
+		// No authorization result to report back yet.
+		return nil
+	}
+
+	svcExportAuthorizedCond := meta.FindStatusCondition(svcExport.Status.Conditions, string(fleetnetv1alpha1.ServiceExportAuthorized))
+	if reflect.DeepEqual(internalSvcExportAuthorizedCond, svcExportAuthorizedCond) {
+		return nil
+	}
+
+	if internalSvcExportAuthorizedCond.Status == metav1.ConditionFalse {
+		r.Recorder.Eventf(svcExport, corev1.EventTypeWarning, "ServiceExportNotAuthorized", "Service %s is not allowed to be exported: %s", svcExport.Name, internalSvcExportAuthorizedCond.Message)
+	}
+	meta.SetStatusCondition(&svcExport.Status.Conditions, *internalSvcExportAuthorizedCond)
+	return r.MemberClient.Status().Update(ctx, svcExport)
+}
+
 // Observe data points for metrics.
 func (r *Reconciler) observeMetrics(ctx context.Context,
 	internalSvcExport *fleetnetv1alpha1.InternalServiceExport,
@@ -16,10 +16,12 @@ import (
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/cloud-provider-azure/pkg/azclient/publicipaddressclient"
@@ -27,13 +29,16 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"go.goms.io/fleet/pkg/utils/controller"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 	"go.goms.io/fleet-networking/pkg/common/condition"
+	"go.goms.io/fleet-networking/pkg/common/membership"
 	"go.goms.io/fleet-networking/pkg/common/metrics"
 	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/tracing"
 )
 
 const (
@@ -63,6 +68,15 @@ type Reconciler struct {
 	AzurePublicIPAddressClient publicipaddressclient.Interface
 
 	EnableTrafficManagerFeature bool
+
+	// ExportedLabelKeys and ExportedAnnotationKeys are the allow-lists of the label and annotation keys of a Service
+	// which are exported with the Service; an entry ending with "*" matches all the keys with the same prefix.
+	ExportedLabelKeys      []string
+	ExportedAnnotationKeys []string
+
+	// MembershipGate (if set) stops the controller from exporting Services while the member cluster is leaving the
+	// fleet; Services being unexported are still processed.
+	MembershipGate *membership.Gate
 }
 
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceexports,verbs=get;list;watch;create;update;patch;delete
@@ -82,6 +96,14 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		klog.V(2).InfoS("Reconciliation ends", "service", svcRef, "latency", latency)
 	}()
 
+	// Start a trace for the export; the trace is continued by the controllers which process the exported Service,
+	// if the export changes.
+	ctx, span := tracing.StartSpan(ctx, "ExportService",
+		tracing.AttributeKeyNamespace.String(req.Namespace),
+		tracing.AttributeKeyName.String(req.Name),
+		tracing.AttributeKeyClusterID.String(r.MemberClusterID))
+	defer span.End()
+
 	// Retrieve the ServiceExport object.
 	var svcExport fleetnetv1alpha1.ServiceExport
 	if err := r.MemberClient.Get(ctx, req.NamespacedName, &svcExport); err != nil {
@@ -114,6 +136,13 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return ctrl.Result{}, nil
 	}
 
+	// Skip exporting the Service if the member cluster is leaving the fleet; the ServiceExport will be processed
+	// again when the member cluster re-joins the fleet.
+	if r.MembershipGate != nil && !r.MembershipGate.IsOpen() {
+		klog.V(2).InfoS("Member cluster is leaving the fleet; skip exporting the service", "service", svcRef)
+		return ctrl.Result{}, nil
+	}
+
 	// Check if the Service to export exists.
 	svc := corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
@@ -207,7 +236,9 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	klog.V(2).InfoS("Export the service or update the exported service",
 		"service", svcExport,
 		"internalServiceExport", klog.KObj(&internalSvcExport))
-	createOrUpdateOp, err := controllerutil.CreateOrUpdate(ctx, r.HubClient, &internalSvcExport, func() error {
+	exportCtx, exportSpan := tracing.StartSpan(ctx, "CreateOrUpdateInternalServiceExport")
+	createOrUpdateOp, err := controllerutil.CreateOrUpdate(exportCtx, r.HubClient, &internalSvcExport, func() error {
+		oldSpec := internalSvcExport.Spec.DeepCopy()
 		if internalSvcExport.CreationTimestamp.IsZero() {
 			// Set the ServiceReference only when the InternalServiceExport is created; most of the fields in
 			// an ExportedObjectReference should be immutable.
@@ -233,6 +264,10 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		}
 
 		internalSvcExport.Spec.Ports = svcExportPorts
+		internalSvcExport.Spec.SessionAffinity = svc.Spec.SessionAffinity
+		internalSvcExport.Spec.SessionAffinityConfig = svc.Spec.SessionAffinityConfig.DeepCopy()
+		internalSvcExport.Spec.ExportedLabels = filterAllowedMetadata(svc.Labels, r.ExportedLabelKeys)
+		internalSvcExport.Spec.ExportedAnnotations = filterAllowedMetadata(svc.Annotations, r.ExportedAnnotationKeys)
 		internalSvcExport.Spec.ServiceReference.UpdateFromMetaObject(svc.ObjectMeta, metav1.NewTime(exportedSince))
 
 		if r.EnableTrafficManagerFeature {
@@ -242,8 +277,13 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 				return err
 			}
 		}
+		// Stamp the trace context only when the export changes, so that an unchanged export is not updated.
+		if !equality.Semantic.DeepEqual(oldSpec, &internalSvcExport.Spec) {
+			tracing.Inject(exportCtx, &internalSvcExport)
+		}
 		return nil
 	})
+	tracing.EndSpan(exportSpan, err)
 	statusErr := &apierrors.StatusError{}
 	ok := errors.As(err, &statusErr)
 	switch {
@@ -343,12 +383,32 @@ func (r *Reconciler) lookupPublicIPResourceIDByLoadBalancerIP(ctx context.Contex
 
 // SetupWithManager builds a controller with Reconciler and sets it up with a controller manager.
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		// The ServiceExport controller watches over ServiceExport objects.
 		For(&fleetnetv1alpha1.ServiceExport{}).
 		// The ServiceExport controller watches over Service objects.
-		Watches(&corev1.Service{}, &handler.EnqueueRequestForObject{}).
-		Complete(r)
+		Watches(&corev1.Service{}, &handler.EnqueueRequestForObject{})
+	if r.MembershipGate != nil {
+		// Re-process all the ServiceExports when the member cluster re-joins the fleet.
+		b = b.WatchesRawSource(r.MembershipGate.Source(handler.EnqueueRequestsFromMapFunc(r.allServiceExportRequests)))
+	}
+	return b.Complete(metrics.InstrumentReconciler("serviceexport", r))
+}
+
+// allServiceExportRequests returns the reconcile requests for all the ServiceExports in the member cluster.
+func (r *Reconciler) allServiceExportRequests(ctx context.Context, _ client.Object) []reconcile.Request {
+	svcExportList := &fleetnetv1alpha1.ServiceExportList{}
+	if err := r.MemberClient.List(ctx, svcExportList); err != nil {
+		klog.ErrorS(err, "Failed to list service exports")
+		return []reconcile.Request{}
+	}
+	reqs := make([]reconcile.Request, 0, len(svcExportList.Items))
+	for i := range svcExportList.Items {
+		reqs = append(reqs, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: svcExportList.Items[i].Namespace, Name: svcExportList.Items[i].Name},
+		})
+	}
+	return reqs
 }
 
 // unexportService unexports a Service, specifically, it deletes the corresponding InternalServiceExport from the
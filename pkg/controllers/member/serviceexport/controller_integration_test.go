@@ -298,7 +298,8 @@ var (
 				svc.ObjectMeta,
 				metav1.NewTime(lastSeenTimestamp),
 			),
-			Type: serviceType,
+			Type:            serviceType,
+			SessionAffinity: corev1.ServiceAffinityNone,
 		}
 		if isPublicAzureLoadBalancer {
 			expectedInternalSvcExportSpec.IsDNSLabelConfigured = true
@@ -469,7 +470,8 @@ var _ = Describe("serviceexport controller", func() {
 						svc.ObjectMeta,
 						metav1.Now(),
 					),
-					Type: svc.Spec.Type,
+					Type:            svc.Spec.Type,
+					SessionAffinity: corev1.ServiceAffinityNone,
 				}
 				if diff := cmp.Diff(internalSvcExport.Spec, expectedInternalSvcExportSpec, ignoredRefFields); diff != "" {
 					return fmt.Errorf("internalServiceExport spec (-got, +want): %s", diff)
@@ -249,6 +249,67 @@ func TestExtractServicePorts(t *testing.T) {
 	}
 }
 
+// TestFilterAllowedMetadata tests the filterAllowedMetadata function.
+func TestFilterAllowedMetadata(t *testing.T) {
+	metadata := map[string]string{
+		"app":                              "web",
+		"team":                             "payments",
+		"service.beta.kubernetes.io/a":     "1",
+		"service.beta.kubernetes.io/b":     "2",
+		"service.kubernetes.io/topology-x": "3",
+		// The keys reserved for the fleet networking controllers are never exported.
+		"networking.fleet.azure.com/exported-label-keys": "app",
+		"lb.networking.fleet.azure.com/x":                "4",
+	}
+	testCases := []struct {
+		name      string
+		allowList []string
+		want      map[string]string
+	}{
+		{
+			name: "no allow-list",
+		},
+		{
+			name:      "exact keys",
+			allowList: []string{"app", "missing"},
+			want:      map[string]string{"app": "web"},
+		},
+		{
+			name:      "prefix keys",
+			allowList: []string{"service.beta.kubernetes.io/*", "team"},
+			want: map[string]string{
+				"team":                         "payments",
+				"service.beta.kubernetes.io/a": "1",
+				"service.beta.kubernetes.io/b": "2",
+			},
+		},
+		{
+			name:      "no matching keys",
+			allowList: []string{"foo/*"},
+		},
+		{
+			name:      "reserved keys",
+			allowList: []string{"*"},
+			want: map[string]string{
+				"app":                              "web",
+				"team":                             "payments",
+				"service.beta.kubernetes.io/a":     "1",
+				"service.beta.kubernetes.io/b":     "2",
+				"service.kubernetes.io/topology-x": "3",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := filterAllowedMetadata(metadata, tc.allowList)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("filterAllowedMetadata() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 // TestMarkServiceExportAsInvalidNotFound tests the *Reconciler.markServiceExportAsInvalidNotFound method.
 func TestMarkServiceExportAsInvalidNotFound(t *testing.T) {
 	testCases := []struct {
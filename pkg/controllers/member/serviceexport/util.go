@@ -7,10 +7,12 @@ package serviceexport
 
 import (
 	"fmt"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
 )
 
 // formatInternalServiceExportName returns the unique name assigned to an exported Service.
@@ -42,3 +44,29 @@ func extractServicePorts(svc *corev1.Service) []fleetnetv1alpha1.ServicePort {
 
 	return svcExportPorts
 }
+
+// filterAllowedMetadata returns the labels or annotations whose keys are in the allow-list; an allow-list entry
+// ending with "*" matches all the keys with the same prefix. The keys reserved for the fleet networking controllers
+// are never exported.
+func filterAllowedMetadata(metadata map[string]string, allowList []string) map[string]string {
+	if len(metadata) == 0 || len(allowList) == 0 {
+		return nil
+	}
+	filtered := map[string]string{}
+	for key, val := range metadata {
+		if objectmeta.IsReservedKey(key) {
+			continue
+		}
+		for _, allowed := range allowList {
+			prefix, isPrefix := strings.CutSuffix(allowed, "*")
+			if (isPrefix && strings.HasPrefix(key, prefix)) || key == allowed {
+				filtered[key] = val
+				break
+			}
+		}
+	}
+	if len(filtered) == 0 {
+		return nil
+	}
+	return filtered
+}
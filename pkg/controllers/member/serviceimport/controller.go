@@ -14,13 +14,16 @@ import (
 
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
 )
 
 const (
@@ -41,6 +44,7 @@ type Reconciler struct {
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports,verbs=get;list;watch;update;patch
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports/finalizers,verbs=get;update
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalserviceimports,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=multiclusterservices,verbs=get;list;watch
 
 // Reconcile in member cluster creates hub cluster internal service import out of member cluster service import.
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -104,6 +108,12 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		}
 	}
 
+	clusterSelector, err := r.clusterSelectorFromOwner(ctx, serviceImport)
+	if err != nil {
+		klog.ErrorS(err, "Failed to get the cluster selector of the serviceimport owner", "ServiceImport", serviceImportRef)
+		return ctrl.Result{}, err
+	}
+
 	klog.V(2).InfoS("Create or update internal service import", "InternalServiceImport", internalServiceImportRef)
 	if op, err := controllerutil.CreateOrUpdate(ctx, r.HubClient, internalServiceImport, func() error {
 		if internalServiceImport.CreationTimestamp.IsZero() {
@@ -115,6 +125,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		// TO-DO: InternalServiceImport object is not an exported object and the ServiceImportReference (an
 		// exportedObject field) will be removed; information updated here is not used.
 		internalServiceImport.Spec.ServiceImportReference.UpdateFromMetaObject(serviceImport.ObjectMeta, serviceImport.CreationTimestamp)
+		internalServiceImport.Spec.ClusterSelector = clusterSelector
 		return nil
 	}); err != nil {
 		klog.ErrorS(err, "Failed to create or update InternalServiceImport from ServiceImport", "InternalServiceImport", internalServiceImportRef, "ServiceImport", serviceImportRef, "op", op)
@@ -124,10 +135,38 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	return ctrl.Result{}, nil
 }
 
+// clusterSelectorFromOwner returns the cluster selector of the MultiClusterService which owns the service import,
+// if any.
+func (r *Reconciler) clusterSelectorFromOwner(ctx context.Context, serviceImport *fleetnetv1alpha1.ServiceImport) (*fleetnetv1alpha1.ClusterSelector, error) {
+	owner := metav1.GetControllerOf(serviceImport)
+	if owner == nil || owner.Kind != "MultiClusterService" || owner.APIVersion != fleetnetv1alpha1.GroupVersion.String() {
+		return nil, nil
+	}
+	mcs := &fleetnetv1alpha1.MultiClusterService{}
+	if err := r.MemberClient.Get(ctx, types.NamespacedName{Namespace: serviceImport.Namespace, Name: owner.Name}, mcs); err != nil {
+		if errors.IsNotFound(err) {
+			// The service import will be garbage collected with its owner.
+			return nil, nil
+		}
+		return nil, err
+	}
+	return mcs.Spec.ClusterSelector.DeepCopy(), nil
+}
+
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// The cluster selector of a multi-cluster service is synced to the internal service import of the service
+	// import it owns.
+	enqueueServiceImport := handler.EnqueueRequestsFromMapFunc(func(_ context.Context, o client.Object) []reconcile.Request {
+		mcs, ok := o.(*fleetnetv1alpha1.MultiClusterService)
+		if !ok || mcs.Spec.ServiceImport.Name == "" {
+			return []reconcile.Request{}
+		}
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: mcs.Namespace, Name: mcs.Spec.ServiceImport.Name}}}
+	})
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&fleetnetv1alpha1.ServiceImport{}).
-		Complete(r)
+		Watches(&fleetnetv1alpha1.MultiClusterService{}, enqueueServiceImport).
+		Complete(metrics.InstrumentReconciler("serviceimport", r))
 }
 
 // formatInternalServiceImportName returns the unique name assigned to an service import
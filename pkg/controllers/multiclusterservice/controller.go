@@ -9,17 +9,20 @@ package multiclusterservice
 
 import (
 	"context"
-	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -30,7 +33,10 @@ import (
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 	"go.goms.io/fleet-networking/pkg/common/condition"
+	"go.goms.io/fleet-networking/pkg/common/membership"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
 	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/uniquename"
 )
 
 const (
@@ -38,10 +44,6 @@ const (
 	multiClusterServiceFinalizer          = "networking.fleet.azure.com/service-resources-cleanup"
 	multiClusterServiceLabelServiceImport = "networking.fleet.azure.com/service-import"
 
-	// service label
-	serviceLabelMCSName      = "networking.fleet.azure.com/multi-cluster-service-name"
-	serviceLabelMCSNamespace = "networking.fleet.azure.com/multi-cluster-service-namespace"
-
 	conditionReasonUnknownServiceImport = "UnknownServiceImport"
 	conditionReasonFoundServiceImport   = "FoundServiceImport"
 
@@ -52,9 +54,24 @@ const (
 
 	// multiClusterService annotation
 	multiClusterServiceAnnotationInternalLoadBalancer = "networking.fleet.azure.com/azure-load-balancer-internal"
+	// multiClusterServiceAnnotationPreviousDerivedService records the derived service which the mcs has migrated
+	// from, so that it can be removed once no imported endpointSlices are associated with it.
+	multiClusterServiceAnnotationPreviousDerivedService = "networking.fleet.azure.com/previous-derived-service"
 
 	// service annotation
 	serviceAnnotationInternalLoadBalancer = "service.beta.kubernetes.io/azure-load-balancer-internal"
+	// serviceAnnotationPublicIPName, serviceAnnotationLoadBalancerIPv4 and serviceAnnotationLoadBalancerIPv6 pin the
+	// IP address of the load balancer of a service, which can be held by one service at a time.
+	serviceAnnotationPublicIPName     = "service.beta.kubernetes.io/azure-pip-name"
+	serviceAnnotationLoadBalancerIPv4 = "service.beta.kubernetes.io/azure-load-balancer-ipv4"
+	serviceAnnotationLoadBalancerIPv6 = "service.beta.kubernetes.io/azure-load-balancer-ipv6"
+	// serviceAnnotationExportedLabelKeys and serviceAnnotationExportedAnnotationKeys keep track of the exported
+	// labels and annotations applied to the derived service, so that they can be removed once no longer exported.
+	serviceAnnotationExportedLabelKeys      = "networking.fleet.azure.com/exported-label-keys"
+	serviceAnnotationExportedAnnotationKeys = "networking.fleet.azure.com/exported-annotation-keys"
+	// serviceAnnotationConfiguredAnnotationKeys keeps track of the annotations configured in the mcs and applied to
+	// the derived service, so that they can be removed once no longer configured.
+	serviceAnnotationConfiguredAnnotationKeys = "networking.fleet.azure.com/configured-annotation-keys"
 )
 
 // Reconciler reconciles a MultiClusterService object.
@@ -63,6 +80,9 @@ type Reconciler struct {
 	Scheme               *runtime.Scheme
 	FleetSystemNamespace string // reserved fleet namespace
 	Recorder             record.EventRecorder
+	// MembershipGate (if set) stops the controller from importing Services while the member cluster is leaving the
+	// fleet; MCSes being deleted are still processed.
+	MembershipGate *membership.Gate
 }
 
 //+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
@@ -71,6 +91,7 @@ type Reconciler struct {
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=multiclusterservices/finalizers,verbs=get;update
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch
 
 // Reconcile triggers a single reconcile round.
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -98,6 +119,13 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return r.handleDelete(ctx, &mcs)
 	}
 
+	// Skip importing the Service if the member cluster is leaving the fleet; the MCS will be processed again when
+	// the member cluster re-joins the fleet.
+	if r.MembershipGate != nil && !r.MembershipGate.IsOpen() {
+		klog.V(2).InfoS("Member cluster is leaving the fleet; skip importing the service", "multiClusterService", mcsKRef)
+		return ctrl.Result{}, nil
+	}
+
 	// register finalizer
 	if !controllerutil.ContainsFinalizer(&mcs, multiClusterServiceFinalizer) {
 		controllerutil.AddFinalizer(&mcs, multiClusterServiceFinalizer)
@@ -128,6 +156,11 @@ func (r *Reconciler) handleDelete(ctx context.Context, mcs *fleetnetv1alpha1.Mul
 			return ctrl.Result{}, err
 		}
 	}
+	// delete the derived service which the mcs is migrating from, if any
+	if err := r.deletePreviousDerivedService(ctx, mcs); err != nil {
+		klog.ErrorS(err, "Failed to remove previous derived service of mcs", "multiClusterService", mcsKObj)
+		return ctrl.Result{}, err
+	}
 	// delete service import in the same namespace as the multi-cluster service
 	serviceImportName := r.serviceImportFromLabel(mcs)
 	if err := r.deleteServiceImport(ctx, serviceImportName); err != nil {
@@ -180,6 +213,14 @@ func (r *Reconciler) derivedServiceFromLabel(mcs *fleetnetv1alpha1.MultiClusterS
 	return nil
 }
 
+// mcs-controller will record the derived service which the mcs is migrating from as the annotation.
+func (r *Reconciler) previousDerivedServiceFromAnnotation(mcs *fleetnetv1alpha1.MultiClusterService) *types.NamespacedName {
+	if val, ok := mcs.GetAnnotations()[multiClusterServiceAnnotationPreviousDerivedService]; ok {
+		return &types.NamespacedName{Namespace: r.FleetSystemNamespace, Name: val}
+	}
+	return nil
+}
+
 // mcs-controller will record service import name as the label when it successfully creates the service import.
 func (r *Reconciler) serviceImportFromLabel(mcs *fleetnetv1alpha1.MultiClusterService) *types.NamespacedName {
 	if val, ok := mcs.GetLabels()[multiClusterServiceLabelServiceImport]; ok {
@@ -245,10 +286,9 @@ func (r *Reconciler) handleUpdate(ctx context.Context, mcs *fleetnetv1alpha1.Mul
 	}
 	r.Recorder.Eventf(mcs, corev1.EventTypeNormal, "FoundValidService", "Found valid service %s and importing", serviceImport.Name)
 
-	serviceName := r.derivedServiceFromLabel(mcs)
-	if serviceName == nil {
-		serviceName = r.generateDerivedServiceName(mcs)
-		klog.V(4).InfoS("Generated derived service name", "multiClusterService", mcsKObj, "service", serviceName)
+	serviceName, isMigrating, err := r.resolveDerivedServiceName(ctx, mcs, serviceImport)
+	if err != nil {
+		return ctrl.Result{}, err
 	}
 	// update mcs service label first to prevent the controller abort before we create the resource
 	if err := r.updateMultiClusterLabel(ctx, mcs, objectmeta.MultiClusterServiceLabelDerivedService, serviceName.Name); err != nil {
@@ -265,9 +305,23 @@ func (r *Reconciler) handleUpdate(ctx context.Context, mcs *fleetnetv1alpha1.Mul
 	// 1) Create a service if not exists.
 	// OR 2) Update a service if the desired state does not match with current state.
 	// OR 3) Get a service when Service status change triggers the MCS reconcile.
-	if op, err := controllerutil.CreateOrUpdate(ctx, r.Client, service, func() error {
-		return r.ensureDerivedService(mcs, serviceImport, service)
-	}); err != nil {
+	var clusterSetIP string
+	if len(serviceImport.Status.IPs) == 1 {
+		clusterSetIP = serviceImport.Status.IPs[0]
+	}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, service, func() error {
+		return r.ensureDerivedService(mcs, serviceImport, service, clusterSetIP)
+	})
+	if err != nil && errors.IsInvalid(err) && clusterSetIP != "" && service.CreationTimestamp.IsZero() {
+		// The ClusterSetIP is not in the service CIDR of the cluster or is used by another service; create the
+		// derived service with a cluster IP allocated by the cluster instead, so that the service is still imported.
+		klog.ErrorS(err, "Failed to create derived service with the ClusterSetIP", "multiClusterService", mcsKObj, "service", klog.KObj(service), "clusterSetIP", clusterSetIP)
+		r.Recorder.Eventf(mcs, corev1.EventTypeWarning, "ClusterSetIPNotProgrammed", "Failed to program ClusterSetIP %s on the derived service: %v", clusterSetIP, err)
+		op, err = controllerutil.CreateOrUpdate(ctx, r.Client, service, func() error {
+			return r.ensureDerivedService(mcs, serviceImport, service, "")
+		})
+	}
+	if err != nil {
 		klog.ErrorS(err, "Failed to create or update derived service of mcs", "multiClusterService", mcsKObj, "service", klog.KObj(service), "op", op)
 		return ctrl.Result{}, err
 	}
@@ -275,9 +329,209 @@ func (r *Reconciler) handleUpdate(ctx context.Context, mcs *fleetnetv1alpha1.Mul
 		return ctrl.Result{}, err
 	}
 	r.Recorder.Eventf(mcs, corev1.EventTypeNormal, "SuccessfulUpdateStatus", "Imported %s service and updated %s status", serviceImport.Name, mcs.Name)
+
+	isRemoved, err := r.removePreviousDerivedService(ctx, mcs)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if isMigrating || !isRemoved {
+		// have to requeue the request to complete the migration of the derived service
+		klog.V(3).InfoS("Derived service of mcs is being migrated and requeue the request", "multiClusterService", mcsKObj)
+		return ctrl.Result{RequeueAfter: mcsRetryInterval}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
+// resolveDerivedServiceName returns the name of the derived service which serves the traffic of the mcs, and whether
+// the mcs is being migrated to a new derived service.
+//
+// The derived services created by earlier versions are named as [NAMESPACE]-[NAME]; such a name is kept as long as it
+// is a valid service name which no other mcs uses. Otherwise, the mcs is migrated to the generated name without
+// dropping traffic: the derived service with the generated name is created first, and the mcs is switched to it once
+// it is ready. The previous derived service is recorded in the mcs annotations, and it keeps serving the imported
+// endpointSlices which have not been associated with the new derived service yet.
+//
+// A derived service which pins the IP address of its load balancer cannot become ready while the previous derived
+// service holds the address; the mcs is then switched right away, and the address is handed off once the previous
+// derived service is removed.
+func (r *Reconciler) resolveDerivedServiceName(ctx context.Context, mcs *fleetnetv1alpha1.MultiClusterService, serviceImport *fleetnetv1alpha1.ServiceImport) (*types.NamespacedName, bool, error) {
+	mcsKObj := klog.KObj(mcs)
+	desiredServiceName, err := r.generateDerivedServiceName(mcs)
+	if err != nil {
+		klog.ErrorS(err, "Failed to generate derived service name", "multiClusterService", mcsKObj)
+		return nil, false, err
+	}
+	serviceName := r.derivedServiceFromLabel(mcs)
+	if serviceName == nil || serviceName.Name == desiredServiceName.Name {
+		return desiredServiceName, false, nil
+	}
+	if r.previousDerivedServiceFromAnnotation(mcs) != nil {
+		// The previous migration has not completed yet.
+		klog.V(2).InfoS("Waiting for the previous derived service to be removed", "multiClusterService", mcsKObj)
+		return serviceName, true, nil
+	}
+	isKept, err := r.isLegacyDerivedServiceNameKept(ctx, mcs, serviceName)
+	if err != nil {
+		return nil, false, err
+	}
+	if isKept {
+		return serviceName, false, nil
+	}
+
+	klog.V(2).InfoS("Migrating derived service of mcs", "multiClusterService", mcsKObj, "from", serviceName, "to", desiredServiceName)
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: desiredServiceName.Namespace,
+			Name:      desiredServiceName.Name,
+		},
+	}
+	// The ClusterSetIP, if any, is still held by the previous derived service.
+	if op, err := controllerutil.CreateOrUpdate(ctx, r.Client, service, func() error {
+		return r.ensureDerivedService(mcs, serviceImport, service, "")
+	}); err != nil {
+		klog.ErrorS(err, "Failed to create or update new derived service of mcs", "multiClusterService", mcsKObj, "service", klog.KObj(service), "op", op)
+		return nil, false, err
+	}
+	if !isDerivedServiceReady(service) {
+		if !isLoadBalancerIPPinned(service) {
+			klog.V(2).InfoS("Waiting for the new derived service to be ready", "multiClusterService", mcsKObj, "service", klog.KObj(service))
+			return serviceName, true, nil
+		}
+		klog.V(2).InfoS("New derived service pins the load balancer IP held by the previous one; handing it off", "multiClusterService", mcsKObj, "service", klog.KObj(service))
+		r.Recorder.Eventf(mcs, corev1.EventTypeWarning, "HandingOffLoadBalancerIP",
+			"Derived service %s pins the load balancer IP held by %s; the IP is handed off once %s is removed, and the load balancer is unavailable in the meantime",
+			desiredServiceName.Name, serviceName.Name, serviceName.Name)
+	}
+	// The annotation is persisted along with the derived service label.
+	if mcs.GetAnnotations() == nil {
+		mcs.Annotations = map[string]string{}
+	}
+	mcs.Annotations[multiClusterServiceAnnotationPreviousDerivedService] = serviceName.Name
+	r.Recorder.Eventf(mcs, corev1.EventTypeNormal, "MigratedDerivedService", "Migrated derived service from %s to %s", serviceName.Name, desiredServiceName.Name)
+	return desiredServiceName, false, nil
+}
+
+// isLegacyDerivedServiceNameKept returns if the derived service name of the mcs, which is not the generated one, is
+// kept, i.e. it is a valid service name and no other mcs uses it.
+func (r *Reconciler) isLegacyDerivedServiceNameKept(ctx context.Context, mcs *fleetnetv1alpha1.MultiClusterService, serviceName *types.NamespacedName) (bool, error) {
+	mcsKObj := klog.KObj(mcs)
+	if errs := validation.IsDNS1035Label(serviceName.Name); len(errs) != 0 {
+		klog.V(2).InfoS("Derived service name of mcs is invalid", "multiClusterService", mcsKObj, "service", serviceName, "errors", errs)
+		return false, nil
+	}
+	service := &corev1.Service{}
+	err := r.Client.Get(ctx, *serviceName, service)
+	switch {
+	case err != nil && !errors.IsNotFound(err):
+		klog.ErrorS(err, "Failed to get derived service of mcs", "multiClusterService", mcsKObj, "service", serviceName)
+		return false, err
+	case err == nil && !isDerivedServiceOwnedBy(service, mcs):
+		klog.V(2).InfoS("Derived service name of mcs is used by another mcs", "multiClusterService", mcsKObj, "service", serviceName)
+		return false, nil
+	}
+	mcsList := &fleetnetv1alpha1.MultiClusterServiceList{}
+	if err := r.Client.List(ctx, mcsList, client.MatchingLabels{objectmeta.MultiClusterServiceLabelDerivedService: serviceName.Name}); err != nil {
+		klog.ErrorS(err, "Failed to list mcs with the same derived service", "multiClusterService", mcsKObj, "service", serviceName)
+		return false, err
+	}
+	for i := range mcsList.Items {
+		if mcsList.Items[i].Namespace != mcs.Namespace || mcsList.Items[i].Name != mcs.Name {
+			klog.V(2).InfoS("Derived service name of mcs is used by another mcs", "multiClusterService", mcsKObj, "service", serviceName, "other", klog.KObj(&mcsList.Items[i]))
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// isDerivedServiceOwnedBy returns if the derived service has been created for the mcs.
+func isDerivedServiceOwnedBy(service *corev1.Service, mcs *fleetnetv1alpha1.MultiClusterService) bool {
+	return service.Labels[objectmeta.ServiceLabelMultiClusterServiceName] == mcs.Name &&
+		service.Labels[objectmeta.ServiceLabelMultiClusterServiceNamespace] == mcs.Namespace
+}
+
+// isLoadBalancerIPPinned returns if the service pins the IP address of its load balancer, which cannot be allocated
+// to the service while another service holds it.
+func isLoadBalancerIPPinned(service *corev1.Service) bool {
+	if service.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		return false
+	}
+	for _, key := range []string{serviceAnnotationPublicIPName, serviceAnnotationLoadBalancerIPv4, serviceAnnotationLoadBalancerIPv6} {
+		if service.Annotations[key] != "" {
+			return true
+		}
+	}
+	return service.Spec.LoadBalancerIP != "" //nolint:staticcheck // the field is deprecated but still honored
+}
+
+// isDerivedServiceReady returns if the derived service is ready to serve the traffic.
+func isDerivedServiceReady(service *corev1.Service) bool {
+	if service.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		return true
+	}
+	return len(service.Status.LoadBalancer.Ingress) > 0
+}
+
+// removePreviousDerivedService removes the derived service which the mcs has migrated from once no imported
+// endpointSlices are associated with it, and returns whether it has been removed. A previous derived service which
+// is used by another mcs is left as it is.
+func (r *Reconciler) removePreviousDerivedService(ctx context.Context, mcs *fleetnetv1alpha1.MultiClusterService) (bool, error) {
+	serviceName := r.previousDerivedServiceFromAnnotation(mcs)
+	if serviceName == nil {
+		return true, nil
+	}
+	mcsKObj := klog.KObj(mcs)
+	svcKRef := klog.KRef(serviceName.Namespace, serviceName.Name)
+	service := &corev1.Service{}
+	err := r.Client.Get(ctx, *serviceName, service)
+	switch {
+	case errors.IsNotFound(err):
+		klog.V(2).InfoS("Previous derived service has been removed", "multiClusterService", mcsKObj, "service", svcKRef)
+	case err != nil:
+		klog.ErrorS(err, "Failed to get previous derived service of mcs", "multiClusterService", mcsKObj, "service", svcKRef)
+		return false, err
+	case !isDerivedServiceOwnedBy(service, mcs):
+		klog.V(2).InfoS("Previous derived service is used by another mcs and kept", "multiClusterService", mcsKObj, "service", svcKRef)
+	default:
+		endpointSliceList := &discoveryv1.EndpointSliceList{}
+		if err := r.Client.List(ctx, endpointSliceList, client.InNamespace(serviceName.Namespace), client.MatchingLabels{discoveryv1.LabelServiceName: serviceName.Name}); err != nil {
+			klog.ErrorS(err, "Failed to list endpointSlices of previous derived service", "multiClusterService", mcsKObj, "service", svcKRef)
+			return false, err
+		}
+		if len(endpointSliceList.Items) > 0 {
+			klog.V(2).InfoS("Previous derived service is still in use", "multiClusterService", mcsKObj, "service", svcKRef, "endpointSlices", len(endpointSliceList.Items))
+			return false, nil
+		}
+		if err := r.Client.Delete(ctx, service); err != nil && !errors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to remove previous derived service of mcs", "multiClusterService", mcsKObj, "service", svcKRef)
+			return false, err
+		}
+	}
+	delete(mcs.Annotations, multiClusterServiceAnnotationPreviousDerivedService)
+	if err := r.Client.Update(ctx, mcs); err != nil {
+		klog.ErrorS(err, "Failed to remove the previous derived service annotation of mcs", "multiClusterService", mcsKObj)
+		return false, err
+	}
+	return true, nil
+}
+
+// deletePreviousDerivedService deletes the derived service which the mcs is migrating from, if any, unless it is used
+// by another mcs.
+func (r *Reconciler) deletePreviousDerivedService(ctx context.Context, mcs *fleetnetv1alpha1.MultiClusterService) error {
+	serviceName := r.previousDerivedServiceFromAnnotation(mcs)
+	if serviceName == nil {
+		return nil
+	}
+	service := &corev1.Service{}
+	if err := r.Client.Get(ctx, *serviceName, service); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !isDerivedServiceOwnedBy(service, mcs) {
+		klog.V(2).InfoS("Previous derived service is used by another mcs and kept", "multiClusterService", klog.KObj(mcs), "service", klog.KObj(service))
+		return nil
+	}
+	return client.IgnoreNotFound(r.Client.Delete(ctx, service))
+}
+
 func isServiceImportOwnedByOthers(mcs *fleetnetv1alpha1.MultiClusterService, serviceImport *fleetnetv1alpha1.ServiceImport) bool {
 	for _, owner := range serviceImport.OwnerReferences {
 		if owner.APIVersion == mcs.APIVersion &&
@@ -303,18 +557,25 @@ func (r *Reconciler) handleInvalidServiceImport(ctx context.Context, mcs *fleetn
 	r.Recorder.Eventf(mcs, corev1.EventTypeNormal, "SuccessfulUpdateStatus", "Importing %s service and updated %s status", serviceImport.Name, mcs.Name)
 
 	serviceName := r.derivedServiceFromLabel(mcs)
+	previousServiceName := r.previousDerivedServiceFromAnnotation(mcs)
 	mcsKObj := klog.KObj(mcs)
-	if serviceName == nil {
+	if serviceName == nil && previousServiceName == nil {
 		klog.V(4).InfoS("Skipping deleting derived service", "multiClusterService", mcsKObj)
 		return nil // do nothing
 	}
-	svcKRef := klog.KRef(serviceName.Namespace, serviceName.Name)
-	if err := r.deleteDerivedService(ctx, serviceName); err != nil && !errors.IsNotFound(err) {
-		klog.ErrorS(err, "Failed to remove derived service of mcs", "multiClusterService", mcsKObj, "service", svcKRef)
+	if serviceName != nil {
+		if err := r.deleteDerivedService(ctx, serviceName); err != nil && !errors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to remove derived service of mcs", "multiClusterService", mcsKObj, "service", klog.KRef(serviceName.Namespace, serviceName.Name))
+			return err
+		}
+	}
+	if err := r.deletePreviousDerivedService(ctx, mcs); err != nil {
+		klog.ErrorS(err, "Failed to remove previous derived service of mcs", "multiClusterService", mcsKObj, "service", klog.KRef(previousServiceName.Namespace, previousServiceName.Name))
 		return err
 	}
 	// update mcs label
 	delete(mcs.GetLabels(), objectmeta.MultiClusterServiceLabelDerivedService)
+	delete(mcs.GetAnnotations(), multiClusterServiceAnnotationPreviousDerivedService)
 	if err := r.Client.Update(ctx, mcs); err != nil {
 		klog.ErrorS(err, "Failed to update the derived service label of mcs", "multiClusterService", mcsKObj)
 		return err
@@ -352,30 +613,147 @@ func configureInternalLoadBalancer(mcs *fleetnetv1alpha1.MultiClusterService, se
 	service.Annotations[serviceAnnotationInternalLoadBalancer] = "true"
 }
 
-func (r *Reconciler) ensureDerivedService(mcs *fleetnetv1alpha1.MultiClusterService, serviceImport *fleetnetv1alpha1.ServiceImport, service *corev1.Service) error {
+// applyExportedMetadata applies the exported labels or annotations to the metadata of the derived service and removes
+// the ones applied before but no longer exported; the applied keys are recorded in the annotations under trackingKey.
+// The keys reserved for the fleet networking controllers are never applied, as the controllers interpret them.
+func applyExportedMetadata(metadata, annotations map[string]string, trackingKey string, exported map[string]string) {
+	keys := make([]string, 0, len(exported))
+	for key := range exported {
+		if !objectmeta.IsReservedKey(key) {
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range strings.Split(annotations[trackingKey], ",") {
+		if _, ok := exported[key]; !ok && !objectmeta.IsReservedKey(key) {
+			delete(metadata, key)
+		}
+	}
+	if len(keys) == 0 {
+		delete(annotations, trackingKey)
+		return
+	}
+	for _, key := range keys {
+		metadata[key] = exported[key]
+	}
+	sort.Strings(keys)
+	annotations[trackingKey] = strings.Join(keys, ",")
+}
+
+// configureDerivedServiceSpec applies the derived service settings configured in the mcs to the derived service.
+// The fields which are not configured are set to their default values explicitly, so that the settings removed from
+// the mcs are reverted and the derived service won't be updated again and again.
+func configureDerivedServiceSpec(mcs *fleetnetv1alpha1.MultiClusterService, service *corev1.Service) {
+	spec := mcs.Spec.DerivedService
+	if spec == nil {
+		spec = &fleetnetv1alpha1.DerivedServiceSpec{}
+	}
+	service.Spec.Type = corev1.ServiceTypeLoadBalancer
+	if spec.Type != "" {
+		service.Spec.Type = spec.Type
+	}
+
+	// The external traffic policy only applies to the service which is accessible externally.
+	service.Spec.ExternalTrafficPolicy = ""
+	if service.Spec.Type != corev1.ServiceTypeClusterIP {
+		service.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyCluster
+		if spec.ExternalTrafficPolicy != "" {
+			service.Spec.ExternalTrafficPolicy = spec.ExternalTrafficPolicy
+		}
+	}
+
+	if service.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		service.Spec.LoadBalancerClass = nil
+		service.Spec.LoadBalancerSourceRanges = nil
+		service.Spec.AllocateLoadBalancerNodePorts = nil
+		return
+	}
+	service.Spec.LoadBalancerClass = spec.LoadBalancerClass
+	service.Spec.LoadBalancerSourceRanges = spec.LoadBalancerSourceRanges
+	allocateLoadBalancerNodePorts := true
+	if spec.AllocateLoadBalancerNodePorts != nil {
+		allocateLoadBalancerNodePorts = *spec.AllocateLoadBalancerNodePorts
+	}
+	service.Spec.AllocateLoadBalancerNodePorts = &allocateLoadBalancerNodePorts
+}
+
+// configureSessionAffinity applies the session affinity settings resolved in the service import to the derived service.
+func configureSessionAffinity(serviceImport *fleetnetv1alpha1.ServiceImport, service *corev1.Service) {
+	if serviceImport.Status.SessionAffinity != corev1.ServiceAffinityClientIP {
+		// Set the default value explicitly so that the derived service won't be updated again and again.
+		service.Spec.SessionAffinity = corev1.ServiceAffinityNone
+		service.Spec.SessionAffinityConfig = nil
+		return
+	}
+	service.Spec.SessionAffinity = corev1.ServiceAffinityClientIP
+	service.Spec.SessionAffinityConfig = serviceImport.Status.SessionAffinityConfig.DeepCopy()
+}
+
+// configureClusterSetIP programs the ClusterSetIP allocated by the hub, if any, as the cluster IP of the derived
+// service, so that the traffic sent to the ClusterSetIP in this cluster is routed to the imported endpoints by
+// kube-proxy. The ClusterSetIP must be in the service CIDR of the cluster.
+//
+// The cluster IP of a service is immutable, so the ClusterSetIP is only programmed when the derived service is
+// created; an existing derived service keeps its cluster IP, so that its traffic is not dropped.
+func configureClusterSetIP(clusterSetIP string, service *corev1.Service) {
+	// The ClusterSetIP used to be programmed as an external IP, which is denied by the DenyServiceExternalIPs
+	// admission plugin on many clusters.
+	service.Spec.ExternalIPs = nil
+	if !service.CreationTimestamp.IsZero() {
+		if clusterSetIP != "" && service.Spec.ClusterIP != clusterSetIP {
+			klog.V(2).InfoS("ClusterSetIP cannot be programmed on the existing derived service", "service", klog.KObj(service), "clusterSetIP", clusterSetIP, "clusterIP", service.Spec.ClusterIP)
+		}
+		return
+	}
+	if clusterSetIP == "" {
+		service.Spec.ClusterIP = ""
+		service.Spec.ClusterIPs = nil
+		return
+	}
+	service.Spec.ClusterIP = clusterSetIP
+	service.Spec.ClusterIPs = []string{clusterSetIP}
+}
+
+func (r *Reconciler) ensureDerivedService(mcs *fleetnetv1alpha1.MultiClusterService, serviceImport *fleetnetv1alpha1.ServiceImport, service *corev1.Service, clusterSetIP string) error {
 	svcPorts := make([]corev1.ServicePort, len(serviceImport.Status.Ports))
 	for i, importPort := range serviceImport.Status.Ports {
 		svcPorts[i] = importPort.ToServicePort()
 	}
 	service.Spec.Ports = svcPorts
-	service.Spec.Type = corev1.ServiceTypeLoadBalancer
+	configureClusterSetIP(clusterSetIP, service)
+	configureDerivedServiceSpec(mcs, service)
+	configureSessionAffinity(serviceImport, service)
 
 	if service.GetLabels() == nil { // in case labels map is nil and causes the panic
 		service.Labels = map[string]string{}
 	}
+	if service.GetAnnotations() == nil {
+		service.Annotations = map[string]string{}
+	}
+	// Apply the exported metadata first so that the keys managed by the controller always take precedence.
+	applyExportedMetadata(service.Labels, service.Annotations, serviceAnnotationExportedLabelKeys, serviceImport.Status.ExportedLabels)
+	applyExportedMetadata(service.Annotations, service.Annotations, serviceAnnotationExportedAnnotationKeys, serviceImport.Status.ExportedAnnotations)
+	// The annotations configured in the mcs take precedence over the exported ones.
+	var configuredAnnotations map[string]string
+	if mcs.Spec.DerivedService != nil {
+		configuredAnnotations = mcs.Spec.DerivedService.Annotations
+	}
+	applyExportedMetadata(service.Annotations, service.Annotations, serviceAnnotationConfiguredAnnotationKeys, configuredAnnotations)
 
-	service.Labels[serviceLabelMCSName] = mcs.Name
-	service.Labels[serviceLabelMCSNamespace] = mcs.Namespace
+	service.Labels[objectmeta.ServiceLabelMultiClusterServiceName] = mcs.Name
+	service.Labels[objectmeta.ServiceLabelMultiClusterServiceNamespace] = mcs.Namespace
 	configureInternalLoadBalancer(mcs, service)
 	return nil
 }
 
-// generateDerivedServiceName appends multiclusterservice name and namespace as the derived service name since a service
-// import may be exported by the multiple MCSs.
-// It makes sure the service name is unique and less than 63 characters.
-func (r *Reconciler) generateDerivedServiceName(mcs *fleetnetv1alpha1.MultiClusterService) *types.NamespacedName {
-	// TODO make sure the service name is unique and less than 63 characters.
-	return &types.NamespacedName{Namespace: r.FleetSystemNamespace, Name: fmt.Sprintf("%v-%v", mcs.Namespace, mcs.Name)}
+// generateDerivedServiceName generates the derived service name using the multiclusterservice namespace and name since
+// a service import may be exported by the multiple MCSs.
+// The name is a valid RFC 1035 DNS label which is unique in the cluster and stays the same for the mcs.
+func (r *Reconciler) generateDerivedServiceName(mcs *fleetnetv1alpha1.MultiClusterService) (*types.NamespacedName, error) {
+	name, err := uniquename.ClusterScopedHashedName(uniquename.DNS1035Label, mcs.Namespace, mcs.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &types.NamespacedName{Namespace: r.FleetSystemNamespace, Name: name}, nil
 }
 
 // updateMultiClusterServiceStatus updates mcs condition and status based on the service import and service status.
@@ -400,11 +778,14 @@ func (r *Reconciler) updateMultiClusterServiceStatus(ctx context.Context, mcs *f
 
 	mcsKObj := klog.KObj(mcs)
 	if equality.Semantic.DeepEqual(mcs.Status.LoadBalancer, service.Status.LoadBalancer) &&
+		equality.Semantic.DeepEqual(mcs.Status.Clusters, serviceImport.Status.Clusters) &&
 		condition.EqualCondition(currentCond, desiredCond) {
 		klog.V(4).InfoS("Status is in the desired state and skipping updating status", "multiClusterService", mcsKObj)
 		return nil
 	}
 	mcs.Status.LoadBalancer = service.Status.LoadBalancer
+	// The clusters of the service import are the exporting clusters selected by the cluster selector, if any.
+	mcs.Status.Clusters = serviceImport.Status.Clusters
 	meta.SetStatusCondition(&mcs.Status.Conditions, *desiredCond)
 
 	klog.V(2).InfoS("Updating mcs status", "multiClusterService", mcsKObj)
@@ -417,7 +798,7 @@ func (r *Reconciler) updateMultiClusterServiceStatus(ctx context.Context, mcs *f
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&fleetnetv1alpha1.MultiClusterService{}).
 		Owns(&fleetnetv1alpha1.ServiceImport{}).
 		// cannot add cross-namespace owner reference on service object
@@ -427,14 +808,34 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Watches(
 			&corev1.Service{},
 			handler.EnqueueRequestsFromMapFunc(r.serviceEventHandler()),
-		).
-		Complete(r)
+		)
+	if r.MembershipGate != nil {
+		// Re-process all the MCSes when the member cluster re-joins the fleet.
+		b = b.WatchesRawSource(r.MembershipGate.Source(handler.EnqueueRequestsFromMapFunc(r.allMultiClusterServiceRequests)))
+	}
+	return b.Complete(metrics.InstrumentReconciler("multiclusterservice", r))
+}
+
+// allMultiClusterServiceRequests returns the reconcile requests for all the MCSes in the member cluster.
+func (r *Reconciler) allMultiClusterServiceRequests(ctx context.Context, _ client.Object) []reconcile.Request {
+	mcsList := &fleetnetv1alpha1.MultiClusterServiceList{}
+	if err := r.Client.List(ctx, mcsList); err != nil {
+		klog.ErrorS(err, "Failed to list multiClusterServices")
+		return []reconcile.Request{}
+	}
+	reqs := make([]reconcile.Request, 0, len(mcsList.Items))
+	for i := range mcsList.Items {
+		reqs = append(reqs, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: mcsList.Items[i].Namespace, Name: mcsList.Items[i].Name},
+		})
+	}
+	return reqs
 }
 
 func (r *Reconciler) serviceEventHandler() handler.MapFunc {
 	return func(_ context.Context, object client.Object) []reconcile.Request {
-		namespace := object.GetLabels()[serviceLabelMCSNamespace]
-		name := object.GetLabels()[serviceLabelMCSName]
+		namespace := object.GetLabels()[objectmeta.ServiceLabelMultiClusterServiceNamespace]
+		name := object.GetLabels()[objectmeta.ServiceLabelMultiClusterServiceName]
 
 		// ignore any service which is not in the fleet system namespace and does not have two labels
 		if object.GetNamespace() != r.FleetSystemNamespace || namespace == "" || name == "" {
@@ -7,20 +7,23 @@ package multiclusterservice
 
 import (
 	"context"
-	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 	"go.goms.io/fleet-networking/pkg/common/objectmeta"
@@ -43,7 +46,7 @@ var (
 		Kind:       "Service",
 		APIVersion: "v1",
 	}
-	derivedServiceName = fmt.Sprintf("%v-%v", testNamespace, testName)
+	derivedServiceName = "my-ns-my-mcs-6ada6"
 )
 
 func multiClusterServiceScheme(t *testing.T) *runtime.Scheme {
@@ -54,6 +57,9 @@ func multiClusterServiceScheme(t *testing.T) *runtime.Scheme {
 	if err := corev1.AddToScheme(scheme); err != nil {
 		t.Fatalf("failed to add scheme: %v", err)
 	}
+	if err := discoveryv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
 	return scheme
 }
 
@@ -282,8 +288,8 @@ func TestHandleUpdate(t *testing.T) {
 		Reason:             conditionReasonFoundServiceImport,
 	}
 	serviceLabel := map[string]string{
-		serviceLabelMCSName:      testName,
-		serviceLabelMCSNamespace: testNamespace,
+		objectmeta.ServiceLabelMultiClusterServiceName:      testName,
+		objectmeta.ServiceLabelMultiClusterServiceNamespace: testNamespace,
 	}
 
 	tests := []struct {
@@ -633,8 +639,11 @@ func TestHandleUpdate(t *testing.T) {
 					Labels:    serviceLabel,
 				},
 				Spec: corev1.ServiceSpec{
-					Ports: servicePorts,
-					Type:  corev1.ServiceTypeLoadBalancer,
+					Ports:                         servicePorts,
+					Type:                          corev1.ServiceTypeLoadBalancer,
+					ExternalTrafficPolicy:         corev1.ServiceExternalTrafficPolicyCluster,
+					AllocateLoadBalancerNodePorts: ptr.To(true),
+					SessionAffinity:               corev1.ServiceAffinityNone,
 				},
 			},
 			wantMCS: &fleetnetv1alpha1.MultiClusterService{
@@ -657,6 +666,9 @@ func TestHandleUpdate(t *testing.T) {
 					Conditions: []metav1.Condition{
 						validCondition,
 					},
+					Clusters: []fleetnetv1alpha1.ClusterStatus{
+						{Cluster: "member1"},
+					},
 				},
 			},
 		},
@@ -699,8 +711,11 @@ func TestHandleUpdate(t *testing.T) {
 					Labels:    serviceLabel,
 				},
 				Spec: corev1.ServiceSpec{
-					Ports: servicePorts,
-					Type:  corev1.ServiceTypeLoadBalancer,
+					Ports:                         servicePorts,
+					Type:                          corev1.ServiceTypeLoadBalancer,
+					ExternalTrafficPolicy:         corev1.ServiceExternalTrafficPolicyCluster,
+					AllocateLoadBalancerNodePorts: ptr.To(true),
+					SessionAffinity:               corev1.ServiceAffinityNone,
 				},
 			},
 			wantMCS: &fleetnetv1alpha1.MultiClusterService{
@@ -723,6 +738,9 @@ func TestHandleUpdate(t *testing.T) {
 					Conditions: []metav1.Condition{
 						validCondition,
 					},
+					Clusters: []fleetnetv1alpha1.ClusterStatus{
+						{Cluster: "member1"},
+					},
 				},
 			},
 		},
@@ -805,8 +823,11 @@ func TestHandleUpdate(t *testing.T) {
 					Labels:    serviceLabel,
 				},
 				Spec: corev1.ServiceSpec{
-					Ports: servicePorts,
-					Type:  corev1.ServiceTypeLoadBalancer,
+					Ports:                         servicePorts,
+					Type:                          corev1.ServiceTypeLoadBalancer,
+					ExternalTrafficPolicy:         corev1.ServiceExternalTrafficPolicyCluster,
+					AllocateLoadBalancerNodePorts: ptr.To(true),
+					SessionAffinity:               corev1.ServiceAffinityNone,
 				},
 				Status: corev1.ServiceStatus{
 					LoadBalancer: loadBalancerStatus,
@@ -832,6 +853,9 @@ func TestHandleUpdate(t *testing.T) {
 					Conditions: []metav1.Condition{
 						validCondition,
 					},
+					Clusters: []fleetnetv1alpha1.ClusterStatus{
+						{Cluster: "member1"},
+					},
 				},
 			},
 		},
@@ -947,8 +971,11 @@ func TestHandleUpdate(t *testing.T) {
 					},
 				},
 				Spec: corev1.ServiceSpec{
-					Ports: servicePorts,
-					Type:  corev1.ServiceTypeLoadBalancer,
+					Ports:                         servicePorts,
+					Type:                          corev1.ServiceTypeLoadBalancer,
+					ExternalTrafficPolicy:         corev1.ServiceExternalTrafficPolicyCluster,
+					AllocateLoadBalancerNodePorts: ptr.To(true),
+					SessionAffinity:               corev1.ServiceAffinityNone,
 				},
 			},
 			wantMCS: &fleetnetv1alpha1.MultiClusterService{
@@ -974,6 +1001,9 @@ func TestHandleUpdate(t *testing.T) {
 					Conditions: []metav1.Condition{
 						validCondition,
 					},
+					Clusters: []fleetnetv1alpha1.ClusterStatus{
+						{Cluster: "member1"},
+					},
 				},
 			},
 		},
@@ -1055,6 +1085,343 @@ func TestHandleUpdate(t *testing.T) {
 	}
 }
 
+func TestHandleUpdate_DerivedServiceMigration(t *testing.T) {
+	legacyServiceName := testNamespace + "-" + testName
+	ctx := context.Background()
+	mcsObj := multiClusterServiceForTest()
+	mcsObj.Labels = map[string]string{
+		multiClusterServiceLabelServiceImport:             testServiceName,
+		objectmeta.MultiClusterServiceLabelDerivedService: legacyServiceName,
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testServiceName,
+			Namespace: testNamespace,
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Ports: []fleetnetv1alpha1.ServicePort{
+				{
+					Name:     "portA",
+					Protocol: "TCP",
+					Port:     8080,
+				},
+			},
+			Clusters: []fleetnetv1alpha1.ClusterStatus{
+				{Cluster: "member1"},
+			},
+		},
+	}
+	legacyService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      legacyServiceName,
+			Namespace: systemNamespace,
+			Labels: map[string]string{
+				objectmeta.ServiceLabelMultiClusterServiceName:      testName,
+				objectmeta.ServiceLabelMultiClusterServiceNamespace: testNamespace,
+			},
+		},
+	}
+	// Another mcs, e.g. "my-ns-my/mcs", ends up with the same legacy derived service name.
+	collidingMCS := &fleetnetv1alpha1.MultiClusterService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "mcs",
+			Namespace: "my-ns-my",
+			Labels: map[string]string{
+				objectmeta.MultiClusterServiceLabelDerivedService: legacyServiceName,
+			},
+		},
+	}
+	importedEndpointSlice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "imported-endpointslice",
+			Namespace: systemNamespace,
+			Labels: map[string]string{
+				discoveryv1.LabelServiceName: legacyServiceName,
+			},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+	}
+	objects := []client.Object{mcsObj, collidingMCS, serviceImport, legacyService, importedEndpointSlice}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(multiClusterServiceScheme(t)).
+		WithObjects(objects...).
+		WithStatusSubresource(objects...).
+		Build()
+	r := multiClusterServiceReconciler(fakeClient)
+
+	handleUpdate := func() (ctrl.Result, *fleetnetv1alpha1.MultiClusterService) {
+		mcs := &fleetnetv1alpha1.MultiClusterService{}
+		if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: testNamespace, Name: testName}, mcs); err != nil {
+			t.Fatalf("MultiClusterService Get() got error %v, want no error", err)
+		}
+		got, err := r.handleUpdate(ctx, mcs)
+		if err != nil {
+			t.Fatalf("failed to handle update: %v", err)
+		}
+		if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: testNamespace, Name: testName}, mcs); err != nil {
+			t.Fatalf("MultiClusterService Get() got error %v, want no error", err)
+		}
+		return got, mcs
+	}
+	wantRequeue := ctrl.Result{RequeueAfter: mcsRetryInterval}
+	newServiceKey := types.NamespacedName{Namespace: systemNamespace, Name: derivedServiceName}
+	legacyServiceKey := types.NamespacedName{Namespace: systemNamespace, Name: legacyServiceName}
+
+	// The new derived service is created, while the mcs keeps using the legacy one until the new one is ready.
+	got, mcs := handleUpdate()
+	if !cmp.Equal(got, wantRequeue) {
+		t.Errorf("handleUpdate() = %+v, want %+v", got, wantRequeue)
+	}
+	if gotName := mcs.Labels[objectmeta.MultiClusterServiceLabelDerivedService]; gotName != legacyServiceName {
+		t.Errorf("derived service label got %s, want %s", gotName, legacyServiceName)
+	}
+	newService := &corev1.Service{}
+	if err := fakeClient.Get(ctx, newServiceKey, newService); err != nil {
+		t.Fatalf("Service Get(%v) got error %v, want no error", newServiceKey, err)
+	}
+
+	// The mcs is switched to the new derived service once it is ready, while the legacy one is kept as the
+	// imported endpointSlice is still associated with it.
+	newService.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "10.0.0.1"}}
+	if err := fakeClient.Status().Update(ctx, newService); err != nil {
+		t.Fatalf("Service UpdateStatus() got error %v, want no error", err)
+	}
+	got, mcs = handleUpdate()
+	if !cmp.Equal(got, wantRequeue) {
+		t.Errorf("handleUpdate() = %+v, want %+v", got, wantRequeue)
+	}
+	if gotName := mcs.Labels[objectmeta.MultiClusterServiceLabelDerivedService]; gotName != derivedServiceName {
+		t.Errorf("derived service label got %s, want %s", gotName, derivedServiceName)
+	}
+	if gotName := mcs.Annotations[multiClusterServiceAnnotationPreviousDerivedService]; gotName != legacyServiceName {
+		t.Errorf("previous derived service annotation got %s, want %s", gotName, legacyServiceName)
+	}
+	if err := fakeClient.Get(ctx, legacyServiceKey, &corev1.Service{}); err != nil {
+		t.Fatalf("Service Get(%v) got error %v, want no error", legacyServiceKey, err)
+	}
+
+	// The legacy derived service is removed once the imported endpointSlice is associated with the new one.
+	importedEndpointSlice.Labels[discoveryv1.LabelServiceName] = derivedServiceName
+	if err := fakeClient.Update(ctx, importedEndpointSlice); err != nil {
+		t.Fatalf("EndpointSlice Update() got error %v, want no error", err)
+	}
+	got, mcs = handleUpdate()
+	if !cmp.Equal(got, ctrl.Result{}) {
+		t.Errorf("handleUpdate() = %+v, want %+v", got, ctrl.Result{})
+	}
+	if _, ok := mcs.Annotations[multiClusterServiceAnnotationPreviousDerivedService]; ok {
+		t.Errorf("previous derived service annotation got %v, want not found", mcs.Annotations)
+	}
+	if err := fakeClient.Get(ctx, legacyServiceKey, &corev1.Service{}); !errors.IsNotFound(err) {
+		t.Errorf("Service Get(%v) got error %v, want not found error", legacyServiceKey, err)
+	}
+}
+
+func TestHandleUpdate_KeepLegacyDerivedServiceName(t *testing.T) {
+	legacyServiceName := testNamespace + "-" + testName
+	ctx := context.Background()
+	mcsObj := multiClusterServiceForTest()
+	mcsObj.Labels = map[string]string{
+		multiClusterServiceLabelServiceImport:             testServiceName,
+		objectmeta.MultiClusterServiceLabelDerivedService: legacyServiceName,
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testServiceName,
+			Namespace: testNamespace,
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Ports: []fleetnetv1alpha1.ServicePort{
+				{
+					Name:     "portA",
+					Protocol: "TCP",
+					Port:     8080,
+				},
+			},
+			Clusters: []fleetnetv1alpha1.ClusterStatus{
+				{Cluster: "member1"},
+			},
+		},
+	}
+	legacyService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      legacyServiceName,
+			Namespace: systemNamespace,
+			Labels: map[string]string{
+				objectmeta.ServiceLabelMultiClusterServiceName:      testName,
+				objectmeta.ServiceLabelMultiClusterServiceNamespace: testNamespace,
+			},
+		},
+	}
+	objects := []client.Object{mcsObj, serviceImport, legacyService}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(multiClusterServiceScheme(t)).
+		WithObjects(objects...).
+		WithStatusSubresource(objects...).
+		Build()
+	r := multiClusterServiceReconciler(fakeClient)
+
+	got, err := r.handleUpdate(ctx, mcsObj)
+	if err != nil {
+		t.Fatalf("failed to handle update: %v", err)
+	}
+	if !cmp.Equal(got, ctrl.Result{}) {
+		t.Errorf("handleUpdate() = %+v, want %+v", got, ctrl.Result{})
+	}
+	mcs := &fleetnetv1alpha1.MultiClusterService{}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: testNamespace, Name: testName}, mcs); err != nil {
+		t.Fatalf("MultiClusterService Get() got error %v, want no error", err)
+	}
+	if gotName := mcs.Labels[objectmeta.MultiClusterServiceLabelDerivedService]; gotName != legacyServiceName {
+		t.Errorf("derived service label got %s, want %s", gotName, legacyServiceName)
+	}
+	if _, ok := mcs.Annotations[multiClusterServiceAnnotationPreviousDerivedService]; ok {
+		t.Errorf("previous derived service annotation got %v, want not found", mcs.Annotations)
+	}
+	newServiceKey := types.NamespacedName{Namespace: systemNamespace, Name: derivedServiceName}
+	if err := fakeClient.Get(ctx, newServiceKey, &corev1.Service{}); !errors.IsNotFound(err) {
+		t.Errorf("Service Get(%v) got error %v, want not found error", newServiceKey, err)
+	}
+	service := &corev1.Service{}
+	legacyServiceKey := types.NamespacedName{Namespace: systemNamespace, Name: legacyServiceName}
+	if err := fakeClient.Get(ctx, legacyServiceKey, service); err != nil {
+		t.Fatalf("Service Get(%v) got error %v, want no error", legacyServiceKey, err)
+	}
+	if len(service.Spec.Ports) != 1 {
+		t.Errorf("legacy derived service ports got %v, want 1 port", service.Spec.Ports)
+	}
+}
+
+func TestHandleUpdate_DerivedServiceMigration_PinnedLoadBalancerIP(t *testing.T) {
+	legacyServiceName := testNamespace + "-" + testName
+	ctx := context.Background()
+	mcsObj := multiClusterServiceForTest()
+	mcsObj.Labels = map[string]string{
+		multiClusterServiceLabelServiceImport:             testServiceName,
+		objectmeta.MultiClusterServiceLabelDerivedService: legacyServiceName,
+	}
+	mcsObj.Spec.DerivedService = &fleetnetv1alpha1.DerivedServiceSpec{
+		Annotations: map[string]string{
+			serviceAnnotationPublicIPName: "my-pip",
+		},
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testServiceName,
+			Namespace: testNamespace,
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Clusters: []fleetnetv1alpha1.ClusterStatus{
+				{Cluster: "member1"},
+			},
+		},
+	}
+	legacyService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      legacyServiceName,
+			Namespace: systemNamespace,
+			Labels: map[string]string{
+				objectmeta.ServiceLabelMultiClusterServiceName:      testName,
+				objectmeta.ServiceLabelMultiClusterServiceNamespace: testNamespace,
+			},
+		},
+	}
+	collidingMCS := &fleetnetv1alpha1.MultiClusterService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "mcs",
+			Namespace: "my-ns-my",
+			Labels: map[string]string{
+				objectmeta.MultiClusterServiceLabelDerivedService: legacyServiceName,
+			},
+		},
+	}
+	objects := []client.Object{mcsObj, collidingMCS, serviceImport, legacyService}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(multiClusterServiceScheme(t)).
+		WithObjects(objects...).
+		WithStatusSubresource(objects...).
+		Build()
+	r := multiClusterServiceReconciler(fakeClient)
+	recorder := record.NewFakeRecorder(10)
+	r.Recorder = recorder
+
+	// The new derived service cannot become ready while the legacy one holds the public IP, so the mcs is switched
+	// right away instead of waiting for it.
+	if _, err := r.handleUpdate(ctx, mcsObj); err != nil {
+		t.Fatalf("failed to handle update: %v", err)
+	}
+	mcs := &fleetnetv1alpha1.MultiClusterService{}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: testNamespace, Name: testName}, mcs); err != nil {
+		t.Fatalf("MultiClusterService Get() got error %v, want no error", err)
+	}
+	if gotName := mcs.Labels[objectmeta.MultiClusterServiceLabelDerivedService]; gotName != derivedServiceName {
+		t.Errorf("derived service label got %s, want %s", gotName, derivedServiceName)
+	}
+	gotEvent := false
+	for len(recorder.Events) > 0 {
+		if strings.HasPrefix(<-recorder.Events, corev1.EventTypeWarning+" HandingOffLoadBalancerIP") {
+			gotEvent = true
+		}
+	}
+	if !gotEvent {
+		t.Error("HandingOffLoadBalancerIP event got none, want one")
+	}
+	// The legacy derived service has no endpointSlices and is removed, which releases the public IP.
+	if _, ok := mcs.Annotations[multiClusterServiceAnnotationPreviousDerivedService]; ok {
+		t.Errorf("previous derived service annotation got %v, want not found", mcs.Annotations)
+	}
+	legacyServiceKey := types.NamespacedName{Namespace: systemNamespace, Name: legacyServiceName}
+	if err := fakeClient.Get(ctx, legacyServiceKey, &corev1.Service{}); !errors.IsNotFound(err) {
+		t.Errorf("Service Get(%v) got error %v, want not found error", legacyServiceKey, err)
+	}
+}
+
+func TestRemovePreviousDerivedService_UsedByAnotherMCS(t *testing.T) {
+	previousServiceName := testNamespace + "-" + testName
+	ctx := context.Background()
+	mcsObj := multiClusterServiceForTest()
+	mcsObj.Annotations = map[string]string{
+		multiClusterServiceAnnotationPreviousDerivedService: previousServiceName,
+	}
+	previousService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      previousServiceName,
+			Namespace: systemNamespace,
+			Labels: map[string]string{
+				objectmeta.ServiceLabelMultiClusterServiceName:      "mcs",
+				objectmeta.ServiceLabelMultiClusterServiceNamespace: "my-ns-my",
+			},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(multiClusterServiceScheme(t)).
+		WithObjects(mcsObj, previousService).
+		Build()
+	r := multiClusterServiceReconciler(fakeClient)
+
+	if err := r.deletePreviousDerivedService(ctx, mcsObj); err != nil {
+		t.Fatalf("deletePreviousDerivedService() got error %v, want no error", err)
+	}
+	isRemoved, err := r.removePreviousDerivedService(ctx, mcsObj)
+	if err != nil {
+		t.Fatalf("removePreviousDerivedService() got error %v, want no error", err)
+	}
+	if !isRemoved {
+		t.Error("removePreviousDerivedService() got false, want true")
+	}
+	mcs := &fleetnetv1alpha1.MultiClusterService{}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: testNamespace, Name: testName}, mcs); err != nil {
+		t.Fatalf("MultiClusterService Get() got error %v, want no error", err)
+	}
+	if _, ok := mcs.Annotations[multiClusterServiceAnnotationPreviousDerivedService]; ok {
+		t.Errorf("previous derived service annotation got %v, want not found", mcs.Annotations)
+	}
+	previousServiceKey := types.NamespacedName{Namespace: systemNamespace, Name: previousServiceName}
+	if err := fakeClient.Get(ctx, previousServiceKey, &corev1.Service{}); err != nil {
+		t.Errorf("Service Get(%v) got error %v, want no error", previousServiceKey, err)
+	}
+}
+
 func TestConfigureInternalLoadBalancer(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -1111,3 +1478,381 @@ func TestConfigureInternalLoadBalancer(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigureSessionAffinity(t *testing.T) {
+	timeoutSeconds := int32(600)
+	clientIPConfig := &corev1.SessionAffinityConfig{
+		ClientIP: &corev1.ClientIPConfig{TimeoutSeconds: &timeoutSeconds},
+	}
+	tests := []struct {
+		name     string
+		status   fleetnetv1alpha1.ServiceImportStatus
+		service  corev1.ServiceSpec
+		wantSpec corev1.ServiceSpec
+	}{
+		{
+			name: "session affinity is not set",
+			wantSpec: corev1.ServiceSpec{
+				SessionAffinity: corev1.ServiceAffinityNone,
+			},
+		},
+		{
+			name: "client IP session affinity is set",
+			status: fleetnetv1alpha1.ServiceImportStatus{
+				SessionAffinity:       corev1.ServiceAffinityClientIP,
+				SessionAffinityConfig: clientIPConfig,
+			},
+			wantSpec: corev1.ServiceSpec{
+				SessionAffinity:       corev1.ServiceAffinityClientIP,
+				SessionAffinityConfig: clientIPConfig,
+			},
+		},
+		{
+			name: "client IP session affinity is removed",
+			status: fleetnetv1alpha1.ServiceImportStatus{
+				SessionAffinity: corev1.ServiceAffinityNone,
+			},
+			service: corev1.ServiceSpec{
+				SessionAffinity:       corev1.ServiceAffinityClientIP,
+				SessionAffinityConfig: clientIPConfig,
+			},
+			wantSpec: corev1.ServiceSpec{
+				SessionAffinity: corev1.ServiceAffinityNone,
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			serviceImport := &fleetnetv1alpha1.ServiceImport{Status: tc.status}
+			service := &corev1.Service{Spec: tc.service}
+			configureSessionAffinity(serviceImport, service)
+			if diff := cmp.Diff(tc.wantSpec, service.Spec); diff != "" {
+				t.Errorf("configureSessionAffinity() service spec mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestApplyExportedMetadata(t *testing.T) {
+	tests := []struct {
+		name            string
+		labels          map[string]string
+		annotations     map[string]string
+		exported        map[string]string
+		wantLabels      map[string]string
+		wantAnnotations map[string]string
+	}{
+		{
+			name:            "nothing is exported",
+			labels:          map[string]string{"foo": "bar"},
+			annotations:     map[string]string{},
+			wantLabels:      map[string]string{"foo": "bar"},
+			wantAnnotations: map[string]string{},
+		},
+		{
+			name:        "labels are exported",
+			labels:      map[string]string{"foo": "bar"},
+			annotations: map[string]string{},
+			exported:    map[string]string{"team": "payments", "app": "web"},
+			wantLabels:  map[string]string{"foo": "bar", "team": "payments", "app": "web"},
+			wantAnnotations: map[string]string{
+				serviceAnnotationExportedLabelKeys: "app,team",
+			},
+		},
+		{
+			name:   "labels are no longer exported",
+			labels: map[string]string{"foo": "bar", "team": "payments", "app": "web"},
+			annotations: map[string]string{
+				serviceAnnotationExportedLabelKeys: "app,team",
+			},
+			exported:   map[string]string{"app": "api"},
+			wantLabels: map[string]string{"foo": "bar", "app": "api"},
+			wantAnnotations: map[string]string{
+				serviceAnnotationExportedLabelKeys: "app",
+			},
+		},
+		{
+			name:        "reserved keys are not applied",
+			labels:      map[string]string{objectmeta.ServiceLabelMultiClusterServiceName: "my-mcs"},
+			annotations: map[string]string{},
+			exported: map[string]string{
+				"app": "web",
+				objectmeta.ServiceLabelMultiClusterServiceName: "other-mcs",
+				serviceAnnotationExportedLabelKeys:             "foo",
+			},
+			wantLabels: map[string]string{
+				"app": "web",
+				objectmeta.ServiceLabelMultiClusterServiceName: "my-mcs",
+			},
+			wantAnnotations: map[string]string{
+				serviceAnnotationExportedLabelKeys: "app",
+			},
+		},
+		{
+			name:   "all the labels are removed",
+			labels: map[string]string{"foo": "bar", "app": "web"},
+			annotations: map[string]string{
+				serviceAnnotationExportedLabelKeys: "app",
+			},
+			wantLabels:      map[string]string{"foo": "bar"},
+			wantAnnotations: map[string]string{},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			applyExportedMetadata(tc.labels, tc.annotations, serviceAnnotationExportedLabelKeys, tc.exported)
+			if diff := cmp.Diff(tc.wantLabels, tc.labels); diff != "" {
+				t.Errorf("applyExportedMetadata() labels mismatch (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantAnnotations, tc.annotations); diff != "" {
+				t.Errorf("applyExportedMetadata() annotations mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConfigureDerivedServiceSpec(t *testing.T) {
+	loadBalancerClass := "example.com/lb"
+	tests := []struct {
+		name           string
+		derivedService *fleetnetv1alpha1.DerivedServiceSpec
+		service        *corev1.Service
+		want           corev1.ServiceSpec
+	}{
+		{
+			name:    "derived service is not configured",
+			service: &corev1.Service{},
+			want: corev1.ServiceSpec{
+				Type:                          corev1.ServiceTypeLoadBalancer,
+				ExternalTrafficPolicy:         corev1.ServiceExternalTrafficPolicyCluster,
+				AllocateLoadBalancerNodePorts: ptr.To(true),
+			},
+		},
+		{
+			name: "load balancer settings are configured",
+			derivedService: &fleetnetv1alpha1.DerivedServiceSpec{
+				Type:                          corev1.ServiceTypeLoadBalancer,
+				LoadBalancerClass:             &loadBalancerClass,
+				ExternalTrafficPolicy:         corev1.ServiceExternalTrafficPolicyLocal,
+				LoadBalancerSourceRanges:      []string{"10.0.0.0/8"},
+				AllocateLoadBalancerNodePorts: ptr.To(false),
+			},
+			service: &corev1.Service{},
+			want: corev1.ServiceSpec{
+				Type:                          corev1.ServiceTypeLoadBalancer,
+				LoadBalancerClass:             &loadBalancerClass,
+				ExternalTrafficPolicy:         corev1.ServiceExternalTrafficPolicyLocal,
+				LoadBalancerSourceRanges:      []string{"10.0.0.0/8"},
+				AllocateLoadBalancerNodePorts: ptr.To(false),
+			},
+		},
+		{
+			name: "load balancer service is changed to cluster ip service",
+			derivedService: &fleetnetv1alpha1.DerivedServiceSpec{
+				Type:                  corev1.ServiceTypeClusterIP,
+				ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyLocal,
+			},
+			service: &corev1.Service{
+				Spec: corev1.ServiceSpec{
+					Type:                          corev1.ServiceTypeLoadBalancer,
+					LoadBalancerClass:             &loadBalancerClass,
+					ExternalTrafficPolicy:         corev1.ServiceExternalTrafficPolicyLocal,
+					LoadBalancerSourceRanges:      []string{"10.0.0.0/8"},
+					AllocateLoadBalancerNodePorts: ptr.To(true),
+				},
+			},
+			want: corev1.ServiceSpec{
+				Type: corev1.ServiceTypeClusterIP,
+			},
+		},
+		{
+			name: "cluster ip service with the cluster set ip",
+			derivedService: &fleetnetv1alpha1.DerivedServiceSpec{
+				Type: corev1.ServiceTypeClusterIP,
+			},
+			service: &corev1.Service{
+				Spec: corev1.ServiceSpec{
+					ClusterIP:  "10.0.255.1",
+					ClusterIPs: []string{"10.0.255.1"},
+				},
+			},
+			want: corev1.ServiceSpec{
+				Type:       corev1.ServiceTypeClusterIP,
+				ClusterIP:  "10.0.255.1",
+				ClusterIPs: []string{"10.0.255.1"},
+			},
+		},
+		{
+			name: "node port service",
+			derivedService: &fleetnetv1alpha1.DerivedServiceSpec{
+				Type:                  corev1.ServiceTypeNodePort,
+				ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyLocal,
+			},
+			service: &corev1.Service{},
+			want: corev1.ServiceSpec{
+				Type:                  corev1.ServiceTypeNodePort,
+				ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyLocal,
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mcs := &fleetnetv1alpha1.MultiClusterService{
+				Spec: fleetnetv1alpha1.MultiClusterServiceSpec{
+					DerivedService: tc.derivedService,
+				},
+			}
+			configureDerivedServiceSpec(mcs, tc.service)
+			if diff := cmp.Diff(tc.want, tc.service.Spec); diff != "" {
+				t.Errorf("configureDerivedServiceSpec() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConfigureClusterSetIP(t *testing.T) {
+	tests := []struct {
+		name         string
+		clusterSetIP string
+		service      *corev1.Service
+		want         corev1.ServiceSpec
+	}{
+		{
+			name:         "new service with the cluster set ip",
+			clusterSetIP: "10.0.255.1",
+			service: &corev1.Service{
+				Spec: corev1.ServiceSpec{
+					ExternalIPs: []string{"10.0.255.1"},
+				},
+			},
+			want: corev1.ServiceSpec{
+				ClusterIP:  "10.0.255.1",
+				ClusterIPs: []string{"10.0.255.1"},
+			},
+		},
+		{
+			name: "new service without the cluster set ip",
+			service: &corev1.Service{
+				Spec: corev1.ServiceSpec{
+					ClusterIP:  "10.0.255.1",
+					ClusterIPs: []string{"10.0.255.1"},
+				},
+			},
+			want: corev1.ServiceSpec{},
+		},
+		{
+			name:         "existing service keeps its cluster ip",
+			clusterSetIP: "10.0.255.1",
+			service: &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					CreationTimestamp: metav1.Now(),
+				},
+				Spec: corev1.ServiceSpec{
+					ClusterIP:   "10.0.0.10",
+					ClusterIPs:  []string{"10.0.0.10"},
+					ExternalIPs: []string{"10.0.255.1"},
+				},
+			},
+			want: corev1.ServiceSpec{
+				ClusterIP:  "10.0.0.10",
+				ClusterIPs: []string{"10.0.0.10"},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			configureClusterSetIP(tc.clusterSetIP, tc.service)
+			if diff := cmp.Diff(tc.want, tc.service.Spec); diff != "" {
+				t.Errorf("configureClusterSetIP() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHandleUpdate_ClusterSetIP(t *testing.T) {
+	ctx := context.Background()
+	clusterSetIP := "10.0.255.1"
+	tests := []struct {
+		name string
+		// serviceCIDRPrefix is the prefix of the cluster IPs the cluster accepts.
+		serviceCIDRPrefix string
+		wantClusterIP     string
+		wantEvent         bool
+	}{
+		{
+			name:              "cluster set ip is in the service cidr",
+			serviceCIDRPrefix: "10.0.",
+			wantClusterIP:     clusterSetIP,
+		},
+		{
+			name:              "cluster set ip is not in the service cidr",
+			serviceCIDRPrefix: "192.168.",
+			wantEvent:         true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mcsObj := multiClusterServiceForTest()
+			serviceImport := &fleetnetv1alpha1.ServiceImport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testServiceName,
+					Namespace: testNamespace,
+				},
+				Status: fleetnetv1alpha1.ServiceImportStatus{
+					Ports: []fleetnetv1alpha1.ServicePort{
+						{
+							Name:     "portA",
+							Protocol: "TCP",
+							Port:     8080,
+						},
+					},
+					IPs: []string{clusterSetIP},
+					Clusters: []fleetnetv1alpha1.ClusterStatus{
+						{Cluster: "member1"},
+					},
+				},
+			}
+			objects := []client.Object{mcsObj, serviceImport}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(multiClusterServiceScheme(t)).
+				WithObjects(objects...).
+				WithStatusSubresource(objects...).
+				WithInterceptorFuncs(interceptor.Funcs{
+					Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+						if svc, ok := obj.(*corev1.Service); ok && svc.Spec.ClusterIP != "" && !strings.HasPrefix(svc.Spec.ClusterIP, tc.serviceCIDRPrefix) {
+							return errors.NewInvalid(corev1.SchemeGroupVersion.WithKind("Service").GroupKind(), svc.Name, nil)
+						}
+						return c.Create(ctx, obj, opts...)
+					},
+				}).
+				Build()
+			r := multiClusterServiceReconciler(fakeClient)
+			recorder := record.NewFakeRecorder(10)
+			r.Recorder = recorder
+
+			if _, err := r.handleUpdate(ctx, mcsObj); err != nil {
+				t.Fatalf("handleUpdate() got error %v, want no error", err)
+			}
+			service := &corev1.Service{}
+			serviceKey := types.NamespacedName{Namespace: systemNamespace, Name: derivedServiceName}
+			if err := fakeClient.Get(ctx, serviceKey, service); err != nil {
+				t.Fatalf("Service Get(%v) got error %v, want no error", serviceKey, err)
+			}
+			if service.Spec.ClusterIP != tc.wantClusterIP {
+				t.Errorf("derived service cluster IP got %q, want %q", service.Spec.ClusterIP, tc.wantClusterIP)
+			}
+			if len(service.Spec.ExternalIPs) != 0 {
+				t.Errorf("derived service external IPs got %v, want none", service.Spec.ExternalIPs)
+			}
+			gotEvent := false
+			for len(recorder.Events) > 0 {
+				if strings.Contains(<-recorder.Events, "ClusterSetIPNotProgrammed") {
+					gotEvent = true
+				}
+			}
+			if gotEvent != tc.wantEvent {
+				t.Errorf("ClusterSetIPNotProgrammed event got %t, want %t", gotEvent, tc.wantEvent)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package fleetnetctl
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/servicespec"
+)
+
+// ExplainConflict writes why the exports of a service are in conflict: the differences between the spec exported
+// by every member cluster and the spec resolved in the ServiceImport. The hub resolves the spec from the first
+// export it accepts; every export must then agree with it on the ports (in the same order), the session affinity
+// settings and the exported labels and annotations.
+func (i *Inspector) ExplainConflict(ctx context.Context, svc types.NamespacedName) error {
+	svcImport, err := i.getServiceImport(ctx, svc)
+	if err != nil {
+		return err
+	}
+	exports, err := i.listExports(ctx, svc)
+	if err != nil {
+		return err
+	}
+	if len(exports) == 0 {
+		fmt.Fprintf(i.Out, "Service %s is not exported by any member cluster.\n", svc)
+		return nil
+	}
+
+	var resolved *fleetnetv1alpha1.InternalServiceExportSpec
+	if svcImport != nil && len(svcImport.Status.Clusters) != 0 {
+		resolved = &fleetnetv1alpha1.InternalServiceExportSpec{
+			Ports:                 svcImport.Status.Ports,
+			SessionAffinity:       svcImport.Status.SessionAffinity,
+			SessionAffinityConfig: svcImport.Status.SessionAffinityConfig,
+			ExportedLabels:        svcImport.Status.ExportedLabels,
+			ExportedAnnotations:   svcImport.Status.ExportedAnnotations,
+		}
+		fmt.Fprintf(i.Out, "Comparing the exports of %s with the spec resolved in ServiceImport %s (ports: %s).\n",
+			svc, svc, valueOrNone(formatServicePorts(resolved.Ports)))
+	} else {
+		// Without a resolved spec, compare with the earliest export, which is the most likely one to be accepted.
+		earliest := slices.MinFunc(exports, func(a, b fleetnetv1alpha1.InternalServiceExport) int {
+			return a.Spec.ServiceReference.ExportedSince.Compare(b.Spec.ServiceReference.ExportedSince.Time)
+		})
+		resolved = &earliest.Spec
+		fmt.Fprintf(i.Out, "The spec of %s has not been resolved yet; comparing the exports with the earliest one from member cluster %s (ports: %s).\n",
+			svc, earliest.Spec.ServiceReference.ClusterID, valueOrNone(formatServicePorts(resolved.Ports)))
+	}
+
+	conflicts := 0
+	for idx := range exports {
+		export := &exports[idx]
+		diffs := diffExportedSpec(resolved, &export.Spec)
+		conflict := conditionSummary(export.Status.Conditions, string(fleetnetv1alpha1.ServiceExportConflict))
+		fmt.Fprintf(i.Out, "\nMember cluster %s (conflict: %s):\n", export.Spec.ServiceReference.ClusterID, valueOrNone(conflict))
+		if len(diffs) == 0 {
+			fmt.Fprintln(i.Out, "  matches the resolved spec")
+			continue
+		}
+		conflicts++
+		for _, diff := range diffs {
+			fmt.Fprintf(i.Out, "  - %s\n", diff)
+		}
+	}
+	fmt.Fprintf(i.Out, "\n%d of %d exports differ from the resolved spec.\n", conflicts, len(exports))
+	return nil
+}
+
+// diffExportedSpec returns the differences of an exported spec from the resolved one, in the terms the hub resolves
+// the conflicts with.
+func diffExportedSpec(resolved, exported *fleetnetv1alpha1.InternalServiceExportSpec) []string {
+	diffs := diffPorts(resolved.Ports, exported.Ports)
+	if a, b := servicespec.NormalizeSessionAffinity(resolved.SessionAffinity), servicespec.NormalizeSessionAffinity(exported.SessionAffinity); a != b {
+		diffs = append(diffs, fmt.Sprintf("sessionAffinity is %s, want %s", b, a))
+	}
+	if !equality.Semantic.DeepEqual(resolved.SessionAffinityConfig, exported.SessionAffinityConfig) {
+		diffs = append(diffs, fmt.Sprintf("sessionAffinityConfig timeoutSeconds is %s, want %s",
+			clientIPTimeout(exported.SessionAffinityConfig), clientIPTimeout(resolved.SessionAffinityConfig)))
+	}
+	diffs = append(diffs, diffMap("exported label", resolved.ExportedLabels, exported.ExportedLabels)...)
+	diffs = append(diffs, diffMap("exported annotation", resolved.ExportedAnnotations, exported.ExportedAnnotations)...)
+	return diffs
+}
+
+// diffPorts returns the differences of the exported ports from the resolved ones; the ports are identified by the
+// port and the protocol.
+func diffPorts(resolved, exported []fleetnetv1alpha1.ServicePort) []string {
+	if equality.Semantic.DeepEqual(resolved, exported) {
+		return nil
+	}
+	var diffs []string
+	exportedByKey := make(map[string]*fleetnetv1alpha1.ServicePort, len(exported))
+	for idx := range exported {
+		exportedByKey[servicePortKey(&exported[idx])] = &exported[idx]
+	}
+	resolvedKeys := make(map[string]bool, len(resolved))
+	for idx := range resolved {
+		want := &resolved[idx]
+		key := servicePortKey(want)
+		resolvedKeys[key] = true
+		got, ok := exportedByKey[key]
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("port %s is not exported", key))
+			continue
+		}
+		if got.Name != want.Name {
+			diffs = append(diffs, fmt.Sprintf("port %s: name is %q, want %q", key, got.Name, want.Name))
+		}
+		if got.TargetPort != want.TargetPort {
+			diffs = append(diffs, fmt.Sprintf("port %s: targetPort is %s, want %s", key, got.TargetPort.String(), want.TargetPort.String()))
+		}
+		if a, b := ptr.Deref(got.AppProtocol, ""), ptr.Deref(want.AppProtocol, ""); a != b {
+			diffs = append(diffs, fmt.Sprintf("port %s: appProtocol is %q, want %q", key, a, b))
+		}
+	}
+	for idx := range exported {
+		if key := servicePortKey(&exported[idx]); !resolvedKeys[key] {
+			diffs = append(diffs, fmt.Sprintf("port %s is exported but not in the resolved spec", key))
+		}
+	}
+	if len(diffs) == 0 {
+		// The ports are compared in order.
+		diffs = append(diffs, fmt.Sprintf("ports are in a different order: %s, want %s", formatServicePorts(exported), formatServicePorts(resolved)))
+	}
+	return diffs
+}
+
+// diffMap returns the differences of the exported labels or annotations from the resolved ones.
+func diffMap(kind string, resolved, exported map[string]string) []string {
+	var diffs []string
+	for key, want := range resolved {
+		got, ok := exported[key]
+		switch {
+		case !ok:
+			diffs = append(diffs, fmt.Sprintf("%s %s is not exported", kind, key))
+		case got != want:
+			diffs = append(diffs, fmt.Sprintf("%s %s is %q, want %q", kind, key, got, want))
+		}
+	}
+	for key := range exported {
+		if _, ok := resolved[key]; !ok {
+			diffs = append(diffs, fmt.Sprintf("%s %s is exported but not in the resolved spec", kind, key))
+		}
+	}
+	slices.Sort(diffs)
+	return diffs
+}
+
+func clientIPTimeout(config *corev1.SessionAffinityConfig) string {
+	if config == nil || config.ClientIP == nil || config.ClientIP.TimeoutSeconds == nil {
+		return none
+	}
+	return fmt.Sprintf("%d", *config.ClientIP.TimeoutSeconds)
+}
+
+func valueOrNone(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return none
+	}
+	return s
+}
@@ -0,0 +1,75 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package fleetnetctl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+// Endpoints writes the endpoints of a service exported by every member cluster, i.e. the endpoints the importing
+// member clusters receive once the EndpointSlices are distributed.
+func (i *Inspector) Endpoints(ctx context.Context, svc types.NamespacedName) error {
+	endpointSliceExports, err := i.listEndpointSliceExports(ctx, svc)
+	if err != nil {
+		return err
+	}
+
+	endpointsByCluster := map[string]int{}
+	var clusters []string
+	t := i.newTable(fmt.Sprintf("Endpoints of %s", svc), "CLUSTER", "ENDPOINTSLICE", "ADDRESSES", "PORTS", "DISTRIBUTED")
+	for idx := range endpointSliceExports {
+		endpointSliceExport := &endpointSliceExports[idx]
+		clusterID := endpointSliceExport.Spec.EndpointSliceReference.ClusterID
+		if _, ok := endpointsByCluster[clusterID]; !ok {
+			clusters = append(clusters, clusterID)
+		}
+		endpointsByCluster[clusterID] += len(endpointSliceExport.Spec.Endpoints)
+		ports := formatEndpointPorts(endpointSliceExport.Spec.Ports)
+		distributed := conditionSummary(endpointSliceExport.Status.Conditions, string(fleetnetv1alpha1.EndpointSliceExportDistributed))
+		for _, endpoint := range endpointSliceExport.Spec.Endpoints {
+			t.addRow(clusterID, endpointSliceExport.Spec.EndpointSliceReference.Name, strings.Join(endpoint.Addresses, ", "), ports, distributed)
+		}
+	}
+	if err := t.flush(); err != nil {
+		return err
+	}
+
+	summary := make([]string, 0, len(clusters))
+	for _, cluster := range clusters {
+		summary = append(summary, fmt.Sprintf("%s: %d", cluster, endpointsByCluster[cluster]))
+	}
+	fmt.Fprintf(i.Out, "\nEndpoints per cluster: %s\n", valueOrNone(strings.Join(summary, ", ")))
+	return nil
+}
+
+// formatEndpointPorts formats the ports of an EndpointSlice, e.g. "http 8080/TCP"; a port without a number
+// stands for all the ports.
+func formatEndpointPorts(ports []discoveryv1.EndpointPort) string {
+	formatted := make([]string, 0, len(ports))
+	for _, port := range ports {
+		number, protocol := "all", string(corev1.ProtocolTCP)
+		if port.Port != nil {
+			number = fmt.Sprintf("%d", *port.Port)
+		}
+		if port.Protocol != nil {
+			protocol = string(*port.Protocol)
+		}
+		if port.Name != nil && *port.Name != "" {
+			formatted = append(formatted, fmt.Sprintf("%s %s/%s", *port.Name, number, protocol))
+		} else {
+			formatted = append(formatted, fmt.Sprintf("%s/%s", number, protocol))
+		}
+	}
+	return strings.Join(formatted, ", ")
+}
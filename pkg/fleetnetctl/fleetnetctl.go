@@ -0,0 +1,120 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package fleetnetctl features the commands of fleetnetctl, a CLI which inspects the multi-cluster services and the
+// Traffic Manager resources of a fleet from the hub cluster, so that the path of a service from its exports to its
+// imports and endpoints can be triaged without reading the controller logs.
+//
+// The commands read only from the hub cluster; the state of the member clusters is the one they have reported to
+// the hub cluster, e.g. in the InternalServiceExports and the InternalServiceImports.
+package fleetnetctl
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// none is printed in place of an empty value.
+	none = "<none>"
+)
+
+// ParseNamespacedName parses a namespaced name in the form of <namespace>/<name>.
+func ParseNamespacedName(s string) (types.NamespacedName, error) {
+	namespace, name, ok := strings.Cut(s, "/")
+	if !ok || namespace == "" || name == "" || strings.Contains(name, "/") {
+		return types.NamespacedName{}, fmt.Errorf("invalid name %q, want <namespace>/<name>", s)
+	}
+	return types.NamespacedName{Namespace: namespace, Name: name}, nil
+}
+
+// Inspector inspects the fleet networking objects in the hub cluster and writes what it finds in a human-readable
+// form.
+type Inspector struct {
+	// HubClient reads the objects from the hub cluster directly, e.g. a client without a cache; the objects of a
+	// service or a TrafficManagerProfile are picked out of the lists in memory, as the API server does not support
+	// selecting them by their spec fields.
+	HubClient client.Reader
+	// Out is where the findings are written to.
+	Out io.Writer
+}
+
+// table writes the rows of a section in aligned columns.
+type table struct {
+	w    *tabwriter.Writer
+	rows int
+}
+
+// newTable writes the title of a section and starts its table with the given header.
+func (i *Inspector) newTable(title string, header ...string) *table {
+	fmt.Fprintf(i.Out, "\n%s:\n", title)
+	t := &table{w: tabwriter.NewWriter(i.Out, 0, 8, 2, ' ', 0)}
+	fmt.Fprintf(t.w, "  %s\n", strings.Join(header, "\t"))
+	return t
+}
+
+// addRow adds a row to the table; the empty values are printed as "-".
+func (t *table) addRow(values ...string) {
+	for idx := range values {
+		if values[idx] == "" {
+			values[idx] = "-"
+		}
+	}
+	fmt.Fprintf(t.w, "  %s\n", strings.Join(values, "\t"))
+	t.rows++
+}
+
+// flush writes the table out; a table without rows is written as <none>.
+func (t *table) flush() error {
+	if t.rows == 0 {
+		fmt.Fprintf(t.w, "  %s\n", none)
+	}
+	return t.w.Flush()
+}
+
+// writeFields writes name and value pairs in aligned columns.
+func (i *Inspector) writeFields(fields [][2]string) error {
+	w := tabwriter.NewWriter(i.Out, 0, 8, 2, ' ', 0)
+	for _, field := range fields {
+		value := field[1]
+		if value == "" {
+			value = none
+		}
+		fmt.Fprintf(w, "%s:\t%s\n", field[0], value)
+	}
+	return w.Flush()
+}
+
+// conditionSummary returns the status and the reason of a condition, e.g. "False (Invalid)".
+func conditionSummary(conditions []metav1.Condition, conditionType string) string {
+	cond := meta.FindStatusCondition(conditions, conditionType)
+	if cond == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s (%s)", cond.Status, cond.Reason)
+}
+
+// conditionMessage returns the message of a condition.
+func conditionMessage(conditions []metav1.Condition, conditionType string) string {
+	if cond := meta.FindStatusCondition(conditions, conditionType); cond != nil {
+		return cond.Message
+	}
+	return ""
+}
+
+// formatTime formats a time in RFC 3339; the zero time is formatted as an empty string.
+func formatTime(t metav1.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format("2006-01-02T15:04:05Z")
+}
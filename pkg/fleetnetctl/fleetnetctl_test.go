@@ -0,0 +1,694 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package fleetnetctl
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+const (
+	testNamespace   = "work"
+	testServiceName = "app"
+	testProfileName = "profile"
+	testBackendName = "backend"
+	memberClusterA  = "member-a"
+	memberClusterB  = "member-b"
+	hubNSForMemberA = "fleet-member-member-a"
+	hubNSForMemberB = "fleet-member-member-b"
+)
+
+var (
+	testService = types.NamespacedName{Namespace: testNamespace, Name: testServiceName}
+
+	exportedSinceA = metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	exportedSinceB = metav1.NewTime(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC))
+	importedSince  = metav1.NewTime(time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC))
+)
+
+func TestMain(m *testing.M) {
+	// Add custom APIs to the runtime scheme.
+	if err := fleetnetv1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		log.Fatalf("failed to add custom APIs to the runtime scheme: %v", err)
+	}
+	os.Exit(m.Run())
+}
+
+func newInspector(objs ...client.Object) (*Inspector, *bytes.Buffer) {
+	hubClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objs...).Build()
+	out := &bytes.Buffer{}
+	return &Inspector{HubClient: hubClient, Out: out}, out
+}
+
+func serviceReference(clusterID string, exportedSince metav1.Time) fleetnetv1alpha1.ExportedObjectReference {
+	return fleetnetv1alpha1.ExportedObjectReference{
+		ClusterID:      clusterID,
+		Kind:           "Service",
+		Namespace:      testNamespace,
+		Name:           testServiceName,
+		NamespacedName: testService.String(),
+		ExportedSince:  exportedSince,
+	}
+}
+
+func internalServiceExport(namespace, clusterID string, exportedSince metav1.Time, ports []fleetnetv1alpha1.ServicePort, conditions ...metav1.Condition) *fleetnetv1alpha1.InternalServiceExport {
+	return &fleetnetv1alpha1.InternalServiceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      testNamespace + "-" + testServiceName,
+		},
+		Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+			Ports:            ports,
+			ServiceReference: serviceReference(clusterID, exportedSince),
+		},
+		Status: fleetnetv1alpha1.InternalServiceExportStatus{
+			Conditions: conditions,
+		},
+	}
+}
+
+func conflictCondition(status metav1.ConditionStatus, reason string) metav1.Condition {
+	return metav1.Condition{
+		Type:   string(fleetnetv1alpha1.ServiceExportConflict),
+		Status: status,
+		Reason: reason,
+	}
+}
+
+var (
+	httpPort = fleetnetv1alpha1.ServicePort{
+		Name:       "http",
+		Protocol:   corev1.ProtocolTCP,
+		Port:       80,
+		TargetPort: intstr.FromInt32(8080),
+	}
+	httpsPort = fleetnetv1alpha1.ServicePort{
+		Name:       "https",
+		Protocol:   corev1.ProtocolTCP,
+		Port:       443,
+		TargetPort: intstr.FromInt32(8443),
+	}
+)
+
+// TestParseNamespacedName tests the ParseNamespacedName function.
+func TestParseNamespacedName(t *testing.T) {
+	testCases := []struct {
+		name    string
+		s       string
+		want    types.NamespacedName
+		wantErr bool
+	}{
+		{
+			name: "namespaced name",
+			s:    "work/app",
+			want: types.NamespacedName{Namespace: "work", Name: "app"},
+		},
+		{
+			name:    "no namespace",
+			s:       "app",
+			wantErr: true,
+		},
+		{
+			name:    "empty namespace",
+			s:       "/app",
+			wantErr: true,
+		},
+		{
+			name:    "empty name",
+			s:       "work/",
+			wantErr: true,
+		},
+		{
+			name:    "too many segments",
+			s:       "work/app/extra",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseNamespacedName(tc.s)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("ParseNamespacedName(%q) error = %v, want error %t", tc.s, err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("ParseNamespacedName(%q) = %v, want %v", tc.s, got, tc.want)
+			}
+		})
+	}
+}
+
+// TestStatus tests the Inspector.Status method.
+func TestStatus(t *testing.T) {
+	svcImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testServiceName,
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Type:     fleetnetv1alpha1.ClusterSetIP,
+			Ports:    []fleetnetv1alpha1.ServicePort{httpPort},
+			Clusters: []fleetnetv1alpha1.ClusterStatus{{Cluster: memberClusterA}},
+			ClusterExports: []fleetnetv1alpha1.ClusterExportStatus{
+				{Cluster: memberClusterA, State: fleetnetv1alpha1.ClusterExportStateAccepted},
+				{Cluster: memberClusterB, State: fleetnetv1alpha1.ClusterExportStateConflicted, Message: "ports differ"},
+			},
+			ImportedBy: []fleetnetv1alpha1.ClusterImportStatus{
+				{ClusterNamespace: hubNSForMemberB, Cluster: memberClusterB, ImportedSince: importedSince},
+			},
+		},
+	}
+	internalSvcImport := &fleetnetv1alpha1.InternalServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: hubNSForMemberB,
+			Name:      testNamespace + "-" + testServiceName,
+		},
+		Spec: fleetnetv1alpha1.InternalServiceImportSpec{
+			ServiceImportReference: fleetnetv1alpha1.ExportedObjectReference{
+				ClusterID:      memberClusterB,
+				Kind:           "ServiceImport",
+				Namespace:      testNamespace,
+				Name:           testServiceName,
+				NamespacedName: testService.String(),
+			},
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Conditions: []metav1.Condition{
+				{
+					Type:   string(fleetnetv1alpha1.ServiceImportReady),
+					Status: metav1.ConditionTrue,
+					Reason: "Imported",
+				},
+			},
+		},
+	}
+	endpointSliceExport := &fleetnetv1alpha1.EndpointSliceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: hubNSForMemberA,
+			Name:      "app-12345",
+		},
+		Spec: fleetnetv1alpha1.EndpointSliceExportSpec{
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Endpoints: []fleetnetv1alpha1.Endpoint{
+				{Addresses: []string{"1.2.3.4"}},
+				{Addresses: []string{"1.2.3.5"}},
+			},
+			EndpointSliceReference: fleetnetv1alpha1.ExportedObjectReference{
+				ClusterID: memberClusterA,
+				Kind:      "EndpointSlice",
+				Namespace: testNamespace,
+				Name:      "app-abcde",
+			},
+			OwnerServiceReference: fleetnetv1alpha1.OwnerServiceReference{
+				Namespace:      testNamespace,
+				Name:           testServiceName,
+				NamespacedName: testService.String(),
+			},
+		},
+		Status: fleetnetv1alpha1.EndpointSliceExportStatus{
+			Conditions: []metav1.Condition{
+				{
+					Type:   string(fleetnetv1alpha1.EndpointSliceExportDistributed),
+					Status: metav1.ConditionFalse,
+					Reason: "DistributionFailed",
+				},
+			},
+			Distributions: []fleetnetv1alpha1.EndpointSliceDistribution{
+				{ClusterNamespace: hubNSForMemberB, Cluster: memberClusterB, Message: "namespace is terminating"},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name string
+		objs []client.Object
+		want string
+	}{
+		{
+			name: "service is not exported",
+			want: `Service:        work/app
+ServiceImport:  <none>
+
+Exports:
+  CLUSTER  INTERNALSERVICEEXPORT  STATE  CONFLICT  EXPORTED SINCE  MESSAGE
+  <none>
+
+Imports:
+  CLUSTER  INTERNALSERVICEIMPORT  CLAIMED  IMPORTED SINCE  READY  MESSAGE
+  <none>
+
+EndpointSlices:
+  CLUSTER  ENDPOINTSLICEEXPORT  ENDPOINTS  DISTRIBUTED  DISTRIBUTED TO
+  <none>
+`,
+		},
+		{
+			name: "service is exported and imported",
+			objs: []client.Object{
+				svcImport,
+				internalServiceExport(hubNSForMemberA, memberClusterA, exportedSinceA, []fleetnetv1alpha1.ServicePort{httpPort},
+					conflictCondition(metav1.ConditionFalse, "NoConflictFound")),
+				internalServiceExport(hubNSForMemberB, memberClusterB, exportedSinceB, []fleetnetv1alpha1.ServicePort{httpsPort},
+					conflictCondition(metav1.ConditionTrue, "ConflictFound")),
+				internalSvcImport,
+				endpointSliceExport,
+				// The export and the import of another service are not listed.
+				func() client.Object {
+					otherExport := internalServiceExport(hubNSForMemberA, memberClusterA, exportedSinceA, []fleetnetv1alpha1.ServicePort{httpPort})
+					otherExport.Name = testNamespace + "-other"
+					otherExport.Spec.ServiceReference.Name = "other"
+					otherExport.Spec.ServiceReference.NamespacedName = testNamespace + "/other"
+					return otherExport
+				}(),
+				func() client.Object {
+					otherImport := internalSvcImport.DeepCopy()
+					otherImport.Name = testNamespace + "-other"
+					otherImport.Spec.ServiceImportReference.Name = "other"
+					otherImport.Spec.ServiceImportReference.NamespacedName = testNamespace + "/other"
+					return otherImport
+				}(),
+			},
+			want: `Service:             work/app
+ServiceImport:       work/app
+Type:                ClusterSetIP
+ClusterSetIP:        <none>
+Ports:               http 80/TCP->8080
+Exporting clusters:  member-a
+Imported by:         member-b
+
+Exports:
+  CLUSTER   INTERNALSERVICEEXPORT           STATE       CONFLICT                 EXPORTED SINCE        MESSAGE
+  member-a  fleet-member-member-a/work-app  Accepted    False (NoConflictFound)  2024-01-01T00:00:00Z  -
+  member-b  fleet-member-member-b/work-app  Conflicted  True (ConflictFound)     2024-01-02T00:00:00Z  ports differ
+
+Imports:
+  CLUSTER   INTERNALSERVICEIMPORT           CLAIMED  IMPORTED SINCE        READY            MESSAGE
+  member-b  fleet-member-member-b/work-app  Yes      2024-01-03T00:00:00Z  True (Imported)  -
+
+EndpointSlices:
+  CLUSTER   ENDPOINTSLICEEXPORT              ENDPOINTS  DISTRIBUTED                 DISTRIBUTED TO
+  member-a  fleet-member-member-a/app-12345  2          False (DistributionFailed)  member-b (failed: namespace is terminating)
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			inspector, out := newInspector(tc.objs...)
+			if err := inspector.Status(context.Background(), testService); err != nil {
+				t.Fatalf("Status() = %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.want, out.String()); diff != "" {
+				t.Errorf("Status() output mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestExplainConflict tests the Inspector.ExplainConflict method.
+func TestExplainConflict(t *testing.T) {
+	resolvedSvcImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testServiceName,
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Type:     fleetnetv1alpha1.ClusterSetIP,
+			Ports:    []fleetnetv1alpha1.ServicePort{httpPort, httpsPort},
+			Clusters: []fleetnetv1alpha1.ClusterStatus{{Cluster: memberClusterA}},
+		},
+	}
+	otherTargetPort := httpPort
+	otherTargetPort.TargetPort = intstr.FromInt32(9090)
+
+	testCases := []struct {
+		name string
+		objs []client.Object
+		want string
+	}{
+		{
+			name: "service is not exported",
+			want: "Service work/app is not exported by any member cluster.\n",
+		},
+		{
+			name: "export differs from the resolved spec",
+			objs: []client.Object{
+				resolvedSvcImport,
+				internalServiceExport(hubNSForMemberA, memberClusterA, exportedSinceA, []fleetnetv1alpha1.ServicePort{httpPort, httpsPort},
+					conflictCondition(metav1.ConditionFalse, "NoConflictFound")),
+				internalServiceExport(hubNSForMemberB, memberClusterB, exportedSinceB, []fleetnetv1alpha1.ServicePort{otherTargetPort},
+					conflictCondition(metav1.ConditionTrue, "ConflictFound")),
+			},
+			want: `Comparing the exports of work/app with the spec resolved in ServiceImport work/app (ports: http 80/TCP->8080, https 443/TCP->8443).
+
+Member cluster member-a (conflict: False (NoConflictFound)):
+  matches the resolved spec
+
+Member cluster member-b (conflict: True (ConflictFound)):
+  - port 80/TCP: targetPort is 9090, want 8080
+  - port 443/TCP is not exported
+
+1 of 2 exports differ from the resolved spec.
+`,
+		},
+		{
+			name: "spec is not resolved yet",
+			objs: []client.Object{
+				internalServiceExport(hubNSForMemberA, memberClusterA, exportedSinceA, []fleetnetv1alpha1.ServicePort{httpPort, httpsPort}),
+				internalServiceExport(hubNSForMemberB, memberClusterB, exportedSinceB, []fleetnetv1alpha1.ServicePort{httpsPort, httpPort}),
+			},
+			want: `The spec of work/app has not been resolved yet; comparing the exports with the earliest one from member cluster member-a (ports: http 80/TCP->8080, https 443/TCP->8443).
+
+Member cluster member-a (conflict: <none>):
+  matches the resolved spec
+
+Member cluster member-b (conflict: <none>):
+  - ports are in a different order: https 443/TCP->8443, http 80/TCP->8080, want http 80/TCP->8080, https 443/TCP->8443
+
+1 of 2 exports differ from the resolved spec.
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			inspector, out := newInspector(tc.objs...)
+			if err := inspector.ExplainConflict(context.Background(), testService); err != nil {
+				t.Fatalf("ExplainConflict() = %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.want, out.String()); diff != "" {
+				t.Errorf("ExplainConflict() output mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestDiffExportedSpec tests the diffExportedSpec function.
+func TestDiffExportedSpec(t *testing.T) {
+	udpPort := httpPort
+	udpPort.Protocol = corev1.ProtocolUDP
+
+	testCases := []struct {
+		name     string
+		resolved fleetnetv1alpha1.InternalServiceExportSpec
+		exported fleetnetv1alpha1.InternalServiceExportSpec
+		want     []string
+	}{
+		{
+			name: "same spec with the session affinity defaulted",
+			resolved: fleetnetv1alpha1.InternalServiceExportSpec{
+				Ports:           []fleetnetv1alpha1.ServicePort{httpPort},
+				SessionAffinity: corev1.ServiceAffinityNone,
+			},
+			exported: fleetnetv1alpha1.InternalServiceExportSpec{
+				Ports: []fleetnetv1alpha1.ServicePort{httpPort},
+			},
+		},
+		{
+			name: "different protocol",
+			resolved: fleetnetv1alpha1.InternalServiceExportSpec{
+				Ports: []fleetnetv1alpha1.ServicePort{httpPort},
+			},
+			exported: fleetnetv1alpha1.InternalServiceExportSpec{
+				Ports: []fleetnetv1alpha1.ServicePort{udpPort},
+			},
+			want: []string{
+				"port 80/TCP is not exported",
+				"port 80/UDP is exported but not in the resolved spec",
+			},
+		},
+		{
+			name: "different session affinity, labels and annotations",
+			resolved: fleetnetv1alpha1.InternalServiceExportSpec{
+				Ports:           []fleetnetv1alpha1.ServicePort{httpPort},
+				SessionAffinity: corev1.ServiceAffinityClientIP,
+				SessionAffinityConfig: &corev1.SessionAffinityConfig{
+					ClientIP: &corev1.ClientIPConfig{TimeoutSeconds: ptr.To(int32(60))},
+				},
+				ExportedLabels:      map[string]string{"app": "web", "tier": "frontend"},
+				ExportedAnnotations: map[string]string{"owner": "team-a"},
+			},
+			exported: fleetnetv1alpha1.InternalServiceExportSpec{
+				Ports:          []fleetnetv1alpha1.ServicePort{httpPort},
+				ExportedLabels: map[string]string{"app": "api", "env": "prod"},
+			},
+			want: []string{
+				"sessionAffinity is None, want ClientIP",
+				"sessionAffinityConfig timeoutSeconds is <none>, want 60",
+				`exported label app is "api", want "web"`,
+				"exported label env is exported but not in the resolved spec",
+				"exported label tier is not exported",
+				"exported annotation owner is not exported",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := diffExportedSpec(&tc.resolved, &tc.exported)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("diffExportedSpec() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestEndpoints tests the Inspector.Endpoints method.
+func TestEndpoints(t *testing.T) {
+	endpointSliceExport := func(namespace, name, clusterID string, addresses ...string) *fleetnetv1alpha1.EndpointSliceExport {
+		endpoints := make([]fleetnetv1alpha1.Endpoint, 0, len(addresses))
+		for _, address := range addresses {
+			endpoints = append(endpoints, fleetnetv1alpha1.Endpoint{Addresses: []string{address}})
+		}
+		return &fleetnetv1alpha1.EndpointSliceExport{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      name,
+			},
+			Spec: fleetnetv1alpha1.EndpointSliceExportSpec{
+				AddressType: discoveryv1.AddressTypeIPv4,
+				Endpoints:   endpoints,
+				Ports: []discoveryv1.EndpointPort{
+					{Name: ptr.To("http"), Port: ptr.To(int32(8080)), Protocol: ptr.To(corev1.ProtocolTCP)},
+				},
+				EndpointSliceReference: fleetnetv1alpha1.ExportedObjectReference{
+					ClusterID: clusterID,
+					Kind:      "EndpointSlice",
+					Namespace: testNamespace,
+					Name:      name,
+				},
+				OwnerServiceReference: fleetnetv1alpha1.OwnerServiceReference{
+					Namespace:      testNamespace,
+					Name:           testServiceName,
+					NamespacedName: testService.String(),
+				},
+			},
+			Status: fleetnetv1alpha1.EndpointSliceExportStatus{
+				Conditions: []metav1.Condition{
+					{
+						Type:   string(fleetnetv1alpha1.EndpointSliceExportDistributed),
+						Status: metav1.ConditionTrue,
+						Reason: "Distributed",
+					},
+				},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name string
+		objs []client.Object
+		want string
+	}{
+		{
+			name: "no endpoints",
+			want: `
+Endpoints of work/app:
+  CLUSTER  ENDPOINTSLICE  ADDRESSES  PORTS  DISTRIBUTED
+  <none>
+
+Endpoints per cluster: <none>
+`,
+		},
+		{
+			name: "endpoints of multiple clusters",
+			objs: []client.Object{
+				endpointSliceExport(hubNSForMemberB, "app-fghij", memberClusterB, "10.0.1.1"),
+				endpointSliceExport(hubNSForMemberA, "app-abcde", memberClusterA, "10.0.0.1", "10.0.0.2"),
+				// The EndpointSliceExport of another service is not listed.
+				&fleetnetv1alpha1.EndpointSliceExport{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: hubNSForMemberA,
+						Name:      "other-12345",
+					},
+					Spec: fleetnetv1alpha1.EndpointSliceExportSpec{
+						Endpoints: []fleetnetv1alpha1.Endpoint{{Addresses: []string{"10.0.0.9"}}},
+						OwnerServiceReference: fleetnetv1alpha1.OwnerServiceReference{
+							Namespace:      testNamespace,
+							Name:           "other",
+							NamespacedName: testNamespace + "/other",
+						},
+					},
+				},
+			},
+			want: `
+Endpoints of work/app:
+  CLUSTER   ENDPOINTSLICE  ADDRESSES  PORTS          DISTRIBUTED
+  member-a  app-abcde      10.0.0.1   http 8080/TCP  True (Distributed)
+  member-a  app-abcde      10.0.0.2   http 8080/TCP  True (Distributed)
+  member-b  app-fghij      10.0.1.1   http 8080/TCP  True (Distributed)
+
+Endpoints per cluster: member-a: 2, member-b: 1
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			inspector, out := newInspector(tc.objs...)
+			if err := inspector.Endpoints(context.Background(), testService); err != nil {
+				t.Fatalf("Endpoints() = %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.want, out.String()); diff != "" {
+				t.Errorf("Endpoints() output mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestTrafficManagerStatus tests the Inspector.TrafficManagerStatus method.
+func TestTrafficManagerStatus(t *testing.T) {
+	profileName := types.NamespacedName{Namespace: testNamespace, Name: testProfileName}
+	profile := &fleetnetv1alpha1.TrafficManagerProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testProfileName,
+		},
+		Spec: fleetnetv1alpha1.TrafficManagerProfileSpec{
+			MonitorConfig: &fleetnetv1alpha1.MonitorConfig{
+				Protocol:          ptr.To(fleetnetv1alpha1.TrafficManagerMonitorProtocolHTTP),
+				Port:              ptr.To(int64(80)),
+				Path:              ptr.To("/healthz"),
+				IntervalInSeconds: ptr.To(int64(30)),
+			},
+		},
+		Status: fleetnetv1alpha1.TrafficManagerProfileStatus{
+			DNSName: ptr.To("work-profile.trafficmanager.net"),
+			Conditions: []metav1.Condition{
+				{
+					Type:   string(fleetnetv1alpha1.TrafficManagerProfileConditionProgrammed),
+					Status: metav1.ConditionTrue,
+					Reason: "Programmed",
+				},
+			},
+		},
+	}
+	backend := &fleetnetv1alpha1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testBackendName,
+		},
+		Spec: fleetnetv1alpha1.TrafficManagerBackendSpec{
+			Profile: fleetnetv1alpha1.TrafficManagerProfileRef{Name: testProfileName},
+			Backend: fleetnetv1alpha1.TrafficManagerBackendRef{Name: testServiceName},
+			Weight:  ptr.To(int64(100)),
+		},
+		Status: fleetnetv1alpha1.TrafficManagerBackendStatus{
+			Conditions: []metav1.Condition{
+				{
+					Type:   string(fleetnetv1alpha1.TrafficManagerBackendConditionAccepted),
+					Status: metav1.ConditionTrue,
+					Reason: "Accepted",
+				},
+			},
+			Endpoints: []fleetnetv1alpha1.TrafficManagerEndpointStatus{
+				{
+					Name:    "work#app#member-a",
+					Weight:  ptr.To(int64(50)),
+					Target:  ptr.To("app-a.eastus.cloudapp.azure.com"),
+					Cluster: &fleetnetv1alpha1.ClusterStatus{Cluster: memberClusterA},
+				},
+				{
+					Name:    "work#app#member-b",
+					Weight:  ptr.To(int64(50)),
+					Target:  ptr.To("app-b.westus.cloudapp.azure.com"),
+					Cluster: &fleetnetv1alpha1.ClusterStatus{Cluster: memberClusterB},
+				},
+			},
+		},
+	}
+	otherBackend := &fleetnetv1alpha1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      "other-backend",
+		},
+		Spec: fleetnetv1alpha1.TrafficManagerBackendSpec{
+			Profile: fleetnetv1alpha1.TrafficManagerProfileRef{Name: "other-profile"},
+			Backend: fleetnetv1alpha1.TrafficManagerBackendRef{Name: testServiceName},
+		},
+	}
+	svcImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testServiceName,
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			ClusterExports: []fleetnetv1alpha1.ClusterExportStatus{
+				{Cluster: memberClusterA, State: fleetnetv1alpha1.ClusterExportStateAccepted},
+				{Cluster: memberClusterB, State: fleetnetv1alpha1.ClusterExportStateStale},
+			},
+		},
+	}
+
+	t.Run("profile is not found", func(t *testing.T) {
+		inspector, _ := newInspector()
+		if err := inspector.TrafficManagerStatus(context.Background(), profileName); err == nil {
+			t.Fatal("TrafficManagerStatus() = nil, want error")
+		}
+	})
+
+	t.Run("profile with backends", func(t *testing.T) {
+		inspector, out := newInspector(profile, backend, otherBackend, svcImport)
+		if err := inspector.TrafficManagerStatus(context.Background(), profileName); err != nil {
+			t.Fatalf("TrafficManagerStatus() = %v, want no error", err)
+		}
+		want := `TrafficManagerProfile:  work/profile
+DNS name:               work-profile.trafficmanager.net
+Programmed:             True (Programmed)
+Message:                <none>
+Monitor:                HTTP :80/healthz every 30s
+
+Backends:
+  NAME     SERVICEIMPORT  WEIGHT  ACCEPTED         MESSAGE
+  backend  app            100     True (Accepted)  -
+
+Endpoints:
+  BACKEND  ENDPOINT           CLUSTER   TARGET                           WEIGHT  EXPORT STATE
+  backend  work#app#member-a  member-a  app-a.eastus.cloudapp.azure.com  50      Accepted
+  backend  work#app#member-b  member-b  app-b.westus.cloudapp.azure.com  50      Stale
+`
+		if diff := cmp.Diff(want, out.String()); diff != "" {
+			t.Errorf("TrafficManagerStatus() output mismatch (-want, +got):\n%s", diff)
+		}
+	})
+}
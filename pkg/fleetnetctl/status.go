@@ -0,0 +1,236 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package fleetnetctl
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+// Status writes the path of a service across the fleet: the ServiceImport resolved in the hub cluster, the exports
+// of the member clusters and whether they are in conflict, the imports of the member clusters and whether they are
+// claimed, and the exported EndpointSlices and the member clusters they are distributed to.
+func (i *Inspector) Status(ctx context.Context, svc types.NamespacedName) error {
+	svcImport, err := i.getServiceImport(ctx, svc)
+	if err != nil {
+		return err
+	}
+	exports, err := i.listExports(ctx, svc)
+	if err != nil {
+		return err
+	}
+	imports, err := i.listImports(ctx, svc)
+	if err != nil {
+		return err
+	}
+	endpointSliceExports, err := i.listEndpointSliceExports(ctx, svc)
+	if err != nil {
+		return err
+	}
+
+	fields := [][2]string{{"Service", svc.String()}}
+	if svcImport == nil {
+		fields = append(fields, [2]string{"ServiceImport", none})
+	} else {
+		exportingClusters := make([]string, 0, len(svcImport.Status.Clusters))
+		for _, cluster := range svcImport.Status.Clusters {
+			exportingClusters = append(exportingClusters, cluster.Cluster)
+		}
+		importingClusters := make([]string, 0, len(svcImport.Status.ImportedBy))
+		for _, importedBy := range svcImport.Status.ImportedBy {
+			importingClusters = append(importingClusters, string(importedBy.Cluster))
+		}
+		fields = append(fields,
+			[2]string{"ServiceImport", svc.String()},
+			[2]string{"Type", string(svcImport.Status.Type)},
+			[2]string{"ClusterSetIP", strings.Join(svcImport.Status.IPs, ", ")},
+			[2]string{"Ports", formatServicePorts(svcImport.Status.Ports)},
+			[2]string{"Exporting clusters", strings.Join(exportingClusters, ", ")},
+			[2]string{"Imported by", strings.Join(importingClusters, ", ")},
+		)
+	}
+	if err := i.writeFields(fields); err != nil {
+		return err
+	}
+
+	exportTable := i.newTable("Exports", "CLUSTER", "INTERNALSERVICEEXPORT", "STATE", "CONFLICT", "EXPORTED SINCE", "MESSAGE")
+	for idx := range exports {
+		export := &exports[idx]
+		state, message := "", conditionMessage(export.Status.Conditions, string(fleetnetv1alpha1.ServiceExportConflict))
+		if clusterExport := findClusterExport(svcImport, export.Spec.ServiceReference.ClusterID); clusterExport != nil {
+			state, message = string(clusterExport.State), clusterExport.Message
+		}
+		exportTable.addRow(export.Spec.ServiceReference.ClusterID, client.ObjectKeyFromObject(export).String(), state,
+			conditionSummary(export.Status.Conditions, string(fleetnetv1alpha1.ServiceExportConflict)),
+			formatTime(export.Spec.ServiceReference.ExportedSince), message)
+	}
+	if err := exportTable.flush(); err != nil {
+		return err
+	}
+
+	importTable := i.newTable("Imports", "CLUSTER", "INTERNALSERVICEIMPORT", "CLAIMED", "IMPORTED SINCE", "READY", "MESSAGE")
+	for idx := range imports {
+		internalSvcImport := &imports[idx]
+		claimed, importedSince := "No", ""
+		if importedBy := findClusterImport(svcImport, internalSvcImport.Namespace); importedBy != nil {
+			claimed, importedSince = "Yes", formatTime(importedBy.ImportedSince)
+		}
+		importTable.addRow(internalSvcImport.Spec.ServiceImportReference.ClusterID, client.ObjectKeyFromObject(internalSvcImport).String(),
+			claimed, importedSince,
+			conditionSummary(internalSvcImport.Status.Conditions, string(fleetnetv1alpha1.ServiceImportReady)),
+			conditionMessage(internalSvcImport.Status.Conditions, string(fleetnetv1alpha1.ServiceImportReady)))
+	}
+	if err := importTable.flush(); err != nil {
+		return err
+	}
+
+	endpointTable := i.newTable("EndpointSlices", "CLUSTER", "ENDPOINTSLICEEXPORT", "ENDPOINTS", "DISTRIBUTED", "DISTRIBUTED TO")
+	for idx := range endpointSliceExports {
+		endpointSliceExport := &endpointSliceExports[idx]
+		distributedTo := make([]string, 0, len(endpointSliceExport.Status.Distributions))
+		for _, distribution := range endpointSliceExport.Status.Distributions {
+			if distribution.Distributed {
+				distributedTo = append(distributedTo, string(distribution.Cluster))
+			} else {
+				distributedTo = append(distributedTo, fmt.Sprintf("%s (failed: %s)", distribution.Cluster, distribution.Message))
+			}
+		}
+		endpointTable.addRow(endpointSliceExport.Spec.EndpointSliceReference.ClusterID, client.ObjectKeyFromObject(endpointSliceExport).String(),
+			strconv.Itoa(len(endpointSliceExport.Spec.Endpoints)),
+			conditionSummary(endpointSliceExport.Status.Conditions, string(fleetnetv1alpha1.EndpointSliceExportDistributed)),
+			strings.Join(distributedTo, ", "))
+	}
+	return endpointTable.flush()
+}
+
+// getServiceImport returns the ServiceImport of a service, or nil if the service has not been exported yet.
+func (i *Inspector) getServiceImport(ctx context.Context, svc types.NamespacedName) (*fleetnetv1alpha1.ServiceImport, error) {
+	svcImport := &fleetnetv1alpha1.ServiceImport{}
+	if err := i.HubClient.Get(ctx, svc, svcImport); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get ServiceImport %s: %w", svc, err)
+	}
+	return svcImport, nil
+}
+
+// listExports returns the InternalServiceExports of a service, sorted by cluster.
+func (i *Inspector) listExports(ctx context.Context, svc types.NamespacedName) ([]fleetnetv1alpha1.InternalServiceExport, error) {
+	exportList := &fleetnetv1alpha1.InternalServiceExportList{}
+	// The InternalServiceExports are kept in the namespaces of the member clusters, and are listed from all the namespaces.
+	if err := i.HubClient.List(ctx, exportList); err != nil {
+		return nil, fmt.Errorf("failed to list InternalServiceExports of %s: %w", svc, err)
+	}
+	exportList.Items = slices.DeleteFunc(exportList.Items, func(obj fleetnetv1alpha1.InternalServiceExport) bool {
+		return obj.Spec.ServiceReference.NamespacedName != svc.String()
+	})
+	slices.SortFunc(exportList.Items, func(a, b fleetnetv1alpha1.InternalServiceExport) int {
+		return strings.Compare(a.Spec.ServiceReference.ClusterID, b.Spec.ServiceReference.ClusterID)
+	})
+	return exportList.Items, nil
+}
+
+// listImports returns the InternalServiceImports of a service, sorted by cluster.
+func (i *Inspector) listImports(ctx context.Context, svc types.NamespacedName) ([]fleetnetv1alpha1.InternalServiceImport, error) {
+	importList := &fleetnetv1alpha1.InternalServiceImportList{}
+	// The InternalServiceImports are kept in the namespaces of the member clusters, and are listed from all the namespaces.
+	if err := i.HubClient.List(ctx, importList); err != nil {
+		return nil, fmt.Errorf("failed to list InternalServiceImports of %s: %w", svc, err)
+	}
+	importList.Items = slices.DeleteFunc(importList.Items, func(obj fleetnetv1alpha1.InternalServiceImport) bool {
+		return obj.Spec.ServiceImportReference.NamespacedName != svc.String()
+	})
+	slices.SortFunc(importList.Items, func(a, b fleetnetv1alpha1.InternalServiceImport) int {
+		return strings.Compare(a.Spec.ServiceImportReference.ClusterID, b.Spec.ServiceImportReference.ClusterID)
+	})
+	return importList.Items, nil
+}
+
+// listEndpointSliceExports returns the EndpointSliceExports of a service, sorted by cluster and name.
+func (i *Inspector) listEndpointSliceExports(ctx context.Context, svc types.NamespacedName) ([]fleetnetv1alpha1.EndpointSliceExport, error) {
+	endpointSliceExportList := &fleetnetv1alpha1.EndpointSliceExportList{}
+	// The EndpointSliceExports are kept in the namespaces of the member clusters, and are listed from all the namespaces.
+	if err := i.HubClient.List(ctx, endpointSliceExportList); err != nil {
+		return nil, fmt.Errorf("failed to list EndpointSliceExports of %s: %w", svc, err)
+	}
+	endpointSliceExportList.Items = slices.DeleteFunc(endpointSliceExportList.Items, func(obj fleetnetv1alpha1.EndpointSliceExport) bool {
+		return obj.Spec.OwnerServiceReference.NamespacedName != svc.String()
+	})
+	slices.SortFunc(endpointSliceExportList.Items, func(a, b fleetnetv1alpha1.EndpointSliceExport) int {
+		if c := strings.Compare(a.Spec.EndpointSliceReference.ClusterID, b.Spec.EndpointSliceReference.ClusterID); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return endpointSliceExportList.Items, nil
+}
+
+// findClusterExport returns the export status of a cluster reported in the ServiceImport, if any.
+func findClusterExport(svcImport *fleetnetv1alpha1.ServiceImport, clusterID string) *fleetnetv1alpha1.ClusterExportStatus {
+	if svcImport == nil {
+		return nil
+	}
+	for idx := range svcImport.Status.ClusterExports {
+		if svcImport.Status.ClusterExports[idx].Cluster == clusterID {
+			return &svcImport.Status.ClusterExports[idx]
+		}
+	}
+	return nil
+}
+
+// findClusterImport returns the import of the cluster with the given namespace in the ServiceImport, if any.
+func findClusterImport(svcImport *fleetnetv1alpha1.ServiceImport, clusterNamespace string) *fleetnetv1alpha1.ClusterImportStatus {
+	if svcImport == nil {
+		return nil
+	}
+	for idx := range svcImport.Status.ImportedBy {
+		if string(svcImport.Status.ImportedBy[idx].ClusterNamespace) == clusterNamespace {
+			return &svcImport.Status.ImportedBy[idx]
+		}
+	}
+	return nil
+}
+
+// formatServicePorts formats the ports of a service, e.g. "http 80/TCP->8080".
+func formatServicePorts(ports []fleetnetv1alpha1.ServicePort) string {
+	formatted := make([]string, 0, len(ports))
+	for idx := range ports {
+		formatted = append(formatted, formatServicePort(&ports[idx]))
+	}
+	return strings.Join(formatted, ", ")
+}
+
+func formatServicePort(port *fleetnetv1alpha1.ServicePort) string {
+	var b strings.Builder
+	if port.Name != "" {
+		fmt.Fprintf(&b, "%s ", port.Name)
+	}
+	fmt.Fprintf(&b, "%s", servicePortKey(port))
+	if port.TargetPort.String() != "0" {
+		fmt.Fprintf(&b, "->%s", port.TargetPort.String())
+	}
+	return b.String()
+}
+
+// servicePortKey returns the port and the protocol of a port, e.g. "80/TCP", which identify the port.
+func servicePortKey(port *fleetnetv1alpha1.ServicePort) string {
+	protocol := port.Protocol
+	if protocol == "" {
+		protocol = corev1.ProtocolTCP
+	}
+	return fmt.Sprintf("%d/%s", port.Port, protocol)
+}
@@ -0,0 +1,122 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package fleetnetctl
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+// TrafficManagerStatus writes the state of a TrafficManagerProfile, the TrafficManagerBackends attached to it and
+// their Azure Traffic Manager endpoints.
+//
+// The hub cluster does not keep the monitor status of the Azure Traffic Manager endpoints; the health of an endpoint
+// is the state of the export behind it, e.g. an endpoint whose member cluster has stopped sending heartbeats is
+// backed by a Stale export.
+func (i *Inspector) TrafficManagerStatus(ctx context.Context, profileName types.NamespacedName) error {
+	profile := &fleetnetv1alpha1.TrafficManagerProfile{}
+	if err := i.HubClient.Get(ctx, profileName, profile); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("TrafficManagerProfile %s is not found", profileName)
+		}
+		return fmt.Errorf("failed to get TrafficManagerProfile %s: %w", profileName, err)
+	}
+	backendList := &fleetnetv1alpha1.TrafficManagerBackendList{}
+	if err := i.HubClient.List(ctx, backendList, client.InNamespace(profileName.Namespace)); err != nil {
+		return fmt.Errorf("failed to list TrafficManagerBackends of %s: %w", profileName, err)
+	}
+	backends := slices.DeleteFunc(backendList.Items, func(backend fleetnetv1alpha1.TrafficManagerBackend) bool {
+		return backend.Spec.Profile.Name != profileName.Name
+	})
+	slices.SortFunc(backends, func(a, b fleetnetv1alpha1.TrafficManagerBackend) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	if err := i.writeFields([][2]string{
+		{"TrafficManagerProfile", profileName.String()},
+		{"DNS name", ptr.Deref(profile.Status.DNSName, "")},
+		{"Programmed", conditionSummary(profile.Status.Conditions, string(fleetnetv1alpha1.TrafficManagerProfileConditionProgrammed))},
+		{"Message", conditionMessage(profile.Status.Conditions, string(fleetnetv1alpha1.TrafficManagerProfileConditionProgrammed))},
+		{"Monitor", formatMonitorConfig(profile.Spec.MonitorConfig)},
+	}); err != nil {
+		return err
+	}
+
+	backendTable := i.newTable("Backends", "NAME", "SERVICEIMPORT", "WEIGHT", "ACCEPTED", "MESSAGE")
+	for idx := range backends {
+		backend := &backends[idx]
+		backendTable.addRow(backend.Name, backend.Spec.Backend.Name, formatWeight(backend.Spec.Weight),
+			conditionSummary(backend.Status.Conditions, string(fleetnetv1alpha1.TrafficManagerBackendConditionAccepted)),
+			conditionMessage(backend.Status.Conditions, string(fleetnetv1alpha1.TrafficManagerBackendConditionAccepted)))
+	}
+	if err := backendTable.flush(); err != nil {
+		return err
+	}
+
+	endpointTable := i.newTable("Endpoints", "BACKEND", "ENDPOINT", "CLUSTER", "TARGET", "WEIGHT", "EXPORT STATE")
+	for idx := range backends {
+		backend := &backends[idx]
+		if len(backend.Status.Endpoints) == 0 {
+			continue
+		}
+		svcImport, err := i.getServiceImport(ctx, types.NamespacedName{Namespace: backend.Namespace, Name: backend.Spec.Backend.Name})
+		if err != nil {
+			return err
+		}
+		for _, endpoint := range backend.Status.Endpoints {
+			cluster, state := "", ""
+			if endpoint.Cluster != nil {
+				cluster = endpoint.Cluster.Cluster
+				if clusterExport := findClusterExport(svcImport, cluster); clusterExport != nil {
+					state = string(clusterExport.State)
+				}
+			}
+			endpointTable.addRow(backend.Name, endpoint.Name, cluster, ptr.Deref(endpoint.Target, ""), formatWeight(endpoint.Weight), state)
+		}
+	}
+	return endpointTable.flush()
+}
+
+// formatMonitorConfig formats the monitor settings of a profile, e.g. "HTTP :80/healthz every 30s".
+func formatMonitorConfig(config *fleetnetv1alpha1.MonitorConfig) string {
+	if config == nil {
+		return ""
+	}
+	var b strings.Builder
+	if config.Protocol != nil {
+		fmt.Fprintf(&b, "%s ", *config.Protocol)
+	}
+	if config.Port != nil {
+		fmt.Fprintf(&b, ":%d", *config.Port)
+	}
+	b.WriteString(ptr.Deref(config.Path, ""))
+	if config.IntervalInSeconds != nil {
+		fmt.Fprintf(&b, " every %ds", *config.IntervalInSeconds)
+	}
+	if config.TimeoutInSeconds != nil {
+		fmt.Fprintf(&b, ", timeout %ds", *config.TimeoutInSeconds)
+	}
+	if config.ToleratedNumberOfFailures != nil {
+		fmt.Fprintf(&b, ", %d tolerated failures", *config.ToleratedNumberOfFailures)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func formatWeight(weight *int64) string {
+	if weight == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d", *weight)
+}
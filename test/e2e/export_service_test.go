@@ -104,7 +104,8 @@ var _ = Describe("Test exporting service", func() {
 						Cluster: memberClusters[1].Name(),
 					},
 				},
-				Type: fleetnetv1alpha1.ClusterSetIP,
+				Type:            fleetnetv1alpha1.ClusterSetIP,
+				SessionAffinity: corev1.ServiceAffinityNone,
 				Ports: []fleetnetv1alpha1.ServicePort{
 					{
 						Port:       svcDef.Spec.Ports[0].Port,
@@ -113,7 +114,8 @@ var _ = Describe("Test exporting service", func() {
 					},
 				},
 			}
-			Expect(cmp.Diff(wantedSvcImportStatus, svcImportObj.Status)).Should(BeEmpty(), "Validate service import status mismatch (-want, +got):")
+			statusCmpOptions := cmpopts.IgnoreFields(fleetnetv1alpha1.ServiceImportStatus{}, "Conditions", "ClusterExports")
+			Expect(cmp.Diff(wantedSvcImportStatus, svcImportObj.Status, statusCmpOptions)).Should(BeEmpty(), "Validate service import status mismatch (-want, +got):")
 
 			By("Validating multi-cluster service request distribution")
 			requestURL := fmt.Sprintf("http://%s:%d", mcsLBAddr, svcDef.Spec.Ports[0].Port)